@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"core/internal/config"
+	"core/internal/repository"
+)
+
+// normalize-unit-types backfills listing_info.unit_type_normalized from the raw unit_type.
+// New and updated rows are normalized by the database trigger from sql/add_unit_type_normalized.sql.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Print the mapping without updating rows")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	repo, err := repository.NewPostgresRepository(
+		cfg.GetPostgreSQLDSN(),
+		cfg.PostgreSQL.MaxConnections,
+		cfg.PostgreSQL.MaxIdleConnections,
+		time.Duration(cfg.PostgreSQL.ConnMaxLifetime)*time.Second,
+		time.Duration(cfg.PostgreSQL.ConnMaxIdleTime)*time.Second,
+	)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	mappings, err := repo.ListUnitTypeMappings(ctx)
+	if err != nil {
+		log.Fatalf("Failed to list unit types: %v", err)
+	}
+
+	for _, m := range mappings {
+		if m.Normalized == "" {
+			log.Printf("⚠️  %-40q -> (unmapped)  [%d listings]", m.Raw, m.Count)
+			continue
+		}
+		log.Printf("   %-40q -> %-10s [%d listings]", m.Raw, m.Normalized, m.Count)
+	}
+
+	if *dryRun {
+		log.Println("Dry run, no rows updated")
+		return
+	}
+
+	updated, err := repo.BackfillUnitTypeNormalized(ctx, mappings)
+	if err != nil {
+		log.Fatalf("Backfill failed after %d rows: %v", updated, err)
+	}
+	log.Printf("✅ Normalized unit_type for %d listings", updated)
+}
@@ -18,7 +18,7 @@ import (
 var webDist embed.FS
 
 // setupStaticFiles configures the static file serving with embedded frontend
-func setupStaticFiles(router *gin.Engine) {
+func setupStaticFiles(router *gin.Engine, apiBasePath string) {
 	log.Println("📦 Using embedded frontend assets")
 
 	// Get the sub-filesystem for dist directory
@@ -32,7 +32,7 @@ func setupStaticFiles(router *gin.Engine) {
 		urlPath := c.Request.URL.Path
 
 		// Skip API routes (they are handled by other routes)
-		if len(urlPath) >= 4 && urlPath[:4] == "/api" {
+		if isAPIPath(urlPath, apiBasePath) {
 			c.JSON(404, gin.H{"error": "API endpoint not found"})
 			return
 		}
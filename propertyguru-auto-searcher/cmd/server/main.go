@@ -2,20 +2,27 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"core/internal/config"
 	"core/internal/handler"
+	"core/internal/middleware"
 	"core/internal/repository"
 	"core/internal/service"
+	"core/internal/utils"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 )
 
 var (
@@ -24,6 +31,86 @@ var (
 	GitCommit = "unknown"
 )
 
+// warmupAI primes the AI provider connection; failures are logged and otherwise ignored
+func warmupAI(client *service.OpenAIClient) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if err := client.Warmup(ctx); err != nil {
+		log.Printf("⚠️  AI connection warmup failed: %v", err)
+		return
+	}
+	log.Printf("✅ AI connection warmed up in %v", time.Since(start).Round(time.Millisecond))
+}
+
+// configureFullText decides whether searches rank keywords with ts_rank. In "auto" mode a
+// schema without listing_info.search_vector falls back to filter-only, recency-ordered search.
+func configureFullText(repo *repository.PostgresRepository, mode string) {
+	if mode == "off" {
+		repo.SetFullText(false)
+		log.Println("⚠️  Full-text ranking disabled (SEARCH_FULLTEXT=off); searches are filter-only, newest first")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	hasColumn, err := repo.HasColumn(ctx, "listing_info", "search_vector")
+	if err != nil {
+		// Keep the previous behavior rather than guess from a failed check
+		log.Printf("⚠️  Could not check for listing_info.search_vector, assuming it exists: %v", err)
+		return
+	}
+	if hasColumn {
+		log.Println("✅ Full-text search enabled (listing_info.search_vector)")
+		return
+	}
+	if mode == "on" {
+		log.Fatal("listing_info.search_vector is missing but SEARCH_FULLTEXT=on; add the column or set SEARCH_FULLTEXT=auto")
+	}
+	repo.SetFullText(false)
+	log.Println("⚠️  listing_info.search_vector not found: full-text ranking disabled, searches are filter-only, newest first")
+	log.Println("   Add the search_vector column (see sql/init_postgresql_unified.sql) and restart to enable it")
+}
+
+// newRedisIntentCache connects the intent cache to REDIS_URL. It returns nil when Redis isn't
+// configured or can't be reached at startup, so the server keeps the in-memory cache instead.
+func newRedisIntentCache(cfg *config.CacheConfig) service.IntentCache {
+	if cfg.RedisURL == "" {
+		return nil
+	}
+	opts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		log.Printf("⚠️  Invalid REDIS_URL, using the in-memory intent cache: %v", utils.RedactSecrets(err.Error(), cfg.RedisURL))
+		return nil
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Printf("⚠️  Redis unreachable at %s, using the in-memory intent cache: %v", utils.RedactURL(cfg.RedisURL), err)
+		_ = client.Close()
+		return nil
+	}
+	log.Printf("✅ Intent cache enabled in Redis at %s (prefix %q, TTL %ds)", utils.RedactURL(cfg.RedisURL), cfg.IntentRedisPrefix, cfg.IntentTTL)
+	return service.NewRedisIntentCache(client, cfg.IntentRedisPrefix, time.Duration(cfg.IntentTTL)*time.Second)
+}
+
+// isAPIPath reports whether an unmatched request path belongs to the API, so NoRoute answers
+// it with a JSON 404 instead of the frontend
+func isAPIPath(path, apiBasePath string) bool {
+	if apiBasePath != "" {
+		return path == apiBasePath || strings.HasPrefix(path, apiBasePath+"/")
+	}
+	for _, version := range []string{middleware.APIv1, middleware.APIv2} {
+		if strings.HasPrefix(path, "/"+version+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 // performAIHealthCheck performs a basic health check on the AI service
 func performAIHealthCheck(client *service.OpenAIClient, model string) error {
 	if client == nil || !client.IsEnabled() {
@@ -70,35 +157,74 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Structured logging; slog.SetDefault also routes the standard log package through it
+	logger, err := utils.NewLogger(os.Stderr, cfg.Logging.Level, cfg.Logging.Format)
+	if err != nil {
+		log.Fatalf("Failed to configure logging: %v", err)
+	}
+	slog.SetDefault(logger)
+
 	// Set Gin mode
 	gin.SetMode(cfg.Server.GinMode)
 
+	// Mask configured secret keys in logged bodies, before anything is logged
+	utils.AddRedactedKeys(cfg.Logging.RedactKeys)
+
 	// Initialize database connection
+	log.Printf("🔌 Connecting to PostgreSQL: %s", utils.RedactDSN(cfg.GetPostgreSQLDSN()))
 	repo, err := repository.NewPostgresRepository(
 		cfg.GetPostgreSQLDSN(),
 		cfg.PostgreSQL.MaxConnections,
 		cfg.PostgreSQL.MaxIdleConnections,
+		time.Duration(cfg.PostgreSQL.ConnMaxLifetime)*time.Second,
+		time.Duration(cfg.PostgreSQL.ConnMaxIdleTime)*time.Second,
 	)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Fatalf("Failed to connect to database: %s", utils.RedactSecrets(err.Error(), cfg.PostgreSQL.Password))
 	}
 	defer repo.Close()
 
 	log.Println("✅ Connected to PostgreSQL database")
 
+	configureFullText(repo, cfg.Search.FullTextMode)
+
+	if cfg.PostgreSQL.PoolMonitorSeconds > 0 {
+		go repo.MonitorPool(context.Background(), time.Duration(cfg.PostgreSQL.PoolMonitorSeconds)*time.Second)
+	}
+
 	// Initialize OpenAI client
 	var openaiClient *service.OpenAIClient
 	if cfg.OpenAI.Enabled {
 		openaiClient = service.NewOpenAIClient(&cfg.OpenAI)
 		log.Printf("✅ OpenAI client initialized")
-		log.Printf("   - API Base: %s", cfg.OpenAI.APIBase)
+		log.Printf("   - API Base: %s", utils.RedactURL(cfg.OpenAI.APIBase))
 		log.Printf("   - Chat model: %s", cfg.OpenAI.ChatModel)
 		log.Printf("   - Embedding model: %s", cfg.OpenAI.EmbeddingModel)
+		log.Printf("   - Allowed embedding models: %s", strings.Join(openaiClient.AllowedEmbeddingModels(), ", "))
 		log.Printf("   - Chat Temperature: %.2f", cfg.OpenAI.ChatTemperature)
 		log.Printf("   - Chat TopP: %.2f", cfg.OpenAI.ChatTopP)
 		log.Printf("   - Chat MaxTokens: %d", cfg.OpenAI.ChatMaxTokens)
-		log.Printf("   - Chat ExtraBody: %s", cfg.OpenAI.ChatExtraBody)
-		log.Printf("   - Embedding ExtraBody: %s", cfg.OpenAI.EmbeddingExtraBody)
+		log.Printf("   - Chat ExtraBody: %s", utils.RedactJSON([]byte(cfg.OpenAI.ChatExtraBody), cfg.OpenAI.APIKey))
+		if cfg.OpenAI.FewShotFile != "" {
+			examples, err := service.LoadFewShotExamples(cfg.OpenAI.FewShotFile)
+			if err != nil {
+				log.Fatalf("Failed to load few-shot examples: %v", err)
+			}
+			openaiClient.SetFewShotExamples(examples)
+			log.Printf("   - Few-shot examples: %d from %s", len(examples), cfg.OpenAI.FewShotFile)
+		}
+		if cfg.OpenAI.IntentPromptPath != "" {
+			prompt, err := service.LoadIntentPrompt(cfg.OpenAI.IntentPromptPath)
+			if err != nil {
+				log.Fatalf("Failed to load intent prompt: %v", err)
+			}
+			openaiClient.SetIntentPrompt(prompt)
+			log.Printf("   - Intent prompt: %d chars from %s", len(prompt), cfg.OpenAI.IntentPromptPath)
+		}
+		log.Printf("   - Embedding ExtraBody: %s", utils.RedactJSON([]byte(cfg.OpenAI.EmbeddingExtraBody), cfg.OpenAI.APIKey))
+		if cfg.OpenAI.TokenBudget > 0 {
+			log.Printf("   - Token budget: %d tokens / %ds", cfg.OpenAI.TokenBudget, cfg.OpenAI.TokenBudgetWindow)
+		}
 
 		// Perform health check on AI service
 		if err := performAIHealthCheck(openaiClient, cfg.OpenAI.ChatModel); err != nil {
@@ -112,42 +238,68 @@ func main() {
 		log.Println("   Set OPENAI_API_KEY environment variable to enable AI features")
 	}
 
+	// Load custom area table for location matching
+	if cfg.Search.AreaTablePath != "" {
+		count, err := utils.LoadAreaTable(cfg.Search.AreaTablePath)
+		if err != nil {
+			log.Printf("⚠️  Failed to load area table, using built-in areas: %v", err)
+		} else {
+			log.Printf("✅ Loaded %d areas from %s", count, cfg.Search.AreaTablePath)
+		}
+	}
+
 	// Initialize services
-	intentParser := service.NewIntentParser(openaiClient)
-	ranker := service.NewRanker(
-		cfg.Ranking.WeightText,
-		cfg.Ranking.WeightPrice,
-		cfg.Ranking.WeightRecency,
-	)
-	searchService := service.NewSearchService(repo, intentParser, ranker)
+	var intentCache service.IntentCache
+	if cfg.Cache.IntentTTL > 0 {
+		intentCache = newRedisIntentCache(&cfg.Cache)
+		if intentCache == nil {
+			intentCache = service.NewMemoryIntentCache(cfg.Cache.IntentMaxEntries, time.Duration(cfg.Cache.IntentTTL)*time.Second)
+			log.Printf("✅ Intent cache enabled (max %d entries, TTL %ds)", cfg.Cache.IntentMaxEntries, cfg.Cache.IntentTTL)
+		}
+	}
+	intentParser := service.NewIntentParser(openaiClient, intentCache, cfg.Cache.IntentSchemaMismatch)
+	ranker := service.NewRanker(&cfg.Ranking)
+	searchService := service.NewSearchService(repo, intentParser, ranker, &cfg.Search)
+	embeddingService := service.NewEmbeddingService(repo, openaiClient, &cfg.Embedding)
 
 	log.Println("✅ Services initialized")
 
 	// Initialize handlers
-	searchHandler := handler.NewSearchHandler(searchService, cfg.Search.DefaultLimit, cfg.Search.MaxLimit)
-	embeddingHandler := handler.NewEmbeddingHandler(searchService)
+	searchHandler := handler.NewSearchHandler(searchService, &cfg.Search, cfg.OpenAI.EmbeddingDimensions, cfg.Embedding.ChunkAggregate)
+	embeddingHandler := handler.NewEmbeddingHandler(searchService, openaiClient, cfg.OpenAI.EmbeddingDimensions)
 	feedbackHandler := handler.NewFeedbackHandler(searchService)
+	metricsHandler := handler.NewMetricsHandler(openaiClient, searchService)
+	adminHandler := handler.NewAdminHandler(intentParser, searchService, embeddingService, openaiClient, cfg.Admin.StaleListingHours)
+	schemaHandler := handler.NewSchemaHandler(&cfg.Search)
+	healthHandler := handler.NewHealthHandler(repo, openaiClient, func() gin.H {
+		return gin.H{
+			"service":      "property-search-engine",
+			"version":      Version,
+			"build_time":   BuildTime,
+			"git_commit":   GitCommit,
+			"capabilities": repo.Capabilities(),
+		}
+	})
 
 	// Setup Gin router
-	router := gin.Default()
+	router := gin.New()
+	// Only configured proxies may set the client IP via X-Forwarded-For; otherwise any
+	// client could pick a fresh rate limit bucket per request
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	router.Use(gin.Recovery(), middleware.RequestLogger(logger))
 
 	// CORS configuration
 	corsConfig := cors.DefaultConfig()
 	corsConfig.AllowOrigins = []string{cfg.Server.AllowedOrigins}
 	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	corsConfig.AllowHeaders = []string{"Content-Type", "Authorization"}
+	corsConfig.AllowHeaders = []string{"Content-Type", "Authorization", "X-API-Key"}
 	router.Use(cors.New(corsConfig))
 
-	// Health check endpoint
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status":     "healthy",
-			"service":    "property-search-engine",
-			"version":    Version,
-			"build_time": BuildTime,
-			"git_commit": GitCommit,
-		})
-	})
+	// Health check endpoint (503 when the database is unreachable; ?deep=true also checks the AI provider)
+	router.GET("/health", healthHandler.Get)
+	router.GET("/health/db", healthHandler.GetDatabase) // Ping plus connection pool stats
 
 	// Version endpoint
 	router.GET("/version", func(c *gin.Context) {
@@ -158,35 +310,77 @@ func main() {
 		})
 	})
 
-	// API routes
-	apiV1 := router.Group("/api/v1")
-	{
+	// Metrics endpoint
+	router.GET("/metrics", metricsHandler.Get)
+
+	// API routes. v1 and v2 share handlers until a v2 response shape diverges; v1 stays
+	// stable for current consumers.
+	session := middleware.SessionCookie(cfg.Search.SessionCookieName, time.Duration(cfg.Search.RecentSearchDays)*24*time.Hour)
+	registerAPIRoutes := func(api *gin.RouterGroup) {
 		// Search endpoints
-		apiV1.POST("/search", searchHandler.Search)
-		apiV1.POST("/search/results", searchHandler.SearchResults) // Paginated search results
-		apiV1.POST("/search/stream", searchHandler.SearchStream) // Streaming search
-		apiV1.GET("/listings/:id", searchHandler.GetListing)
+		api.POST("/search", session, searchHandler.Search)
+		api.POST("/search/results", searchHandler.SearchResults) // Paginated search results
+		api.POST("/search/stream", session, searchHandler.SearchStream) // Streaming search
+		api.POST("/search/vector", searchHandler.VectorSearch) // Search by a client-computed query embedding
+		api.GET("/listings/:id", searchHandler.GetListing)
+		api.GET("/listings/:id/similar", searchHandler.SimilarListings) // "More like this" by embedding
+		api.DELETE("/listings/:id", middleware.APIKeyAuth(cfg.Admin.APIKey), adminHandler.DeleteListing) // Takedowns and test data cleanup
+		api.GET("/suggestions", searchHandler.Suggestions) // Example queries for an empty search page
+		api.GET("/price-anchor", searchHandler.PriceAnchor) // Price quartiles for a location / unit type / bedrooms segment
+		api.GET("/green-scores", searchHandler.GreenScores) // Average green score per location
+		api.GET("/searches/recent", session, searchHandler.RecentSearches) // This session's recent queries
+		api.DELETE("/searches/recent", session, searchHandler.ForgetRecentSearches)
 
 		// Embedding endpoints
-		apiV1.POST("/embeddings/batch", embeddingHandler.BatchUpdate)
+		api.POST("/embeddings/batch", embeddingHandler.BatchUpdate)
+		api.POST("/embeddings/generate", middleware.APIKeyAuth(cfg.Admin.APIKey), embeddingHandler.Generate) // Embed texts with the configured provider; spends provider quota
 
 		// Feedback endpoint
-		apiV1.POST("/feedback", feedbackHandler.Submit)
+		api.POST("/feedback", feedbackHandler.Submit)
+
+		// API schema (filter fields, enums, limits)
+		api.GET("/schema", schemaHandler.Get)
+
+		// Admin endpoints (require ADMIN_API_KEY)
+		admin := api.Group("/admin", middleware.APIKeyAuth(cfg.Admin.APIKey))
+		{
+			admin.GET("/intent-cache", adminHandler.IntentCacheStats)
+			admin.DELETE("/intent-cache", adminHandler.ClearIntentCache)
+			admin.POST("/intent-cache/warm", adminHandler.WarmIntentCache)
+			admin.POST("/listings/purge-stale", adminHandler.PurgeStaleListings)
+			admin.POST("/listings/export", adminHandler.ExportListings) // CSV, streamed from the database
+			admin.POST("/embeddings/backfill", adminHandler.BackfillEmbeddings)
+			admin.POST("/parse-test", adminHandler.ParseTest)
+		}
 	}
 
+	// Rate limiting covers only the API; /health, /version, /metrics and the web UI are exempt
+	apiBase := router.Group(cfg.Server.APIBasePath,
+		middleware.RateLimit(cfg.Server.RateLimitRPS, cfg.Server.RateLimitBurst),
+		middleware.MaxBodySize(int64(cfg.Server.MaxBodyBytes)))
+	middleware.RegisterVersions(apiBase, registerAPIRoutes)
+
 	// Serve static files (frontend)
 	// This function is implemented in embed.go (production) or static_dev.go (development)
-	setupStaticFiles(router)
+	setupStaticFiles(router, cfg.Server.APIBasePath)
 
 	// Start server
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 	log.Printf("🚀 Starting server on %s", addr)
-	log.Printf("📝 API Documentation: http://localhost:%d/api/v1", cfg.Server.Port)
+	log.Printf("📝 API Documentation: http://localhost:%d%s/v1 (v2 at %s/v2)", cfg.Server.Port, cfg.Server.APIBasePath, cfg.Server.APIBasePath)
 	log.Printf("🌐 Web UI: http://localhost:%d", cfg.Server.Port)
 
-	// Graceful shutdown
+	// Prime the LLM connection so the first search doesn't pay DNS + TLS setup
+	if openaiClient != nil && cfg.OpenAI.Warmup {
+		go warmupAI(openaiClient)
+	}
+
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: router,
+	}
 	go func() {
-		if err := router.Run(addr); err != nil {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("Failed to start server: %v", err)
 		}
 	}()
@@ -196,6 +390,14 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	// Graceful shutdown: stop accepting connections and let in-flight requests, including
+	// SSE streams, finish. The deferred repo.Close runs only after this returns.
 	log.Println("🛑 Shutting down server...")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("⚠️  Requests still running after %ds, closing their connections: %v", cfg.Server.ShutdownTimeout, err)
+		srv.Close()
+	}
 	log.Println("✅ Server stopped")
 }
@@ -10,7 +10,7 @@ import (
 )
 
 // setupStaticFiles configures static file serving for development (no embedding)
-func setupStaticFiles(router *gin.Engine) {
+func setupStaticFiles(router *gin.Engine, apiBasePath string) {
 	log.Println("🔧 Using local filesystem for frontend assets (development mode)")
 	log.Println("   Frontend should be served separately with: cd web && npm run dev")
 
@@ -21,7 +21,7 @@ func setupStaticFiles(router *gin.Engine) {
 
 	// For new React frontend, redirect to dev server
 	router.NoRoute(func(c *gin.Context) {
-		if len(c.Request.URL.Path) > 4 && c.Request.URL.Path[:4] == "/api" {
+		if isAPIPath(c.Request.URL.Path, apiBasePath) {
 			c.JSON(404, gin.H{"error": "API endpoint not found"})
 			return
 		}
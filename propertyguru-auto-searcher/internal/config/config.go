@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -17,6 +18,9 @@ type Config struct {
 	Ranking    RankingConfig
 	Logging    LoggingConfig
 	OpenAI     OpenAIConfig
+	Admin      AdminConfig
+	Cache      CacheConfig
+	Embedding  EmbeddingConfig
 }
 
 // PostgreSQLConfig holds PostgreSQL database configuration
@@ -30,6 +34,9 @@ type PostgreSQLConfig struct {
 	SSLMode            string
 	MaxConnections     int
 	MaxIdleConnections int
+	ConnMaxLifetime    int // Seconds before a connection is recycled
+	ConnMaxIdleTime    int // Seconds an idle connection is kept open
+	PoolMonitorSeconds int // Interval for logging pool wait growth (0 = disabled)
 }
 
 // ServerConfig holds server configuration
@@ -40,43 +47,158 @@ type ServerConfig struct {
 	AllowedOrigins string
 	AllowedMethods string
 	AllowedHeaders string
+	MaxBodyBytes   int    // Largest request body accepted under the API base path (larger bodies get 413)
+	APIBasePath    string // Prefix of the versioned API groups: "/api" serves /api/v1 and /api/v2
+
+	ShutdownTimeout int // Seconds in-flight requests get to finish on SIGINT/SIGTERM before being cut off
+
+	RateLimitRPS   float64 // Sustained API requests per second per client IP (0 = no rate limit)
+	RateLimitBurst int     // Requests a client IP may make in a burst before RateLimitRPS applies
+
+	// TrustedProxies are the proxy IPs/CIDRs whose X-Forwarded-For is believed when resolving
+	// the client IP; empty trusts none, so the client IP is the connection's remote address
+	TrustedProxies []string
 }
 
 // SearchConfig holds search-related configuration
 type SearchConfig struct {
-	DefaultLimit  int
-	MaxLimit      int
-	DefaultOffset int
+	DefaultLimit           int
+	MaxLimit               int
+	DefaultOffset          int
+	MaxOffset              int               // Deepest offset a request may page to (0 = unlimited)
+	SortNulls              map[string]string // Sortable column -> "first" or "last" (e.g. price=last,price_per_sqft=last)
+	StreamReplayTTL        int               // Seconds a finished stream stays replayable via Last-Event-ID (0 = disabled)
+	StreamReplayMaxEntries int               // Max streams kept for replay; the oldest are evicted first (0 = unlimited)
+	ResultTTL              int               // Seconds a response is considered fresh (reported as expires_at)
+	StaleAfterHours        int               // Listing data older than this is flagged stale in data_freshness
+	AreaTablePath          string            // Optional JSON file replacing the built-in canonical area table
+	MinResults             int               // Fewer primary results than this triggers a relaxed "broader matches" search (0 = disabled)
+	RelaxPriceRatio        float64           // Fraction the price range is widened by in the relaxed search
+	ShareBaseURL           string            // Base URL of the web app for per-result share links (empty = no share_url)
+	NoKeywordSort          string            // Sort used for relevance requests with no keywords (ts_rank is skipped)
+	SeenLookbackDays       int               // How far back exclude_seen looks in the session's feedback
+	SeenMaxIDs             int               // Max listings exclude_seen leaves out, most recent first
+	SuggestionMinCount     int               // Min clicked searches before a query is suggested (filters out rare queries)
+	SuggestionLookbackDays int               // How far back suggestions look in search_logs
+	SuggestionCacheTTL     int               // Seconds suggestions are cached (0 = query every request)
+	PriceAnchorCacheTTL    int               // Seconds a price-anchor segment summary is cached (0 = no cache)
+	PriceAnchorMaxEntries  int               // Max cached segment summaries; the least recently used are evicted (0 = unlimited)
+	PriceAnchorMinListings int               // Segments with fewer priced listings are flagged low_confidence
+	GreenScoreMinListings  int               // Locations with fewer green-scored listings are left out of green score averages
+	GreenScoreCacheTTL     int               // Seconds the per-location green score aggregate is cached (0 = query every request)
+	RankWindow             int               // Relevance candidates ranked together so pages within it never overlap (0 = rank per page)
+	ImagesPath             string            // property_details key path holding listing image URLs (e.g. "media.images")
+	PrimaryImagePath       string            // property_details key path of the cover image (empty = first flagged or first image)
+	SessionCookieName      string            // Anonymous session cookie linking a browser's searches (empty = no cookie)
+	RecentSearchDays       int               // Recent searches look back this far; also the session cookie lifetime
+	FullTextMode           string            // "auto" (use search_vector if the column exists), "on" (require it), or "off"
+	LocaleFromHeader       bool              // Use Accept-Language for number formats when a search has no locale
+
+	MortgageInterestRate       float64 // Default annual rate (percent) for options.mortgage estimates
+	MortgageTenureYears        int     // Default loan term in years
+	MortgageDownPaymentPercent float64 // Default share of the price paid upfront (percent)
+
+	MaxInFlight    int    // Concurrent searches (LLM + database work) allowed at once (0 = unlimited)
+	OverloadMode   string // Searches beyond MaxInFlight: "queue" (wait) or "reject" (429 immediately)
+	QueueTimeoutMs int    // Longest a queued search waits for a slot before a 429 (0 = until the client gives up)
 }
 
 // RankingConfig holds ranking weights configuration
 type RankingConfig struct {
-	WeightText    float64
-	WeightPrice   float64
-	WeightRecency float64
+	WeightText          float64
+	WeightPrice         float64
+	WeightRecency       float64
+	WeightTitle         float64 // Boost when search keywords appear in the listing title
+	WeightAmenities     float64 // Boost for matching more requested amenities/facilities (match_mode "any")
+	WeightCompleteness  float64 // Boost for listings with photos, a full description, coordinates, ...
+	WeightValue         float64 // Boost for a low price per sqft when the search is budget-conscious
+	MaxReasons          int     // Max matched_reasons per result, most informative first (0 = unlimited)
+	VectorRecencyWeight float64 // Recency share of pure vector search scores; the rest is similarity
+	ReasonTextScore     float64 // Text score above which "Content relevant" is shown
+	ReasonPriceScore    float64 // Price score above which "Price within budget" is shown
+	ReasonNewListedDays int     // Listings younger than this many days are "Newly listed"
+	ReasonGreenScore    float64 // Green score at or above which "High green score" is shown
 }
 
 // LoggingConfig holds logging configuration
 type LoggingConfig struct {
-	Level  string
-	Format string
+	Level      string
+	Format     string
+	RedactKeys []string // Extra JSON keys/query parameters masked in logs, on top of api_key, password, token, ...
 }
 
 // OpenAIConfig holds OpenAI API configuration
 type OpenAIConfig struct {
 	APIKey              string
 	APIBase             string
+	Provider            string // Stream format: "openai", "nvidia" or "ollama" (empty = detect from APIBase)
+	ExtractThinkTags    bool   // Move inline <think>...</think> out of OpenAI-format content into thinking
 	ChatModel           string // Model for chat/intent parsing
 	ChatTemperature     float64
 	ChatTopP            float64
 	ChatMaxTokens       int
 	ChatExtraBody       string // JSON string for extra_body (e.g., {"chat_template_kwargs":{"thinking":true}})
+	FewShotFile         string // JSON file of query -> intent examples for the intent prompt (empty = built-in examples)
+	IntentPromptPath    string // Text file replacing the built-in intent system prompt (empty or missing = built-in)
 	EmbeddingModel      string // Model for embeddings
 	EmbeddingDimensions int
-	EmbeddingExtraBody  string // JSON string for extra_body (e.g., {"truncate":"NONE"})
-	BatchSize           int
-	Timeout             int
-	Enabled             bool
+	EmbeddingExtraBody  string         // JSON string for extra_body (e.g., {"truncate":"NONE"})
+	EmbeddingModels     map[string]int // Allowed embedding model -> expected dimensions (always includes EmbeddingModel)
+
+	// Secondary embedding provider used after EmbeddingPrimaryAttempts failures on a batch
+	EmbeddingPrimaryAttempts            int
+	EmbeddingFallbackAPIBase            string // Empty = no fallback
+	EmbeddingFallbackAPIKey             string // Defaults to APIKey
+	EmbeddingFallbackModel              string // Defaults to EmbeddingModel
+	EmbeddingFallbackDimensions         int    // Defaults to EmbeddingDimensions; must match it
+	EmbeddingFallbackAllowModelMismatch bool   // Allow a different fallback model that shares the primary's vector space
+
+	// Provider limits per embeddings request, enforced whatever BatchSize is set to
+	EmbeddingMaxInputs     int // Max inputs in one request; larger batches are split (0 = unlimited)
+	EmbeddingMaxInputChars int // Max characters in a single input; longer inputs are rejected (0 = unchecked)
+	EmbeddingCacheSize     int // Max embeddings kept in the in-memory LRU, keyed by model and text (0 = no cache)
+
+	BatchSize         int
+	Timeout           int  // Default per-call deadline in seconds for chat and embedding requests
+	TokenBudget       int  // Max LLM tokens per budget window (0 = unlimited)
+	TokenBudgetWindow int  // Budget window in seconds
+	Warmup            bool // Prime DNS/TLS to the provider in the background at startup
+	Enabled           bool
+
+	// Per-call deadlines in seconds; 0 falls back to Timeout. Streams with reasoning
+	// models can think for minutes, so they get a much longer deadline by default.
+	ChatTimeout      int
+	StreamTimeout    int
+	EmbeddingTimeout int
+}
+
+// AdminConfig holds admin API configuration
+type AdminConfig struct {
+	APIKey            string // Required for /api/v1/admin routes (empty = admin API disabled)
+	StaleListingHours int    // Default age (relative to the latest crawl) after which a listing is purged as stale
+}
+
+// EmbeddingConfig holds listing embedding configuration
+type EmbeddingConfig struct {
+	ChunkSize      int    // Max characters per description chunk (0 = no chunking)
+	ChunkOverlap   int    // Characters repeated between consecutive chunks
+	ChunkAggregate string // How chunk distances combine into a listing score: "max" (nearest chunk) or "mean"
+	BackfillBatch  int    // Listings embedded and committed together during backfill
+}
+
+// CacheConfig holds caching configuration
+type CacheConfig struct {
+	IntentTTL        int // Seconds a parsed intent stays cached (0 = caching disabled)
+	IntentMaxEntries int // Max cached intents for the in-memory cache
+
+	// IntentSchemaMismatch is what happens to a cached intent from an older schema version:
+	// "upgrade" migrates it in place, "reparse" treats it as a miss so the AI fills new fields
+	IntentSchemaMismatch string
+
+	// RedisURL (redis://[:password@]host:port/db) moves the intent cache into Redis so every
+	// instance shares it; empty keeps the in-memory cache
+	RedisURL          string
+	IntentRedisPrefix string // Key prefix for cached intents in Redis
 }
 
 // Load reads configuration from environment variables
@@ -96,6 +218,9 @@ func Load() (*Config, error) {
 			SSLMode:            getEnv("PG_SSLMODE", "disable"),
 			MaxConnections:     getEnvAsInt("PG_MAX_CONNECTIONS", 25),
 			MaxIdleConnections: getEnvAsInt("PG_MAX_IDLE_CONNECTIONS", 5),
+			ConnMaxLifetime:    getEnvAsInt("PG_CONN_MAX_LIFETIME", 300),
+			ConnMaxIdleTime:    getEnvAsInt("PG_CONN_MAX_IDLE_TIME", 120),
+			PoolMonitorSeconds: getEnvAsInt("PG_POOL_MONITOR_INTERVAL", 60),
 		},
 		Server: ServerConfig{
 			Port:           getEnvAsInt("SERVER_PORT", 8080),
@@ -104,41 +229,204 @@ func Load() (*Config, error) {
 			AllowedOrigins: getEnv("CORS_ALLOWED_ORIGINS", "*"),
 			AllowedMethods: getEnv("CORS_ALLOWED_METHODS", "GET,POST,PUT,DELETE,OPTIONS"),
 			AllowedHeaders: getEnv("CORS_ALLOWED_HEADERS", "Content-Type,Authorization"),
+			MaxBodyBytes:   getEnvAsInt("SERVER_MAX_BODY_BYTES", 8<<20),
+			APIBasePath:    normalizeBasePath(getEnv("API_BASE_PATH", "/api")),
+
+			ShutdownTimeout: getEnvAsInt("SHUTDOWN_TIMEOUT", 10),
+
+			RateLimitRPS:   getEnvAsFloat("RATE_LIMIT_RPS", 5),
+			RateLimitBurst: getEnvAsInt("RATE_LIMIT_BURST", 20),
+			TrustedProxies: getEnvAsSlice("TRUSTED_PROXIES", ""),
 		},
 		Search: SearchConfig{
-			DefaultLimit:  getEnvAsInt("SEARCH_DEFAULT_LIMIT", 20),
-			MaxLimit:      getEnvAsInt("SEARCH_MAX_LIMIT", 100),
-			DefaultOffset: getEnvAsInt("SEARCH_DEFAULT_OFFSET", 0),
+			DefaultLimit:           getEnvAsInt("SEARCH_DEFAULT_LIMIT", 20),
+			MaxLimit:               getEnvAsInt("SEARCH_MAX_LIMIT", 100),
+			DefaultOffset:          getEnvAsInt("SEARCH_DEFAULT_OFFSET", 0),
+			MaxOffset:              getEnvAsInt("SEARCH_MAX_OFFSET", 10000),
+			SortNulls:              getEnvAsMap("SEARCH_SORT_NULLS", "price=last,area_sqft=last,listed_date=last,price_per_sqft=last,mrt_distance_m=last"),
+			StreamReplayTTL:        getEnvAsInt("SEARCH_STREAM_REPLAY_TTL", 300),
+			StreamReplayMaxEntries: getEnvAsInt("SEARCH_STREAM_REPLAY_MAX_ENTRIES", 1000),
+			ResultTTL:              getEnvAsInt("SEARCH_RESULT_TTL", 300),
+			StaleAfterHours:        getEnvAsInt("SEARCH_STALE_AFTER_HOURS", 72),
+			AreaTablePath:          getEnv("LOCATION_AREAS_PATH", ""),
+			MinResults:             getEnvAsInt("SEARCH_MIN_RESULTS", 3),
+			RelaxPriceRatio:        getEnvAsFloat("SEARCH_RELAX_PRICE_RATIO", 0.2),
+			ShareBaseURL:           getEnv("SHARE_BASE_URL", ""),
+			NoKeywordSort:          getEnv("SEARCH_NO_KEYWORD_SORT", "newest"),
+			SeenLookbackDays:       getEnvAsInt("SEARCH_SEEN_LOOKBACK_DAYS", 30),
+			SeenMaxIDs:             getEnvAsInt("SEARCH_SEEN_MAX_IDS", 500),
+			SuggestionMinCount:     getEnvAsInt("SUGGESTIONS_MIN_COUNT", 3),
+			SuggestionLookbackDays: getEnvAsInt("SUGGESTIONS_LOOKBACK_DAYS", 30),
+			SuggestionCacheTTL:     getEnvAsInt("SUGGESTIONS_CACHE_TTL", 600),
+			PriceAnchorCacheTTL:    getEnvAsInt("PRICE_ANCHOR_CACHE_TTL", 300),
+			PriceAnchorMaxEntries:  getEnvAsInt("PRICE_ANCHOR_CACHE_MAX_ENTRIES", 1000),
+			PriceAnchorMinListings: getEnvAsInt("PRICE_ANCHOR_MIN_LISTINGS", 10),
+			GreenScoreMinListings:  getEnvAsInt("GREEN_SCORES_MIN_LISTINGS", 5),
+			GreenScoreCacheTTL:     getEnvAsInt("GREEN_SCORES_CACHE_TTL", 3600),
+			RankWindow:             getEnvAsInt("SEARCH_RANK_WINDOW", 200),
+			ImagesPath:             getEnv("LISTING_IMAGES_PATH", "images"),
+			PrimaryImagePath:       getEnv("LISTING_PRIMARY_IMAGE_PATH", "primary_image"),
+			SessionCookieName:      getEnv("SESSION_COOKIE_NAME", "pg_session"),
+			RecentSearchDays:       getEnvAsInt("RECENT_SEARCHES_DAYS", 7),
+			FullTextMode:           getEnv("SEARCH_FULLTEXT", "auto"),
+			LocaleFromHeader:       getEnvAsBool("SEARCH_LOCALE_FROM_HEADER", true),
+
+			MortgageInterestRate:       getEnvAsFloat("MORTGAGE_INTEREST_RATE", 3.5),
+			MortgageTenureYears:        getEnvAsInt("MORTGAGE_TENURE_YEARS", 25),
+			MortgageDownPaymentPercent: getEnvAsFloat("MORTGAGE_DOWN_PAYMENT_PERCENT", 25),
+
+			MaxInFlight:    getEnvAsInt("SEARCH_MAX_IN_FLIGHT", 32),
+			OverloadMode:   getEnv("SEARCH_OVERLOAD_MODE", "queue"),
+			QueueTimeoutMs: getEnvAsInt("SEARCH_QUEUE_TIMEOUT_MS", 5000),
 		},
 		Ranking: RankingConfig{
-			WeightText:    getEnvAsFloat("RANK_WEIGHT_TEXT", 0.5),
-			WeightPrice:   getEnvAsFloat("RANK_WEIGHT_PRICE", 0.3),
-			WeightRecency: getEnvAsFloat("RANK_WEIGHT_RECENCY", 0.2),
+			WeightText:          getEnvAsFloat("RANK_WEIGHT_TEXT", 0.5),
+			WeightPrice:         getEnvAsFloat("RANK_WEIGHT_PRICE", 0.3),
+			WeightRecency:       getEnvAsFloat("RANK_WEIGHT_RECENCY", 0.2),
+			WeightTitle:         getEnvAsFloat("RANK_WEIGHT_TITLE", 0.15),
+			WeightAmenities:     getEnvAsFloat("RANK_WEIGHT_AMENITIES", 0.2),
+			WeightCompleteness:  getEnvAsFloat("RANK_WEIGHT_COMPLETENESS", 0.05),
+			WeightValue:         getEnvAsFloat("RANK_WEIGHT_VALUE", 0.1),
+			MaxReasons:          getEnvAsInt("RANK_MAX_REASONS", 4),
+			VectorRecencyWeight: getEnvAsFloat("RANK_VECTOR_RECENCY_WEIGHT", 0.05),
+			ReasonTextScore:     getEnvAsFloat("RANK_REASON_TEXT_SCORE", 0.1),
+			ReasonPriceScore:    getEnvAsFloat("RANK_REASON_PRICE_SCORE", 0.8),
+			ReasonNewListedDays: getEnvAsInt("RANK_REASON_NEW_LISTED_DAYS", 7),
+			ReasonGreenScore:    getEnvAsFloat("RANK_REASON_GREEN_SCORE", 4.0),
 		},
 		Logging: LoggingConfig{
-			Level:  getEnv("LOG_LEVEL", "info"),
-			Format: getEnv("LOG_FORMAT", "json"),
+			Level:      getEnv("LOG_LEVEL", "info"),
+			Format:     getEnv("LOG_FORMAT", "json"),
+			RedactKeys: getEnvAsSlice("LOG_REDACT_KEYS", ""),
 		},
 		OpenAI: OpenAIConfig{
 			APIKey:              getEnv("OPENAI_API_KEY", ""),
 			APIBase:             getEnv("OPENAI_API_BASE", "https://integrate.api.nvidia.com/v1"),
+			Provider:            getEnv("OPENAI_PROVIDER", ""),
+			ExtractThinkTags:    getEnvAsBool("OPENAI_EXTRACT_THINK_TAGS", true),
 			ChatModel:           getEnv("OPENAI_CHAT_MODEL", "deepseek-ai/deepseek-v3.1-terminus"),
 			ChatTemperature:     getEnvAsFloat("OPENAI_CHAT_TEMPERATURE", 0.2),
 			ChatTopP:            getEnvAsFloat("OPENAI_CHAT_TOP_P", 0.7),
 			ChatMaxTokens:       getEnvAsInt("OPENAI_CHAT_MAX_TOKENS", 8192),
 			ChatExtraBody:       getEnv("OPENAI_CHAT_EXTRA_BODY", ``),
+			FewShotFile:         getEnv("OPENAI_FEW_SHOT_FILE", ""),
+			IntentPromptPath:    getEnv("INTENT_PROMPT_PATH", ""),
 			EmbeddingModel:      getEnv("OPENAI_EMBEDDING_MODEL", "baai/bge-m3"),
 			EmbeddingDimensions: getEnvAsInt("OPENAI_EMBEDDING_DIMENSIONS", 1024),
 			EmbeddingExtraBody:  getEnv("OPENAI_EMBEDDING_EXTRA_BODY", `{"truncate":"NONE"}`),
-			BatchSize:           getEnvAsInt("OPENAI_BATCH_SIZE", 100),
-			Timeout:             getEnvAsInt("OPENAI_TIMEOUT", 30),
-			Enabled:             getEnv("OPENAI_API_KEY", "") != "",
+
+			EmbeddingPrimaryAttempts:            getEnvAsInt("EMBEDDING_PRIMARY_ATTEMPTS", 2),
+			EmbeddingFallbackAPIBase:            getEnv("EMBEDDING_FALLBACK_API_BASE", ""),
+			EmbeddingFallbackAPIKey:             getEnv("EMBEDDING_FALLBACK_API_KEY", ""),
+			EmbeddingFallbackModel:              getEnv("EMBEDDING_FALLBACK_MODEL", ""),
+			EmbeddingFallbackDimensions:         getEnvAsInt("EMBEDDING_FALLBACK_DIMENSIONS", 0),
+			EmbeddingFallbackAllowModelMismatch: getEnvAsBool("EMBEDDING_FALLBACK_ALLOW_MODEL_MISMATCH", false),
+
+			EmbeddingMaxInputs:     getEnvAsInt("OPENAI_EMBEDDING_MAX_INPUTS", 2048),
+			EmbeddingMaxInputChars: getEnvAsInt("OPENAI_EMBEDDING_MAX_INPUT_CHARS", 32000),
+			EmbeddingCacheSize:     getEnvAsInt("EMBEDDING_CACHE_SIZE", 1000),
+
+			BatchSize:         getEnvAsInt("OPENAI_BATCH_SIZE", 100),
+			Timeout:           getEnvAsInt("OPENAI_TIMEOUT", 30),
+			TokenBudget:       getEnvAsInt("OPENAI_TOKEN_BUDGET", 0),
+			TokenBudgetWindow: getEnvAsInt("OPENAI_TOKEN_BUDGET_WINDOW", 3600),
+			Warmup:            getEnvAsBool("OPENAI_WARMUP", false),
+			Enabled:           getEnv("OPENAI_API_KEY", "") != "",
+
+			ChatTimeout:      getEnvAsInt("OPENAI_CHAT_TIMEOUT", 0),
+			StreamTimeout:    getEnvAsInt("OPENAI_STREAM_TIMEOUT", 300),
+			EmbeddingTimeout: getEnvAsInt("OPENAI_EMBEDDING_TIMEOUT", 0),
 		},
+		Admin: AdminConfig{
+			APIKey:            getEnv("ADMIN_API_KEY", ""),
+			StaleListingHours: getEnvAsInt("STALE_LISTING_HOURS", 720),
+		},
+		Cache: CacheConfig{
+			IntentTTL:        getEnvAsInt("INTENT_CACHE_TTL", 3600),
+			IntentMaxEntries: getEnvAsInt("INTENT_CACHE_MAX_ENTRIES", 1000),
+
+			IntentSchemaMismatch: getEnv("INTENT_CACHE_SCHEMA_MISMATCH", "upgrade"),
+
+			RedisURL:          getEnv("REDIS_URL", ""),
+			IntentRedisPrefix: getEnv("INTENT_CACHE_REDIS_PREFIX", "intent:"),
+		},
+		Embedding: EmbeddingConfig{
+			ChunkSize:      getEnvAsInt("EMBEDDING_CHUNK_SIZE", 1000),
+			ChunkOverlap:   getEnvAsInt("EMBEDDING_CHUNK_OVERLAP", 100),
+			ChunkAggregate: getEnv("VECTOR_CHUNK_AGGREGATE", "max"),
+			BackfillBatch:  getEnvAsInt("EMBEDDING_BACKFILL_BATCH", 20),
+		},
+	}
+
+	embeddingModels, err := loadEmbeddingModels(&cfg.OpenAI)
+	if err != nil {
+		return nil, err
+	}
+	cfg.OpenAI.EmbeddingModels = embeddingModels
+
+	if cfg.Server.RateLimitRPS > 0 && cfg.Server.RateLimitBurst < 1 {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_BURST %d, must be at least 1 when RATE_LIMIT_RPS is set", cfg.Server.RateLimitBurst)
+	}
+
+	switch strings.ToLower(cfg.Logging.Level) {
+	case "debug", "info", "warn", "warning", "error":
+	default:
+		return nil, fmt.Errorf("invalid LOG_LEVEL %q, must be debug, info, warn, or error", cfg.Logging.Level)
+	}
+
+	switch strings.ToLower(cfg.Logging.Format) {
+	case "json", "text":
+	default:
+		return nil, fmt.Errorf("invalid LOG_FORMAT %q, must be json or text", cfg.Logging.Format)
+	}
+
+	switch cfg.Search.FullTextMode {
+	case "auto", "on", "off":
+	default:
+		return nil, fmt.Errorf("invalid SEARCH_FULLTEXT %q, must be auto, on, or off", cfg.Search.FullTextMode)
+	}
+
+	switch cfg.Search.OverloadMode {
+	case "queue", "reject":
+	default:
+		return nil, fmt.Errorf("invalid SEARCH_OVERLOAD_MODE %q, must be queue or reject", cfg.Search.OverloadMode)
+	}
+
+	switch cfg.Cache.IntentSchemaMismatch {
+	case "upgrade", "reparse":
+	default:
+		return nil, fmt.Errorf("invalid INTENT_CACHE_SCHEMA_MISMATCH %q, must be upgrade or reparse", cfg.Cache.IntentSchemaMismatch)
 	}
 
 	return cfg, nil
 }
 
+// loadEmbeddingModels parses EMBEDDING_MODEL_ALLOWLIST (model=dimensions,...). The configured
+// embedding model is always allowed. Every listed model must produce OPENAI_EMBEDDING_DIMENSIONS
+// vectors, the size of the stored listing embeddings; any other size is a config error.
+func loadEmbeddingModels(cfg *OpenAIConfig) (map[string]int, error) {
+	return parseEmbeddingModels(getEnvAsMap("EMBEDDING_MODEL_ALLOWLIST", ""), cfg.EmbeddingModel, cfg.EmbeddingDimensions)
+}
+
+// parseEmbeddingModels validates an allowlist against the default model and its dimensions
+func parseEmbeddingModels(allowlist map[string]string, defaultModel string, defaultDims int) (map[string]int, error) {
+	models := make(map[string]int)
+	for model, dims := range allowlist {
+		value, err := strconv.Atoi(dims)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("invalid dimensions %q for model %q in EMBEDDING_MODEL_ALLOWLIST", dims, model)
+		}
+		if value != defaultDims {
+			return nil, fmt.Errorf("EMBEDDING_MODEL_ALLOWLIST lists %s with %d dimensions, but stored embeddings have %d (OPENAI_EMBEDDING_DIMENSIONS)",
+				model, value, defaultDims)
+		}
+		models[model] = value
+	}
+	models[defaultModel] = defaultDims
+
+	return models, nil
+}
+
 // GetPostgreSQLDSN returns PostgreSQL connection string
 func (c *Config) GetPostgreSQLDSN() string {
 	// 优先使用完整的 DSN
@@ -160,6 +448,15 @@ func (c *Config) GetPostgreSQLDSN() string {
 
 // Helper functions
 
+// normalizeBasePath turns "api", "/api/" or "/api" into "/api"; "/" becomes "" (routes at the root)
+func normalizeBasePath(path string) string {
+	path = strings.Trim(strings.TrimSpace(path), "/")
+	if path == "" {
+		return ""
+	}
+	return "/" + path
+}
+
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -193,3 +490,43 @@ func getEnvAsFloat(key string, defaultValue float64) float64 {
 	}
 	return value
 }
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		log.Printf("Warning: Invalid boolean value for %s, using default %t", key, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvAsSlice parses a comma-separated list, skipping empty entries
+func getEnvAsSlice(key, defaultValue string) []string {
+	var result []string
+	for _, item := range strings.Split(getEnv(key, defaultValue), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// getEnvAsMap parses a comma-separated list of key=value pairs
+func getEnvAsMap(key, defaultValue string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(getEnv(key, defaultValue), ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || k == "" {
+			if pair != "" {
+				log.Printf("Warning: Ignoring malformed entry %q in %s", pair, key)
+			}
+			continue
+		}
+		result[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return result
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEmbeddingModels(t *testing.T) {
+	models, err := parseEmbeddingModels(map[string]string{"nvidia/nv-embedqa-e5-v5": "1024"}, "baai/bge-m3", 1024)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(models) != 2 || models["baai/bge-m3"] != 1024 || models["nvidia/nv-embedqa-e5-v5"] != 1024 {
+		t.Errorf("Expected both models at 1024 dimensions, got %v", models)
+	}
+
+	// Vectors of another size can't be compared with the stored listing embeddings
+	_, err = parseEmbeddingModels(map[string]string{"text-embedding-3-small": "1536"}, "baai/bge-m3", 1024)
+	if err == nil || !strings.Contains(err.Error(), "text-embedding-3-small with 1536 dimensions") {
+		t.Errorf("Expected a dimension mismatch error, got %v", err)
+	}
+	if _, err := parseEmbeddingModels(map[string]string{"baai/bge-m3": "768"}, "baai/bge-m3", 1024); err == nil {
+		t.Error("Expected the default model listed with other dimensions to be rejected")
+	}
+	if _, err := parseEmbeddingModels(map[string]string{"nvidia/nv-embedqa-e5-v5": "big"}, "baai/bge-m3", 1024); err == nil {
+		t.Error("Expected invalid dimensions to be rejected")
+	}
+}
@@ -0,0 +1,341 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"core/internal/model"
+	"core/internal/repository"
+	"core/internal/service"
+	"core/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler handles operational endpoints under /api/v1/admin
+type AdminHandler struct {
+	intentParser      *service.IntentParser
+	searchService     *service.SearchService
+	embeddingService  *service.EmbeddingService
+	aiClient          *service.OpenAIClient
+	staleListingHours int
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(
+	intentParser *service.IntentParser,
+	searchService *service.SearchService,
+	embeddingService *service.EmbeddingService,
+	aiClient *service.OpenAIClient,
+	staleListingHours int,
+) *AdminHandler {
+	return &AdminHandler{
+		intentParser:      intentParser,
+		searchService:     searchService,
+		embeddingService:  embeddingService,
+		aiClient:          aiClient,
+		staleListingHours: staleListingHours,
+	}
+}
+
+// IntentCacheStats handles GET /api/v1/admin/intent-cache
+func (h *AdminHandler) IntentCacheStats(c *gin.Context) {
+	stats := h.intentParser.CacheStats(c.Request.Context())
+	if stats == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": true,
+		"stats":   stats,
+	})
+}
+
+// ClearIntentCache handles DELETE /api/v1/admin/intent-cache
+func (h *AdminHandler) ClearIntentCache(c *gin.Context) {
+	cleared, err := h.intentParser.ClearCache(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear intent cache: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"cleared": cleared,
+	})
+}
+
+// WarmIntentCache handles POST /api/v1/admin/intent-cache/warm.
+// Pre-parses common queries one at a time so they're served from cache after a deploy,
+// prompt change, or cache flush. Queries already cached are not re-parsed.
+func (h *AdminHandler) WarmIntentCache(c *gin.Context) {
+	var req model.WarmCacheRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if h.intentParser.CacheStats(c.Request.Context()) == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Intent cache is disabled (INTENT_CACHE_TTL=0)"})
+		return
+	}
+
+	response := model.WarmCacheResponse{Results: make([]model.WarmCacheResult, 0, len(req.Queries))}
+	for _, query := range req.Queries {
+		if c.Request.Context().Err() != nil {
+			break
+		}
+		start := time.Now()
+		cached, err := h.intentParser.WarmCache(c.Request.Context(), query)
+		result := model.WarmCacheResult{
+			Query:     query,
+			Success:   err == nil,
+			Cached:    cached,
+			LatencyMs: time.Since(start).Milliseconds(),
+		}
+		switch {
+		case err != nil:
+			result.Error = err.Error()
+			response.Failed++
+		case cached:
+			response.Cached++
+		default:
+			response.Warmed++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	log.Printf("🔥 Intent cache warm-up: warmed=%d, cached=%d, failed=%d", response.Warmed, response.Cached, response.Failed)
+
+	if response.Failed > 0 {
+		c.JSON(http.StatusPartialContent, response)
+	} else {
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// PurgeStaleListings handles POST /api/v1/admin/listings/purge-stale.
+// Defaults to a dry run so the affected listings can be previewed first.
+func (h *AdminHandler) PurgeStaleListings(c *gin.Context) {
+	var req model.PurgeStaleRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBindError(c, err)
+			return
+		}
+	}
+
+	if req.OlderThanHours <= 0 {
+		req.OlderThanHours = h.staleListingHours
+	}
+	if req.Mode == "" {
+		req.Mode = repository.PurgeModeMark
+	}
+	if req.Mode != repository.PurgeModeMark && req.Mode != repository.PurgeModeDelete {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mode. Must be one of: mark, delete"})
+		return
+	}
+	dryRun := req.DryRun == nil || *req.DryRun
+
+	result, err := h.searchService.PurgeStaleListings(
+		c.Request.Context(),
+		time.Duration(req.OlderThanHours)*time.Hour,
+		req.Mode,
+		req.ClearEmbeddings,
+		dryRun,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge stale listings: " + err.Error()})
+		return
+	}
+
+	if !dryRun {
+		log.Printf("🧹 Purged stale listings: mode=%s, older_than=%dh, affected=%d, embeddings_cleared=%d",
+			result.Mode, req.OlderThanHours, result.Affected, result.EmbeddingsCleared)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteListing handles DELETE /api/v1/listings/:id (admin API key required).
+// Removes the listing, its embeddings, and its references in search logs, e.g. for takedowns.
+func (h *AdminHandler) DeleteListing(c *gin.Context) {
+	listingID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid listing ID"})
+		return
+	}
+
+	result, err := h.searchService.DeleteListing(c.Request.Context(), listingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete listing: " + err.Error()})
+		return
+	}
+	if result == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+		return
+	}
+
+	log.Printf("🗑️  Deleted listing %d: embedding_cleared=%t, chunks=%d, search_logs_updated=%d",
+		result.ListingID, result.EmbeddingCleared, result.ChunksDeleted, result.SearchLogsUpdated)
+	c.JSON(http.StatusOK, result)
+}
+
+// exportFlushRows is how many CSV rows are buffered before flushing to the client
+const exportFlushRows = 500
+
+// exportErrorTrailer carries an error hit after the CSV started streaming, when the
+// status code can no longer change
+const exportErrorTrailer = "X-Export-Error"
+
+// ExportListings handles POST /api/v1/admin/listings/export.
+// Streams matching listings as CSV straight from the database cursor, so large exports
+// don't build the whole result in memory.
+func (h *AdminHandler) ExportListings(c *gin.Context) {
+	var req model.ExportRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBindError(c, err)
+			return
+		}
+	}
+	if err := model.ValidateFields(req.Fields); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	columns := model.ExportColumns(req.Fields)
+
+	writer := csv.NewWriter(c.Writer)
+	started := false
+	startCSV := func() error {
+		started = true
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="listings.csv"`)
+		c.Header("Trailer", exportErrorTrailer)
+		c.Status(http.StatusOK)
+		return writer.Write(columns)
+	}
+
+	rows := 0
+	start := time.Now()
+	err := h.searchService.ExportListings(c.Request.Context(), req.Filters, req.Fields, req.Limit, func(listing *model.Listing) error {
+		if !started {
+			if err := startCSV(); err != nil {
+				return err
+			}
+		}
+		record, err := listing.CSVRecord(columns)
+		if err != nil {
+			return fmt.Errorf("listing %d: %w", listing.ListingID, err)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+		if rows++; rows%exportFlushRows == 0 {
+			writer.Flush()
+			c.Writer.Flush()
+		}
+		return writer.Error()
+	})
+	if err == nil && !started {
+		// Nothing matched: still a valid CSV, with just the header row
+		err = startCSV()
+	}
+	writer.Flush()
+
+	if err != nil {
+		log.Printf("❌ Listing export failed after %d rows: %v", rows, err)
+		if !started {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export listings: " + err.Error()})
+			return
+		}
+		// The CSV is already partly sent; report the truncation in the trailer declared up front
+		c.Writer.Header().Set(exportErrorTrailer, err.Error())
+		return
+	}
+	log.Printf("📤 Exported %d listings in %v", rows, time.Since(start))
+}
+
+// defaultBackfillLimit is the number of listings embedded per backfill call when unset
+const defaultBackfillLimit = 100
+
+// BackfillEmbeddings handles POST /api/v1/admin/embeddings/backfill.
+// Embeds listings that have no embedding yet, chunking long descriptions.
+func (h *AdminHandler) BackfillEmbeddings(c *gin.Context) {
+	var req model.EmbeddingBackfillRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBindError(c, err)
+			return
+		}
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultBackfillLimit
+	}
+
+	result, err := h.embeddingService.BackfillEmbeddings(c.Request.Context(), req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to backfill embeddings: " + err.Error()})
+		return
+	}
+
+	log.Printf("🧬 Embedding backfill: processed=%d, chunks=%d, success=%d, failed=%d",
+		result.Processed, result.Chunks, result.Success, result.Failed)
+
+	if result.Failed > 0 {
+		c.JSON(http.StatusPartialContent, result)
+	} else {
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// ParseTest handles POST /api/v1/admin/parse-test.
+// Parses a query with a temporary client for the requested provider/model/endpoint and
+// reports the intent, latency, and token usage, leaving the configured client untouched.
+func (h *AdminHandler) ParseTest(c *gin.Context) {
+	var req model.ParseTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if h.aiClient == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "AI client is not configured"})
+		return
+	}
+
+	client, err := h.aiClient.WithProvider(service.ProviderOverride{
+		Provider: req.Provider,
+		Model:    req.Model,
+		APIBase:  req.APIBase,
+		APIKey:   req.APIKey,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	start := time.Now()
+	intent, err := client.ParseIntentWithAI(c.Request.Context(), req.Query, utils.ParseLocale(req.Locale))
+	latencyMs := time.Since(start).Milliseconds()
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":      "Parse failed: " + err.Error(),
+			"latency_ms": latencyMs,
+		})
+		return
+	}
+
+	log.Printf("🧪 Parse test: model=%s, base=%s, latency=%dms", req.Model, utils.RedactURL(req.APIBase), latencyMs)
+
+	c.JSON(http.StatusOK, gin.H{
+		"intent":         intent,
+		"raw_content":    intent.RawContent,
+		"parse_strategy": intent.ParseStrategy,
+		"latency_ms":     latencyMs,
+		"usage":          intent.Usage,
+	})
+}
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"core/internal/model"
 	"core/internal/service"
@@ -11,13 +14,17 @@ import (
 
 // EmbeddingHandler handles embedding-related HTTP requests
 type EmbeddingHandler struct {
-	searchService *service.SearchService
+	searchService       *service.SearchService
+	aiClient            *service.OpenAIClient // nil when no provider is configured
+	embeddingDimensions int                   // Dimensions of stored listing embeddings (OPENAI_EMBEDDING_DIMENSIONS)
 }
 
 // NewEmbeddingHandler creates a new embedding handler
-func NewEmbeddingHandler(searchService *service.SearchService) *EmbeddingHandler {
+func NewEmbeddingHandler(searchService *service.SearchService, aiClient *service.OpenAIClient, embeddingDimensions int) *EmbeddingHandler {
 	return &EmbeddingHandler{
-		searchService: searchService,
+		searchService:       searchService,
+		aiClient:            aiClient,
+		embeddingDimensions: embeddingDimensions,
 	}
 }
 
@@ -25,7 +32,7 @@ func NewEmbeddingHandler(searchService *service.SearchService) *EmbeddingHandler
 func (h *EmbeddingHandler) BatchUpdate(c *gin.Context) {
 	var req model.EmbeddingBatchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
@@ -34,14 +41,9 @@ func (h *EmbeddingHandler) BatchUpdate(c *gin.Context) {
 		return
 	}
 
-	// Validate embedding dimensions
-	for i, item := range req.Embeddings {
-		if len(item.Embedding) != 1536 {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Invalid embedding dimension at index " + string(rune(i)) + ", expected 1536",
-			})
-			return
-		}
+	if err := validateEmbeddingDimensions(req.Embeddings, h.embeddingDimensions); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	// Update embeddings
@@ -59,3 +61,56 @@ func (h *EmbeddingHandler) BatchUpdate(c *gin.Context) {
 		c.JSON(http.StatusOK, response)
 	}
 }
+
+// Generate handles POST /api/v1/embeddings/generate.
+// Embeds texts with the server's provider so ingestion pipelines don't need their own.
+func (h *EmbeddingHandler) Generate(c *gin.Context) {
+	var req model.EmbeddingGenerateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if h.aiClient == nil || !h.aiClient.IsEnabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Embedding provider is not configured (set OPENAI_API_KEY)"})
+		return
+	}
+
+	modelName, dims, err := h.aiClient.ResolveEmbeddingModel(req.Model)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	embeddings, err := h.aiClient.CreateEmbeddingsWithModel(c.Request.Context(), modelName, req.Texts)
+	if err != nil {
+		var tooLong *service.EmbeddingInputTooLongError
+		if errors.As(err, &tooLong) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to generate embeddings: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.EmbeddingGenerateResponse{
+		Model:      modelName,
+		Dimensions: dims,
+		Embeddings: embeddings,
+	})
+}
+
+// validateEmbeddingDimensions checks every listing embedding has the configured dimensions
+// and every chunk embedding matches its listing's
+func validateEmbeddingDimensions(items []model.EmbeddingItem, dimensions int) error {
+	for i, item := range items {
+		if len(item.Embedding) != dimensions {
+			return errors.New("Invalid embedding dimension at index " + strconv.Itoa(i) +
+				", expected " + strconv.Itoa(dimensions) + ", got " + strconv.Itoa(len(item.Embedding)))
+		}
+		for _, chunk := range item.Chunks {
+			if len(chunk.Embedding) != len(item.Embedding) {
+				return fmt.Errorf("Invalid chunk embedding dimension for listing_id %d, expected %d", item.ListingID, len(item.Embedding))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"core/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestValidateEmbeddingDimensions(t *testing.T) {
+	valid := make([]float32, 1024)
+	items := []model.EmbeddingItem{
+		{ListingID: 1, Embedding: valid},
+		{ListingID: 2, Embedding: valid, Chunks: []model.EmbeddingChunk{{Content: "a", Embedding: valid}}},
+	}
+	if err := validateEmbeddingDimensions(items, 1024); err != nil {
+		t.Errorf("Expected 1024-dim embeddings to be accepted, got %v", err)
+	}
+
+	items = append(items, model.EmbeddingItem{ListingID: 3, Embedding: make([]float32, 1536)})
+	err := validateEmbeddingDimensions(items, 1024)
+	if err == nil || err.Error() != "Invalid embedding dimension at index 2, expected 1024, got 1536" {
+		t.Errorf("Expected a mismatch naming index 2, got %v", err)
+	}
+
+	chunked := []model.EmbeddingItem{{ListingID: 4, Embedding: valid, Chunks: []model.EmbeddingChunk{{Embedding: make([]float32, 3)}}}}
+	if err := validateEmbeddingDimensions(chunked, 1024); err == nil {
+		t.Error("Expected a mismatched chunk embedding to be rejected")
+	}
+}
+
+func TestBatchUpdateRejectsMismatchedDimensions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/embeddings/batch", NewEmbeddingHandler(nil, nil, 4).BatchUpdate)
+
+	body := `{"embeddings": [{"listing_id": 1, "embedding": [0.1, 0.2, 0.3]}]}`
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/embeddings/batch", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "at index 0, expected 4, got 3") {
+		t.Errorf("Expected 400 for a 3-dim embedding, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGenerateEmbeddingsRequestChecks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/embeddings/generate", NewEmbeddingHandler(nil, nil, 4).Generate)
+
+	tooMany := `{"texts": [` + strings.Repeat(`"a", `, 256) + `"a"]}`
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"no texts", `{"texts": []}`, http.StatusBadRequest},
+		{"over the per-request cap", tooMany, http.StatusBadRequest},
+		{"provider disabled", `{"texts": ["3 bedroom condo"]}`, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/embeddings/generate", strings.NewReader(tt.body)))
+		if w.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d %s", tt.name, tt.code, w.Code, w.Body.String())
+		}
+	}
+}
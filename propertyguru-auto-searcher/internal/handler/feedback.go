@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"strings"
 
 	"core/internal/model"
 	"core/internal/service"
@@ -25,19 +26,21 @@ func NewFeedbackHandler(searchService *service.SearchService) *FeedbackHandler {
 func (h *FeedbackHandler) Submit(c *gin.Context) {
 	var req model.FeedbackRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
 	// Validate action
-	validActions := map[string]bool{
-		"click":        true,
-		"contact":      true,
-		"view_details": true,
+	validAction := false
+	for _, action := range model.FeedbackActions {
+		if req.Action == action {
+			validAction = true
+			break
+		}
 	}
 
-	if !validActions[req.Action] {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action. Must be one of: click, contact, view_details"})
+	if !validAction {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action. Must be one of: " + strings.Join(model.FeedbackActions, ", ")})
 		return
 	}
 
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"core/internal/repository"
+	"core/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Time limits of the health checks; a probe must answer well within its own timeout
+const (
+	healthDBTimeout = 2 * time.Second
+	healthAITimeout = 5 * time.Second
+)
+
+// Health check component states
+const (
+	healthUp       = "up"
+	healthDown     = "down"
+	healthDisabled = "disabled"
+)
+
+// databasePinger is the database dependency of the health checks
+type databasePinger interface {
+	Ping(ctx context.Context) error
+	PoolStats() repository.PoolStats
+}
+
+// ComponentHealth is the result of checking one dependency
+type ComponentHealth struct {
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// HealthHandler answers liveness/readiness probes
+type HealthHandler struct {
+	db       databasePinger
+	aiClient *service.OpenAIClient
+	info     func() gin.H // Static fields (version, build, capabilities) included in every response
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(db databasePinger, aiClient *service.OpenAIClient, info func() gin.H) *HealthHandler {
+	return &HealthHandler{
+		db:       db,
+		aiClient: aiClient,
+		info:     info,
+	}
+}
+
+// Get handles GET /health. The database is always pinged: when it is unreachable the
+// response is a 503 with status "unhealthy", so load balancers and readiness probes take the
+// instance out of rotation. With ?deep=true the AI provider is checked too; an unreachable
+// provider only makes the status "degraded" (still 200), since search falls back to
+// rule-based intent parsing without it.
+func (h *HealthHandler) Get(c *gin.Context) {
+	checks := map[string]ComponentHealth{
+		"database": checkComponent(c.Request.Context(), healthDBTimeout, h.db.Ping),
+	}
+	if c.Query("deep") == "true" {
+		if h.aiClient == nil || !h.aiClient.IsEnabled() {
+			checks["ai"] = ComponentHealth{Status: healthDisabled}
+		} else {
+			checks["ai"] = checkComponent(c.Request.Context(), healthAITimeout, h.aiClient.Warmup)
+		}
+	}
+
+	body := gin.H{}
+	if h.info != nil {
+		body = h.info()
+	}
+	body["checks"] = checks
+
+	status := http.StatusOK
+	switch {
+	case checks["database"].Status == healthDown:
+		status = http.StatusServiceUnavailable
+		body["status"] = "unhealthy"
+		body["failing"] = []string{"database"}
+	case checks["ai"].Status == healthDown:
+		body["status"] = "degraded"
+		body["failing"] = []string{"ai"}
+	default:
+		body["status"] = "healthy"
+	}
+
+	c.JSON(status, body)
+}
+
+// GetDatabase handles GET /health/db: the database ping plus connection pool statistics
+// (open, in use, idle, wait count/duration), for checking whether PG_MAX_CONNECTIONS is
+// saturated. Like /health it answers 503 when the database is unreachable.
+func (h *HealthHandler) GetDatabase(c *gin.Context) {
+	check := checkComponent(c.Request.Context(), healthDBTimeout, h.db.Ping)
+	status := http.StatusOK
+	if check.Status == healthDown {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, gin.H{
+		"database": check,
+		"pool":     h.db.PoolStats(),
+	})
+}
+
+// checkComponent runs one health check under its own timeout
+func checkComponent(ctx context.Context, timeout time.Duration, check func(context.Context) error) ComponentHealth {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := ComponentHealth{Status: healthUp, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = healthDown
+		result.Error = err.Error()
+	}
+	return result
+}
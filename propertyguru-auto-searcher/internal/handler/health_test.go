@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"core/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakePinger struct{ err error }
+
+func (p fakePinger) Ping(ctx context.Context) error { return p.err }
+
+func (p fakePinger) PoolStats() repository.PoolStats {
+	return repository.PoolStats{MaxOpen: 10, Open: 4, InUse: 3, Idle: 1, WaitCount: 2, Utilization: 0.3}
+}
+
+func TestHealthReportsDatabaseState(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   int
+		wantStatus string
+	}{
+		{"database up", nil, http.StatusOK, "healthy"},
+		{"database down", errors.New("connection refused"), http.StatusServiceUnavailable, "unhealthy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealthHandler(fakePinger{tt.err}, nil, func() gin.H { return gin.H{"version": "test"} })
+			router := gin.New()
+			router.GET("/health", h.Get)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?deep=true", nil))
+			if w.Code != tt.wantCode {
+				t.Fatalf("code = %d, want %d", w.Code, tt.wantCode)
+			}
+
+			var body struct {
+				Status  string                     `json:"status"`
+				Version string                     `json:"version"`
+				Failing []string                   `json:"failing"`
+				Checks  map[string]ComponentHealth `json:"checks"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Status != tt.wantStatus || body.Version != "test" {
+				t.Errorf("status = %q, version = %q", body.Status, body.Version)
+			}
+			if tt.err != nil && (len(body.Failing) != 1 || body.Failing[0] != "database" || body.Checks["database"].Error == "") {
+				t.Errorf("failing component not reported: %+v", body)
+			}
+			// AI is not configured, which doesn't count as a failure
+			if body.Checks["ai"].Status != healthDisabled {
+				t.Errorf("ai check = %+v, want disabled", body.Checks["ai"])
+			}
+		})
+	}
+}
+
+func TestHealthDatabaseReportsPoolStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/health/db", NewHealthHandler(fakePinger{}, nil, nil).GetDatabase)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/db", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("code = %d", w.Code)
+	}
+
+	var body struct {
+		Database ComponentHealth      `json:"database"`
+		Pool     repository.PoolStats `json:"pool"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Database.Status != healthUp || body.Pool.InUse != 3 || body.Pool.WaitCount != 2 {
+		t.Errorf("unexpected body: %+v", body)
+	}
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+
+	"core/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsHandler exposes operational metrics
+type MetricsHandler struct {
+	aiClient      *service.OpenAIClient
+	searchService *service.SearchService
+}
+
+// NewMetricsHandler creates a new metrics handler
+func NewMetricsHandler(aiClient *service.OpenAIClient, searchService *service.SearchService) *MetricsHandler {
+	return &MetricsHandler{
+		aiClient:      aiClient,
+		searchService: searchService,
+	}
+}
+
+// Get handles GET /metrics
+func (h *MetricsHandler) Get(c *gin.Context) {
+	metrics := gin.H{
+		"ai_enabled": h.aiClient != nil && h.aiClient.IsEnabled(),
+	}
+
+	if h.aiClient != nil {
+		metrics["llm_token_budget"] = h.aiClient.TokenBudgetStatus()
+		if stats, ok := h.aiClient.EmbeddingCacheStats(); ok {
+			metrics["embedding_cache"] = stats
+		}
+	}
+	if h.searchService != nil {
+		metrics["search_latency"] = h.searchService.LatencyStats()
+		metrics["db_pool"] = h.searchService.PoolStats()
+		metrics["search_concurrency"] = h.searchService.ConcurrencyStats()
+	}
+
+	c.JSON(http.StatusOK, metrics)
+}
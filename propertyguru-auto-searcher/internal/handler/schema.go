@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"core/internal/config"
+	"core/internal/model"
+	"core/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FieldSchema describes one request field
+type FieldSchema struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required,omitempty"`
+	Enum     []string `json:"enum,omitempty"`
+}
+
+// SchemaHandler describes the search API's request fields, enums, and limits
+type SchemaHandler struct {
+	config *config.SearchConfig
+}
+
+// NewSchemaHandler creates a new schema handler
+func NewSchemaHandler(cfg *config.SearchConfig) *SchemaHandler {
+	return &SchemaHandler{
+		config: cfg,
+	}
+}
+
+// schemaEnums returns the allowed values for enum-like fields, keyed by JSON field name
+func schemaEnums() map[string][]string {
+	return map[string][]string{
+		"unit_type":          utils.CanonicalUnitTypes,
+		"unit_types":         utils.CanonicalUnitTypes,
+		"exclude_unit_types": utils.CanonicalUnitTypes,
+		"district":           utils.DistrictCodes(),
+		"mrt_lines":          utils.MRTLineCodes(),
+		"mrt_line":           utils.MRTLineCodes(),
+		"sort_by":            model.SortOptions,
+		"nulls_order":        model.NullsOrders,
+		"match_mode":         model.MatchModes,
+		"action":             model.FeedbackActions,
+		"fields":             model.RequestableFields(),
+		"facets":             model.FacetFields,
+	}
+}
+
+// Get handles GET /api/v1/schema
+func (h *SchemaHandler) Get(c *gin.Context) {
+	enums := schemaEnums()
+
+	c.JSON(http.StatusOK, gin.H{
+		"search_request": describeFields(reflect.TypeOf(model.SearchRequest{}), enums),
+		"filters":        describeFields(reflect.TypeOf(model.SearchFilters{}), enums),
+		"options":        describeFields(reflect.TypeOf(model.SearchOptions{}), enums),
+		"feedback":       describeFields(reflect.TypeOf(model.FeedbackRequest{}), enums),
+		"enums":          enums,
+		"limits": gin.H{
+			"default_top_k": h.config.DefaultLimit,
+			"max_top_k":     h.config.MaxLimit,
+			"max_offset":    h.config.MaxOffset,
+		},
+	})
+}
+
+// describeFields lists a struct's JSON fields with their JSON types
+func describeFields(t reflect.Type, enums map[string][]string) []FieldSchema {
+	fields := make([]FieldSchema, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		fields = append(fields, FieldSchema{
+			Name:     name,
+			Type:     jsonType(field.Type),
+			Required: strings.Contains(field.Tag.Get("binding"), "required"),
+			Enum:     enums[name],
+		})
+	}
+	return fields
+}
+
+// jsonType maps a Go type to its JSON schema type name
+func jsonType(t reflect.Type) string {
+	if t == reflect.TypeOf(model.StringOrList{}) {
+		return "string|array<string>"
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array<" + jsonType(t.Elem()) + ">"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+
+	"core/internal/model"
+)
+
+func TestDescribeFields(t *testing.T) {
+	fields := describeFields(reflect.TypeOf(model.SearchFilters{}), schemaEnums())
+
+	byName := make(map[string]FieldSchema, len(fields))
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+
+	tests := []struct {
+		name     string
+		wantType string
+	}{
+		{"price_max", "number"},
+		{"bedrooms", "integer"},
+		{"location", "string"},
+		{"is_completed", "boolean"},
+		{"amenities", "array<string>"},
+	}
+	for _, tt := range tests {
+		if got := byName[tt.name].Type; got != tt.wantType {
+			t.Errorf("%s type = %q, want %q", tt.name, got, tt.wantType)
+		}
+	}
+
+	if len(byName["unit_type"].Enum) == 0 {
+		t.Error("Expected unit_type to list its enum values")
+	}
+
+	request := describeFields(reflect.TypeOf(model.SearchRequest{}), nil)
+	if request[0].Name != "query" || !request[0].Required {
+		t.Errorf("Expected required query field, got %+v", request[0])
+	}
+}
@@ -1,13 +1,18 @@
 package handler
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
+	"core/internal/config"
+	"core/internal/middleware"
 	"core/internal/model"
 	"core/internal/service"
+	"core/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,14 +22,30 @@ type SearchHandler struct {
 	searchService *service.SearchService
 	defaultLimit  int
 	maxLimit      int
+	maxOffset     int
+	replay        *streamReplayCache // Recently emitted streams for Last-Event-ID resume
+
+	greenScoreMinListings int  // Reported with green scores so clients know the sample cutoff
+	localeFromHeader      bool // Fall back to Accept-Language when a search has no locale
+
+	embeddingDimensions int    // Dimensions of stored listing embeddings; vector queries must match
+	vectorAggregate     string // How chunk distances combine per listing in vector search
 }
 
 // NewSearchHandler creates a new search handler
-func NewSearchHandler(searchService *service.SearchService, defaultLimit, maxLimit int) *SearchHandler {
+func NewSearchHandler(searchService *service.SearchService, cfg *config.SearchConfig, embeddingDimensions int, vectorAggregate string) *SearchHandler {
 	return &SearchHandler{
 		searchService: searchService,
-		defaultLimit:  defaultLimit,
-		maxLimit:      maxLimit,
+		defaultLimit:  cfg.DefaultLimit,
+		maxLimit:      cfg.MaxLimit,
+		maxOffset:     cfg.MaxOffset,
+		replay:        newStreamReplayCache(time.Duration(cfg.StreamReplayTTL)*time.Second, cfg.StreamReplayMaxEntries),
+
+		greenScoreMinListings: cfg.GreenScoreMinListings,
+		localeFromHeader:      cfg.LocaleFromHeader,
+
+		embeddingDimensions: embeddingDimensions,
+		vectorAggregate:     vectorAggregate,
 	}
 }
 
@@ -32,34 +53,31 @@ func NewSearchHandler(searchService *service.SearchService, defaultLimit, maxLim
 func (h *SearchHandler) Search(c *gin.Context) {
 	var req model.SearchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
-	// Set default options if not provided
-	if req.Options == nil {
-		req.Options = &model.SearchOptions{
-			TopK:     h.defaultLimit,
-			Offset:   0,
-			Semantic: true,
-		}
-	} else {
-		// Validate and cap limits
-		if req.Options.TopK <= 0 {
-			req.Options.TopK = h.defaultLimit
-		}
-		if req.Options.TopK > h.maxLimit {
-			req.Options.TopK = h.maxLimit
-		}
-		if req.Options.Offset < 0 {
-			req.Options.Offset = 0
-		}
+	options, err := h.normalizeOptions(req.Options, c.Query("fields"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
 	}
+	req.Options = options
+	if err := req.FilterOverrides.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	// Searches without a client session token are logged against the session cookie
+	if req.SessionID == "" {
+		req.SessionID = middleware.SessionIDOf(c)
+	}
+	req.Locale = h.requestLocale(c, req.Locale)
 
-	// Perform search
-	response, err := h.searchService.Search(c.Request.Context(), &req)
+	// Perform search, with the query attached to everything logged for this request
+	ctx := withQueryLogger(c.Request.Context(), req.Query)
+	response, err := h.searchService.Search(ctx, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed: " + err.Error()})
+		respondSearchError(c, err)
 		return
 	}
 
@@ -67,41 +85,38 @@ func (h *SearchHandler) Search(c *gin.Context) {
 }
 
 // SearchStream handles POST /api/v1/search/stream - SSE streaming search
+//
+// Every event carries a monotonic "id". A client that drops mid-stream can re-POST the
+// same request with a Last-Event-ID header: if the original stream is still running or
+// finished within SEARCH_STREAM_REPLAY_TTL, the missed events (including intent and
+// results) are replayed from memory without re-running the search. Otherwise the search
+// is re-run and IDs continue after Last-Event-ID.
+//
+// A disconnect cancels the in-flight LLM stream and database query. The events sent so
+// far stay recorded, but the stream is marked incomplete, so a reconnect re-runs the
+// search instead of replaying a partial stream.
 func (h *SearchHandler) SearchStream(c *gin.Context) {
 	var req model.SearchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
-	// Set default options if not provided
-	if req.Options == nil {
-		req.Options = &model.SearchOptions{
-			TopK:     h.defaultLimit,
-			Offset:   0,
-			Semantic: true,
-		}
-	} else {
-		// Validate and cap limits
-		if req.Options.TopK <= 0 {
-			req.Options.TopK = h.defaultLimit
-		}
-		if req.Options.TopK > h.maxLimit {
-			req.Options.TopK = h.maxLimit
-		}
-		if req.Options.Offset < 0 {
-			req.Options.Offset = 0
-		}
+	options, err := h.normalizeOptions(req.Options, c.Query("fields"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
 	}
-
-	// Set SSE headers
-	c.Header("Content-Type", "text/event-stream; charset=utf-8")
-	c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
-	c.Header("Pragma", "no-cache")
-	c.Header("Expires", "0")
-	c.Header("Connection", "keep-alive")
-	c.Header("X-Accel-Buffering", "no")
-	c.Header("Transfer-Encoding", "chunked")
+	req.Options = options
+	if err := req.FilterOverrides.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	// Searches without a client session token are logged against the session cookie
+	if req.SessionID == "" {
+		req.SessionID = middleware.SessionIDOf(c)
+	}
+	req.Locale = h.requestLocale(c, req.Locale)
 
 	// Create flusher for SSE
 	flusher, ok := c.Writer.(http.Flusher)
@@ -110,92 +125,267 @@ func (h *SearchHandler) SearchStream(c *gin.Context) {
 		return
 	}
 
+	key := streamKey(&req)
+	lastEventID := parseLastEventID(c.GetHeader("Last-Event-ID"))
+
+	// Resume from the recorded stream when possible
+	if lastEventID > 0 {
+		if record := h.replay.get(key); record != nil && record.wait(c.Request.Context()) {
+			setSSEHeaders(c)
+			stream := &sseStream{c: c, flusher: flusher}
+			for _, event := range record.eventsAfter(lastEventID) {
+				if stream.write(event) != nil {
+					return
+				}
+			}
+			return
+		}
+	}
+
+	// Wait for (or fail fast on) a search slot before the stream starts, so overload is a 429
+	release, err := h.searchService.AcquireSearchSlot(c.Request.Context())
+	if err != nil {
+		respondSearchError(c, err)
+		return
+	}
+	defer release()
+
+	setSSEHeaders(c)
+	record := h.replay.start(key)
+	stream := &sseStream{c: c, flusher: flusher, lastID: lastEventID, record: record}
+	completed := false
+	defer func() { record.finish(completed) }()
+
+	// Stop the LLM stream and database work as soon as the client goes away
+	ctx, cancel := context.WithCancel(withQueryLogger(c.Request.Context(), req.Query))
+	defer cancel()
+
 	// Send initial event
-	sendSSE(c, "start", map[string]any{"query": req.Query})
-	flusher.Flush()
+	if stream.send("start", map[string]any{"query": req.Query}) != nil {
+		return
+	}
 
 	// Perform search with streaming
-	response, err := h.searchService.SearchStream(c.Request.Context(), &req, func(event string, data any) error {
-		sendSSE(c, event, data)
-		flusher.Flush()
+	response, err := h.searchService.SearchStream(ctx, &req, func(event string, data any) error {
+		if err := stream.send(event, data); err != nil {
+			cancel()
+			return err
+		}
 		return nil
 	})
 
+	if ctx.Err() != nil {
+		utils.LoggerFrom(ctx).Info("stream client disconnected, search cancelled")
+		return
+	}
 	if err != nil {
-		sendSSE(c, "error", map[string]any{"error": err.Error()})
-		flusher.Flush()
+		stream.send("error", map[string]any{"error": err.Error()})
 		return
 	}
 
 	// Send final results
-	sendSSE(c, "results", response)
-	flusher.Flush()
+	stream.send("results", response)
 
 	// Send done event
-	sendSSE(c, "done", nil)
-	flusher.Flush()
+	stream.send("done", nil)
+	completed = true
 }
 
-// sendSSE sends a Server-Sent Event
-func sendSSE(c *gin.Context, event string, data any) {
-	if data != nil {
-		jsonData, err := json.Marshal(data)
+// setSSEHeaders prepares the response for a server-sent event stream
+func setSSEHeaders(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream; charset=utf-8")
+	c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
+	c.Header("Pragma", "no-cache")
+	c.Header("Expires", "0")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Header("Transfer-Encoding", "chunked")
+}
+
+// respondSearchError writes a 429 with Retry-After when every search slot is taken, and a
+// 500 for any other search failure
+func respondSearchError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrSearchBusy) {
+		c.Header("Retry-After", "1")
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed: " + err.Error()})
+}
+
+// withQueryLogger scopes the request logger to a search query, so the intent, AI and
+// database logs of one search can be correlated
+func withQueryLogger(ctx context.Context, query string) context.Context {
+	return utils.WithLogger(ctx, utils.LoggerFrom(ctx).With("query", query))
+}
+
+// normalizeOptions applies default options, caps limits, and validates sort settings, facets,
+// and the requested fieldset. A non-empty fields query parameter overrides options.fields.
+func (h *SearchHandler) normalizeOptions(options *model.SearchOptions, fieldsParam string) (*model.SearchOptions, error) {
+	// Set default options if not provided
+	if options == nil {
+		options = &model.SearchOptions{
+			TopK:     h.defaultLimit,
+			Offset:   0,
+			Semantic: true,
+		}
+	}
+
+	// Sparse fieldsets
+	if fieldsParam != "" {
+		fields, err := model.ParseFields(fieldsParam)
 		if err != nil {
-			fmt.Fprintf(c.Writer, "event: error\ndata: {\"error\": \"JSON marshal failed\"}\n\n")
-			return
+			return nil, err
 		}
-		fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, string(jsonData))
-	} else {
-		fmt.Fprintf(c.Writer, "event: %s\ndata: {}\n\n", event)
+		options.Fields = fields
+	} else if err := model.ValidateFields(options.Fields); err != nil {
+		return nil, err
+	}
+
+	if err := model.ValidateFacets(options.Facets); err != nil {
+		return nil, err
+	}
+
+	// Validate and cap limits
+	if options.TopK <= 0 {
+		options.TopK = h.defaultLimit
+	}
+	if options.TopK > h.maxLimit {
+		options.TopK = h.maxLimit
+	}
+	if options.Offset < 0 {
+		options.Offset = 0
+	}
+	if h.maxOffset > 0 && options.Offset > h.maxOffset {
+		return nil, fmt.Errorf("offset must be at most %d", h.maxOffset)
+	}
+
+	// Validate sort settings
+	if options.SortBy != "" && options.SortBy != model.SortRelevance && !options.IsDBSort() {
+		return nil, fmt.Errorf("unsupported sort_by %q", options.SortBy)
+	}
+	if options.NullsOrder != "" && options.NullsOrder != model.NullsFirst && options.NullsOrder != model.NullsLast {
+		return nil, fmt.Errorf("nulls_order must be %q or %q", model.NullsFirst, model.NullsLast)
+	}
+
+	// Ranking weights are bound with gte=0; supplying all three as zero leaves nothing to rank by
+	if options.WeightText != nil && options.WeightPrice != nil && options.WeightRecency != nil &&
+		*options.WeightText+*options.WeightPrice+*options.WeightRecency == 0 {
+		return nil, fmt.Errorf("weight_text, weight_price and weight_recency must not all be 0")
 	}
+
+	return options, nil
 }
 
 // SearchResults handles POST /api/v1/search/results - paginated search results
 func (h *SearchHandler) SearchResults(c *gin.Context) {
 	var req model.SearchResultRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
-	// Set default options if not provided
-	if req.Options == nil {
-		req.Options = &model.SearchOptions{
-			TopK:     h.defaultLimit,
-			Offset:   0,
-			Semantic: true,
-		}
-	} else {
-		// Validate and cap limits
-		if req.Options.TopK <= 0 {
-			req.Options.TopK = h.defaultLimit
-		}
-		if req.Options.TopK > h.maxLimit {
-			req.Options.TopK = h.maxLimit
-		}
-		if req.Options.Offset < 0 {
-			req.Options.Offset = 0
-		}
+	options, err := h.normalizeOptions(req.Options, c.Query("fields"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
 	}
+	req.Options = options
 
 	// Perform search with pre-parsed filters (no AI parsing)
 	response, err := h.searchService.SearchWithFilters(c.Request.Context(), req.Filters, req.Options)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed: " + err.Error()})
+		respondSearchError(c, err)
 		return
 	}
 
-	// Return only the results and pagination info
-	result := &model.SearchResultResponse{
+	c.JSON(http.StatusOK, searchResultResponse(response))
+}
+
+// searchResultResponse keeps only the results and pagination info of a search response
+func searchResultResponse(response *model.SearchResponse) *model.SearchResultResponse {
+	return &model.SearchResultResponse{
 		Results:    response.Results,
+		Total:      response.Total,
 		Page:       response.Page,
 		PageSize:   response.PageSize,
 		TotalPages: response.TotalPages,
 		HasMore:    response.HasMore,
 		Took:       response.Took,
+		Mortgage:   response.Mortgage,
+		Facets:     response.Facets,
+
+		GeneratedAt:   response.GeneratedAt,
+		ExpiresAt:     response.ExpiresAt,
+		DataFreshness: response.DataFreshness,
+	}
+}
+
+// VectorSearch handles POST /api/v1/search/vector - nearest-neighbour search by a
+// client-computed query embedding, without an LLM call
+func (h *SearchHandler) VectorSearch(c *gin.Context) {
+	var req model.VectorSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	if err := validateQueryEmbedding(req.Embedding, h.embeddingDimensions); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	options, err := h.normalizeOptions(&model.SearchOptions{TopK: req.TopK, Semantic: true}, c.Query("fields"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	response, err := h.searchService.SearchByVector(c.Request.Context(), req.Embedding, req.Filters, options, h.vectorAggregate)
+	if err != nil {
+		respondSearchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// validateQueryEmbedding checks a query vector has the stored dimensions and a direction
+// (an all-zero vector has no cosine distance to anything)
+func validateQueryEmbedding(embedding []float32, dimensions int) error {
+	if len(embedding) != dimensions {
+		return fmt.Errorf("embedding must have %d dimensions, got %d", dimensions, len(embedding))
+	}
+	for _, x := range embedding {
+		if x != 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("embedding must not be all zeros")
+}
+
+// GreenScores handles GET /api/v1/green-scores - average green score per location.
+// ?limit keeps the greenest N locations (default all).
+func (h *SearchHandler) GreenScores(c *gin.Context) {
+	limit := 0
+	if param := c.Query("limit"); param != "" {
+		parsed, err := strconv.Atoi(param)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit. Must be a positive integer"})
+			return
+		}
+		limit = parsed
+	}
+
+	scores, err := h.searchService.GreenScoresByLocation(c.Request.Context(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get green scores: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.GreenScoresResponse{
+		Locations:   scores,
+		MinListings: h.greenScoreMinListings,
+	})
 }
 
 // GetListing handles GET /api/v1/listings/:id
@@ -220,3 +410,139 @@ func (h *SearchHandler) GetListing(c *gin.Context) {
 
 	c.JSON(http.StatusOK, listing)
 }
+
+// SimilarListings handles GET /api/v1/listings/:id/similar - the listings nearest to this
+// one's embedding. ?limit defaults to the search default and is capped at the max limit.
+func (h *SearchHandler) SimilarListings(c *gin.Context) {
+	listingID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid listing ID"})
+		return
+	}
+
+	limit := h.defaultLimit
+	if param := c.Query("limit"); param != "" {
+		parsed, err := strconv.Atoi(param)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit. Must be a positive integer"})
+			return
+		}
+		limit = parsed
+	}
+	options, err := h.normalizeOptions(&model.SearchOptions{TopK: limit, Semantic: true}, c.Query("fields"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	response, err := h.searchService.SimilarListings(c.Request.Context(), listingID, options, h.vectorAggregate)
+	if errors.Is(err, service.ErrListingNotFound) || errors.Is(err, service.ErrListingNotEmbedded) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondSearchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// defaultSuggestionLimit is the number of suggestions returned when ?limit is unset
+const defaultSuggestionLimit = 10
+
+// Suggestions handles GET /api/v1/suggestions
+func (h *SearchHandler) Suggestions(c *gin.Context) {
+	limit := defaultSuggestionLimit
+	if param := c.Query("limit"); param != "" {
+		parsed, err := strconv.Atoi(param)
+		if err != nil || parsed < 1 || parsed > 50 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit. Must be between 1 and 50"})
+			return
+		}
+		limit = parsed
+	}
+
+	suggestions, err := h.searchService.Suggestions(c.Request.Context(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get suggestions: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuggestionsResponse{Suggestions: suggestions})
+}
+
+// defaultRecentSearchLimit is the number of recent searches returned when ?limit is unset
+const defaultRecentSearchLimit = 10
+
+// RecentSearches handles GET /api/v1/searches/recent - the session cookie's recent
+// distinct queries, for a "continue where you left off" list
+func (h *SearchHandler) RecentSearches(c *gin.Context) {
+	limit := defaultRecentSearchLimit
+	if param := c.Query("limit"); param != "" {
+		parsed, err := strconv.Atoi(param)
+		if err != nil || parsed < 1 || parsed > 50 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit. Must be between 1 and 50"})
+			return
+		}
+		limit = parsed
+	}
+
+	sessionID := middleware.SessionIDOf(c)
+	if sessionID == "" {
+		c.JSON(http.StatusOK, model.RecentSearchesResponse{Searches: []model.RecentSearch{}})
+		return
+	}
+
+	searches, err := h.searchService.RecentSearches(c.Request.Context(), sessionID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get recent searches: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.RecentSearchesResponse{Searches: searches})
+}
+
+// ForgetRecentSearches handles DELETE /api/v1/searches/recent - unlinks the session
+// cookie from its logged searches
+func (h *SearchHandler) ForgetRecentSearches(c *gin.Context) {
+	sessionID := middleware.SessionIDOf(c)
+	if sessionID == "" {
+		c.JSON(http.StatusOK, gin.H{"forgotten": 0})
+		return
+	}
+
+	forgotten, err := h.searchService.ForgetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to forget recent searches: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"forgotten": forgotten})
+}
+
+// PriceAnchor handles GET /api/v1/price-anchor?location=Punggol&unit_type=Condo&bedrooms=3
+func (h *SearchHandler) PriceAnchor(c *gin.Context) {
+	var req model.PriceAnchorRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	anchor, err := h.searchService.PriceAnchor(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute price anchor: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, anchor)
+}
+
+// requestLocale returns the request's locale field, or the preferred Accept-Language locale
+// when it has none and the header is enabled, normalized to a language tag ("" = SGD defaults)
+func (h *SearchHandler) requestLocale(c *gin.Context, locale string) string {
+	if parsed := utils.ParseLocale(locale); parsed != "" || !h.localeFromHeader {
+		return parsed
+	}
+	return utils.ParseLocale(c.GetHeader("Accept-Language"))
+}
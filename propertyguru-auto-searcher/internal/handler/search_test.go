@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"core/internal/config"
+	"core/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestValidateQueryEmbedding(t *testing.T) {
+	if err := validateQueryEmbedding([]float32{0.1, 0, -0.2}, 3); err != nil {
+		t.Errorf("Expected a valid embedding, got %v", err)
+	}
+	if err := validateQueryEmbedding([]float32{0.1, 0.2}, 3); err == nil {
+		t.Error("Expected a dimension mismatch error")
+	}
+	if err := validateQueryEmbedding([]float32{0, 0, 0}, 3); err == nil {
+		t.Error("Expected an all-zero embedding to be rejected")
+	}
+}
+
+func TestSimilarListingsRejectsBadParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewSearchHandler(nil, &config.SearchConfig{DefaultLimit: 20, MaxLimit: 100}, 4, "")
+	router := gin.New()
+	router.GET("/listings/:id", handler.GetListing)
+	router.GET("/listings/:id/similar", handler.SimilarListings)
+
+	for _, path := range []string{
+		"/listings/abc/similar",
+		"/listings/42/similar?limit=0",
+		"/listings/42/similar?limit=ten",
+		"/listings/42/similar?fields=bogus",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d %s", path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestSearchResultResponseKeepsTotal(t *testing.T) {
+	// A count_only search has no results, so total is all there is to report
+	response := &model.SearchResponse{Results: []model.ListingSearchResult{}, Total: 52, Page: 1, PageSize: 20}
+	body, err := json.Marshal(searchResultResponse(response))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded["total"] != 52.0 {
+		t.Errorf("Expected total 52, got %s", body)
+	}
+}
+
+func TestSearchResultResponseKeepsFacets(t *testing.T) {
+	facets := map[string]map[string]int{"bedrooms": {"2": 4, "3": 12}, "unit_type": {"Condo": 16}}
+	response := &model.SearchResponse{Results: []model.ListingSearchResult{}, Total: 16, Facets: facets}
+	body, err := json.Marshal(searchResultResponse(response))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded model.SearchResultResponse
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Facets, facets) {
+		t.Errorf("Expected facets %v, got %s", facets, body)
+	}
+}
+
+func TestSearchResultsRejectsUnknownFacets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewSearchHandler(nil, &config.SearchConfig{DefaultLimit: 20, MaxLimit: 100}, 4, "")
+	router := gin.New()
+	router.POST("/search/results", handler.SearchResults)
+
+	// Validation runs before the search service is touched, so a nil service never runs
+	body := `{"filters": {"bedrooms": 3}, "options": {"top_k": 20, "facets": ["bedrooms", "price"]}}`
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/search/results", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown facet, got %d %s", w.Code, w.Body.String())
+	}
+}
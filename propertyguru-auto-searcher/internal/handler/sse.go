@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseEvent is a single emitted Server-Sent Event
+type sseEvent struct {
+	ID    int
+	Event string
+	Data  []byte
+}
+
+// streamRecord holds the events emitted for one streaming search so reconnecting clients can resume
+type streamRecord struct {
+	mu       sync.Mutex
+	events   []sseEvent
+	complete bool
+	done     chan struct{}
+	expires  time.Time
+}
+
+// append records an emitted event
+func (r *streamRecord) append(event sseEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// finish marks the stream as ended; complete is false when it was aborted before the final event
+func (r *streamRecord) finish(complete bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	select {
+	case <-r.done:
+		return
+	default:
+	}
+	r.complete = complete
+	close(r.done)
+}
+
+// wait blocks until the original stream ends and reports whether it completed
+func (r *streamRecord) wait(ctx context.Context) bool {
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.complete
+}
+
+// eventsAfter returns the recorded events with an ID greater than lastID
+func (r *streamRecord) eventsAfter(lastID int) []sseEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []sseEvent
+	for _, event := range r.events {
+		if event.ID > lastID {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// streamReplayCache keeps recently emitted streams keyed by request fingerprint
+type streamReplayCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	records    map[string]*streamRecord
+}
+
+// newStreamReplayCache creates a replay cache holding up to maxEntries streams (0 = unlimited);
+// a zero TTL disables replay
+func newStreamReplayCache(ttl time.Duration, maxEntries int) *streamReplayCache {
+	return &streamReplayCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		records:    make(map[string]*streamRecord),
+	}
+}
+
+// start registers a fresh record for the key, replacing any previous one. Expired records
+// are dropped, then the oldest ones while the cache is over maxEntries.
+func (c *streamReplayCache) start(key string) *streamRecord {
+	record := &streamRecord{done: make(chan struct{})}
+	if c.ttl <= 0 {
+		return record
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, r := range c.records {
+		if now.After(r.expires) {
+			delete(c.records, k)
+		}
+	}
+	record.expires = now.Add(c.ttl)
+	c.records[key] = record
+
+	// Every record shares the TTL, so the earliest to expire is the oldest
+	for c.maxEntries > 0 && len(c.records) > c.maxEntries {
+		oldestKey := ""
+		for k, r := range c.records {
+			if k == key {
+				continue
+			}
+			if oldestKey == "" || r.expires.Before(c.records[oldestKey].expires) {
+				oldestKey = k
+			}
+		}
+		delete(c.records, oldestKey)
+	}
+	return record
+}
+
+// get returns the live record for the key, if any
+func (c *streamReplayCache) get(key string) *streamRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	record, ok := c.records[key]
+	if !ok || time.Now().After(record.expires) {
+		return nil
+	}
+	return record
+}
+
+// streamKey fingerprints a request so a reconnect can find the stream it belongs to
+func streamKey(req any) string {
+	body, _ := json.Marshal(req)
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// parseLastEventID reads the Last-Event-ID header, returning 0 when absent or invalid
+func parseLastEventID(header string) int {
+	id, err := strconv.Atoi(strings.TrimSpace(header))
+	if err != nil || id < 0 {
+		return 0
+	}
+	return id
+}
+
+// sseStream writes Server-Sent Events with monotonic IDs and records them for replay
+type sseStream struct {
+	c       *gin.Context
+	flusher http.Flusher
+	lastID  int
+	record  *streamRecord
+}
+
+// send marshals data and emits it as the next event. The event is recorded even when the
+// write fails; the error reports that the client has gone away.
+func (s *sseStream) send(event string, data any) error {
+	payload := []byte("{}")
+	if data != nil {
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			event = "error"
+			jsonData = []byte(`{"error": "JSON marshal failed"}`)
+		}
+		payload = jsonData
+	}
+
+	s.lastID++
+	sseEvt := sseEvent{ID: s.lastID, Event: event, Data: payload}
+	err := s.write(sseEvt)
+	if s.record != nil {
+		s.record.append(sseEvt)
+	}
+	return err
+}
+
+// write emits an already-encoded event and flushes it, returning an error once the
+// client has disconnected
+func (s *sseStream) write(event sseEvent) error {
+	if err := s.c.Request.Context().Err(); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Event, string(event.Data)); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSSEStreamSendAfterDisconnect(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	ctx, cancel := context.WithCancel(context.Background())
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/search/stream", nil).WithContext(ctx)
+
+	record := &streamRecord{done: make(chan struct{})}
+	stream := &sseStream{c: c, flusher: c.Writer, record: record}
+
+	if err := stream.send("start", map[string]any{"query": "condo"}); err != nil {
+		t.Fatalf("Expected send to succeed while connected, got %v", err)
+	}
+
+	cancel()
+	if err := stream.send("intent", nil); err == nil {
+		t.Fatal("Expected send to fail after the client disconnected")
+	}
+	if strings.Contains(w.Body.String(), "event: intent") {
+		t.Errorf("Expected nothing written after disconnect, got %q", w.Body.String())
+	}
+	// Events are still recorded, but the aborted stream finishes incomplete, so a
+	// reconnecting client re-runs the search rather than replaying it
+	if events := record.eventsAfter(0); len(events) != 2 {
+		t.Errorf("Expected 2 recorded events, got %d", len(events))
+	}
+	record.finish(false)
+	if record.wait(context.Background()) {
+		t.Error("Expected an aborted stream not to be replayable")
+	}
+}
+
+func TestStreamReplayCacheReplaysFinishedStream(t *testing.T) {
+	cache := newStreamReplayCache(time.Minute, 10)
+	record := cache.start("a")
+	for id, event := range []string{"start", "intent", "results", "done"} {
+		record.append(sseEvent{ID: id + 1, Event: event})
+	}
+	record.finish(true)
+
+	got := cache.get("a")
+	if got != record || !got.wait(context.Background()) {
+		t.Fatal("Expected the completed record back")
+	}
+	events := got.eventsAfter(2)
+	if len(events) != 2 || events[0].Event != "results" || events[1].Event != "done" {
+		t.Errorf("Expected the events after ID 2, got %+v", events)
+	}
+	if cache.get("b") != nil {
+		t.Error("Expected no record for an unknown key")
+	}
+
+	disabled := newStreamReplayCache(0, 10)
+	disabled.start("a")
+	if disabled.get("a") != nil {
+		t.Error("Expected a zero TTL to disable replay")
+	}
+}
+
+func TestStreamReplayCacheExpires(t *testing.T) {
+	cache := newStreamReplayCache(time.Minute, 10)
+	cache.start("old").expires = time.Now().Add(-time.Second)
+	if cache.get("old") != nil {
+		t.Error("Expected an expired record to be ignored")
+	}
+
+	cache.start("new")
+	if _, ok := cache.records["old"]; ok {
+		t.Error("Expected starting a stream to drop expired records")
+	}
+	if cache.get("new") == nil {
+		t.Error("Expected the fresh record to be kept")
+	}
+}
+
+func TestStreamReplayCacheEvictsOldest(t *testing.T) {
+	cache := newStreamReplayCache(time.Minute, 2)
+	first := cache.start("first")
+	cache.start("second")
+	first.expires = time.Now().Add(time.Second) // Started earliest, so expires earliest
+	cache.start("third")
+
+	if len(cache.records) != 2 {
+		t.Errorf("Expected the cache capped at 2 records, got %d", len(cache.records))
+	}
+	if cache.get("first") != nil || cache.get("second") == nil || cache.get("third") == nil {
+		t.Errorf("Expected the oldest record evicted, got %v", cache.records)
+	}
+}
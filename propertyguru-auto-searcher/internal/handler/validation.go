@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"core/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Report JSON field names ("query") instead of Go struct field names ("Query")
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+		// "district": a postal district code such as "D09" or "district 9"
+		_ = v.RegisterValidation("district", func(fl validator.FieldLevel) bool {
+			_, ok := utils.ParseDistrict(fl.Field().String())
+			return ok
+		})
+	}
+}
+
+// respondBindError writes a 400 with a per-field error map for a failed ShouldBindJSON,
+// or a 413 when the body exceeded the request size limit
+func respondBindError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+		return
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":  "Invalid request",
+		"fields": bindingErrors(err),
+	})
+}
+
+// bindingErrors translates binding/validation errors into {"field": "message"}.
+// Nested fields use dotted JSON paths ("filters.price_min").
+func bindingErrors(err error) map[string]string {
+	fields := make(map[string]string)
+
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+
+	switch {
+	case errors.As(err, &validationErrs):
+		for _, fe := range validationErrs {
+			fields[fieldPath(fe)] = validationMessage(fe)
+		}
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+		fields[field] = "must be " + kindDescription(typeErr.Type.Kind().String())
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		fields["body"] = "is not valid JSON"
+	case errors.Is(err, io.EOF):
+		fields["body"] = "is required"
+	default:
+		fields["body"] = "is invalid"
+	}
+
+	return fields
+}
+
+// fieldPath drops the top-level struct name from the validator namespace
+func fieldPath(fe validator.FieldError) string {
+	if _, path, ok := strings.Cut(fe.Namespace(), "."); ok {
+		return path
+	}
+	return fe.Field()
+}
+
+// validationMessage renders a validator tag as a short human-readable message
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_with":
+		return fmt.Sprintf("is required with %s", strings.Join(jsonFieldNames(fe), ", "))
+	case "min", "gte":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max", "lte":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "lt":
+		return fmt.Sprintf("must be less than %s", fe.Param())
+	case "district":
+		return "must be a postal district from D01 to D28"
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(fe.Param(), " ", ", "))
+	default:
+		return "is invalid"
+	}
+}
+
+// jsonFieldNames maps the Go field names in a cross-field tag param to their JSON names
+func jsonFieldNames(fe validator.FieldError) []string {
+	names := strings.Fields(fe.Param())
+	for i, name := range names {
+		var snake strings.Builder
+		for j, r := range name {
+			if j > 0 && unicode.IsUpper(r) {
+				snake.WriteByte('_')
+			}
+			snake.WriteRune(unicode.ToLower(r))
+		}
+		names[i] = snake.String()
+	}
+	return names
+}
+
+// kindDescription describes a JSON kind for type mismatch messages ("a string", "an integer")
+func kindDescription(kind string) string {
+	switch kind {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return "an integer"
+	case "float32", "float64":
+		return "a number"
+	case "slice", "array":
+		return "an array"
+	case "map", "struct":
+		return "an object"
+	case "bool":
+		return "a boolean"
+	default:
+		return "a " + kind
+	}
+}
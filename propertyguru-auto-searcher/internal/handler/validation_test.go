@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"core/internal/middleware"
+	"core/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+func TestBindingErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want map[string]string
+	}{
+		{
+			name: "Missing required field",
+			body: `{"filters": {}}`,
+			want: map[string]string{"query": "is required"},
+		},
+		{
+			name: "Wrong type",
+			body: `{"query": "condo", "filters": {"bedrooms": "three"}}`,
+			want: map[string]string{"filters.bedrooms": "must be an integer"},
+		},
+		{
+			name: "Incomplete radius search",
+			body: `{"query": "condo", "filters": {"lat_center": 1.3, "lng_center": 103.8}}`,
+			want: map[string]string{"filters.radius_km": "is required with lat_center, lng_center"},
+		},
+		{
+			name: "Unknown amenity match mode",
+			body: `{"query": "pool or gym", "filters": {"amenities": ["pool", "gym"], "match_mode": "some"}}`,
+			want: map[string]string{"filters.match_mode": "must be one of: all, any"},
+		},
+		{
+			name: "Unknown postal district",
+			body: `{"query": "condo", "filters": {"district": "D42"}}`,
+			want: map[string]string{"filters.district": "must be a postal district from D01 to D28"},
+		},
+		{
+			name: "Malformed JSON",
+			body: `{"query": `,
+			want: map[string]string{"body": "is not valid JSON"},
+		},
+		{
+			name: "Empty body",
+			body: ``,
+			want: map[string]string{"body": "is required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req model.SearchRequest
+			err := binding.JSON.BindBody([]byte(tt.body), &req)
+			if err == nil {
+				t.Fatal("Expected binding error")
+			}
+			if got := bindingErrors(err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bindingErrors() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindingErrors_FeedbackFields(t *testing.T) {
+	var req model.FeedbackRequest
+	err := binding.JSON.BindBody([]byte(`{"action": "click"}`), &req)
+	if err == nil {
+		t.Fatal("Expected binding error")
+	}
+
+	got := bindingErrors(err)
+	for _, field := range []string{"search_id", "listing_id"} {
+		if got[field] != "is required" {
+			t.Errorf("Expected %s to be required, got %v", field, got)
+		}
+	}
+	for field := range got {
+		if strings.ContainsAny(field, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") {
+			t.Errorf("Expected JSON field names, got %q", field)
+		}
+	}
+}
+
+func TestRequestBodyTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api := router.Group("/api", middleware.MaxBodySize(64))
+	api.POST("/search", func(c *gin.Context) {
+		var req model.SearchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBindError(c, err)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	oversized := `{"query": "` + strings.Repeat("condo ", 100) + `"}`
+	tests := []struct {
+		name string
+		body io.Reader
+		want int
+	}{
+		{name: "Within limit", body: strings.NewReader(`{"query": "condo"}`), want: http.StatusOK},
+		{name: "Declared length over limit", body: strings.NewReader(oversized), want: http.StatusRequestEntityTooLarge},
+		// No Content-Length, so the limit trips while binding
+		{name: "Streamed body over limit", body: io.MultiReader(strings.NewReader(oversized)), want: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/search", tt.body)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"core/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger replaces gin's text access log with one structured line per request, and
+// stores a logger carrying the method and path in the request context for handlers and
+// services (see utils.LoggerFrom)
+func RequestLogger(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		reqLogger := logger.With("method", c.Request.Method, "path", c.Request.URL.Path)
+		c.Request = c.Request.WithContext(utils.WithLogger(c.Request.Context(), reqLogger))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		reqLogger.Log(c.Request.Context(), level, "request completed",
+			"status", status,
+			"took_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+		)
+	}
+}
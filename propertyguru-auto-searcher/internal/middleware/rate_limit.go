@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// rateLimitIdleTTL is how long a client's bucket is kept after its last request. A bucket
+// idle this long has refilled completely, so dropping it changes nothing for the client.
+const rateLimitIdleTTL = 10 * time.Minute
+
+// clientBucket is one client IP's token bucket
+type clientBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// ipRateLimiter hands out a token bucket per client IP
+type ipRateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*clientBucket
+	rps       rate.Limit
+	burst     int
+	lastSweep time.Time
+}
+
+// reserve takes a token for ip, returning how long the client must wait when none is left
+func (l *ipRateLimiter) reserve(ip string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop idle buckets now and then so the map doesn't grow with every IP ever seen
+	if now.Sub(l.lastSweep) > rateLimitIdleTTL {
+		for key, bucket := range l.buckets {
+			if now.Sub(bucket.lastSeen) > rateLimitIdleTTL {
+				delete(l.buckets, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	bucket, ok := l.buckets[ip]
+	if !ok {
+		bucket = &clientBucket{limiter: rate.NewLimiter(l.rps, l.burst)}
+		l.buckets[ip] = bucket
+	}
+	bucket.lastSeen = now
+
+	reservation := bucket.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now) // A rejected request doesn't spend a token
+		return delay, false
+	}
+	return 0, true
+}
+
+// RateLimit limits each client IP to rps requests per second with bursts of up to burst,
+// answering 429 with Retry-After (whole seconds) once the bucket is empty. It guards the
+// API routes, where a search can trigger an AI call; rps <= 0 disables it.
+func RateLimit(rps float64, burst int) gin.HandlerFunc {
+	if rps <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	limiter := &ipRateLimiter{
+		buckets:   make(map[string]*clientBucket),
+		rps:       rate.Limit(rps),
+		burst:     burst,
+		lastSweep: time.Now(),
+	}
+	return func(c *gin.Context) {
+		if delay, ok := limiter.reserve(c.ClientIP(), time.Now()); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded, please retry later"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitPerClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RateLimit(0.5, 2))
+	router.POST("/search", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	search := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/search", nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := search("10.0.0.1"); w.Code != http.StatusOK {
+			t.Fatalf("request %d within burst got %d", i+1, w.Code)
+		}
+	}
+	w := search("10.0.0.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("request over burst got %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+
+	// Other clients have their own bucket
+	if w := search("10.0.0.2"); w.Code != http.StatusOK {
+		t.Errorf("second client got %d", w.Code)
+	}
+}
+
+func TestRateLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if err := router.SetTrustedProxies([]string{"10.0.0.100"}); err != nil {
+		t.Fatal(err)
+	}
+	router.Use(RateLimit(0.5, 2))
+	router.POST("/search", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	search := func(remoteIP, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodPost, "/search", nil)
+		req.RemoteAddr = remoteIP + ":1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// A client connecting directly can't pick a new bucket by rotating the header
+	for i := 0; i < 2; i++ {
+		if code := search("10.0.0.1", fmt.Sprintf("192.0.2.%d", i)); code != http.StatusOK {
+			t.Fatalf("request %d within burst got %d", i+1, code)
+		}
+	}
+	if code := search("10.0.0.1", "192.0.2.99"); code != http.StatusTooManyRequests {
+		t.Errorf("spoofed X-Forwarded-For got %d, want 429", code)
+	}
+
+	// Behind a trusted proxy each forwarded client has its own bucket
+	for i := 0; i < 3; i++ {
+		if code := search("10.0.0.100", fmt.Sprintf("198.51.100.%d", i)); code != http.StatusOK {
+			t.Errorf("forwarded client %d got %d", i, code)
+		}
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RateLimit(0, 0))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for i := 0; i < 50; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d got %d with rate limiting disabled", i+1, w.Code)
+		}
+	}
+}
+
+func TestIPRateLimiterDropsIdleBuckets(t *testing.T) {
+	now := time.Now()
+	limiter := &ipRateLimiter{buckets: make(map[string]*clientBucket), rps: 1, burst: 1, lastSweep: now}
+	limiter.reserve("10.0.0.1", now)
+	limiter.reserve("10.0.0.2", now.Add(2*rateLimitIdleTTL))
+	if _, ok := limiter.buckets["10.0.0.1"]; ok || len(limiter.buckets) != 1 {
+		t.Errorf("idle bucket kept: %d buckets", len(limiter.buckets))
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sessionIDKey is the gin context key holding the anonymous session ID
+const sessionIDKey = "session_id"
+
+// SessionCookie issues an anonymous session cookie: a random ID with no link to the user,
+// used only to log searches for "recent searches". Requests with a valid cookie keep it;
+// others get a new one that expires after maxAge. An empty name disables the cookie.
+func SessionCookie(name string, maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if name == "" {
+			c.Next()
+			return
+		}
+
+		id, err := c.Cookie(name)
+		if err != nil || !validSessionID(id) {
+			if id = newSessionID(); id == "" {
+				c.Next()
+				return
+			}
+			http.SetCookie(c.Writer, &http.Cookie{
+				Name:     name,
+				Value:    id,
+				Path:     "/",
+				MaxAge:   int(maxAge.Seconds()),
+				HttpOnly: true,
+				Secure:   c.Request.TLS != nil,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		c.Set(sessionIDKey, id)
+		c.Next()
+	}
+}
+
+// SessionIDOf returns the request's session cookie ID, or "" when none was issued
+func SessionIDOf(c *gin.Context) string {
+	return c.GetString(sessionIDKey)
+}
+
+// newSessionID returns 128 random bits as hex, or "" if the system RNG fails
+func newSessionID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// validSessionID accepts only IDs in the format newSessionID issues
+func validSessionID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
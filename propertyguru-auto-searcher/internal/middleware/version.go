@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// API versions served side by side. Both currently share every handler and response shape;
+// a breaking response change goes to v2 only, keeping v1 stable for current consumers.
+const (
+	APIv1 = "v1"
+	APIv2 = "v2"
+)
+
+// APIVersion echoes the version of the route group a request came in on in the
+// X-API-Version response header
+func APIVersion(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("X-API-Version", version)
+		c.Next()
+	}
+}
+
+// RegisterVersions mounts the routes added by register under parent/v1 and parent/v2,
+// each tagged with its version
+func RegisterVersions(parent *gin.RouterGroup, register func(api *gin.RouterGroup)) {
+	for _, version := range []string{APIv1, APIv2} {
+		register(parent.Group("/"+version, APIVersion(version)))
+	}
+}
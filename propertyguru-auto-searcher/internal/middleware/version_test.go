@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRegisterVersionsUnderBasePath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// API_BASE_PATH normalizes to a leading slash without a trailing one, or "" for "/"
+	for _, basePath := range []string{"/custom/api", ""} {
+		router := gin.New()
+		RegisterVersions(router.Group(basePath), func(api *gin.RouterGroup) {
+			api.GET("/schema", func(c *gin.Context) { c.Status(http.StatusOK) })
+		})
+
+		for _, version := range []string{APIv1, APIv2} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, basePath+"/"+version+"/schema", nil))
+			if w.Code != http.StatusOK {
+				t.Errorf("%s/%s/schema: got %d, want 200", basePath, version, w.Code)
+			}
+			if got := w.Header().Get("X-API-Version"); got != version {
+				t.Errorf("%s/%s/schema: X-API-Version = %q", basePath, version, got)
+			}
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, basePath+"/v3/schema", nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s/v3/schema: got %d, want 404", basePath, w.Code)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package model
+
+// PurgeStaleRequest represents a request to purge listings the crawler no longer updates
+type PurgeStaleRequest struct {
+	OlderThanHours  int    `json:"older_than_hours,omitempty"` // Defaults to STALE_LISTING_HOURS
+	Mode            string `json:"mode,omitempty"`             // mark (default) or delete
+	ClearEmbeddings bool   `json:"clear_embeddings,omitempty"` // Also drop embeddings of marked listings
+	DryRun          *bool  `json:"dry_run,omitempty"`          // Defaults to true; set false to apply
+}
+
+// ExportRequest selects the listings and columns of a CSV export
+type ExportRequest struct {
+	Filters *SearchFilters `json:"filters,omitempty"`
+	Fields  []string       `json:"fields,omitempty"`                          // CSV columns, in order; default ListingFields
+	Limit   int            `json:"limit,omitempty" binding:"omitempty,gte=1"` // Maximum rows; default all matching listings
+}
+
+// ParseTestRequest represents a one-off intent parse against a chosen provider and model
+type ParseTestRequest struct {
+	Query    string `json:"query" binding:"required"`
+	Provider string `json:"provider,omitempty" binding:"omitempty,oneof=openai nvidia"` // Stream format; detected from api_base when empty
+	Model    string `json:"model,omitempty"`                                            // Chat model (default OPENAI_CHAT_MODEL)
+	APIBase  string `json:"api_base,omitempty" binding:"omitempty,url"`                 // Default OPENAI_API_BASE
+	APIKey   string `json:"api_key,omitempty"`                                          // Required when api_base differs; never logged or returned
+	Locale   string `json:"locale,omitempty"`                                           // Language tag for number formats, as in search requests
+}
+
+// WarmCacheRequest lists queries to pre-parse into the intent cache
+type WarmCacheRequest struct {
+	Queries []string `json:"queries" binding:"required,min=1,max=100"`
+}
+
+// WarmCacheResult is the outcome of warming one query
+type WarmCacheResult struct {
+	Query     string `json:"query"`
+	Success   bool   `json:"success"`
+	Cached    bool   `json:"cached"` // Already in the cache, so no AI call was made
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// WarmCacheResponse summarizes a warm-up run
+type WarmCacheResponse struct {
+	Results []WarmCacheResult `json:"results"`
+	Warmed  int               `json:"warmed"` // Newly parsed and cached
+	Cached  int               `json:"cached"` // Already cached
+	Failed  int               `json:"failed"`
+}
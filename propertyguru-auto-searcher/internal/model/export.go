@@ -0,0 +1,44 @@
+package model
+
+import "encoding/json"
+
+// ExportColumns returns the CSV columns for a fieldset: the requested fields in order, or
+// every listing field when none were requested
+func ExportColumns(fields []string) []string {
+	if len(fields) == 0 {
+		return ListingFields
+	}
+	return fields
+}
+
+// CSVRecord renders the listing's values for the given columns, using the same names and
+// encoding as its JSON: strings as-is, numbers and booleans in JSON form, arrays and objects
+// as JSON, and missing values as empty cells
+func (l *Listing) CSVRecord(columns []string) ([]string, error) {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		raw, ok := values[column]
+		if !ok || string(raw) == "null" {
+			continue
+		}
+		if raw[0] == '"' {
+			var value string
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, err
+			}
+			record[i] = value
+			continue
+		}
+		record[i] = string(raw)
+	}
+	return record, nil
+}
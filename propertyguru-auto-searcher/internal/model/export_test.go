@@ -0,0 +1,35 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCSVRecord(t *testing.T) {
+	title, price, beds := `2BR "Sky" <Suite>`, 1250000.0, 2
+	listing := Listing{
+		ListingID: 42,
+		Title:     &title,
+		Price:     &price,
+		Bedrooms:  &beds,
+		Amenities: JSONArray{"Balcony", "Gym"},
+	}
+
+	record, err := listing.CSVRecord([]string{"listing_id", "title", "price", "bedrooms", "unit_type", "amenities", "is_completed"})
+	if err != nil {
+		t.Fatalf("CSVRecord failed: %v", err)
+	}
+	want := []string{"42", title, "1250000", "2", "", `["Balcony","Gym"]`, "false"}
+	if !reflect.DeepEqual(record, want) {
+		t.Errorf("CSVRecord = %q, want %q", record, want)
+	}
+}
+
+func TestExportColumns(t *testing.T) {
+	if got := ExportColumns(nil); !reflect.DeepEqual(got, ListingFields) {
+		t.Errorf("Expected every listing field by default, got %v", got)
+	}
+	if got := ExportColumns([]string{"price", "title"}); !reflect.DeepEqual(got, []string{"price", "title"}) {
+		t.Errorf("Expected the requested order, got %v", got)
+	}
+}
@@ -0,0 +1,146 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ListingFields lists the listing fields a client may request via sparse fieldsets,
+// in select order. Each JSON field name is also its listing_info column name.
+var ListingFields = []string{
+	"id", "listing_id", "title", "price", "price_per_sqft", "bedrooms", "bathrooms",
+	"area_sqft", "unit_type", "tenure", "build_year", "mrt_station", "mrt_distance_m",
+	"location", "latitude", "longitude", "listed_date", "listed_age",
+	"green_score_value", "green_score_max", "url", "property_details",
+	"description", "description_title", "amenities", "facilities", "is_completed",
+	"created_at", "updated_at",
+}
+
+// DerivedFields are computed from listing columns rather than selected directly; they may
+// still be requested in a fieldset, which fetches their source columns via companionFields
+var DerivedFields = []string{"images", "primary_image", "lease_remaining_years", "mrt_walk_minutes"}
+
+// RequestableFields returns every field a fieldset may name: the columns plus derived fields
+func RequestableFields() []string {
+	return append(append([]string(nil), ListingFields...), DerivedFields...)
+}
+
+// rankingFields are always selected because scoring, matched reasons, and data freshness
+// depend on them, even when the client doesn't return them
+var rankingFields = map[string]bool{
+	"listing_id": true, "title": true, "price": true, "price_per_sqft": true, "bedrooms": true, "bathrooms": true,
+	"unit_type": true, "mrt_distance_m": true, "location": true, "listed_date": true,
+	"green_score_value": true, "updated_at": true, "latitude": true, "longitude": true,
+	"area_sqft": true, "tenure": true, "build_year": true, "amenities": true, "facilities": true,
+}
+
+// resultMetaFields are search result fields returned regardless of the requested fieldset
+var resultMetaFields = []string{"listing_id", "score", "matched_reasons", "share_url", "monthly_mortgage", "distance_km", "highlight"}
+
+// companionFields are also selected when a field is requested because deriving it needs them
+var companionFields = map[string][]string{
+	"price_per_sqft":        {"area_sqft"},
+	"images":                {"property_details"},
+	"primary_image":         {"property_details"},
+	"lease_remaining_years": {"property_details"},
+	"mrt_walk_minutes":      {"mrt_distance_m"},
+}
+
+// ParseFields splits a comma-separated fields parameter and validates every name
+// against ListingFields. An empty parameter means the full field set.
+func ParseFields(param string) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(param, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields, ValidateFields(fields)
+}
+
+// ValidateFields rejects any field that isn't a known listing or derived field
+func ValidateFields(fields []string) error {
+	for _, field := range fields {
+		if !isListingField(field) && !isDerivedField(field) {
+			return fmt.Errorf("unknown field %q", field)
+		}
+	}
+	return nil
+}
+
+func isListingField(field string) bool {
+	for _, known := range ListingFields {
+		if field == known {
+			return true
+		}
+	}
+	return false
+}
+
+func isDerivedField(field string) bool {
+	for _, derived := range DerivedFields {
+		if field == derived {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectColumns returns the listing_info columns to fetch for a fieldset: the requested
+// fields plus those ranking needs, or every field when none were requested.
+// Only names from ListingFields are ever returned, so the result is safe to put in SQL.
+func SelectColumns(fields []string) []string {
+	if len(fields) == 0 {
+		return ListingFields
+	}
+	requested := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		requested[field] = true
+		for _, companion := range companionFields[field] {
+			requested[companion] = true
+		}
+	}
+	columns := make([]string, 0, len(ListingFields))
+	for _, field := range ListingFields {
+		if requested[field] || rankingFields[field] {
+			columns = append(columns, field)
+		}
+	}
+	return columns
+}
+
+// SetFields restricts the JSON output of a result to the given listing fields
+// plus listing_id and the result metadata (score, matched_reasons, share_url)
+func (r *ListingSearchResult) SetFields(fields []string) {
+	r.fields = fields
+}
+
+// MarshalJSON applies the sparse fieldset set via SetFields, if any
+func (r ListingSearchResult) MarshalJSON() ([]byte, error) {
+	type plain ListingSearchResult
+	data, err := json.Marshal(plain(r))
+	if err != nil || len(r.fields) == 0 {
+		return data, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	trimmed := make(map[string]json.RawMessage, len(r.fields)+len(resultMetaFields))
+	for _, fieldset := range [][]string{resultMetaFields, r.fields} {
+		for _, field := range fieldset {
+			if value, ok := all[field]; ok {
+				trimmed[field] = value
+			}
+			// Keep the flag marking a computed value (price_per_sqft_derived)
+			if value, ok := all[field+"_derived"]; ok {
+				trimmed[field+"_derived"] = value
+			}
+		}
+	}
+	return json.Marshal(trimmed)
+}
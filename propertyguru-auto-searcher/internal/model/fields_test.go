@@ -0,0 +1,91 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields("listing_id, price,title,,url")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(fields, ",") != "listing_id,price,title,url" {
+		t.Errorf("Unexpected fields: %v", fields)
+	}
+
+	if _, err := ParseFields("price,embedding"); err == nil {
+		t.Error("Expected unknown field to be rejected")
+	}
+	if _, err := ParseFields("price; DROP TABLE listing_info"); err == nil {
+		t.Error("Expected injection attempt to be rejected")
+	}
+}
+
+func TestSelectColumns(t *testing.T) {
+	if got := SelectColumns(nil); len(got) != len(ListingFields) {
+		t.Errorf("Expected every column without a fieldset, got %v", got)
+	}
+
+	columns := strings.Join(SelectColumns([]string{"url"}), ",")
+	if !strings.Contains(columns, "url") || !strings.Contains(columns, "price") {
+		t.Errorf("Expected requested and ranking columns, got %s", columns)
+	}
+	if strings.Contains(columns, "description") {
+		t.Errorf("Expected unrequested heavy columns to be skipped, got %s", columns)
+	}
+
+	// The amenity and value boosts and their matched reasons read these even when they
+	// aren't returned; a stored price_per_sqft wins over one derived from price and area
+	selected := make(map[string]bool)
+	for _, column := range SelectColumns([]string{"url"}) {
+		selected[column] = true
+	}
+	for _, column := range []string{"amenities", "facilities", "price_per_sqft"} {
+		if !selected[column] {
+			t.Errorf("Expected %s selected for ranking, got %v", column, SelectColumns([]string{"url"}))
+		}
+	}
+
+	columns = strings.Join(SelectColumns([]string{"price_per_sqft"}), ",")
+	if !strings.Contains(columns, "area_sqft") {
+		t.Errorf("Expected area_sqft selected to derive price_per_sqft, got %s", columns)
+	}
+
+	columns = strings.Join(SelectColumns([]string{"images"}), ",")
+	if !strings.Contains(columns, "property_details") || strings.Contains(columns, "images") {
+		t.Errorf("Expected property_details selected (and no images column) for images, got %s", columns)
+	}
+}
+
+func TestListingSearchResultFieldset(t *testing.T) {
+	title := "Cozy condo"
+	price := 3500.0
+	result := ListingSearchResult{
+		Listing:        Listing{ListingID: 7, Title: &title, Price: &price},
+		Score:          0.9,
+		MatchedReasons: []string{"Within budget"},
+	}
+	result.SetFields([]string{"price"})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	for _, key := range []string{"listing_id", "price", "score", "matched_reasons"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("Expected %q in %s", key, data)
+		}
+	}
+	for _, key := range []string{"title", "created_at", "is_completed"} {
+		if _, ok := got[key]; ok {
+			t.Errorf("Expected %q to be trimmed from %s", key, data)
+		}
+	}
+}
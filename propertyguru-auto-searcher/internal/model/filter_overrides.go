@@ -0,0 +1,49 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FilterOverrides are user corrections to individual filters, keyed by filter JSON name
+// ("location"). Each listed field replaces whatever the explicit filters or AI inference
+// produced for that field only, and null clears it; unlisted fields merge as usual.
+// This backs the "edit an extracted filter, then re-search" flow.
+type FilterOverrides map[string]json.RawMessage
+
+// Apply sets every overridden field on filters
+func (o FilterOverrides) Apply(filters *SearchFilters) error {
+	v := reflect.ValueOf(filters).Elem()
+	for name, raw := range o {
+		index := filterFieldIndex(v.Type(), name)
+		if index < 0 {
+			return fmt.Errorf("unknown filter %q in filter_overrides", name)
+		}
+		field := v.Field(index)
+		value := reflect.New(field.Type())
+		if err := json.Unmarshal(raw, value.Interface()); err != nil {
+			return fmt.Errorf("invalid value for filter_overrides.%s: %w", name, err)
+		}
+		field.Set(value.Elem())
+	}
+	return nil
+}
+
+// Validate reports whether every override names a known filter with a well-typed value
+func (o FilterOverrides) Validate() error {
+	return o.Apply(&SearchFilters{})
+}
+
+// filterFieldIndex returns the index of the field with the given JSON name, or -1.
+// Server-side fields (json:"-") can't be overridden.
+func filterFieldIndex(t reflect.Type, name string) int {
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if tag != "" && tag != "-" && tag == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFilterOverridesApply(t *testing.T) {
+	location := "Tampines"
+	bedrooms := 2
+	filters := &SearchFilters{Location: &location, Bedrooms: &bedrooms}
+
+	var overrides FilterOverrides
+	if err := json.Unmarshal([]byte(`{"location": "Bedok", "bedrooms": null, "amenities": ["pool"]}`), &overrides); err != nil {
+		t.Fatal(err)
+	}
+	if err := overrides.Apply(filters); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if filters.Location == nil || *filters.Location != "Bedok" {
+		t.Errorf("Expected location to be replaced, got %v", filters.Location)
+	}
+	if filters.Bedrooms != nil {
+		t.Errorf("Expected null to clear bedrooms, got %d", *filters.Bedrooms)
+	}
+	if len(filters.Amenities) != 1 || filters.Amenities[0] != "pool" {
+		t.Errorf("Expected amenities override, got %v", filters.Amenities)
+	}
+}
+
+func TestFilterOverridesValidate(t *testing.T) {
+	tests := []struct {
+		body string
+		ok   bool
+	}{
+		{`{"price_max": 3000}`, true},
+		{`{"location": null}`, true},
+		{`{"price_max": "cheap"}`, false},
+		{`{"colour": "blue"}`, false},
+		{`{"ExcludeIDs": [1]}`, false},
+	}
+
+	for _, tt := range tests {
+		var overrides FilterOverrides
+		if err := json.Unmarshal([]byte(tt.body), &overrides); err != nil {
+			t.Fatal(err)
+		}
+		if err := overrides.Validate(); (err == nil) != tt.ok {
+			t.Errorf("Validate(%s) error = %v, want ok=%v", tt.body, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"html"
+	"strings"
+)
+
+// Tags ts_headline wraps around matched terms in Listing.Highlight
+const (
+	HighlightStart = "<mark>"
+	HighlightStop  = "</mark>"
+)
+
+// EscapeHighlight HTML-escapes the highlight snippet, keeping only the <mark> tags, so
+// clients can render it as HTML without trusting the listing description
+func (l *Listing) EscapeHighlight() {
+	if l.Highlight == nil {
+		return
+	}
+	escaped := html.EscapeString(*l.Highlight)
+	escaped = strings.ReplaceAll(escaped, html.EscapeString(HighlightStart), HighlightStart)
+	escaped = strings.ReplaceAll(escaped, html.EscapeString(HighlightStop), HighlightStop)
+	l.Highlight = &escaped
+}
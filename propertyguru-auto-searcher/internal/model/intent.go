@@ -5,20 +5,60 @@ type IntentResult struct {
 	Slots            *IntentSlots `json:"slots"`
 	SemanticKeywords []string     `json:"semantic_keywords,omitempty"`
 	Confidence       float64      `json:"confidence"`
+	AISkippedReason  string       `json:"ai_skipped_reason,omitempty"` // Why AI parsing was bypassed (e.g. token_budget_exhausted)
+	Debug            *IntentDebug `json:"debug,omitempty"`             // Raw LLM output; only returned when the request sets debug
 }
 
+// IntentDebug describes the raw LLM output behind a parsed intent
+type IntentDebug struct {
+	RawJSON        string `json:"raw_json"`                  // LLM content before JSON repair
+	Repaired       bool   `json:"repaired"`                  // Whether the raw content needed repair to parse
+	RepairStrategy string `json:"repair_strategy"`           // Strategy that parsed it: direct, markdown, extract, cleanup, truncate
+	StreamRecovery string `json:"stream_recovery,omitempty"` // partial or retry when a streamed response didn't finish cleanly
+}
+
+// AI skip reasons reported in IntentResult.AISkippedReason
+const (
+	AISkippedTokenBudget = "token_budget_exhausted"
+)
+
 // IntentSlots represents structured conditions extracted from query
 type IntentSlots struct {
 	PriceMin       *float64  `json:"price_min,omitempty"`
 	PriceMax       *float64  `json:"price_max,omitempty"`
+	PriceTarget    *float64  `json:"price_target,omitempty"`    // "around $1.2M"
 	Bedrooms       *int      `json:"bedrooms,omitempty"`
 	Bathrooms      *int      `json:"bathrooms,omitempty"`
+	BedroomsMin    *int      `json:"bedrooms_min,omitempty"`    // "at least 3 bedrooms", "3+ bedrooms"
+	BathroomsMin   *int      `json:"bathrooms_min,omitempty"`   // "2 or more bathrooms"
 	AreaSqftMin    *float64  `json:"area_sqft_min,omitempty"`   // 最小面积（平方英尺）
 	AreaSqftMax    *float64  `json:"area_sqft_max,omitempty"`   // 最大面积（平方英尺）
 	UnitType       *string   `json:"unit_type,omitempty"`
-	MRTDistanceMax *int      `json:"mrt_distance_max,omitempty"`
+	UnitTypes      []string  `json:"unit_types,omitempty"`      // Several types the user accepts ("condo or EC")
+	MRTDistanceMax *int      `json:"mrt_distance_max,omitempty"` // Walking minutes; filters use metres
+	MRTStation     *string   `json:"mrt_station,omitempty"`
+	MRTLine        *string   `json:"mrt_line,omitempty"`        // Canonical line code, e.g. "NEL"
+	MRTLines       []string  `json:"mrt_lines,omitempty"`       // Several lines the user would commute on
 	Location       *string   `json:"location,omitempty"`
+	Locations      []string  `json:"locations,omitempty"`       // Several areas the user is considering
+	District       *string   `json:"district,omitempty"`        // Postal district code, e.g. "D09"
 	BuildYearMin   *int      `json:"build_year_min,omitempty"`
+	BuildYearMax   *int      `json:"build_year_max,omitempty"`  // "older than 2000"
 	Amenities      []string  `json:"amenities,omitempty"`       // 用户需求的设施
 	Facilities     []string  `json:"facilities,omitempty"`      // 用户需求的公共设施
+
+	LeaseRemainingMin *int `json:"lease_remaining_min,omitempty"` // "at least 80 years left"
+
+	PricePerSqftMin *float64 `json:"price_per_sqft_min,omitempty"`
+	PricePerSqftMax *float64 `json:"price_per_sqft_max,omitempty"` // "under $1500 psf"
+
+	GreenScoreMin *float64 `json:"green_score_min,omitempty"` // "eco-friendly"
+
+	ExcludeUnitTypes []string `json:"exclude_unit_types,omitempty"` // "anything except HDB"
+	ExcludeLocations []string `json:"exclude_locations,omitempty"` // "not in Woodlands"
+	ExcludeKeywords  []string `json:"exclude_keywords,omitempty"`  // "not near a highway"
+
+	// SchemaVersion is the IntentSchemaVersion the slots were parsed under (0 = before
+	// versioning); cached and logged slots are brought up to date with Upgrade
+	SchemaVersion int `json:"schema_version,omitempty"`
 }
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"core/internal/utils"
+)
+
+// IntentSchemaVersion is the current IntentSlots schema. Bump it whenever a change to the
+// slots (a new field, a changed meaning or encoding) leaves older parses inconsistent, and
+// register the step upgrading the previous version in intentMigrations.
+//
+// Versions:
+//  1. Unversioned slots, logged and cached before schema_version existed; unit types, MRT
+//     lines, and locations may still be raw LLM output ("Condominium", "Circle Line", "Tg Pagar")
+//  2. Canonical unit type, MRT line code, and area names; lease_remaining_min added
+//  3. price_per_sqft_min/max, build_year_max, green_score_min, bedrooms_min and bathrooms_min
+//     ("3+ bedrooms" used to parse as exactly 3), unit_types, exclude_unit_types,
+//     exclude_locations, exclude_keywords, district, and mrt_lines added; mrt_distance_max is
+//     walking minutes, converted to metres when merged
+const IntentSchemaVersion = 3
+
+// intentMigrations upgrades slots from the keyed version to the next one
+var intentMigrations = map[int]func(*IntentSlots){
+	1: upgradeIntentSlotsV1,
+	2: upgradeIntentSlotsV2,
+}
+
+// NewIntentSlots returns empty slots stamped with the current schema version
+func NewIntentSlots() *IntentSlots {
+	return &IntentSlots{SchemaVersion: IntentSchemaVersion}
+}
+
+// Current reports whether the slots are already at IntentSchemaVersion
+func (s *IntentSlots) Current() bool {
+	return s.SchemaVersion == IntentSchemaVersion
+}
+
+// Upgrade migrates slots parsed under an older schema to IntentSchemaVersion in place.
+// Fields added since can't be recovered from the old parse and stay unset. Slots from a
+// newer schema (written by a newer deploy) are left alone and reported as an error.
+func (s *IntentSlots) Upgrade() error {
+	version := max(s.SchemaVersion, 1)
+	if version > IntentSchemaVersion {
+		return fmt.Errorf("intent schema version %d is newer than supported version %d", version, IntentSchemaVersion)
+	}
+	for ; version < IntentSchemaVersion; version++ {
+		migrate, ok := intentMigrations[version]
+		if !ok {
+			return fmt.Errorf("no migration from intent schema version %d", version)
+		}
+		migrate(s)
+	}
+	s.SchemaVersion = IntentSchemaVersion
+	return nil
+}
+
+// upgradeIntentSlotsV1 canonicalizes the values version 1 stored as the LLM wrote them,
+// dropping the ones the current filters can't use
+func upgradeIntentSlotsV1(s *IntentSlots) {
+	if s.UnitType != nil {
+		if normalized := utils.NormalizeUnitType(*s.UnitType); normalized != "" {
+			s.UnitType = &normalized
+		} else {
+			s.UnitType = nil
+		}
+	}
+	if s.MRTLine != nil {
+		if line := utils.ResolveMRTLine(*s.MRTLine); line != nil {
+			s.MRTLine = &line.Code
+		} else {
+			s.MRTLine = nil
+		}
+	}
+	if s.Location != nil {
+		if canonical := utils.CanonicalArea(*s.Location); canonical != "" {
+			s.Location = &canonical
+		} else {
+			s.Location = nil
+		}
+	}
+	for i, location := range s.Locations {
+		s.Locations[i] = utils.CanonicalArea(location)
+	}
+}
+
+// upgradeIntentSlotsV2 has nothing to convert: version 3 only added slots, which a version 2
+// parse never filled, so an upgraded intent lacks e.g. the exclusions of "anything except HDB".
+// Use INTENT_CACHE_SCHEMA_MISMATCH=reparse to recover them for cached queries.
+func upgradeIntentSlotsV2(s *IntentSlots) {}
+
+// Value implements driver.Valuer, storing the slots as JSON (search_logs.intent_slots)
+func (s *IntentSlots) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+// Scan implements sql.Scanner. Slots logged under an older schema are upgraded on read,
+// so analytics over search_logs see one consistent shape.
+func (s *IntentSlots) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*s = IntentSlots{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into IntentSlots", value)
+	}
+
+	var slots IntentSlots
+	if err := json.Unmarshal(data, &slots); err != nil {
+		return err
+	}
+	if err := slots.Upgrade(); err != nil {
+		return err
+	}
+	*s = slots
+	return nil
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIntentSlotsUpgrade(t *testing.T) {
+	unitType, line, location := "Condominium", "purple line", "Tg Pagar"
+	slots := IntentSlots{UnitType: &unitType, MRTLine: &line, Location: &location}
+	if err := slots.Upgrade(); err != nil {
+		t.Fatalf("Upgrade failed: %v", err)
+	}
+	if !slots.Current() {
+		t.Errorf("Expected schema version %d, got %d", IntentSchemaVersion, slots.SchemaVersion)
+	}
+	if *slots.UnitType != "Condo" || *slots.MRTLine != "NEL" || *slots.Location != "Tanjong Pagar" {
+		t.Errorf("Expected canonical values, got %q, %q, %q", *slots.UnitType, *slots.MRTLine, *slots.Location)
+	}
+
+	unknown := "Maglev Line"
+	slots = IntentSlots{MRTLine: &unknown}
+	if slots.Upgrade(); slots.MRTLine != nil {
+		t.Errorf("Expected an unknown line dropped, got %q", *slots.MRTLine)
+	}
+
+	newer := IntentSlots{SchemaVersion: IntentSchemaVersion + 1}
+	if err := newer.Upgrade(); err == nil {
+		t.Error("Expected an error for slots from a newer schema")
+	}
+}
+
+func TestIntentSlotsUpgradeFromV2(t *testing.T) {
+	var slots IntentSlots
+	if err := json.Unmarshal([]byte(`{"schema_version": 2, "unit_type": "Condo", "bedrooms": 3, "mrt_distance_max": 10}`), &slots); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if slots.Current() {
+		t.Fatal("Expected version 2 slots to be outdated")
+	}
+	if err := slots.Upgrade(); err != nil {
+		t.Fatalf("Upgrade failed: %v", err)
+	}
+	if !slots.Current() || *slots.UnitType != "Condo" || *slots.Bedrooms != 3 || *slots.MRTDistanceMax != 10 {
+		t.Errorf("Expected version 2 values kept, got %+v", slots)
+	}
+	if slots.BedroomsMin != nil || slots.ExcludeUnitTypes != nil || slots.District != nil {
+		t.Errorf("Expected slots added in version 3 to stay unset, got %+v", slots)
+	}
+}
+
+func TestIntentSlotsScanUpgradesLoggedSlots(t *testing.T) {
+	var slots IntentSlots
+	if err := slots.Scan([]byte(`{"unit_type": "HDB 4 Rooms", "bedrooms": 3}`)); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if !slots.Current() || *slots.UnitType != "HDB" || *slots.Bedrooms != 3 {
+		t.Errorf("Expected upgraded slots, got %+v", slots)
+	}
+
+	value, err := NewIntentSlots().Value()
+	if err != nil {
+		t.Fatalf("Value failed: %v", err)
+	}
+	var stored map[string]interface{}
+	if err := json.Unmarshal(value.([]byte), &stored); err != nil || stored["schema_version"] != float64(IntentSchemaVersion) {
+		t.Errorf("Expected logged slots stamped with the schema version, got %s", value)
+	}
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// property_details keys describing a lease more precisely than tenure and build_year
+const (
+	LeaseStartYearKey      = "lease_start_year"      // Year the lease commenced, e.g. 1998
+	LeaseRemainingYearsKey = "remaining_lease_years" // Years left as listed, e.g. 72
+)
+
+// LeaseYearsPattern finds the lease length in tenure text such as "99-year Leasehold" or
+// "999 yrs"; the repository filters with the same pattern
+const LeaseYearsPattern = `(\d+)\s*-?\s*(?:year|yr)`
+
+var leaseYearsRegexp = regexp.MustCompile(`(?i)` + LeaseYearsPattern)
+
+// IsFreehold reports whether the listing's tenure is freehold, which has no lease to run out
+func (l *Listing) IsFreehold() bool {
+	return l.Tenure != nil && strings.Contains(strings.ToLower(*l.Tenure), "freehold")
+}
+
+// DeriveLeaseRemaining fills LeaseRemainingYears for leasehold listings: the remaining years
+// listed in property_details, else the lease length in tenure minus the years since
+// lease_start_year (or build_year when the lease start isn't listed). Freehold listings and
+// those without enough data are left alone.
+func (l *Listing) DeriveLeaseRemaining(now time.Time) {
+	if l.LeaseRemainingYears != nil || l.IsFreehold() {
+		return
+	}
+	if remaining, ok := detailNumber(l.PropertyDetails, LeaseRemainingYearsKey); ok {
+		years := int(math.Round(remaining))
+		l.LeaseRemainingYears = &years
+		return
+	}
+
+	if l.Tenure == nil {
+		return
+	}
+	match := leaseYearsRegexp.FindStringSubmatch(*l.Tenure)
+	if match == nil {
+		return
+	}
+	leaseYears, err := strconv.Atoi(match[1])
+	if err != nil {
+		return
+	}
+	start, ok := detailNumber(l.PropertyDetails, LeaseStartYearKey)
+	if !ok {
+		if l.BuildYear == nil {
+			return
+		}
+		start = float64(*l.BuildYear)
+	}
+
+	years := max(0, leaseYears-(now.Year()-int(start)))
+	l.LeaseRemainingYears = &years
+}
+
+// detailNumber reads a top-level property_details value as a number, accepting JSON
+// numbers and numeric strings
+func detailNumber(details map[string]interface{}, key string) (float64, bool) {
+	switch v := details[key].(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeriveLeaseRemaining(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	leasehold, freehold, vague := "99-year Leasehold", "Freehold", "Leasehold"
+	built := 1996
+
+	tests := []struct {
+		name    string
+		listing Listing
+		want    *int
+	}{
+		{"from build year", Listing{Tenure: &leasehold, BuildYear: &built}, intRef(69)},
+		{"lease start overrides build year", Listing{Tenure: &leasehold, BuildYear: &built,
+			PropertyDetails: map[string]interface{}{LeaseStartYearKey: "2000"}}, intRef(73)},
+		{"listed remaining years win", Listing{Tenure: &leasehold, BuildYear: &built,
+			PropertyDetails: map[string]interface{}{LeaseRemainingYearsKey: 71.6}}, intRef(72)},
+		{"expired lease clamps to zero", Listing{Tenure: &leasehold, BuildYear: intRef(1900)}, intRef(0)},
+		{"freehold has no lease", Listing{Tenure: &freehold, BuildYear: &built}, nil},
+		{"no lease length", Listing{Tenure: &vague, BuildYear: &built}, nil},
+		{"no start year", Listing{Tenure: &leasehold}, nil},
+	}
+	for _, tt := range tests {
+		tt.listing.DeriveLeaseRemaining(now)
+		got := tt.listing.LeaseRemainingYears
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, deref(got), deref(tt.want))
+		}
+	}
+}
+
+func intRef(v int) *int { return &v }
+
+func deref(v *int) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
@@ -3,44 +3,172 @@ package model
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"math"
+	"strings"
 	"time"
 
+	"core/internal/utils"
+
 	"github.com/pgvector/pgvector-go"
 )
 
 // Listing represents a property listing
 type Listing struct {
-	ID               int64           `json:"id" db:"id"`
-	ListingID        int64           `json:"listing_id" db:"listing_id"`
-	Title            *string         `json:"title,omitempty" db:"title"`
-	Price            *float64        `json:"price,omitempty" db:"price"`
-	PricePerSqft     *float64        `json:"price_per_sqft,omitempty" db:"price_per_sqft"`
-	Bedrooms         *int            `json:"bedrooms,omitempty" db:"bedrooms"`
-	Bathrooms        *int            `json:"bathrooms,omitempty" db:"bathrooms"`
-	AreaSqft         *float64        `json:"area_sqft,omitempty" db:"area_sqft"`
-	UnitType         *string         `json:"unit_type,omitempty" db:"unit_type"`
-	Tenure           *string         `json:"tenure,omitempty" db:"tenure"`
-	BuildYear        *int            `json:"build_year,omitempty" db:"build_year"`
-	MRTStation       *string         `json:"mrt_station,omitempty" db:"mrt_station"`
-	MRTDistanceM     *int            `json:"mrt_distance_m,omitempty" db:"mrt_distance_m"`
-	Location         *string         `json:"location,omitempty" db:"location"`
-	Latitude         *float64        `json:"latitude,omitempty" db:"latitude"`
-	Longitude        *float64        `json:"longitude,omitempty" db:"longitude"`
-	ListedDate       *time.Time      `json:"listed_date,omitempty" db:"listed_date"`
-	ListedAge        *string         `json:"listed_age,omitempty" db:"listed_age"`
-	GreenScoreValue  *float64        `json:"green_score_value,omitempty" db:"green_score_value"`
-	GreenScoreMax    *float64        `json:"green_score_max,omitempty" db:"green_score_max"`
-	URL              *string         `json:"url,omitempty" db:"url"`
-	PropertyDetails  JSONMap         `json:"property_details,omitempty" db:"property_details"`
-	Description      *string         `json:"description,omitempty" db:"description"`
-	DescriptionTitle *string         `json:"description_title,omitempty" db:"description_title"`
-	Amenities        JSONArray       `json:"amenities,omitempty" db:"amenities"`
-	Facilities       JSONArray       `json:"facilities,omitempty" db:"facilities"`
-	IsCompleted      bool            `json:"is_completed" db:"is_completed"`
-	Embedding        pgvector.Vector `json:"-" db:"embedding"`
-	TextRank         *float64        `json:"text_rank,omitempty" db:"text_rank"` // Full-text search ranking
-	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
-	UpdatedAt        time.Time       `json:"updated_at" db:"updated_at"`
+	ID                  int64           `json:"id" db:"id"`
+	ListingID           int64           `json:"listing_id" db:"listing_id"`
+	Title               *string         `json:"title,omitempty" db:"title"`
+	Price               *float64        `json:"price,omitempty" db:"price"`
+	PricePerSqft        *float64        `json:"price_per_sqft,omitempty" db:"price_per_sqft"`
+	PricePerSqftDerived bool            `json:"price_per_sqft_derived,omitempty" db:"-"` // price_per_sqft computed from price / area_sqft
+	Bedrooms            *int            `json:"bedrooms,omitempty" db:"bedrooms"`
+	Bathrooms           *int            `json:"bathrooms,omitempty" db:"bathrooms"`
+	AreaSqft            *float64        `json:"area_sqft,omitempty" db:"area_sqft"`
+	UnitType            *string         `json:"unit_type,omitempty" db:"unit_type"`
+	Tenure              *string         `json:"tenure,omitempty" db:"tenure"`
+	BuildYear           *int            `json:"build_year,omitempty" db:"build_year"`
+	LeaseRemainingYears *int            `json:"lease_remaining_years,omitempty" db:"-"` // Derived for leasehold listings; see DeriveLeaseRemaining
+	MRTStation          *string         `json:"mrt_station,omitempty" db:"mrt_station"`
+	MRTDistanceM        *int            `json:"mrt_distance_m,omitempty" db:"mrt_distance_m"`
+	MRTWalkMinutes      *int            `json:"mrt_walk_minutes,omitempty" db:"-"` // Derived from mrt_distance_m at utils.WalkingMetersPerMinute
+	Location            *string         `json:"location,omitempty" db:"location"`
+	Latitude            *float64        `json:"latitude,omitempty" db:"latitude"`
+	Longitude           *float64        `json:"longitude,omitempty" db:"longitude"`
+	ListedDate          *time.Time      `json:"listed_date,omitempty" db:"listed_date"`
+	ListedAge           *string         `json:"listed_age,omitempty" db:"listed_age"`
+	GreenScoreValue     *float64        `json:"green_score_value,omitempty" db:"green_score_value"`
+	GreenScoreMax       *float64        `json:"green_score_max,omitempty" db:"green_score_max"`
+	URL                 *string         `json:"url,omitempty" db:"url"`
+	PropertyDetails     JSONMap         `json:"property_details,omitempty" db:"property_details"`
+	Images              []string        `json:"images,omitempty" db:"-"`        // Image URLs extracted from property_details
+	PrimaryImage        *string         `json:"primary_image,omitempty" db:"-"` // Cover/thumbnail image, when one is marked or listed first
+	Description         *string         `json:"description,omitempty" db:"description"`
+	DescriptionTitle    *string         `json:"description_title,omitempty" db:"description_title"`
+	Amenities           JSONArray       `json:"amenities,omitempty" db:"amenities"`
+	Facilities          JSONArray       `json:"facilities,omitempty" db:"facilities"`
+	IsCompleted         bool            `json:"is_completed" db:"is_completed"`
+	Embedding           pgvector.Vector `json:"-" db:"embedding"`
+	TextRank            *float64        `json:"text_rank,omitempty" db:"text_rank"`             // Full-text search ranking
+	VectorDistance      *float64        `json:"vector_distance,omitempty" db:"vector_distance"` // Cosine distance to the query (vector search only)
+	Highlight           *string         `json:"highlight,omitempty" db:"highlight"`             // Description snippet with <mark>ed query terms (options.highlight only)
+	CreatedAt           time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// DerivePricePerSqft fills a missing price_per_sqft from price / area_sqft (rounded to cents,
+// like the stored column) and flags it as derived. Listings without a positive area are left alone.
+func (l *Listing) DerivePricePerSqft() {
+	if l.PricePerSqft != nil || l.Price == nil || l.AreaSqft == nil || *l.AreaSqft <= 0 {
+		return
+	}
+	psf := math.Round(*l.Price / *l.AreaSqft * 100) / 100
+	l.PricePerSqft = &psf
+	l.PricePerSqftDerived = true
+}
+
+// DeriveMRTWalkMinutes fills MRTWalkMinutes from the distance to the nearest MRT station
+func (l *Listing) DeriveMRTWalkMinutes() {
+	if l.MRTDistanceM == nil || *l.MRTDistanceM < 0 {
+		return
+	}
+	minutes := utils.WalkMetersToMinutes(*l.MRTDistanceM)
+	l.MRTWalkMinutes = &minutes
+}
+
+// ImagePaths locates listing images inside property_details. Each path is a dot-separated
+// key path ("media.images"); an empty path disables that lookup.
+type ImagePaths struct {
+	Images  string // An image URL, an array of URLs, or an array of objects with a url/src field
+	Primary string // A single image URL or object marking the cover image
+}
+
+// imageURLKeys are the object keys an image URL is read from, in order
+var imageURLKeys = []string{"url", "src", "href"}
+
+// primaryImageKeys are boolean object keys marking an image as the cover
+var primaryImageKeys = []string{"primary", "is_primary", "cover"}
+
+// ExtractImages fills Images and PrimaryImage from property_details. The primary image is
+// the one at paths.Primary, else the first image flagged primary, else the first image.
+func (l *Listing) ExtractImages(paths ImagePaths) {
+	if len(l.PropertyDetails) == 0 {
+		return
+	}
+
+	var flagged string
+	seen := make(map[string]bool)
+	if value, ok := lookupPath(l.PropertyDetails, paths.Images); ok {
+		items, isArray := value.([]interface{})
+		if !isArray {
+			items = []interface{}{value}
+		}
+		for _, item := range items {
+			url, primary := imageURL(item)
+			if url == "" || seen[url] {
+				continue
+			}
+			seen[url] = true
+			l.Images = append(l.Images, url)
+			if primary && flagged == "" {
+				flagged = url
+			}
+		}
+	}
+
+	primary := flagged
+	if value, ok := lookupPath(l.PropertyDetails, paths.Primary); ok {
+		if url, _ := imageURL(value); url != "" {
+			primary = url
+		}
+	}
+	if primary == "" && len(l.Images) > 0 {
+		primary = l.Images[0]
+	}
+	if primary != "" {
+		l.PrimaryImage = &primary
+	}
+}
+
+// lookupPath follows a dot-separated key path through nested JSON objects
+func lookupPath(details map[string]interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return nil, false
+	}
+	var value interface{} = details
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[key]; !ok || value == nil {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// imageURL reads an http(s) URL from a string or an image object, and whether the object
+// is flagged as the primary image
+func imageURL(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		url := strings.TrimSpace(v)
+		if strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://") {
+			return url, false
+		}
+	case map[string]interface{}:
+		for _, key := range imageURLKeys {
+			if url, _ := imageURL(v[key]); url != "" {
+				primary := false
+				for _, flag := range primaryImageKeys {
+					if isSet, _ := v[flag].(bool); isSet {
+						primary = true
+					}
+				}
+				return url, primary
+			}
+		}
+	}
+	return "", false
 }
 
 // ListingSearchResult represents a search result with additional metadata
@@ -48,6 +176,12 @@ type ListingSearchResult struct {
 	Listing
 	Score          float64  `json:"score"`
 	MatchedReasons []string `json:"matched_reasons"`
+	ShareURL       string   `json:"share_url,omitempty"` // Link back into our app, tagged with the originating search
+
+	MonthlyMortgage *float64 `json:"monthly_mortgage,omitempty"` // Estimated repayment when options.mortgage is set
+	DistanceKm      *float64 `json:"distance_km,omitempty"`      // Distance from the center of a radius search
+
+	fields []string // Sparse fieldset applied when marshaling (nil = all fields)
 }
 
 // JSONArray represents a JSON array field
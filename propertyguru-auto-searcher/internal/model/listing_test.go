@@ -0,0 +1,124 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDerivePricePerSqft(t *testing.T) {
+	price, area, stored, zero := 1250000.0, 1100.0, 1500.0, 0.0
+
+	listing := Listing{Price: &price, AreaSqft: &area}
+	listing.DerivePricePerSqft()
+	if listing.PricePerSqft == nil || *listing.PricePerSqft != 1136.36 || !listing.PricePerSqftDerived {
+		t.Errorf("Expected derived psf 1136.36, got %v (derived=%v)", listing.PricePerSqft, listing.PricePerSqftDerived)
+	}
+
+	listing = Listing{Price: &price, AreaSqft: &area, PricePerSqft: &stored}
+	listing.DerivePricePerSqft()
+	if *listing.PricePerSqft != stored || listing.PricePerSqftDerived {
+		t.Errorf("Expected stored psf kept, got %v (derived=%v)", *listing.PricePerSqft, listing.PricePerSqftDerived)
+	}
+
+	listing = Listing{Price: &price, AreaSqft: &zero}
+	listing.DerivePricePerSqft()
+	if listing.PricePerSqft != nil {
+		t.Errorf("Expected no psf without a positive area, got %v", *listing.PricePerSqft)
+	}
+}
+
+func TestDeriveMRTWalkMinutes(t *testing.T) {
+	tests := []struct {
+		meters *int
+		want   *int
+	}{
+		{intRef(0), intRef(0)},
+		{intRef(400), intRef(5)},
+		{intRef(401), intRef(6)}, // Rounded up: nobody walks 5.01 minutes
+		{intRef(1200), intRef(15)},
+		{nil, nil},
+	}
+
+	for _, tt := range tests {
+		listing := Listing{MRTDistanceM: tt.meters}
+		listing.DeriveMRTWalkMinutes()
+		if (listing.MRTWalkMinutes == nil) != (tt.want == nil) || (tt.want != nil && *listing.MRTWalkMinutes != *tt.want) {
+			t.Errorf("DeriveMRTWalkMinutes(%v) = %v, want %v", tt.meters, listing.MRTWalkMinutes, tt.want)
+		}
+	}
+
+	if columns := SelectColumns([]string{"mrt_walk_minutes"}); !strings.Contains(strings.Join(columns, ","), "mrt_distance_m") {
+		t.Errorf("Expected mrt_distance_m selected for mrt_walk_minutes, got %v", columns)
+	}
+}
+
+func TestDerivedPricePerSqftInFieldset(t *testing.T) {
+	price, area := 1000000.0, 1000.0
+	result := ListingSearchResult{Listing: Listing{ListingID: 1, Price: &price, AreaSqft: &area}}
+	result.DerivePricePerSqft()
+	result.SetFields([]string{"price_per_sqft"})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"price_per_sqft_derived":true`) {
+		t.Errorf("Expected derived flag with price_per_sqft, got %s", data)
+	}
+	if strings.Contains(string(data), `"area_sqft"`) {
+		t.Errorf("Expected area_sqft left out of the fieldset, got %s", data)
+	}
+}
+
+func TestExtractImages(t *testing.T) {
+	paths := ImagePaths{Images: "media.images", Primary: "media.cover"}
+
+	listing := Listing{PropertyDetails: JSONMap{"media": map[string]interface{}{
+		"images": []interface{}{
+			"https://cdn.example.com/a.jpg",
+			map[string]interface{}{"url": "https://cdn.example.com/b.jpg", "is_primary": true},
+			"https://cdn.example.com/a.jpg",
+			"not a url",
+		},
+	}}}
+	listing.ExtractImages(paths)
+	if len(listing.Images) != 2 || listing.Images[1] != "https://cdn.example.com/b.jpg" {
+		t.Errorf("Expected 2 deduplicated image URLs, got %v", listing.Images)
+	}
+	if listing.PrimaryImage == nil || *listing.PrimaryImage != "https://cdn.example.com/b.jpg" {
+		t.Errorf("Expected the flagged image as primary, got %v", listing.PrimaryImage)
+	}
+
+	listing = Listing{PropertyDetails: JSONMap{"media": map[string]interface{}{
+		"images": "https://cdn.example.com/only.jpg",
+		"cover":  map[string]interface{}{"src": "https://cdn.example.com/cover.jpg"},
+	}}}
+	listing.ExtractImages(paths)
+	if len(listing.Images) != 1 || listing.PrimaryImage == nil || *listing.PrimaryImage != "https://cdn.example.com/cover.jpg" {
+		t.Errorf("Expected the cover path to win, got %v / %v", listing.Images, listing.PrimaryImage)
+	}
+
+	listing = Listing{PropertyDetails: JSONMap{"Tenure": "Freehold"}}
+	listing.ExtractImages(paths)
+	if listing.Images != nil || listing.PrimaryImage != nil {
+		t.Errorf("Expected no images without the key path, got %v / %v", listing.Images, listing.PrimaryImage)
+	}
+}
+
+func TestEscapeHighlight(t *testing.T) {
+	snippet := `Bright <mark>condo</mark> with <b>views</b> & a <script>x</script> <mark>pool</mark>`
+	listing := Listing{Highlight: &snippet}
+	listing.EscapeHighlight()
+
+	want := `Bright <mark>condo</mark> with &lt;b&gt;views&lt;/b&gt; &amp; a &lt;script&gt;x&lt;/script&gt; <mark>pool</mark>`
+	if *listing.Highlight != want {
+		t.Errorf("EscapeHighlight() = %q, want %q", *listing.Highlight, want)
+	}
+
+	empty := Listing{}
+	empty.EscapeHighlight()
+	if empty.Highlight != nil {
+		t.Error("Expected no highlight to stay nil")
+	}
+}
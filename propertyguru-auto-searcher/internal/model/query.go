@@ -1,37 +1,351 @@
 package model
 
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"core/internal/utils"
+)
+
 // SearchRequest represents a search query request
 type SearchRequest struct {
-	Query   string         `json:"query" binding:"required"`
-	Filters *SearchFilters `json:"filters,omitempty"`
-	Options *SearchOptions `json:"options,omitempty"`
+	Query           string          `json:"query" binding:"required"`
+	Filters         *SearchFilters  `json:"filters,omitempty"`
+	FilterOverrides FilterOverrides `json:"filter_overrides,omitempty"` // Per-field corrections that beat AI inference; null clears a field
+	Options         *SearchOptions  `json:"options,omitempty"`
+	Debug           bool            `json:"debug,omitempty"`      // Include the raw LLM output in intent.debug
+	SessionID       string          `json:"session_id,omitempty"` // Opaque client session token; links searches for exclude_seen
+	Locale          string          `json:"locale,omitempty"`     // Language tag for number formats ("de-DE", "en-IN"); defaults to Accept-Language
 }
 
 // SearchFilters represents structured search filters
 type SearchFilters struct {
-	PriceMin       *float64 `json:"price_min,omitempty"`
-	PriceMax       *float64 `json:"price_max,omitempty"`
-	Bedrooms       *int     `json:"bedrooms,omitempty"`
-	Bathrooms      *int     `json:"bathrooms,omitempty"`
-	AreaSqftMin    *float64 `json:"area_sqft_min,omitempty"` // 最小面积
-	AreaSqftMax    *float64 `json:"area_sqft_max,omitempty"` // 最大面积
-	UnitType       *string  `json:"unit_type,omitempty"`
-	MRTDistanceMax *int     `json:"mrt_distance_max,omitempty"`
-	Location       *string  `json:"location,omitempty"`
-	IsCompleted    *bool    `json:"is_completed,omitempty"`
-	Amenities      []string `json:"amenities,omitempty"`  // 必须包含的设施
-	Facilities     []string `json:"facilities,omitempty"` // 必须包含的公共设施
+	PriceMin       *float64     `json:"price_min,omitempty"`
+	PriceMax       *float64     `json:"price_max,omitempty"`
+	PriceTarget    *float64     `json:"price_target,omitempty"` // Sweet-spot price; ranks by closeness, doesn't filter
+	Bedrooms       *int         `json:"bedrooms,omitempty"`     // Exact count; BedroomsMin for "3+ bedrooms"
+	Bathrooms      *int         `json:"bathrooms,omitempty"`    // Exact count; BathroomsMin for "2+ bathrooms"
+	BedroomsMin    *int         `json:"bedrooms_min,omitempty" binding:"omitempty,gte=0,lte=10"`
+	BathroomsMin   *int         `json:"bathrooms_min,omitempty" binding:"omitempty,gte=0,lte=10"`
+	AreaSqftMin    *float64     `json:"area_sqft_min,omitempty"` // 最小面积
+	AreaSqftMax    *float64     `json:"area_sqft_max,omitempty"` // 最大面积
+	BuildYearMin   *int         `json:"build_year_min,omitempty" binding:"omitempty,gte=1900,lte=2100"`
+	BuildYearMax   *int         `json:"build_year_max,omitempty" binding:"omitempty,gte=1900,lte=2100"`
+	UnitType       StringOrList `json:"unit_type,omitempty"`  // One type or several, e.g. "Condo" or ["Condo", "EC"]
+	UnitTypes      []string     `json:"unit_types,omitempty"` // Any of several types, e.g. ["Condo", "EC"]
+	MRTDistanceMax *int         `json:"mrt_distance_max,omitempty"`
+	MRTStation     *string      `json:"mrt_station,omitempty"` // Nearest station name, e.g. "Dhoby Ghaut"
+	MRTLine        *string      `json:"mrt_line,omitempty"`    // Line code or name, e.g. "NEL" or "Circle Line"
+	MRTLines       []string     `json:"mrt_lines,omitempty"`   // Any of several lines, e.g. ["NEL", "CCL"]
+	Location       *string      `json:"location,omitempty"`
+	Locations      []string     `json:"locations,omitempty"`                             // Any of several areas, e.g. ["Punggol", "Sengkang"]
+	District       *string      `json:"district,omitempty" binding:"omitempty,district"` // Postal district, e.g. "D09"; any of its areas matches
+	IsCompleted    *bool        `json:"is_completed,omitempty"`
+	ExcludeIDs     []int64      `json:"-"`                    // Listings to leave out (set server-side by exclude_seen)
+	Amenities      []string     `json:"amenities,omitempty"`  // 必须包含的设施
+	Facilities     []string     `json:"facilities,omitempty"` // 必须包含的公共设施
+
+	// MatchMode is "all" (default, every amenity/facility required) or "any" (at least one,
+	// ranked higher the more of them match)
+	MatchMode string `json:"match_mode,omitempty" binding:"omitempty,oneof=all any"`
+
+	// ExcludeUnitTypes, ExcludeLocations, and ExcludeKeywords leave out listings of those
+	// types, in those areas, or mentioning those words in the title, description, amenities,
+	// or facilities ("anything except HDB", "not near a highway")
+	ExcludeUnitTypes []string `json:"exclude_unit_types,omitempty"`
+	ExcludeLocations []string `json:"exclude_locations,omitempty"`
+	ExcludeKeywords  []string `json:"exclude_keywords,omitempty"`
+
+	// RequireCoordinates keeps only listings with latitude and longitude, for map views
+	RequireCoordinates bool `json:"require_coordinates,omitempty"`
+
+	// LeaseRemainingMin keeps freehold listings and leasehold ones with at least this many
+	// years of lease left; listings whose lease can't be worked out are left out
+	LeaseRemainingMin *int `json:"lease_remaining_min,omitempty" binding:"omitempty,gte=1,lte=999"`
+
+	// PricePerSqftMin and PricePerSqftMax bound the price per square foot (SGD psf); listings
+	// without one are matched on price / area_sqft, the same value shown as price_per_sqft
+	PricePerSqftMin *float64 `json:"price_per_sqft_min,omitempty" binding:"omitempty,gt=0"`
+	PricePerSqftMax *float64 `json:"price_per_sqft_max,omitempty" binding:"omitempty,gt=0"`
+
+	// GreenScoreMin keeps listings whose green score (out of 5) is at least this; listings
+	// without a green score are left out
+	GreenScoreMin *float64 `json:"green_score_min,omitempty" binding:"omitempty,gte=0,lte=5"`
+
+	// LatCenter, LngCenter, and RadiusKm keep listings within RadiusKm of a point; the three
+	// are set together. Results then carry their distance_km from the point.
+	LatCenter *float64 `json:"lat_center,omitempty" binding:"required_with=LngCenter RadiusKm,omitempty,gte=-90,lte=90"`
+	LngCenter *float64 `json:"lng_center,omitempty" binding:"required_with=LatCenter RadiusKm,omitempty,gte=-180,lte=180"`
+	RadiusKm  *float64 `json:"radius_km,omitempty" binding:"required_with=LatCenter LngCenter,omitempty,gt=0,lte=100"`
+}
+
+// AllLocations returns the distinct areas from Location and Locations; a listing in any
+// of them matches
+func (f *SearchFilters) AllLocations() []string {
+	if f == nil {
+		return nil
+	}
+	candidates := f.Locations
+	if f.Location != nil {
+		candidates = append([]string{*f.Location}, candidates...)
+	}
+	return DistinctLocations(candidates...)
+}
+
+// DistinctLocations trims locations and drops blanks and case-insensitive duplicates,
+// keeping the first spelling of each
+func DistinctLocations(candidates ...string) []string {
+	var locations []string
+	seen := make(map[string]bool)
+	for _, location := range candidates {
+		key := strings.ToLower(strings.TrimSpace(location))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		locations = append(locations, strings.TrimSpace(location))
+	}
+	return locations
+}
+
+// AllUnitTypes returns the distinct unit types from UnitType and UnitTypes; a listing of
+// any of them matches. Spellings of the same canonical type ("Condo", "condominium") count once.
+func (f *SearchFilters) AllUnitTypes() []string {
+	if f == nil {
+		return nil
+	}
+	var unitTypes []string
+	seen := make(map[string]bool)
+	candidates := append(append([]string{}, f.UnitType...), f.UnitTypes...)
+	for _, unitType := range candidates {
+		unitType = strings.TrimSpace(unitType)
+		key := utils.NormalizeUnitType(unitType)
+		if key == "" {
+			key = strings.ToLower(unitType)
+		}
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unitTypes = append(unitTypes, unitType)
+	}
+	return unitTypes
+}
+
+// AllMRTLines returns the distinct MRT lines from MRTLine and MRTLines; a listing near a
+// station on any of them matches. Spellings of the same line ("NEL", "purple line") count once.
+func (f *SearchFilters) AllMRTLines() []string {
+	if f == nil {
+		return nil
+	}
+	var lines []string
+	seen := make(map[string]bool)
+	candidates := f.MRTLines
+	if f.MRTLine != nil {
+		candidates = append([]string{*f.MRTLine}, candidates...)
+	}
+	for _, line := range candidates {
+		line = strings.TrimSpace(line)
+		key := strings.ToLower(line)
+		if resolved := utils.ResolveMRTLine(line); resolved != nil {
+			key = resolved.Code
+		}
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// ExcludesUnitType reports whether ExcludeUnitTypes leaves out listings of unitType
+func (f *SearchFilters) ExcludesUnitType(unitType string) bool {
+	if f == nil {
+		return false
+	}
+	normalized := utils.NormalizeUnitType(unitType)
+	for _, excluded := range f.ExcludeUnitTypes {
+		if excludedNormalized := utils.NormalizeUnitType(excluded); excludedNormalized != "" {
+			if excludedNormalized == normalized {
+				return true
+			}
+		} else if strings.EqualFold(strings.TrimSpace(excluded), strings.TrimSpace(unitType)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Amenity/facility match modes accepted in SearchFilters.MatchMode
+const (
+	MatchAll = "all"
+	MatchAny = "any"
+)
+
+// MatchModes lists every accepted SearchFilters.MatchMode value
+var MatchModes = []string{MatchAll, MatchAny}
+
+// Radius returns the center point and radius of a radius search, if one was requested
+func (f *SearchFilters) Radius() (lat, lng, km float64, ok bool) {
+	if f == nil || f.LatCenter == nil || f.LngCenter == nil || f.RadiusKm == nil {
+		return 0, 0, 0, false
+	}
+	return *f.LatCenter, *f.LngCenter, *f.RadiusKm, true
+}
+
+// MatchesAnyAmenity reports whether amenities and facilities are soft preferences:
+// a listing needs only one of them, and matching more ranks it higher
+func (f *SearchFilters) MatchesAnyAmenity() bool {
+	return f != nil && f.MatchMode == MatchAny
 }
 
 // SearchOptions represents search options
 type SearchOptions struct {
-	TopK     int  `json:"top_k"`
-	Offset   int  `json:"offset"`
-	Semantic bool `json:"semantic"`
+	TopK        int      `json:"top_k"`
+	Offset      int      `json:"offset"`
+	Semantic    bool     `json:"semantic"`
+	SortBy      string   `json:"sort_by,omitempty"`      // relevance (default), price_asc, price_desc, area_asc, area_desc, newest, psf_asc, mrt_asc
+	NullsOrder  string   `json:"nulls_order,omitempty"`  // first or last; defaults per column from SEARCH_SORT_NULLS
+	Fields      []string `json:"fields,omitempty"`       // Listing fields to return (empty = all); also ?fields=a,b
+	ExcludeSeen bool     `json:"exclude_seen,omitempty"` // Skip listings this session already acted on (requires session_id)
+	CountOnly   bool     `json:"count_only,omitempty"`   // Return only total (empty results); skips fetching and ranking rows
+	Highlight   bool     `json:"highlight,omitempty"`    // Add a description snippet with matched terms in <mark> tags
+	Facets      []string `json:"facets,omitempty"`       // Count matches per value of these fields (unit_type, bedrooms, bathrooms)
+
+	// Mortgage adds an estimated monthly_mortgage to every priced result; unset terms use
+	// the configured defaults, so {} is enough to opt in
+	Mortgage *MortgageOptions `json:"mortgage,omitempty"`
+
+	// WeightText, WeightPrice, and WeightRecency re-weight relevance ranking for this request.
+	// Unset weights keep their configured defaults, then the three are normalized to sum to 1.
+	WeightText    *float64 `json:"weight_text,omitempty" binding:"omitempty,gte=0"`
+	WeightPrice   *float64 `json:"weight_price,omitempty" binding:"omitempty,gte=0"`
+	WeightRecency *float64 `json:"weight_recency,omitempty" binding:"omitempty,gte=0"`
+}
+
+// MortgageOptions overrides the default terms of the monthly mortgage estimate
+type MortgageOptions struct {
+	InterestRate       *float64 `json:"interest_rate,omitempty" binding:"omitempty,gte=0,lte=30"`         // Annual rate in percent
+	TenureYears        *int     `json:"tenure_years,omitempty" binding:"omitempty,gte=1,lte=35"`          // Loan term
+	DownPaymentPercent *float64 `json:"down_payment_percent,omitempty" binding:"omitempty,gte=0,lte=100"` // Share of the price paid upfront
+}
+
+// MortgageTerms are the terms a mortgage estimate was computed with
+type MortgageTerms struct {
+	InterestRate       float64 `json:"interest_rate"`
+	TenureYears        int     `json:"tenure_years"`
+	DownPaymentPercent float64 `json:"down_payment_percent"`
+}
+
+// Sort options accepted in SearchOptions.SortBy
+const (
+	SortRelevance = "relevance"
+	SortPriceAsc  = "price_asc"
+	SortPriceDesc = "price_desc"
+	SortAreaAsc   = "area_asc"
+	SortAreaDesc  = "area_desc"
+	SortNewest    = "newest"
+	SortPsfAsc    = "psf_asc"
+	SortMRTAsc    = "mrt_asc"
+)
+
+// SortOptions lists every accepted SearchOptions.SortBy value
+var SortOptions = []string{SortRelevance, SortPriceAsc, SortPriceDesc, SortAreaAsc, SortAreaDesc, SortNewest, SortPsfAsc, SortMRTAsc}
+
+// Null placement values accepted in SearchOptions.NullsOrder
+const (
+	NullsFirst = "first"
+	NullsLast  = "last"
+)
+
+// NullsOrders lists every accepted SearchOptions.NullsOrder value
+var NullsOrders = []string{NullsFirst, NullsLast}
+
+// SortColumn describes the listing column and direction behind a sort option
+type SortColumn struct {
+	Column string
+	Desc   bool
+}
+
+// SortColumns maps database-level sort options to listing_info columns.
+// Relevance is not listed because it is ordered by text rank and then re-ranked by score.
+var SortColumns = map[string]SortColumn{
+	SortPriceAsc:  {Column: "price", Desc: false},
+	SortPriceDesc: {Column: "price", Desc: true},
+	SortAreaAsc:   {Column: "area_sqft", Desc: false},
+	SortAreaDesc:  {Column: "area_sqft", Desc: true},
+	SortNewest:    {Column: "listed_date", Desc: true},
+	SortPsfAsc:    {Column: "price_per_sqft", Desc: false},
+	SortMRTAsc:    {Column: "mrt_distance_m", Desc: false},
+}
+
+// Facets accepted in SearchOptions.Facets
+const (
+	FacetUnitType  = "unit_type"
+	FacetBedrooms  = "bedrooms"
+	FacetBathrooms = "bathrooms"
+)
+
+// FacetFields lists every accepted SearchOptions.Facets value
+var FacetFields = []string{FacetUnitType, FacetBedrooms, FacetBathrooms}
+
+// FacetColumns maps each facet to the listing_info column its counts are grouped by
+var FacetColumns = map[string]string{
+	FacetUnitType:  "unit_type_normalized",
+	FacetBedrooms:  "bedrooms",
+	FacetBathrooms: "bathrooms",
+}
+
+// ValidateFacets rejects any facet that isn't in FacetFields
+func ValidateFacets(facets []string) error {
+	for _, facet := range facets {
+		if _, ok := FacetColumns[facet]; !ok {
+			return fmt.Errorf("unsupported facet %q", facet)
+		}
+	}
+	return nil
+}
+
+// WithoutFacet returns a copy of the filters without the ones on a facet's own dimension,
+// so its counts cover every value the rest of the filters allow. Exclusions are kept.
+func (f *SearchFilters) WithoutFacet(facet string) *SearchFilters {
+	var filters SearchFilters
+	if f != nil {
+		filters = *f
+	}
+	switch facet {
+	case FacetUnitType:
+		filters.UnitType = nil
+		filters.UnitTypes = nil
+	case FacetBedrooms:
+		filters.Bedrooms = nil
+		filters.BedroomsMin = nil
+	case FacetBathrooms:
+		filters.Bathrooms = nil
+		filters.BathroomsMin = nil
+	}
+	return &filters
+}
+
+// HasRankingWeights reports whether the options override any ranking weight
+func (o *SearchOptions) HasRankingWeights() bool {
+	return o != nil && (o.WeightText != nil || o.WeightPrice != nil || o.WeightRecency != nil)
+}
+
+// IsDBSort reports whether the options request a database-level sort instead of relevance ranking
+func (o *SearchOptions) IsDBSort() bool {
+	if o == nil {
+		return false
+	}
+	_, ok := SortColumns[o.SortBy]
+	return ok
 }
 
 // SearchResponse represents a search result response
 type SearchResponse struct {
+	SearchID   string                `json:"search_id,omitempty"` // Pass back in feedback requests
 	Results    []ListingSearchResult `json:"results"`
 	Total      int                   `json:"total"`
 	Page       int                   `json:"page"`
@@ -39,7 +353,59 @@ type SearchResponse struct {
 	TotalPages int                   `json:"total_pages"`
 	HasMore    bool                  `json:"has_more"`
 	Intent     *IntentResult         `json:"intent,omitempty"`
-	Took       int64                 `json:"took_ms"` // Response time in milliseconds
+	Took       int64                 `json:"took_ms"`   // Response time in milliseconds
+	IntentMs   int64                 `json:"intent_ms"` // Time spent parsing intent (LLM), part of took_ms
+	SearchMs   int64                 `json:"search_ms"` // Time spent querying and ranking, part of took_ms
+
+	// BroaderMatches holds relaxed-filter results when the strict search found too few
+	BroaderMatches *BroaderMatches `json:"broader_matches,omitempty"`
+
+	// Mortgage holds the terms behind each result's monthly_mortgage, when requested
+	Mortgage *MortgageTerms `json:"mortgage,omitempty"`
+
+	// AlternativeUnitTypes counts other unit types matching the remaining filters when a
+	// unit type search found too few results
+	AlternativeUnitTypes []UnitTypeAlternative `json:"alternative_unit_types,omitempty"`
+
+	// Conflicts lists explicit filters the query contradicted; the explicit values were used
+	Conflicts []FilterConflict `json:"conflicts,omitempty"`
+
+	// Facets holds match counts per value of each requested facet, e.g. {"bedrooms": {"3": 12}}
+	Facets map[string]map[string]int `json:"facets,omitempty"`
+
+	GeneratedAt   time.Time      `json:"generated_at"`
+	ExpiresAt     time.Time      `json:"expires_at"` // Clients should re-run the search after this
+	DataFreshness *DataFreshness `json:"data_freshness,omitempty"`
+}
+
+// BroaderMatches are extra results found by relaxing the request's filters.
+// They never duplicate the primary results and are listed separately from them.
+type BroaderMatches struct {
+	Results []ListingSearchResult `json:"results"`
+	Relaxed []string              `json:"relaxed"` // Human-readable description of each relaxation applied
+}
+
+// UnitTypeAlternative is another unit type with listings matching every other filter,
+// e.g. {"unit_type": "HDB", "count": 40, "message": "No Condo listings under $600K, but 40 HDB listings match"}
+type UnitTypeAlternative struct {
+	UnitType string `json:"unit_type"`
+	Count    int    `json:"count"`
+	Message  string `json:"message"`
+}
+
+// FilterConflict is a field where the explicit filter and the value inferred from the query
+// disagree, e.g. price_max 1000000 with a query saying "under 2 million"
+type FilterConflict struct {
+	Field    string      `json:"field"`    // Filter JSON name
+	Explicit interface{} `json:"explicit"` // Value from filters, which was applied
+	Inferred interface{} `json:"inferred"` // Value inferred from the query, which was ignored
+}
+
+// DataFreshness describes how current the listing data behind a response is
+type DataFreshness struct {
+	LastUpdatedAt *time.Time `json:"last_updated_at,omitempty"` // Latest updated_at among results (or globally when empty)
+	AgeHours      float64    `json:"age_hours"`
+	Stale         bool       `json:"stale"` // Older than SEARCH_STALE_AFTER_HOURS
 }
 
 // SearchResultRequest represents a request for paginated search results
@@ -48,14 +414,31 @@ type SearchResultRequest struct {
 	Options *SearchOptions `json:"options,omitempty"`
 }
 
+// VectorSearchRequest searches by a query embedding the client computed itself with the
+// same model as the stored listing embeddings; no LLM call is made
+type VectorSearchRequest struct {
+	Embedding []float32      `json:"embedding" binding:"required"`
+	Filters   *SearchFilters `json:"filters,omitempty"`
+	TopK      int            `json:"top_k"`
+}
+
 // SearchResultResponse represents a paginated search result response
 type SearchResultResponse struct {
 	Results    []ListingSearchResult `json:"results"`
+	Total      int                   `json:"total"` // All matches; the only count with count_only
 	Page       int                   `json:"page"`
 	PageSize   int                   `json:"page_size"`
 	TotalPages int                   `json:"total_pages"`
 	HasMore    bool                  `json:"has_more"`
 	Took       int64                 `json:"took_ms"` // Response time in milliseconds
+	Mortgage   *MortgageTerms        `json:"mortgage,omitempty"`
+
+	// Facets holds match counts per value of each requested facet, as in SearchResponse
+	Facets map[string]map[string]int `json:"facets,omitempty"`
+
+	GeneratedAt   time.Time      `json:"generated_at"`
+	ExpiresAt     time.Time      `json:"expires_at"`
+	DataFreshness *DataFreshness `json:"data_freshness,omitempty"`
 }
 
 // EmbeddingBatchRequest represents a batch embedding update request
@@ -63,11 +446,56 @@ type EmbeddingBatchRequest struct {
 	Embeddings []EmbeddingItem `json:"embeddings" binding:"required"`
 }
 
+// EmbeddingGenerateRequest asks the server to embed texts with its configured provider.
+// At most 256 texts per request, so one call can't hold an unbounded batch in memory.
+type EmbeddingGenerateRequest struct {
+	Texts []string `json:"texts" binding:"required,min=1,max=256"`
+	Model string   `json:"model,omitempty"` // Allowlisted embedding model; default OPENAI_EMBEDDING_MODEL
+}
+
+// EmbeddingGenerateResponse holds one embedding per input text, in input order
+type EmbeddingGenerateResponse struct {
+	Model      string      `json:"model"`
+	Dimensions int         `json:"dimensions"`
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
 // EmbeddingItem represents a single embedding with listing info
 type EmbeddingItem struct {
-	ListingID int64     `json:"listing_id" binding:"required"`
+	ListingID int64            `json:"listing_id" binding:"required"`
+	Embedding []float32        `json:"embedding" binding:"required"`
+	Text      string           `json:"text,omitempty"`   // The text used to generate embedding
+	Chunks    []EmbeddingChunk `json:"chunks,omitempty"` // Per-chunk vectors; replaces the listing's stored chunks when set
+}
+
+// EmbeddingChunk is the embedding of one chunk of a listing's text
+type EmbeddingChunk struct {
+	Content   string    `json:"content"`
 	Embedding []float32 `json:"embedding" binding:"required"`
-	Text      string    `json:"text,omitempty"` // The text used to generate embedding
+}
+
+// EmbeddingBackfillRequest represents a request to embed listings that have no embedding yet
+type EmbeddingBackfillRequest struct {
+	Limit int `json:"limit" binding:"omitempty,min=1,max=1000"` // Max listings to embed (default 100)
+}
+
+// EmbeddingBackfillResponse reports the outcome of an embedding backfill run. Listings are
+// embedded and committed batch by batch, so a failed batch doesn't undo earlier ones and
+// clients can retry just the listings in GenerationErrors and WriteErrors.
+type EmbeddingBackfillResponse struct {
+	Processed        int                  `json:"processed"` // Listings picked up
+	Chunks           int                  `json:"chunks"`    // Chunk vectors generated
+	Success          int                  `json:"success"`
+	Failed           int                  `json:"failed"`
+	SucceededIDs     []int64              `json:"succeeded_ids"`
+	GenerationErrors []EmbeddingItemError `json:"generation_errors,omitempty"` // The provider failed to embed these
+	WriteErrors      []EmbeddingItemError `json:"write_errors,omitempty"`      // Embedded, but storing them failed
+}
+
+// EmbeddingItemError is why one listing's embedding wasn't stored
+type EmbeddingItemError struct {
+	ListingID int64  `json:"listing_id"`
+	Error     string `json:"error"`
 }
 
 // EmbeddingBatchResponse represents the response for batch embedding update
@@ -77,13 +505,74 @@ type EmbeddingBatchResponse struct {
 	Errors  []string `json:"errors,omitempty"`
 }
 
+// Suggestion is an example query that past users searched and clicked through on
+type Suggestion struct {
+	Query string `json:"query" db:"query"`
+	Count int    `json:"count" db:"count"` // Searches with a click in the lookback window
+}
+
+// SuggestionsResponse represents the response for GET /api/v1/suggestions
+type SuggestionsResponse struct {
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// RecentSearch is a query the current session searched recently
+type RecentSearch struct {
+	Query       string    `json:"query" db:"query"`
+	ResultCount int       `json:"result_count" db:"result_count"`
+	SearchedAt  time.Time `json:"searched_at" db:"searched_at"`
+}
+
+// RecentSearchesResponse represents the response for GET /api/v1/searches/recent
+type RecentSearchesResponse struct {
+	Searches []RecentSearch `json:"searches"`
+}
+
+// PriceAnchorRequest selects the market segment for GET /api/v1/price-anchor
+type PriceAnchorRequest struct {
+	Location *string `form:"location"`
+	UnitType *string `form:"unit_type"`
+	Bedrooms *int    `form:"bedrooms" binding:"omitempty,min=0,max=10"`
+}
+
+// PriceAnchor summarizes asking prices for a market segment
+type PriceAnchor struct {
+	Location      *string  `json:"location,omitempty" db:"-"`
+	UnitType      *string  `json:"unit_type,omitempty" db:"-"`
+	Bedrooms      *int     `json:"bedrooms,omitempty" db:"-"`
+	Listings      int      `json:"listings" db:"listings"` // Listings with a price in the segment
+	PriceP25      *float64 `json:"price_p25" db:"price_p25"`
+	PriceMedian   *float64 `json:"price_median" db:"price_median"`
+	PriceP75      *float64 `json:"price_p75" db:"price_p75"`
+	PsfP25        *float64 `json:"psf_p25" db:"psf_p25"`
+	PsfMedian     *float64 `json:"psf_median" db:"psf_median"`
+	PsfP75        *float64 `json:"psf_p75" db:"psf_p75"`
+	LowConfidence bool     `json:"low_confidence" db:"-"` // Too few listings for the quartiles to be reliable
+}
+
+// LocationGreenScore is the average green score of the listings in one location
+type LocationGreenScore struct {
+	Location     string  `json:"location" db:"location"`
+	AverageScore float64 `json:"average_score" db:"average_score"`
+	Listings     int     `json:"listings" db:"listings"` // Listings with a green score in the location
+}
+
+// GreenScoresResponse represents the response for GET /api/v1/green-scores
+type GreenScoresResponse struct {
+	Locations   []LocationGreenScore `json:"locations"`
+	MinListings int                  `json:"min_listings"` // Locations with fewer scored listings are omitted
+}
+
 // FeedbackRequest represents user feedback/action
 type FeedbackRequest struct {
 	SearchID  string `json:"search_id" binding:"required"`
 	ListingID int64  `json:"listing_id" binding:"required"`
-	Action    string `json:"action" binding:"required"` // click, contact, view_details
+	Action    string `json:"action" binding:"required"` // click, contact, view_details, dismiss
 }
 
+// FeedbackActions lists every accepted FeedbackRequest.Action value
+var FeedbackActions = []string{"click", "contact", "view_details", "dismiss"}
+
 // FeedbackResponse represents feedback response
 type FeedbackResponse struct {
 	Success bool   `json:"success"`
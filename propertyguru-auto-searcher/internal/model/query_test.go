@@ -0,0 +1,81 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAllMRTLines(t *testing.T) {
+	line := "Circle Line"
+	filters := &SearchFilters{MRTLine: &line, MRTLines: []string{"ccl", "NEL", "purple line", "Monorail"}}
+	want := []string{"Circle Line", "NEL", "Monorail"}
+	if got := filters.AllMRTLines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("AllMRTLines() = %v, want %v", got, want)
+	}
+}
+
+func TestAllLocations(t *testing.T) {
+	location := "Punggol"
+	tests := []struct {
+		name    string
+		filters *SearchFilters
+		want    []string
+	}{
+		{"nil filters", nil, nil},
+		{"single location", &SearchFilters{Location: &location}, []string{"Punggol"}},
+		{"location first, duplicates dropped case-insensitively", &SearchFilters{
+			Location:  &location,
+			Locations: []string{"Sengkang", " punggol ", "SENGKANG", "Hougang"},
+		}, []string{"Punggol", "Sengkang", "Hougang"}},
+		{"blank entries skipped", &SearchFilters{Locations: []string{"", "  ", "Bedok"}}, []string{"Bedok"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filters.AllLocations(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AllLocations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithoutFacet(t *testing.T) {
+	three, two := 3, 2
+	filters := &SearchFilters{
+		UnitType:         StringOrList{"Condo"},
+		UnitTypes:        []string{"EC"},
+		ExcludeUnitTypes: []string{"HDB"},
+		Bedrooms:         &three,
+		BathroomsMin:     &two,
+	}
+
+	got := filters.WithoutFacet(FacetUnitType)
+	if got.UnitType != nil || got.UnitTypes != nil {
+		t.Errorf("Expected unit type filters cleared, got %v and %v", got.UnitType, got.UnitTypes)
+	}
+	if !reflect.DeepEqual(got.ExcludeUnitTypes, []string{"HDB"}) || got.Bedrooms != &three || got.BathroomsMin != &two {
+		t.Errorf("Expected the other filters kept, got %+v", got)
+	}
+	if !reflect.DeepEqual(filters.UnitType, StringOrList{"Condo"}) {
+		t.Error("Expected the original filters untouched")
+	}
+
+	if got := filters.WithoutFacet(FacetBedrooms); got.Bedrooms != nil || !reflect.DeepEqual(got.UnitType, StringOrList{"Condo"}) {
+		t.Errorf("Expected only bedrooms cleared, got %+v", got)
+	}
+	if got := filters.WithoutFacet(FacetBathrooms); got.BathroomsMin != nil || got.Bedrooms != &three {
+		t.Errorf("Expected only bathrooms cleared, got %+v", got)
+	}
+	if got := (*SearchFilters)(nil).WithoutFacet(FacetBedrooms); got == nil {
+		t.Error("Expected empty filters for nil, got nil")
+	}
+}
+
+func TestValidateFacets(t *testing.T) {
+	if err := ValidateFacets([]string{FacetUnitType, FacetBedrooms, FacetBathrooms}); err != nil {
+		t.Errorf("Expected supported facets to pass, got %v", err)
+	}
+	if err := ValidateFacets([]string{"bedrooms", "price"}); err == nil || err.Error() != `unsupported facet "price"` {
+		t.Errorf("Expected unsupported facet error, got %v", err)
+	}
+}
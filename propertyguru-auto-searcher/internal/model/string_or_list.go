@@ -0,0 +1,46 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// StringOrList is a JSON field that accepts one string or an array of strings,
+// e.g. "Condo" or ["Condo", "EC"]. A single value is written back as a plain string.
+type StringOrList []string
+
+// UnmarshalJSON decodes a string, an array of strings, or null
+func (l *StringOrList) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*l = nil
+		return nil
+	}
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = StringOrList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("expected a string or an array of strings: %w", err)
+	}
+	*l = list
+	return nil
+}
+
+// MarshalJSON writes one value as a string and several as an array
+func (l StringOrList) MarshalJSON() ([]byte, error) {
+	if len(l) == 1 {
+		return json.Marshal(l[0])
+	}
+	return json.Marshal([]string(l))
+}
+
+// First returns the first value, or nil when the list is empty
+func (l StringOrList) First() *string {
+	if len(l) == 0 {
+		return nil
+	}
+	first := l[0]
+	return &first
+}
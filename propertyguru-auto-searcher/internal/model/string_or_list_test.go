@@ -0,0 +1,45 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSearchFiltersUnitTypeStringOrList(t *testing.T) {
+	for _, tt := range []struct {
+		body string
+		want StringOrList
+	}{
+		{`{"unit_type": "Condo"}`, StringOrList{"Condo"}},
+		{`{"unit_type": ["Condo", "EC"]}`, StringOrList{"Condo", "EC"}},
+		{`{"unit_type": null}`, nil},
+		{`{}`, nil},
+	} {
+		var filters SearchFilters
+		if err := json.Unmarshal([]byte(tt.body), &filters); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.body, err)
+		}
+		if !reflect.DeepEqual(filters.UnitType, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.body, tt.want, filters.UnitType)
+		}
+	}
+
+	var filters SearchFilters
+	if err := json.Unmarshal([]byte(`{"unit_type": 3}`), &filters); err == nil {
+		t.Error("Expected an error for a unit_type that is neither a string nor an array")
+	}
+
+	filters = SearchFilters{UnitType: StringOrList{"Condo", "EC"}}
+	if got := filters.AllUnitTypes(); !reflect.DeepEqual(got, []string{"Condo", "EC"}) {
+		t.Errorf("Expected both unit types to match, got %v", got)
+	}
+}
+
+func TestStringOrListMarshalJSON(t *testing.T) {
+	single, _ := json.Marshal(StringOrList{"Condo"})
+	list, _ := json.Marshal(StringOrList{"Condo", "EC"})
+	if string(single) != `"Condo"` || string(list) != `["Condo","EC"]` {
+		t.Errorf("Expected a string for one value and an array for several, got %s and %s", single, list)
+	}
+}
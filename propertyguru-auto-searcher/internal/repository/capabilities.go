@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// Capabilities are optional schema features the service adapts to
+type Capabilities struct {
+	FullText bool `json:"full_text"` // listing_info.search_vector exists, so keywords are ranked with ts_rank
+}
+
+// HasColumn reports whether table has the given column in the current schema search path
+func (r *PostgresRepository) HasColumn(ctx context.Context, table, column string) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_schema = ANY(current_schemas(false)) AND table_name = $1 AND column_name = $2
+		)
+	`
+	if err := r.db.GetContext(ctx, &exists, query, table, column); err != nil {
+		return false, fmt.Errorf("failed to check column %s.%s: %w", table, column, err)
+	}
+	return exists, nil
+}
+
+// SetFullText turns keyword ranking with ts_rank on or off. With it off, searches are
+// filter-only and ordered by recency, for schemas without search_vector.
+func (r *PostgresRepository) SetFullText(enabled bool) {
+	r.noFullText = !enabled
+}
+
+// Capabilities returns the schema features in use
+func (r *PostgresRepository) Capabilities() Capabilities {
+	return Capabilities{FullText: r.FullTextEnabled()}
+}
+
+// FullTextEnabled reports whether searches rank keywords with ts_rank over search_vector
+func (r *PostgresRepository) FullTextEnabled() bool {
+	return r != nil && !r.noFullText
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// PoolStats is a snapshot of the database connection pool
+type PoolStats struct {
+	MaxOpen           int     `json:"max_open"`
+	Open              int     `json:"open"`
+	InUse             int     `json:"in_use"`
+	Idle              int     `json:"idle"`
+	WaitCount         int64   `json:"wait_count"`           // Total times a query waited for a free connection
+	WaitDurationMs    int64   `json:"wait_duration_ms"`     // Total time spent waiting
+	MaxIdleClosed     int64   `json:"max_idle_closed"`      // Closed due to PG_MAX_IDLE_CONNECTIONS
+	MaxIdleTimeClosed int64   `json:"max_idle_time_closed"` // Closed due to PG_CONN_MAX_IDLE_TIME
+	MaxLifetimeClosed int64   `json:"max_lifetime_closed"`  // Closed due to PG_CONN_MAX_LIFETIME
+	Utilization       float64 `json:"utilization"`          // in_use / max_open
+}
+
+// PoolStats returns the current connection pool statistics
+func (r *PostgresRepository) PoolStats() PoolStats {
+	stats := r.db.Stats()
+	pool := PoolStats{
+		MaxOpen:           stats.MaxOpenConnections,
+		Open:              stats.OpenConnections,
+		InUse:             stats.InUse,
+		Idle:              stats.Idle,
+		WaitCount:         stats.WaitCount,
+		WaitDurationMs:    stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:     stats.MaxIdleClosed,
+		MaxIdleTimeClosed: stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed: stats.MaxLifetimeClosed,
+	}
+	if stats.MaxOpenConnections > 0 {
+		pool.Utilization = float64(stats.InUse) / float64(stats.MaxOpenConnections)
+	}
+	return pool
+}
+
+// MonitorPool logs a warning whenever queries had to wait for a connection since the
+// previous check, a sign PG_MAX_CONNECTIONS is too small for the load. Runs until ctx is done.
+func (r *PostgresRepository) MonitorPool(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := r.PoolStats()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := r.PoolStats()
+			if waits := current.WaitCount - last.WaitCount; waits > 0 {
+				log.Printf("⚠️  DB pool saturated: %d queries waited %dms for a connection in the last %v (in use %d/%d)",
+					waits, current.WaitDurationMs-last.WaitDurationMs, interval, current.InUse, current.MaxOpen)
+			}
+			last = current
+		}
+	}
+}
@@ -11,17 +11,19 @@ import (
 	"core/internal/utils"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/pgvector/pgvector-go"
 )
 
 // PostgresRepository handles database operations
 type PostgresRepository struct {
 	db *sqlx.DB
+
+	noFullText bool // Schema lacks search_vector; skip ts_rank (see SetFullText)
 }
 
 // NewPostgresRepository creates a new PostgreSQL repository
-func NewPostgresRepository(dsn string, maxConn, maxIdleConn int) (*PostgresRepository, error) {
+func NewPostgresRepository(dsn string, maxConn, maxIdleConn int, connMaxLifetime, connMaxIdleTime time.Duration) (*PostgresRepository, error) {
 	// Disable prepared statement caching to avoid "unnamed prepared statement does not exist" errors
 	if !strings.Contains(dsn, "?") {
 		dsn += "?prefer_simple_protocol=true"
@@ -36,8 +38,8 @@ func NewPostgresRepository(dsn string, maxConn, maxIdleConn int) (*PostgresRepos
 
 	db.SetMaxOpenConns(maxConn)
 	db.SetMaxIdleConns(maxIdleConn)
-	db.SetConnMaxLifetime(5 * time.Minute) // Shorter lifetime to avoid stale connections
-	db.SetConnMaxIdleTime(2 * time.Minute) // Close idle connections sooner
+	db.SetConnMaxLifetime(connMaxLifetime) // Shorter lifetime to avoid stale connections
+	db.SetConnMaxIdleTime(connMaxIdleTime) // Close idle connections sooner
 
 	// Test connection
 	if err := db.Ping(); err != nil {
@@ -52,20 +54,175 @@ func (r *PostgresRepository) Close() error {
 	return r.db.Close()
 }
 
+// Ping verifies the database is reachable, for health checks
+func (r *PostgresRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
 // SearchWithFilters performs a filtered search with full-text search
 func (r *PostgresRepository) SearchWithFilters(
 	ctx context.Context,
 	filters *model.SearchFilters,
 	semanticKeywords []string,
-	limit, offset int,
+	options *model.SearchOptions,
 ) ([]model.Listing, int, error) {
-	// Build WHERE clause
+	whereClauses, args, argIndex := buildFilterWhere(filters, 1)
+	whereClause := strings.Join(whereClauses, " AND ")
+
+	// Count total matching records
+	total, err := r.countWhere(ctx, whereClause, args)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Pure-filter queries have nothing to rank, so skip ts_rank entirely; so do schemas
+	// without search_vector
+	searchText := strings.TrimSpace(strings.Join(semanticKeywords, " "))
+	rankArg := 0
+	if searchText != "" && r.FullTextEnabled() {
+		rankArg = argIndex
+		args = append(args, searchText)
+		argIndex++
+	}
+	selectQuery := buildSelectQuery(searchColumns(options, rankArg), whereClause, buildOrderBy(options), rankArg, argIndex)
+	args = append(args, options.TopK, options.Offset)
+
+	var listings []model.Listing
+	err = r.db.SelectContext(ctx, &listings, selectQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch listings: %w", err)
+	}
+
+	return listings, total, nil
+}
+
+// StreamWithFilters runs a filtered query over the given (whitelisted) columns and calls fn
+// with each listing as it is read, in listing_id order, so bulk exports hold one row in
+// memory rather than the whole result. limit caps the rows (0 = all). Iteration stops at the
+// first error from fn or the database, which is returned.
+func (r *PostgresRepository) StreamWithFilters(
+	ctx context.Context,
+	filters *model.SearchFilters,
+	columns []string,
+	limit int,
+	fn func(*model.Listing) error,
+) error {
+	whereClauses, args, argIndex := buildFilterWhere(filters, 1)
+	query := fmt.Sprintf("SELECT %s FROM listing_info WHERE %s ORDER BY listing_id",
+		strings.Join(columns, ", "), strings.Join(whereClauses, " AND "))
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query listings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var listing model.Listing
+		if err := rows.StructScan(&listing); err != nil {
+			return fmt.Errorf("failed to scan listing: %w", err)
+		}
+		if err := fn(&listing); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read listings: %w", err)
+	}
+	return nil
+}
+
+// CountWithFilters returns how many searchable listings match filters without fetching any rows
+func (r *PostgresRepository) CountWithFilters(ctx context.Context, filters *model.SearchFilters) (int, error) {
+	whereClauses, args, _ := buildFilterWhere(filters, 1)
+	return r.countWhere(ctx, strings.Join(whereClauses, " AND "), args)
+}
+
+// FacetCounts counts the searchable listings matching filters per value of a facet's column
+// (see model.FacetColumns). Listings without a value are left out.
+func (r *PostgresRepository) FacetCounts(ctx context.Context, filters *model.SearchFilters, facet string) (map[string]int, error) {
+	column, ok := model.FacetColumns[facet]
+	if !ok {
+		return nil, fmt.Errorf("unsupported facet %q", facet)
+	}
+	whereClauses, args, _ := buildFilterWhere(filters, 1)
+
+	var rows []struct {
+		Value string `db:"value"`
+		Count int    `db:"count"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, buildFacetQuery(column, strings.Join(whereClauses, " AND ")), args...); err != nil {
+		return nil, fmt.Errorf("failed to count %s facet: %w", facet, err)
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Value] = row.Count
+	}
+	return counts, nil
+}
+
+// buildFacetQuery groups the listing_info rows matching a WHERE clause by column
+func buildFacetQuery(column, whereClause string) string {
+	return fmt.Sprintf(
+		"SELECT %[1]s::text AS value, COUNT(*) AS count FROM listing_info WHERE %[2]s AND %[1]s IS NOT NULL GROUP BY %[1]s",
+		column, whereClause)
+}
+
+// countWhere counts listing_info rows matching a WHERE clause
+func (r *PostgresRepository) countWhere(ctx context.Context, whereClause string, args []interface{}) (int, error) {
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM listing_info WHERE %s", whereClause)
+	if err := r.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+		return 0, fmt.Errorf("failed to count results: %w", err)
+	}
+	return total, nil
+}
+
+// pricePerSqftExpr is a listing's price per sqft, falling back to price / area_sqft where
+// the stored value is missing like model.Listing.DerivePricePerSqft
+const pricePerSqftExpr = "COALESCE(price_per_sqft, price / NULLIF(area_sqft, 0))"
+
+// mrtStationKeyExpr is a listing's bare lowercased station name, mirroring utils.StationKey:
+// the station codes and the "MRT"/"LRT" suffix are stripped from labels like "NE6 Dhoby Ghaut MRT"
+const mrtStationKeyExpr = `lower(btrim(regexp_replace(mrt_station, '^([A-Za-z]{2}[0-9]+[A-Za-z]?[ /]*)+|\s+(MRT|LRT)(\s+Station)?\s*$', '', 'gi')))`
+
+// leaseRemainingExpr is the years left on a listing's lease, mirroring
+// model.Listing.DeriveLeaseRemaining: the remaining years listed in property_details, else
+// the lease length in tenure less the years since the lease started (or the building was
+// built). It is NULL when there isn't enough data.
+var leaseRemainingExpr = fmt.Sprintf(`COALESCE(
+	CASE WHEN property_details->>'%[1]s' ~ '^\s*\d+(\.\d+)?\s*$'
+		THEN round(trim(property_details->>'%[1]s')::numeric)::int END,
+	substring(lower(tenure) from '%[3]s')::int - (EXTRACT(YEAR FROM CURRENT_DATE)::int - COALESCE(
+		CASE WHEN property_details->>'%[2]s' ~ '^\s*\d{4}\s*$'
+			THEN trim(property_details->>'%[2]s')::int END,
+		build_year)))`, model.LeaseRemainingYearsKey, model.LeaseStartYearKey, model.LeaseYearsPattern)
+
+// excludeKeywordExpr keeps listings that don't mention the keyword bound at %[1]d in the
+// title, description, amenities, or facilities
+const excludeKeywordExpr = `(COALESCE(title, '') NOT ILIKE $%[1]d AND COALESCE(description, '') NOT ILIKE $%[1]d
+	AND NOT EXISTS (SELECT 1 FROM jsonb_array_elements(amenities) elem WHERE elem::text ILIKE $%[1]d)
+	AND NOT EXISTS (SELECT 1 FROM jsonb_array_elements(facilities) elem WHERE elem::text ILIKE $%[1]d))`
+
+// radiusDistanceExpr is the haversine distance in km from the radius search center to a
+// listing, mirroring utils.HaversineKm. %[1]d and %[2]d are the lat and lng placeholders.
+var radiusDistanceExpr = fmt.Sprintf(`(2 * %v * asin(LEAST(1, sqrt(
+	power(sin(radians(latitude - $%%[1]d) / 2), 2) +
+	cos(radians($%%[1]d)) * cos(radians(latitude)) * power(sin(radians(longitude - $%%[2]d) / 2), 2)))))`, utils.EarthRadiusKm)
+
+// buildFilterWhere builds the WHERE conditions shared by filtered and vector search.
+// Placeholders start at argIndex; the next free placeholder index is returned.
+func buildFilterWhere(filters *model.SearchFilters, argIndex int) ([]string, []interface{}, int) {
 	whereClauses := []string{"1=1"}
 	args := []interface{}{}
-	argIndex := 1
 
-	// Always filter for completed listings
-	whereClauses = append(whereClauses, "is_completed = true")
+	// Always filter for completed listings that haven't been marked stale
+	whereClauses = append(whereClauses, "is_completed = true", "stale_at IS NULL")
 
 	if filters != nil {
 		if filters.PriceMin != nil {
@@ -88,6 +245,16 @@ func (r *PostgresRepository) SearchWithFilters(
 			args = append(args, *filters.Bathrooms)
 			argIndex++
 		}
+		if filters.BedroomsMin != nil {
+			whereClauses = append(whereClauses, fmt.Sprintf("bedrooms >= $%d", argIndex))
+			args = append(args, *filters.BedroomsMin)
+			argIndex++
+		}
+		if filters.BathroomsMin != nil {
+			whereClauses = append(whereClauses, fmt.Sprintf("bathrooms >= $%d", argIndex))
+			args = append(args, *filters.BathroomsMin)
+			argIndex++
+		}
 		if filters.AreaSqftMin != nil {
 			whereClauses = append(whereClauses, fmt.Sprintf("area_sqft >= $%d", argIndex))
 			args = append(args, *filters.AreaSqftMin)
@@ -98,74 +265,253 @@ func (r *PostgresRepository) SearchWithFilters(
 			args = append(args, *filters.AreaSqftMax)
 			argIndex++
 		}
-		if filters.UnitType != nil {
-			whereClauses = append(whereClauses, fmt.Sprintf("unit_type ILIKE $%d", argIndex))
-			args = append(args, "%"+*filters.UnitType+"%")
+		if filters.BuildYearMin != nil {
+			whereClauses = append(whereClauses, fmt.Sprintf("build_year >= $%d", argIndex))
+			args = append(args, *filters.BuildYearMin)
 			argIndex++
 		}
+		if filters.BuildYearMax != nil {
+			whereClauses = append(whereClauses, fmt.Sprintf("build_year <= $%d", argIndex))
+			args = append(args, *filters.BuildYearMax)
+			argIndex++
+		}
+		// Comparing with NULL is never true, so unscored listings drop out
+		if filters.GreenScoreMin != nil {
+			whereClauses = append(whereClauses, fmt.Sprintf("green_score_value >= $%d", argIndex))
+			args = append(args, *filters.GreenScoreMin)
+			argIndex++
+		}
+		if filters.PricePerSqftMin != nil {
+			whereClauses = append(whereClauses, fmt.Sprintf("%s >= $%d", pricePerSqftExpr, argIndex))
+			args = append(args, *filters.PricePerSqftMin)
+			argIndex++
+		}
+		if filters.PricePerSqftMax != nil {
+			whereClauses = append(whereClauses, fmt.Sprintf("%s <= $%d", pricePerSqftExpr, argIndex))
+			args = append(args, *filters.PricePerSqftMax)
+			argIndex++
+		}
+		// A listing of any requested unit type matches. Each type is compared on the
+		// normalized column when it maps to a canonical type, otherwise fuzzily on the raw value.
+		if unitTypes := filters.AllUnitTypes(); len(unitTypes) > 0 {
+			var unitTypeConds []string
+			for _, unitType := range unitTypes {
+				if normalized := utils.NormalizeUnitType(unitType); normalized != "" {
+					unitTypeConds = append(unitTypeConds, fmt.Sprintf("unit_type_normalized = $%d", argIndex))
+					args = append(args, normalized)
+				} else {
+					unitTypeConds = append(unitTypeConds, fmt.Sprintf("unit_type ILIKE $%d", argIndex))
+					args = append(args, "%"+unitType+"%")
+				}
+				argIndex++
+			}
+			if len(unitTypeConds) == 1 {
+				whereClauses = append(whereClauses, unitTypeConds[0])
+			} else {
+				whereClauses = append(whereClauses, "("+strings.Join(unitTypeConds, " OR ")+")")
+			}
+		}
 		if filters.MRTDistanceMax != nil {
 			whereClauses = append(whereClauses, fmt.Sprintf("mrt_distance_m <= $%d", argIndex))
 			args = append(args, *filters.MRTDistanceMax)
 			argIndex++
 		}
-		if filters.Location != nil {
-			whereClauses = append(whereClauses, fmt.Sprintf("location ILIKE $%d", argIndex))
-			args = append(args, "%"+*filters.Location+"%")
+		// Stations compare exactly on the bare name, so "Woodlands" doesn't match Woodlands South
+		if filters.MRTStation != nil {
+			whereClauses = append(whereClauses, fmt.Sprintf("%s = $%d", mrtStationKeyExpr, argIndex))
+			args = append(args, utils.StationKey(*filters.MRTStation))
 			argIndex++
 		}
-		// JSONB amenities filtering - fuzzy matching with common aliases
+		// MRT line matches listings whose nearest station is on any of the requested lines;
+		// an interchange on several of them is matched once
+		if lines := filters.AllMRTLines(); len(lines) > 0 {
+			var stations []string
+			seen := make(map[string]bool)
+			for _, name := range lines {
+				lineStations := []string{name}
+				if line := utils.ResolveMRTLine(name); line != nil {
+					lineStations = line.Stations
+				}
+				for _, station := range lineStations {
+					if key := utils.StationKey(station); !seen[key] {
+						seen[key] = true
+						stations = append(stations, key)
+					}
+				}
+			}
+			whereClauses = append(whereClauses, fmt.Sprintf("%s = ANY($%d)", mrtStationKeyExpr, argIndex))
+			args = append(args, pq.Array(stations))
+			argIndex++
+		}
+		// Location matches any spelling of the resolved canonical area(s), in any of the
+		// requested locations or any area of the requested postal district
+		var locationPatterns []string
+		for _, location := range filters.AllLocations() {
+			locationPatterns = append(locationPatterns, utils.ExpandLocation(location)...)
+		}
+		if filters.District != nil {
+			locationPatterns = append(locationPatterns, utils.ExpandDistrict(*filters.District)...)
+		}
+		if len(locationPatterns) > 0 {
+			var locationConds []string
+			for _, pattern := range locationPatterns {
+				locationConds = append(locationConds, fmt.Sprintf("location ILIKE $%d", argIndex))
+				args = append(args, "%"+pattern+"%")
+				argIndex++
+			}
+			whereClauses = append(whereClauses, "("+strings.Join(locationConds, " OR ")+")")
+		}
+		// Exclusions leave out listings of an excluded type, in an excluded area, or mentioning
+		// an excluded keyword. Missing values never match, so listings without them stay in.
+		for _, unitType := range filters.ExcludeUnitTypes {
+			if normalized := utils.NormalizeUnitType(unitType); normalized != "" {
+				whereClauses = append(whereClauses, fmt.Sprintf("unit_type_normalized IS DISTINCT FROM $%d", argIndex))
+				args = append(args, normalized)
+			} else if unitType = strings.TrimSpace(unitType); unitType != "" {
+				whereClauses = append(whereClauses, fmt.Sprintf("COALESCE(unit_type, '') NOT ILIKE $%d", argIndex))
+				args = append(args, "%"+unitType+"%")
+			} else {
+				continue
+			}
+			argIndex++
+		}
+		for _, location := range filters.ExcludeLocations {
+			if strings.TrimSpace(location) == "" {
+				continue
+			}
+			for _, pattern := range utils.ExpandLocation(location) {
+				whereClauses = append(whereClauses, fmt.Sprintf("COALESCE(location, '') NOT ILIKE $%d", argIndex))
+				args = append(args, "%"+pattern+"%")
+				argIndex++
+			}
+		}
+		for _, keyword := range filters.ExcludeKeywords {
+			if keyword = strings.TrimSpace(keyword); keyword == "" {
+				continue
+			}
+			whereClauses = append(whereClauses, fmt.Sprintf(excludeKeywordExpr, argIndex))
+			args = append(args, "%"+keyword+"%")
+			argIndex++
+		}
+		// JSONB amenities and facilities filtering - fuzzy matching with common aliases.
+		// Every term is required unless match_mode is "any", which needs just one of them
+		// (the ranker then favors listings matching more).
+		var amenityConds []string
 		if len(filters.Amenities) > 0 {
-			amenityConds, amenityParams, newIndex := utils.BuildFuzzyAmenityQuery(filters.Amenities, argIndex)
-			whereClauses = append(whereClauses, amenityConds...)
+			conds, amenityParams, newIndex := utils.BuildFuzzyAmenityQuery(filters.Amenities, argIndex)
+			amenityConds = append(amenityConds, conds...)
 			args = append(args, amenityParams...)
 			argIndex = newIndex
 		}
-		// JSONB facilities filtering - fuzzy matching with common aliases
 		if len(filters.Facilities) > 0 {
-			facilityConds, facilityParams, newIndex := utils.BuildFuzzyFacilityQuery(filters.Facilities, argIndex)
-			whereClauses = append(whereClauses, facilityConds...)
+			conds, facilityParams, newIndex := utils.BuildFuzzyFacilityQuery(filters.Facilities, argIndex)
+			amenityConds = append(amenityConds, conds...)
 			args = append(args, facilityParams...)
 			argIndex = newIndex
 		}
+		if filters.MatchesAnyAmenity() && len(amenityConds) > 1 {
+			whereClauses = append(whereClauses, "("+strings.Join(amenityConds, " OR ")+")")
+		} else {
+			whereClauses = append(whereClauses, amenityConds...)
+		}
+		// Map views only want listings that can be plotted
+		if filters.RequireCoordinates {
+			whereClauses = append(whereClauses, "latitude IS NOT NULL", "longitude IS NOT NULL")
+		}
+		// Radius search only sees listings that have coordinates
+		if lat, lng, km, ok := filters.Radius(); ok {
+			whereClauses = append(whereClauses, "latitude IS NOT NULL", "longitude IS NOT NULL",
+				fmt.Sprintf(radiusDistanceExpr, argIndex, argIndex+1)+fmt.Sprintf(" <= $%d", argIndex+2))
+			args = append(args, lat, lng, km)
+			argIndex += 3
+		}
+		// Freehold never runs out; leasehold needs enough lease left
+		if filters.LeaseRemainingMin != nil {
+			whereClauses = append(whereClauses, fmt.Sprintf("(tenure ILIKE '%%freehold%%' OR %s >= $%d)", leaseRemainingExpr, argIndex))
+			args = append(args, *filters.LeaseRemainingMin)
+			argIndex++
+		}
+		if len(filters.ExcludeIDs) > 0 {
+			whereClauses = append(whereClauses, fmt.Sprintf("listing_id <> ALL($%d)", argIndex))
+			args = append(args, pq.Array(filters.ExcludeIDs))
+			argIndex++
+		}
 	}
 
-	whereClause := strings.Join(whereClauses, " AND ")
+	return whereClauses, args, argIndex
+}
 
-	// Count total matching records
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM listing_info WHERE %s", whereClause)
-	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count results: %w", err)
+// buildSelectQuery builds the listing SELECT over the given (whitelisted) columns.
+// rankArg is the placeholder index of the full-text search text; 0 means no keywords,
+// and text_rank is a constant instead. LIMIT and OFFSET use placeholders limitArg and limitArg+1.
+func buildSelectQuery(columns []string, whereClause, orderBy string, rankArg, limitArg int) string {
+	textRank := "0::real"
+	if rankArg > 0 {
+		textRank = fmt.Sprintf("ts_rank(search_vector, plainto_tsquery('english', $%d))", rankArg)
 	}
 
-	// Build SELECT query with full-text search ranking
-	selectQuery := fmt.Sprintf(`
+	return fmt.Sprintf(`
 		SELECT 
-			id, listing_id, title, price, price_per_sqft, bedrooms, bathrooms,
-			area_sqft, unit_type, tenure, build_year, mrt_station, mrt_distance_m,
-			location, latitude, longitude, listed_date, listed_age,
-			green_score_value, green_score_max, url, property_details,
-			description, description_title, amenities, facilities, is_completed,
-			created_at, updated_at,
-			ts_rank(search_vector, plainto_tsquery('english', $%d)) as text_rank
+			%s,
+			%s as text_rank
 		FROM listing_info
 		WHERE %s
-		ORDER BY text_rank DESC, listed_date DESC NULLS LAST
+		ORDER BY %s
 		LIMIT $%d OFFSET $%d
-	`, argIndex, whereClause, argIndex+1, argIndex+2)
+	`, strings.Join(columns, ", "), textRank, whereClause, orderBy, limitArg, limitArg+1)
+}
 
-	// Add semantic keywords for full-text search
-	searchText := strings.Join(semanticKeywords, " ")
-	args = append(args, searchText, limit, offset)
+// searchColumns returns the select list for a search page: the requested fieldset, plus the
+// highlight snippet when asked for and there is search text (bound at rankArg) to match
+func searchColumns(options *model.SearchOptions, rankArg int) []string {
+	columns := model.SelectColumns(options.Fields)
+	if !options.Highlight || rankArg == 0 {
+		return columns
+	}
+	// Full slice expression: SelectColumns may hand back the shared ListingFields
+	return append(columns[:len(columns):len(columns)], highlightColumn(rankArg))
+}
 
-	var listings []model.Listing
-	err = r.db.SelectContext(ctx, &listings, selectQuery, args...)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to fetch listings: %w", err)
+// highlightOptions configures the ts_headline snippet: a couple of short fragments around the matches
+const highlightOptions = "StartSel=" + model.HighlightStart + ", StopSel=" + model.HighlightStop +
+	", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" ... \""
+
+// highlightColumn selects the description snippet matching the search text bound at queryArg
+// (NULL for listings without a description)
+func highlightColumn(queryArg int) string {
+	return fmt.Sprintf("NULLIF(ts_headline('english', COALESCE(description, ''), plainto_tsquery('english', $%d), '%s'), '') AS highlight",
+		queryArg, highlightOptions)
+}
+
+// buildOrderBy builds the ORDER BY clause for the requested sort option.
+// NULL placement is explicit so pagination stays deterministic whatever the direction,
+// and listing_id is appended as a final tiebreaker.
+func buildOrderBy(options *model.SearchOptions) string {
+	if options == nil || !options.IsDBSort() {
+		return "text_rank DESC, listed_date DESC NULLS LAST, listing_id"
 	}
 
-	return listings, total, nil
+	sortCol := model.SortColumns[options.SortBy]
+	direction := "ASC"
+	if sortCol.Desc {
+		direction = "DESC"
+	}
+	nulls := "NULLS LAST"
+	if options.NullsOrder == model.NullsFirst {
+		nulls = "NULLS FIRST"
+	}
+
+	orderExpr := sortCol.Column
+	if expr, ok := sortExprs[sortCol.Column]; ok {
+		orderExpr = expr
+	}
+	return fmt.Sprintf("%s %s %s, listing_id", orderExpr, direction, nulls)
+}
+
+// sortExprs orders by an expression instead of the bare column, so listings without a
+// stored price_per_sqft sort by the value derived from price / area_sqft
+var sortExprs = map[string]string{
+	"price_per_sqft": pricePerSqftExpr,
 }
 
 // GetListingByID retrieves a single listing by its ID
@@ -192,6 +538,23 @@ func (r *PostgresRepository) GetListingByID(ctx context.Context, listingID int64
 	return &listing, nil
 }
 
+// ListingEmbedding returns a completed listing's listing-level embedding. found is false
+// when there is no such listing; a nil embedding means it hasn't been embedded yet.
+func (r *PostgresRepository) ListingEmbedding(ctx context.Context, listingID int64) (embedding []float32, found bool, err error) {
+	var vec *pgvector.Vector
+	query := `SELECT embedding FROM listing_info WHERE listing_id = $1 AND is_completed = true`
+	if err := r.db.GetContext(ctx, &vec, query, listingID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get listing embedding: %w", err)
+	}
+	if vec == nil {
+		return nil, true, nil
+	}
+	return vec.Slice(), true, nil
+}
+
 // UpdateEmbedding updates the embedding vector for a listing
 func (r *PostgresRepository) UpdateEmbedding(ctx context.Context, listingID int64, embedding []float32) error {
 	vec := pgvector.NewVector(embedding)
@@ -203,50 +566,392 @@ func (r *PostgresRepository) UpdateEmbedding(ctx context.Context, listingID int6
 	return nil
 }
 
-// BatchUpdateEmbeddings updates embeddings for multiple listings
+// BatchUpdateEmbeddings updates embeddings for multiple listings.
+// The whole batch is re-run when the transaction hits a serialization failure or deadlock.
 func (r *PostgresRepository) BatchUpdateEmbeddings(ctx context.Context, items []model.EmbeddingItem) (int, []string) {
-	success := 0
+	succeeded, failures := r.BatchUpdateEmbeddingsDetailed(ctx, items)
+
 	var errors []string
+	for _, failure := range failures {
+		errors = append(errors, fmt.Sprintf("listing_id %d: %s", failure.ListingID, failure.Error))
+	}
+	return len(succeeded), errors
+}
+
+// BatchUpdateEmbeddingsDetailed is BatchUpdateEmbeddings reporting which listings were
+// written and why each of the others failed. When the transaction itself fails, every
+// item is reported as failed with that error.
+func (r *PostgresRepository) BatchUpdateEmbeddingsDetailed(ctx context.Context, items []model.EmbeddingItem) ([]int64, []model.EmbeddingItemError) {
+	var succeeded []int64
+	var failures []model.EmbeddingItemError
+
+	err := retryTransient(ctx, txMaxAttempts, txRetryBackoff, func() error {
+		var err error
+		succeeded, failures, err = r.batchUpdateEmbeddingsTx(ctx, items)
+		return err
+	})
+	if err != nil {
+		failures = make([]model.EmbeddingItemError, len(items))
+		for i, item := range items {
+			failures[i] = model.EmbeddingItemError{ListingID: item.ListingID, Error: err.Error()}
+		}
+		return nil, failures
+	}
+
+	return succeeded, failures
+}
+
+// batchUpdateEmbeddingsTx runs one attempt of the batch update in a single transaction.
+// Each item runs under its own savepoint, so a failed item is rolled back and collected while
+// the rest of the batch still commits; an error is returned only when the transaction itself
+// failed and nothing was committed.
+func (r *PostgresRepository) batchUpdateEmbeddingsTx(ctx context.Context, items []model.EmbeddingItem) ([]int64, []model.EmbeddingItemError, error) {
+	var succeeded []int64
+	var failures []model.EmbeddingItemError
 
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		errors = append(errors, fmt.Sprintf("failed to start transaction: %v", err))
-		return success, errors
+		return nil, nil, fmt.Errorf("failed to start transaction: %w", err)
 	}
 	defer tx.Rollback()
 
 	stmt, err := tx.PreparexContext(ctx, `UPDATE listing_info SET embedding = $1, updated_at = NOW() WHERE listing_id = $2`)
 	if err != nil {
-		errors = append(errors, fmt.Sprintf("failed to prepare statement: %v", err))
-		return success, errors
+		return nil, nil, fmt.Errorf("failed to prepare statement: %w", err)
 	}
 	defer stmt.Close()
 
 	for _, item := range items {
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT embedding_item`); err != nil {
+			return nil, nil, fmt.Errorf("failed to create savepoint: %w", err)
+		}
 		vec := pgvector.NewVector(item.Embedding)
 		_, err := stmt.ExecContext(ctx, vec, item.ListingID)
+		if err == nil && item.Chunks != nil {
+			err = replaceEmbeddingChunks(ctx, tx, item.ListingID, item.Chunks)
+		}
 		if err != nil {
-			errors = append(errors, fmt.Sprintf("listing_id %d: %v", item.ListingID, err))
+			// Serialization failures and deadlocks abort the whole transaction; re-run the batch
+			if isRetriableTxError(err) {
+				return nil, nil, fmt.Errorf("listing_id %d: %w", item.ListingID, err)
+			}
+			// Undo just this item so the transaction can go on with the rest
+			if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT embedding_item`); rbErr != nil {
+				return nil, nil, fmt.Errorf("failed to roll back listing_id %d: %w", item.ListingID, rbErr)
+			}
+			failures = append(failures, model.EmbeddingItemError{ListingID: item.ListingID, Error: err.Error()})
 			continue
 		}
-		success++
+		if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT embedding_item`); err != nil {
+			return nil, nil, fmt.Errorf("failed to release savepoint: %w", err)
+		}
+		succeeded = append(succeeded, item.ListingID)
 	}
 
 	if err := tx.Commit(); err != nil {
-		errors = append(errors, fmt.Sprintf("failed to commit transaction: %v", err))
-		return 0, errors
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return success, errors
+	return succeeded, failures, nil
 }
 
-// LogSearch logs a search query
-func (r *PostgresRepository) LogSearch(ctx context.Context, query string, slots *model.IntentSlots, keywords []string, resultCount int, listingIDs []int64, responseTimeMs int) error {
+// replaceEmbeddingChunks swaps a listing's stored chunk embeddings for chunks
+func replaceEmbeddingChunks(ctx context.Context, tx *sqlx.Tx, listingID int64, chunks []model.EmbeddingChunk) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM listing_embedding_chunks WHERE listing_id = $1`, listingID); err != nil {
+		return fmt.Errorf("failed to clear chunks: %w", err)
+	}
+	for i, chunk := range chunks {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO listing_embedding_chunks (listing_id, chunk_index, content, embedding) VALUES ($1, $2, $3, $4)`,
+			listingID, i, chunk.Content, pgvector.NewVector(chunk.Embedding))
+		if err != nil {
+			return fmt.Errorf("failed to insert chunk %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// EmbeddingSource is the text of a listing that still needs an embedding
+type EmbeddingSource struct {
+	ListingID        int64   `db:"listing_id"`
+	Title            *string `db:"title"`
+	DescriptionTitle *string `db:"description_title"`
+	Description      *string `db:"description"`
+}
+
+// ListingsMissingEmbeddings returns up to limit searchable listings without an embedding
+func (r *PostgresRepository) ListingsMissingEmbeddings(ctx context.Context, limit int) ([]EmbeddingSource, error) {
+	var sources []EmbeddingSource
+	query := `
+		SELECT listing_id, title, description_title, description
+		FROM listing_info
+		WHERE embedding IS NULL AND is_completed = true AND stale_at IS NULL
+		ORDER BY updated_at DESC
+		LIMIT $1`
+	if err := r.db.SelectContext(ctx, &sources, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to list listings missing embeddings: %w", err)
+	}
+	return sources, nil
+}
+
+// LatestListingUpdate returns the most recent updated_at across searchable listings
+func (r *PostgresRepository) LatestListingUpdate(ctx context.Context) (*time.Time, error) {
+	var latest sql.NullTime
+	query := `SELECT MAX(updated_at) FROM listing_info WHERE is_completed = true AND stale_at IS NULL`
+	if err := r.db.GetContext(ctx, &latest, query); err != nil {
+		return nil, fmt.Errorf("failed to get latest listing update: %w", err)
+	}
+	if !latest.Valid {
+		return nil, nil
+	}
+	return &latest.Time, nil
+}
+
+// Stale listing purge modes
+const (
+	PurgeModeMark   = "mark"   // Set stale_at so searches skip the listing until it is re-crawled
+	PurgeModeDelete = "delete" // Delete the listing (media and feedback cascade)
+)
+
+// StaleListingPurge is the outcome of a stale listing purge
+type StaleListingPurge struct {
+	Mode              string    `json:"mode"`
+	DryRun            bool      `json:"dry_run"`
+	Cutoff            time.Time `json:"cutoff"`               // Listings last updated before this are stale
+	Matched           int64     `json:"matched"`              // Stale listings found
+	Affected          int64     `json:"affected"`             // Listings marked or deleted (0 in dry-run)
+	EmbeddingsCleared int64     `json:"embeddings_cleared"`   // Embeddings removed (or that would be, in dry-run)
+	SampleIDs         []int64   `json:"sample_ids,omitempty"` // Up to 20 affected listing IDs
+}
+
+// staleSampleSize caps the listing IDs reported by PurgeStaleListings
+const staleSampleSize = 20
+
+// PurgeStaleListings marks or deletes listings not updated within olderThan of the latest crawl.
+// The cutoff is relative to the newest updated_at (not just now) so a paused crawler doesn't
+// make every listing look stale. With dryRun, only counts and sample IDs are returned.
+func (r *PostgresRepository) PurgeStaleListings(
+	ctx context.Context,
+	olderThan time.Duration,
+	mode string,
+	clearEmbeddings bool,
+	dryRun bool,
+) (*StaleListingPurge, error) {
+	var cutoff sql.NullTime
+	cutoffQuery := `SELECT LEAST(NOW(), MAX(updated_at)) - make_interval(secs => $1) FROM listing_info WHERE stale_at IS NULL`
+	if err := r.db.GetContext(ctx, &cutoff, cutoffQuery, olderThan.Seconds()); err != nil {
+		return nil, fmt.Errorf("failed to compute stale cutoff: %w", err)
+	}
+
+	result := &StaleListingPurge{Mode: mode, DryRun: dryRun}
+	if !cutoff.Valid {
+		return result, nil
+	}
+	result.Cutoff = cutoff.Time
+
+	// Marking only considers listings not already marked; deleting includes them
+	staleCondition := "updated_at < $1"
+	if mode == PurgeModeMark {
+		staleCondition += " AND stale_at IS NULL"
+	}
+
+	var counts struct {
+		Matched        int64 `db:"matched"`
+		WithEmbeddings int64 `db:"with_embeddings"`
+	}
+	countQuery := `SELECT COUNT(*) AS matched, COUNT(embedding) AS with_embeddings FROM listing_info WHERE ` + staleCondition
+	if err := r.db.GetContext(ctx, &counts, countQuery, cutoff.Time); err != nil {
+		return nil, fmt.Errorf("failed to count stale listings: %w", err)
+	}
+	result.Matched = counts.Matched
+
+	if dryRun {
+		// Report what would be purged
+		if clearEmbeddings || mode == PurgeModeDelete {
+			result.EmbeddingsCleared = counts.WithEmbeddings
+		}
+		sampleQuery := `SELECT listing_id FROM listing_info WHERE ` + staleCondition + ` ORDER BY updated_at LIMIT $2`
+		if err := r.db.SelectContext(ctx, &result.SampleIDs, sampleQuery, cutoff.Time, staleSampleSize); err != nil {
+			return nil, fmt.Errorf("failed to list stale listings: %w", err)
+		}
+		return result, nil
+	}
+
+	// Lock the stale rows first so embeddings can be reported as they were before the purge
+	staleCTE := `WITH stale AS (
+			SELECT id, listing_id, embedding IS NOT NULL AS had_embedding
+			FROM listing_info WHERE ` + staleCondition + `
+			FOR UPDATE
+		)`
+	var query string
+	switch mode {
+	case PurgeModeDelete:
+		query = staleCTE + `
+			DELETE FROM listing_info l USING stale
+			WHERE l.id = stale.id
+			RETURNING l.listing_id, stale.had_embedding`
+	case PurgeModeMark:
+		embeddingSet := ""
+		if clearEmbeddings {
+			embeddingSet = ", embedding = NULL"
+			// Chunk embeddings go with the listing-level one
+			staleCTE += `, cleared_chunks AS (
+			DELETE FROM listing_embedding_chunks c USING stale
+			WHERE c.listing_id = stale.listing_id
+		)`
+		}
+		query = staleCTE + `
+			UPDATE listing_info l SET stale_at = NOW()` + embeddingSet + `
+			FROM stale
+			WHERE l.id = stale.id
+			RETURNING l.listing_id, stale.had_embedding`
+	default:
+		return nil, fmt.Errorf("unsupported purge mode %q", mode)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, cutoff.Time)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge stale listings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var listingID int64
+		var hadEmbedding bool
+		if err := rows.Scan(&listingID, &hadEmbedding); err != nil {
+			return nil, fmt.Errorf("failed to scan purged listing: %w", err)
+		}
+		result.Affected++
+		if (clearEmbeddings || mode == PurgeModeDelete) && hadEmbedding {
+			result.EmbeddingsCleared++
+		}
+		if len(result.SampleIDs) < staleSampleSize {
+			result.SampleIDs = append(result.SampleIDs, listingID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read purged listings: %w", err)
+	}
+
+	return result, nil
+}
+
+// ListingDeletion is the outcome of deleting a listing
+type ListingDeletion struct {
+	ListingID         int64 `json:"listing_id"`
+	EmbeddingCleared  bool  `json:"embedding_cleared"`   // The listing had a listing-level embedding
+	ChunksDeleted     int64 `json:"chunks_deleted"`      // Chunk embeddings removed with it
+	SearchLogsUpdated int64 `json:"search_logs_updated"` // Logged searches the listing was removed from
+}
+
+// DeleteListing deletes a listing and its chunk embeddings in one transaction, and removes it
+// from logged searches (returned IDs and clicks) so no log reference outlives it. Media and
+// feedback rows cascade. Returns nil when no listing has the ID.
+func (r *PostgresRepository) DeleteListing(ctx context.Context, listingID int64) (*ListingDeletion, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &ListingDeletion{ListingID: listingID}
+	err = tx.GetContext(ctx, &result.EmbeddingCleared,
+		`SELECT embedding IS NOT NULL FROM listing_info WHERE listing_id = $1 FOR UPDATE`, listingID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to lock listing: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM listing_embedding_chunks WHERE listing_id = $1`, listingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete chunk embeddings: %w", err)
+	}
+	if result.ChunksDeleted, err = res.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to count chunk embeddings: %w", err)
+	}
+
+	res, err = tx.ExecContext(ctx, `
+		UPDATE search_logs
+		SET returned_listing_ids = array_remove(returned_listing_ids, $1::bigint),
+			clicked_listing_id = NULLIF(clicked_listing_id, $1::bigint)
+		WHERE $1::bigint = ANY(returned_listing_ids) OR clicked_listing_id = $1::bigint`, listingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clear search log references: %w", err)
+	}
+	if result.SearchLogsUpdated, err = res.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to count search log references: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM listing_info WHERE listing_id = $1`, listingID); err != nil {
+		return nil, fmt.Errorf("failed to delete listing: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return result, nil
+}
+
+// UnitTypeMapping describes how one raw unit_type value is normalized
+type UnitTypeMapping struct {
+	Raw        string `db:"unit_type"`
+	Normalized string `db:"-"`
+	Count      int64  `db:"count"`
+}
+
+// ListUnitTypeMappings returns every distinct raw unit_type with its canonical mapping and row count
+func (r *PostgresRepository) ListUnitTypeMappings(ctx context.Context) ([]UnitTypeMapping, error) {
+	var mappings []UnitTypeMapping
+	query := `
+		SELECT unit_type, COUNT(*) AS count
+		FROM listing_info
+		WHERE unit_type IS NOT NULL
+		GROUP BY unit_type
+		ORDER BY count DESC
+	`
+	if err := r.db.SelectContext(ctx, &mappings, query); err != nil {
+		return nil, fmt.Errorf("failed to list unit types: %w", err)
+	}
+	for i := range mappings {
+		mappings[i].Normalized = utils.NormalizeUnitType(mappings[i].Raw)
+	}
+	return mappings, nil
+}
+
+// BackfillUnitTypeNormalized writes unit_type_normalized for every listing, one raw value at a time.
+// Returns the number of rows changed.
+func (r *PostgresRepository) BackfillUnitTypeNormalized(ctx context.Context, mappings []UnitTypeMapping) (int64, error) {
+	var updated int64
+	query := `
+		UPDATE listing_info
+		SET unit_type_normalized = $1
+		WHERE unit_type = $2 AND unit_type_normalized IS DISTINCT FROM $1
+	`
+	for _, mapping := range mappings {
+		var normalized interface{}
+		if mapping.Normalized != "" {
+			normalized = mapping.Normalized
+		}
+		res, err := r.db.ExecContext(ctx, query, normalized, mapping.Raw)
+		if err != nil {
+			return updated, fmt.Errorf("failed to normalize unit_type %q: %w", mapping.Raw, err)
+		}
+		rows, _ := res.RowsAffected()
+		updated += rows
+	}
+	return updated, nil
+}
+
+// LogSearch logs a search query. The intent slots are stored as JSON stamped with their
+// schema version, so reads can upgrade older rows (see model.IntentSlots.Scan).
+func (r *PostgresRepository) LogSearch(ctx context.Context, searchID, sessionID string, query string, slots *model.IntentSlots, keywords []string, resultCount int, listingIDs []int64, responseTimeMs int) error {
 	logQuery := `
-		INSERT INTO search_logs (query, intent_slots, semantic_keywords, result_count, returned_listing_ids, response_time_ms)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO search_logs (search_id, session_id, query, intent_slots, semantic_keywords, result_count, returned_listing_ids, response_time_ms)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8)
 	`
-	_, err := r.db.ExecContext(ctx, logQuery, query, slots, keywords, resultCount, listingIDs, responseTimeMs)
+	_, err := r.db.ExecContext(ctx, logQuery, searchID, sessionID, query, slots, pq.Array(keywords), resultCount, pq.Array(listingIDs), responseTimeMs)
 	if err != nil {
 		return fmt.Errorf("failed to log search: %w", err)
 	}
@@ -255,10 +960,18 @@ func (r *PostgresRepository) LogSearch(ctx context.Context, query string, slots
 
 // LogFeedback logs user feedback/action
 func (r *PostgresRepository) LogFeedback(ctx context.Context, searchID string, listingID int64, action string) error {
+	// search_logs keeps the latest action per search; user_feedback keeps every one, under
+	// the search's session, so SeenListingIDs sees all listings acted on
 	query := `
-		UPDATE search_logs 
-		SET clicked_listing_id = $2, action = $3
-		WHERE search_id = $1
+		WITH logged AS (
+			UPDATE search_logs
+			SET clicked_listing_id = $2, action = $3
+			WHERE search_id = $1
+			RETURNING session_id
+		)
+		INSERT INTO user_feedback (search_id, session_id, listing_id, feedback_type)
+		SELECT $1, (SELECT session_id FROM logged LIMIT 1), $2, $3
+		WHERE EXISTS (SELECT 1 FROM listing_info WHERE listing_id = $2)
 	`
 	_, err := r.db.ExecContext(ctx, query, searchID, listingID, action)
 	if err != nil {
@@ -267,8 +980,191 @@ func (r *PostgresRepository) LogFeedback(ctx context.Context, searchID string, l
 	return nil
 }
 
-// VectorSearch performs semantic similarity search (Phase 2, MVP 暂不实现)
-func (r *PostgresRepository) VectorSearch(ctx context.Context, queryEmbedding []float32, limit int, filters *model.SearchFilters) ([]model.Listing, error) {
-	// TODO: Implement in Phase 2
-	return nil, fmt.Errorf("vector search not implemented in MVP")
+// SeenListingIDs returns the listings a session gave feedback on since the given time,
+// most recent first, capped at limit
+func (r *PostgresRepository) SeenListingIDs(ctx context.Context, sessionID string, since time.Time, limit int) ([]int64, error) {
+	var ids []int64
+	query := `
+		SELECT listing_id
+		FROM user_feedback
+		WHERE session_id = $1 AND created_at >= $2
+		GROUP BY listing_id
+		ORDER BY MAX(created_at) DESC
+		LIMIT $3
+	`
+	if err := r.db.SelectContext(ctx, &ids, query, sessionID, since, limit); err != nil {
+		return nil, fmt.Errorf("failed to get seen listings: %w", err)
+	}
+	return ids, nil
+}
+
+// RecentSearches returns a session's distinct queries since the given time, latest search
+// of each first, capped at limit
+func (r *PostgresRepository) RecentSearches(ctx context.Context, sessionID string, since time.Time, limit int) ([]model.RecentSearch, error) {
+	var searches []model.RecentSearch
+	query := `
+		SELECT query, result_count, searched_at
+		FROM (
+			SELECT DISTINCT ON (lower(trim(query)))
+				trim(query) AS query, result_count, created_at AS searched_at
+			FROM search_logs
+			WHERE session_id = $1 AND created_at >= $2
+			ORDER BY lower(trim(query)), created_at DESC
+		) latest
+		ORDER BY searched_at DESC
+		LIMIT $3
+	`
+	if err := r.db.SelectContext(ctx, &searches, query, sessionID, since, limit); err != nil {
+		return nil, fmt.Errorf("failed to get recent searches: %w", err)
+	}
+	return searches, nil
+}
+
+// ForgetSession unlinks every search logged for a session, returning how many were unlinked.
+// The searches stay for aggregate statistics but can no longer be tied to the session.
+func (r *PostgresRepository) ForgetSession(ctx context.Context, sessionID string) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `UPDATE search_logs SET session_id = NULL WHERE session_id = $1`, sessionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to forget session: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// PriceAnchor computes price and psf quartiles over the listings matching filters.
+// psf falls back to price / area_sqft where the stored value is missing.
+func (r *PostgresRepository) PriceAnchor(ctx context.Context, filters *model.SearchFilters) (*model.PriceAnchor, error) {
+	whereClauses, args, _ := buildFilterWhere(filters, 1)
+	whereClauses = append(whereClauses, "price IS NOT NULL")
+
+	query := fmt.Sprintf(`
+		WITH segment AS (
+			SELECT price, %s AS psf
+			FROM listing_info
+			WHERE %s
+		)
+		SELECT
+			COUNT(*) AS listings,
+			percentile_cont(0.25) WITHIN GROUP (ORDER BY price) AS price_p25,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY price) AS price_median,
+			percentile_cont(0.75) WITHIN GROUP (ORDER BY price) AS price_p75,
+			percentile_cont(0.25) WITHIN GROUP (ORDER BY psf) AS psf_p25,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY psf) AS psf_median,
+			percentile_cont(0.75) WITHIN GROUP (ORDER BY psf) AS psf_p75
+		FROM segment
+	`, pricePerSqftExpr, strings.Join(whereClauses, " AND "))
+
+	var anchor model.PriceAnchor
+	if err := r.db.GetContext(ctx, &anchor, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to compute price anchor: %w", err)
+	}
+	return &anchor, nil
+}
+
+// GreenScoresByLocation averages green_score_value per raw location string over active
+// listings. The averages are unrounded and unfiltered so callers can combine the addresses
+// of one area.
+func (r *PostgresRepository) GreenScoresByLocation(ctx context.Context) ([]model.LocationGreenScore, error) {
+	whereClauses, args, _ := buildFilterWhere(nil, 1)
+	whereClauses = append(whereClauses, "green_score_value IS NOT NULL", "location IS NOT NULL", "location <> ''")
+
+	query := fmt.Sprintf(`
+		SELECT location, AVG(green_score_value) AS average_score, COUNT(*) AS listings
+		FROM listing_info
+		WHERE %s
+		GROUP BY location
+	`, strings.Join(whereClauses, " AND "))
+
+	var scores []model.LocationGreenScore
+	if err := r.db.SelectContext(ctx, &scores, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to aggregate green scores: %w", err)
+	}
+	return scores, nil
+}
+
+// PopularQueries returns normalized queries that led to a click since the given time,
+// most frequent first, keeping only those seen at least minCount times
+func (r *PostgresRepository) PopularQueries(ctx context.Context, since time.Time, minCount, limit int) ([]model.Suggestion, error) {
+	var suggestions []model.Suggestion
+	query := `
+		SELECT regexp_replace(lower(trim(query)), '\s+', ' ', 'g') AS query, COUNT(*) AS count
+		FROM search_logs
+		WHERE clicked_listing_id IS NOT NULL AND created_at >= $1
+		GROUP BY 1
+		HAVING COUNT(*) >= $2
+		ORDER BY count DESC, query
+		LIMIT $3
+	`
+	if err := r.db.SelectContext(ctx, &suggestions, query, since, minCount, limit); err != nil {
+		return nil, fmt.Errorf("failed to get popular queries: %w", err)
+	}
+	return suggestions, nil
+}
+
+// Chunk aggregation modes for VectorSearch
+const (
+	VectorAggregateMax  = "max"  // Score a listing by its nearest chunk
+	VectorAggregateMean = "mean" // Score a listing by the mean distance of its candidate chunks
+)
+
+// vectorCandidateFactor oversamples nearest neighbours so filtering and per-listing
+// aggregation still leave limit listings
+const vectorCandidateFactor = 10
+
+// VectorSearch returns the listings nearest to queryEmbedding by cosine distance.
+// Listings with chunk embeddings are scored from their nearest chunks (aggregated per
+// listing by max similarity or mean); listings without chunks use their single embedding.
+func (r *PostgresRepository) VectorSearch(
+	ctx context.Context,
+	queryEmbedding []float32,
+	limit int,
+	filters *model.SearchFilters,
+	aggregate string,
+) ([]model.Listing, error) {
+	var aggregateExpr string
+	switch aggregate {
+	case VectorAggregateMax, "":
+		aggregateExpr = "MIN(distance)"
+	case VectorAggregateMean:
+		aggregateExpr = "AVG(distance)"
+	default:
+		return nil, fmt.Errorf("unsupported chunk aggregate %q", aggregate)
+	}
+
+	whereClauses, args, argIndex := buildFilterWhere(filters, 3)
+	args = append([]interface{}{pgvector.NewVector(queryEmbedding), limit * vectorCandidateFactor}, args...)
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`
+		WITH candidates AS (
+			(SELECT listing_id, embedding <=> $1 AS distance
+			FROM listing_embedding_chunks
+			ORDER BY embedding <=> $1
+			LIMIT $2)
+			UNION ALL
+			(SELECT listing_id, embedding <=> $1 AS distance
+			FROM listing_info li
+			WHERE embedding IS NOT NULL
+				AND NOT EXISTS (SELECT 1 FROM listing_embedding_chunks c WHERE c.listing_id = li.listing_id)
+			ORDER BY embedding <=> $1
+			LIMIT $2)
+		), nearest AS (
+			SELECT listing_id, %s AS vector_distance
+			FROM candidates
+			GROUP BY listing_id
+		)
+		SELECT
+			%s,
+			nearest.vector_distance
+		FROM listing_info
+		JOIN nearest USING (listing_id)
+		WHERE %s
+		ORDER BY nearest.vector_distance, listing_id
+		LIMIT $%d
+	`, aggregateExpr, strings.Join(model.ListingFields, ", "), strings.Join(whereClauses, " AND "), argIndex)
+
+	var listings []model.Listing
+	if err := r.db.SelectContext(ctx, &listings, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to run vector search: %w", err)
+	}
+	return listings, nil
 }
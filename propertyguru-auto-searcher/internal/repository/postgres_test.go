@@ -0,0 +1,630 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"core/internal/model"
+	"core/internal/utils"
+
+	"github.com/lib/pq"
+)
+
+func TestBuildSelectQuerySkipsTextRankWithoutKeywords(t *testing.T) {
+	query := buildSelectQuery(model.ListingFields, "1=1", "listed_date DESC NULLS LAST, listing_id", 0, 1)
+	if strings.Contains(query, "ts_rank") {
+		t.Errorf("Expected no ts_rank without keywords, got:\n%s", query)
+	}
+	if !strings.Contains(query, "LIMIT $1 OFFSET $2") {
+		t.Errorf("Expected LIMIT $1 OFFSET $2, got:\n%s", query)
+	}
+
+	query = buildSelectQuery(model.ListingFields, "price <= $1", "text_rank DESC", 2, 3)
+	if !strings.Contains(query, "plainto_tsquery('english', $2)") {
+		t.Errorf("Expected ts_rank on $2, got:\n%s", query)
+	}
+	if !strings.Contains(query, "LIMIT $3 OFFSET $4") {
+		t.Errorf("Expected LIMIT $3 OFFSET $4, got:\n%s", query)
+	}
+}
+
+func TestBuildOrderBy(t *testing.T) {
+	tests := []struct {
+		options *model.SearchOptions
+		want    string
+	}{
+		{nil, "text_rank DESC, listed_date DESC NULLS LAST, listing_id"},
+		{&model.SearchOptions{SortBy: model.SortRelevance}, "text_rank DESC, listed_date DESC NULLS LAST, listing_id"},
+		{&model.SearchOptions{SortBy: model.SortPriceDesc, NullsOrder: model.NullsLast}, "price DESC NULLS LAST, listing_id"},
+		{&model.SearchOptions{SortBy: model.SortAreaAsc, NullsOrder: model.NullsFirst}, "area_sqft ASC NULLS FIRST, listing_id"},
+		{&model.SearchOptions{SortBy: model.SortPsfAsc}, pricePerSqftExpr + " ASC NULLS LAST, listing_id"},
+		{&model.SearchOptions{SortBy: model.SortMRTAsc}, "mrt_distance_m ASC NULLS LAST, listing_id"},
+	}
+
+	for _, tt := range tests {
+		if got := buildOrderBy(tt.options); got != tt.want {
+			t.Errorf("buildOrderBy(%+v) = %q, want %q", tt.options, got, tt.want)
+		}
+	}
+}
+
+func TestBuildFacetQuery(t *testing.T) {
+	three := 3
+	filters := (&model.SearchFilters{UnitType: model.StringOrList{"Condo"}, Bedrooms: &three}).WithoutFacet(model.FacetBedrooms)
+	clauses, args, _ := buildFilterWhere(filters, 1)
+	query := buildFacetQuery(model.FacetColumns[model.FacetBedrooms], strings.Join(clauses, " AND "))
+
+	want := "SELECT bedrooms::text AS value, COUNT(*) AS count FROM listing_info " +
+		"WHERE 1=1 AND is_completed = true AND stale_at IS NULL AND unit_type_normalized = $1 " +
+		"AND bedrooms IS NOT NULL GROUP BY bedrooms"
+	if query != want {
+		t.Errorf("buildFacetQuery() =\n%s\nwant\n%s", query, want)
+	}
+	if len(args) != 1 || args[0] != "Condo" {
+		t.Errorf("Expected only the unit type argument, got %v", args)
+	}
+}
+
+func TestBuildFilterWhereExcludeIDs(t *testing.T) {
+	price := 5000.0
+	filters := &model.SearchFilters{PriceMax: &price, ExcludeIDs: []int64{1, 2}}
+
+	clauses, args, next := buildFilterWhere(filters, 3)
+	if last := clauses[len(clauses)-1]; last != "listing_id <> ALL($4)" {
+		t.Errorf("Expected exclude clause on $4, got %q", last)
+	}
+	if len(args) != 2 || next != 5 {
+		t.Errorf("Expected 2 args and next placeholder $5, got %d args and $%d", len(args), next)
+	}
+
+	clauses, _, _ = buildFilterWhere(&model.SearchFilters{}, 1)
+	for _, clause := range clauses {
+		if strings.Contains(clause, "ALL(") {
+			t.Errorf("Expected no exclude clause without IDs, got %q", clause)
+		}
+	}
+}
+
+func TestBuildFilterWhereAmenityMatchMode(t *testing.T) {
+	filters := &model.SearchFilters{Amenities: []string{"pool", "gym"}, Facilities: []string{"parking"}}
+
+	clauses, _, _ := buildFilterWhere(filters, 1)
+	if got := amenityClauses(clauses); len(got) != 3 {
+		t.Fatalf("Expected one required clause per term by default, got %v", got)
+	}
+
+	filters.MatchMode = model.MatchAny
+	clauses, args, _ := buildFilterWhere(filters, 1)
+	got := amenityClauses(clauses)
+	if len(got) != 1 || strings.Count(got[0], "EXISTS") != 3 || !strings.Contains(got[0], ") OR EXISTS") {
+		t.Fatalf("Expected a single OR clause across terms in any mode, got %v", got)
+	}
+	if len(args) != 8 {
+		t.Errorf("Expected 8 pattern args, got %d", len(args))
+	}
+}
+
+func TestBuildFilterWhereLocations(t *testing.T) {
+	location := "Punggol"
+	filters := &model.SearchFilters{Location: &location, Locations: []string{"Sengkang", "punggol"}}
+
+	clauses, args, _ := buildFilterWhere(filters, 1)
+	var locationClauses []string
+	for _, clause := range clauses {
+		if strings.Contains(clause, "location ILIKE") {
+			locationClauses = append(locationClauses, clause)
+		}
+	}
+	if len(locationClauses) != 1 || !strings.Contains(locationClauses[0], " OR ") {
+		t.Fatalf("Expected one OR'd location clause, got %v", locationClauses)
+	}
+	if len(args) != len(utils.ExpandLocation("Punggol"))+len(utils.ExpandLocation("Sengkang")) {
+		t.Errorf("Expected one pattern per spelling of each distinct area, got %v", args)
+	}
+}
+
+func TestBuildFilterWhereRequireCoordinates(t *testing.T) {
+	clauses, _, _ := buildFilterWhere(&model.SearchFilters{}, 1)
+	if strings.Contains(strings.Join(clauses, " AND "), "latitude") {
+		t.Errorf("Expected no coordinate clause by default, got %v", clauses)
+	}
+
+	clauses, args, next := buildFilterWhere(&model.SearchFilters{RequireCoordinates: true}, 1)
+	where := strings.Join(clauses, " AND ")
+	if !strings.Contains(where, "latitude IS NOT NULL AND longitude IS NOT NULL") {
+		t.Errorf("Expected coordinate clauses, got %v", clauses)
+	}
+	if len(args) != 0 || next != 1 {
+		t.Errorf("Expected no placeholders for coordinate clauses, got %d args and $%d", len(args), next)
+	}
+}
+
+func TestBuildFilterWhereLeaseRemaining(t *testing.T) {
+	minYears := 80
+	clauses, args, next := buildFilterWhere(&model.SearchFilters{LeaseRemainingMin: &minYears}, 3)
+	lease := clauses[len(clauses)-1]
+	if !strings.Contains(lease, "tenure ILIKE '%freehold%'") || !strings.HasSuffix(lease, ">= $3)") {
+		t.Errorf("Expected freehold or enough lease left, got %s", lease)
+	}
+	if strings.Contains(lease, "%!") {
+		t.Errorf("Expected a well-formed clause, got %s", lease)
+	}
+	if len(args) != 1 || args[0] != 80 || next != 4 {
+		t.Errorf("Expected one placeholder for the minimum, got %v and $%d", args, next)
+	}
+}
+
+func TestBuildFilterWhereBuildYears(t *testing.T) {
+	from, to := 2000, 2015
+	clauses, args, next := buildFilterWhere(&model.SearchFilters{BuildYearMin: &from, BuildYearMax: &to}, 1)
+	where := strings.Join(clauses, " AND ")
+	if !strings.Contains(where, "build_year >= $1") || !strings.Contains(where, "build_year <= $2") {
+		t.Errorf("Expected a build year range, got %s", where)
+	}
+	if len(args) != 2 || args[0] != 2000 || args[1] != 2015 || next != 3 {
+		t.Errorf("Expected min and max placeholders, got %v and $%d", args, next)
+	}
+}
+
+func TestBuildFilterWhereRoomMinimums(t *testing.T) {
+	bedrooms, bathrooms := 3, 2
+	clauses, args, next := buildFilterWhere(&model.SearchFilters{BedroomsMin: &bedrooms, BathroomsMin: &bathrooms}, 1)
+	where := strings.Join(clauses, " AND ")
+	if !strings.Contains(where, "bedrooms >= $1") || !strings.Contains(where, "bathrooms >= $2") || strings.Contains(where, "bedrooms = ") {
+		t.Errorf("Expected minimum room counts, got %v", clauses)
+	}
+	if len(args) != 2 || args[0] != 3 || args[1] != 2 || next != 3 {
+		t.Errorf("Expected two placeholders, got %v and $%d", args, next)
+	}
+}
+
+func TestBuildFilterWhereUnitTypes(t *testing.T) {
+	condo := model.StringOrList{"Condo"}
+	filters := &model.SearchFilters{UnitType: condo, UnitTypes: []string{"condominium", "EC", "Shoebox"}}
+	clauses, args, next := buildFilterWhere(filters, 1)
+	want := "(unit_type_normalized = $1 OR unit_type_normalized = $2 OR unit_type ILIKE $3)"
+	if !strings.Contains(strings.Join(clauses, " AND "), want) {
+		t.Errorf("Expected %s, got %v", want, clauses)
+	}
+	if len(args) != 3 || args[0] != "Condo" || args[1] != "EC" || args[2] != "%Shoebox%" || next != 4 {
+		t.Errorf("Expected deduplicated unit types, got %v and $%d", args, next)
+	}
+
+	clauses, _, _ = buildFilterWhere(&model.SearchFilters{UnitType: condo}, 1)
+	if !strings.Contains(strings.Join(clauses, " AND "), "unit_type_normalized = $1") || strings.Contains(strings.Join(clauses, " AND "), "(unit_type") {
+		t.Errorf("Expected a single unit type condition, got %v", clauses)
+	}
+}
+
+func TestBuildFilterWhereMRTLines(t *testing.T) {
+	line := "North East Line"
+	filters := &model.SearchFilters{MRTLine: &line, MRTLines: []string{"NEL", "CCL"}}
+	clauses, args, _ := buildFilterWhere(filters, 1)
+
+	if want := mrtStationKeyExpr + " = ANY($1)"; !slices.Contains(clauses, want) || len(args) != 1 {
+		t.Fatalf("Expected %s with one array arg, got %v and %v", want, clauses, args)
+	}
+
+	// Interchanges on both lines (Serangoon, Dhoby Ghaut) are matched once
+	stations := make(map[string]bool)
+	for _, code := range []string{"NEL", "CCL"} {
+		for _, station := range utils.ResolveMRTLine(code).Stations {
+			stations[strings.ToLower(station)] = true
+		}
+	}
+	got := []string(*args[0].(*pq.StringArray))
+	if len(got) != len(stations) {
+		t.Errorf("Expected %d distinct stations, got %d: %v", len(stations), len(got), got)
+	}
+	for _, station := range got {
+		if !stations[station] {
+			t.Errorf("Unexpected station %q", station)
+		}
+	}
+}
+
+func TestBuildFilterWhereMRTStationIsExact(t *testing.T) {
+	station := " Woodlands MRT "
+	clauses, args, next := buildFilterWhere(&model.SearchFilters{MRTStation: &station}, 1)
+	if want := mrtStationKeyExpr + " = $1"; !slices.Contains(clauses, want) || strings.Contains(strings.Join(clauses, " "), "ILIKE") {
+		t.Errorf("Expected only %s, got %v", want, clauses)
+	}
+	if len(args) != 1 || args[0] != "woodlands" || next != 2 {
+		t.Errorf("Expected the bare station name, got %v and $%d", args, next)
+	}
+}
+
+func TestBuildFilterWhereDistrict(t *testing.T) {
+	district := "district 9"
+	location := "Punggol"
+	clauses, args, _ := buildFilterWhere(&model.SearchFilters{District: &district, Location: &location}, 1)
+	var locationClauses []string
+	for _, clause := range clauses {
+		if strings.Contains(clause, "location ILIKE") {
+			locationClauses = append(locationClauses, clause)
+		}
+	}
+	if len(locationClauses) != 1 {
+		t.Fatalf("Expected the district's areas in the location OR clause, got %v", locationClauses)
+	}
+	want := len(utils.ExpandLocation("Punggol")) + len(utils.ExpandDistrict("D09"))
+	if len(args) != want || args[len(args)-1] != "%River Valley%" {
+		t.Errorf("Expected %d location patterns ending with the district's, got %v", want, args)
+	}
+}
+
+func TestBuildFilterWhereExcludeUnitTypes(t *testing.T) {
+	filters := &model.SearchFilters{ExcludeUnitTypes: []string{"hdb", "Shoebox"}}
+	clauses, args, next := buildFilterWhere(filters, 1)
+	where := strings.Join(clauses, " AND ")
+	if !strings.Contains(where, "unit_type_normalized IS DISTINCT FROM $1") || !strings.Contains(where, "COALESCE(unit_type, '') NOT ILIKE $2") {
+		t.Errorf("Expected a NULL-safe exclusion per unit type, got %v", clauses)
+	}
+	if len(args) != 2 || args[0] != "HDB" || args[1] != "%Shoebox%" || next != 3 {
+		t.Errorf("Expected the canonical type and a raw pattern, got %v and $%d", args, next)
+	}
+}
+
+func TestBuildFilterWhereExcludeLocations(t *testing.T) {
+	filters := &model.SearchFilters{ExcludeLocations: []string{"Woodlands", " "}}
+	clauses, args, next := buildFilterWhere(filters, 1)
+	var excluded int
+	for _, clause := range clauses {
+		if strings.HasPrefix(clause, "COALESCE(location, '') NOT ILIKE $") {
+			excluded++
+		}
+	}
+	if patterns := len(utils.ExpandLocation("Woodlands")); excluded != patterns || len(args) != patterns || next != patterns+1 {
+		t.Errorf("Expected one NOT ILIKE per spelling of Woodlands, got %v with %v", clauses, args)
+	}
+}
+
+func TestBuildFilterWhereExcludeKeywords(t *testing.T) {
+	filters := &model.SearchFilters{ExcludeKeywords: []string{"highway", ""}}
+	clauses, args, next := buildFilterWhere(filters, 3)
+	where := strings.Join(clauses, " AND ")
+	for _, want := range []string{
+		"COALESCE(title, '') NOT ILIKE $3",
+		"COALESCE(description, '') NOT ILIKE $3",
+		"NOT EXISTS (SELECT 1 FROM jsonb_array_elements(amenities) elem WHERE elem::text ILIKE $3)",
+		"NOT EXISTS (SELECT 1 FROM jsonb_array_elements(facilities) elem WHERE elem::text ILIKE $3)",
+	} {
+		if !strings.Contains(where, want) {
+			t.Errorf("Expected %s, got %s", want, where)
+		}
+	}
+	if len(args) != 1 || args[0] != "%highway%" || next != 4 {
+		t.Errorf("Expected one shared placeholder per keyword, got %v and $%d", args, next)
+	}
+}
+
+func TestBuildFilterWhereGreenScore(t *testing.T) {
+	minScore := 4.0
+	clauses, args, next := buildFilterWhere(&model.SearchFilters{GreenScoreMin: &minScore}, 2)
+	if !strings.Contains(strings.Join(clauses, " AND "), "green_score_value >= $2") {
+		t.Errorf("Expected a green score minimum, got %v", clauses)
+	}
+	if len(args) != 1 || args[0] != minScore || next != 3 {
+		t.Errorf("Expected one placeholder for the minimum, got %v and $%d", args, next)
+	}
+}
+
+func TestBuildFilterWherePricePerSqft(t *testing.T) {
+	psfMin, psfMax := 800.0, 1500.0
+	clauses, args, next := buildFilterWhere(&model.SearchFilters{PricePerSqftMin: &psfMin, PricePerSqftMax: &psfMax}, 1)
+	where := strings.Join(clauses, " AND ")
+	if !strings.Contains(where, pricePerSqftExpr+" >= $1") || !strings.Contains(where, pricePerSqftExpr+" <= $2") {
+		t.Errorf("Expected psf bounds with the price / area_sqft fallback, got %s", where)
+	}
+	if len(args) != 2 || args[0] != psfMin || args[1] != psfMax || next != 3 {
+		t.Errorf("Expected min and max placeholders, got %v and $%d", args, next)
+	}
+}
+
+func TestBuildFilterWhereRadius(t *testing.T) {
+	lat, lng, km := 1.3521, 103.8198, 2.5
+	clauses, args, next := buildFilterWhere(&model.SearchFilters{LatCenter: &lat, LngCenter: &lng, RadiusKm: &km}, 2)
+	where := strings.Join(clauses, " AND ")
+	if !strings.Contains(where, "latitude IS NOT NULL AND longitude IS NOT NULL") {
+		t.Errorf("Expected coordinate clauses, got %s", where)
+	}
+	radius := clauses[len(clauses)-1]
+	if !strings.Contains(radius, "latitude - $2") || !strings.Contains(radius, "longitude - $3") || !strings.HasSuffix(radius, "<= $4") {
+		t.Errorf("Expected a haversine distance bounded by the radius, got %s", radius)
+	}
+	if strings.Contains(radius, "%!") {
+		t.Errorf("Expected a well-formed clause, got %s", radius)
+	}
+	if len(args) != 3 || args[0] != lat || args[1] != lng || args[2] != km || next != 5 {
+		t.Errorf("Expected lat, lng and radius placeholders, got %v and $%d", args, next)
+	}
+
+	clauses, args, _ = buildFilterWhere(&model.SearchFilters{LatCenter: &lat, LngCenter: &lng}, 1)
+	if strings.Contains(strings.Join(clauses, " AND "), "asin") || len(args) != 0 {
+		t.Errorf("Expected no radius clause without a radius, got %v", clauses)
+	}
+}
+
+// TestVectorSearchNearestNeighbours runs VectorSearch over a few known 3-dimensional vectors.
+// Needs PostgreSQL with pgvector: TEST_DATABASE_URL=postgres://... go test -run VectorSearch ./internal/repository
+// The tables are session-local temporary tables shadowing the real ones, so no data is touched.
+func TestVectorSearchNearestNeighbours(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	// One connection, so every statement sees the same temporary tables
+	repo, err := NewPostgresRepository(dsn, 1, 1, 5*time.Minute, 2*time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		`CREATE EXTENSION IF NOT EXISTS vector`,
+		`CREATE TEMP TABLE listing_info (
+			id bigserial, listing_id bigint PRIMARY KEY, title text, price numeric, price_per_sqft numeric,
+			bedrooms int, bathrooms int, area_sqft numeric, unit_type text, tenure text, build_year int,
+			mrt_station text, mrt_distance_m int, location text, latitude double precision,
+			longitude double precision, listed_date timestamptz, listed_age text,
+			green_score_value numeric, green_score_max numeric, url text, property_details jsonb,
+			description text, description_title text, amenities jsonb, facilities jsonb,
+			is_completed boolean NOT NULL DEFAULT true, created_at timestamptz NOT NULL DEFAULT now(),
+			updated_at timestamptz NOT NULL DEFAULT now(), stale_at timestamptz, embedding vector(3))`,
+		`CREATE TEMP TABLE listing_embedding_chunks (listing_id bigint, content text, embedding vector(3))`,
+		// 1 points along x, 2 is 45 degrees off, 3 is orthogonal; 4 has no embedding but a chunk
+		// close to x, and 5 is nearest of all but not searchable
+		`INSERT INTO listing_info (listing_id, embedding, is_completed) VALUES
+			(1, '[1,0,0]', true), (2, '[1,1,0]', true), (3, '[0,0,1]', true), (4, NULL, true), (5, '[1,0,0]', false)`,
+		`INSERT INTO listing_embedding_chunks (listing_id, content, embedding) VALUES
+			(4, 'near', '[1,0.1,0]'), (4, 'far', '[0,1,0]')`,
+	} {
+		if _, err := repo.db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("Failed to set up vectors: %v\n%s", err, stmt)
+		}
+	}
+
+	listings, err := repo.VectorSearch(ctx, []float32{1, 0, 0}, 10, nil, VectorAggregateMax)
+	if err != nil {
+		t.Fatalf("VectorSearch failed: %v", err)
+	}
+	var order []int64
+	for i, listing := range listings {
+		order = append(order, listing.ListingID)
+		if listing.VectorDistance == nil {
+			t.Fatalf("Expected a distance for listing %d", listing.ListingID)
+		}
+		if i > 0 && *listing.VectorDistance < *listings[i-1].VectorDistance {
+			t.Errorf("Expected distances in ascending order, got %v", order)
+		}
+	}
+	if want := []int64{1, 4, 2, 3}; !reflect.DeepEqual(order, want) {
+		t.Errorf("Expected nearest-neighbour order %v, got %v", want, order)
+	}
+	if d := *listings[0].VectorDistance; d > 1e-6 {
+		t.Errorf("Expected an identical vector at distance 0, got %v", d)
+	}
+
+	// Averaging chunks pulls listing 4 behind listing 2
+	listings, err = repo.VectorSearch(ctx, []float32{1, 0, 0}, 2, nil, VectorAggregateMean)
+	if err != nil {
+		t.Fatalf("VectorSearch failed: %v", err)
+	}
+	if len(listings) != 2 || listings[0].ListingID != 1 || listings[1].ListingID != 2 {
+		t.Errorf("Expected listings 1 and 2 with mean aggregation, got %+v", listings)
+	}
+}
+
+// TestSeenListingIDsKeepsEveryFeedback logs two actions on one search and reads them back.
+// Needs PostgreSQL: TEST_DATABASE_URL=postgres://... go test -run SeenListingIDs ./internal/repository
+func TestSeenListingIDsKeepsEveryFeedback(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	repo, err := NewPostgresRepository(dsn, 1, 1, 5*time.Minute, 2*time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		`CREATE TEMP TABLE listing_info (listing_id bigint PRIMARY KEY)`,
+		`CREATE TEMP TABLE search_logs (search_id text, session_id text, clicked_listing_id bigint,
+			action text, created_at timestamp DEFAULT CURRENT_TIMESTAMP)`,
+		`CREATE TEMP TABLE user_feedback (id bigserial, search_id text, session_id text, listing_id bigint,
+			feedback_type text, created_at timestamp DEFAULT CURRENT_TIMESTAMP)`,
+		`INSERT INTO listing_info VALUES (1), (2), (3)`,
+		`INSERT INTO search_logs (search_id, session_id) VALUES ('s1', 'session-1'), ('s2', 'session-2')`,
+	} {
+		if _, err := repo.db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("Failed to set up feedback tables: %v\n%s", err, stmt)
+		}
+	}
+
+	for _, feedback := range []struct {
+		searchID  string
+		listingID int64
+	}{{"s1", 1}, {"s1", 2}, {"s2", 3}, {"s1", 99}} {
+		if err := repo.LogFeedback(ctx, feedback.searchID, feedback.listingID, "dismiss"); err != nil {
+			t.Fatalf("LogFeedback failed: %v", err)
+		}
+	}
+
+	seen, err := repo.SeenListingIDs(ctx, "session-1", time.Now().Add(-time.Hour), 10)
+	if err != nil {
+		t.Fatalf("SeenListingIDs failed: %v", err)
+	}
+	if len(seen) != 2 || !slices.Contains(seen, 1) || !slices.Contains(seen, 2) {
+		t.Errorf("Expected both listings dismissed in session-1, got %v", seen)
+	}
+}
+
+// amenityClauses returns the JSONB amenity/facility clauses among where clauses
+func amenityClauses(clauses []string) []string {
+	var result []string
+	for _, clause := range clauses {
+		if strings.Contains(clause, "jsonb_array_elements") {
+			result = append(result, clause)
+		}
+	}
+	return result
+}
+
+// BenchmarkPureFilterSearch compares a pure-filter query with and without ts_rank.
+// Needs a populated database: BENCH_DATABASE_URL=postgres://... go test -bench PureFilter ./internal/repository
+func BenchmarkPureFilterSearch(b *testing.B) {
+	dsn := os.Getenv("BENCH_DATABASE_URL")
+	if dsn == "" {
+		b.Skip("BENCH_DATABASE_URL not set")
+	}
+	repo, err := NewPostgresRepository(dsn, 4, 2, 5*time.Minute, 2*time.Minute)
+	if err != nil {
+		b.Fatalf("Failed to connect: %v", err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	bedrooms := 3
+	whereClause := "is_completed = true AND stale_at IS NULL AND bedrooms = $1"
+	options := &model.SearchOptions{TopK: 20}
+
+	b.Run("ts_rank_empty_query", func(b *testing.B) {
+		// Previous behavior: rank every matching row against an empty tsquery
+		query := buildSelectQuery(model.ListingFields, whereClause, buildOrderBy(options), 2, 3)
+		for i := 0; i < b.N; i++ {
+			var listings []model.Listing
+			if err := repo.db.SelectContext(ctx, &listings, query, bedrooms, "", options.TopK, 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("no_rank", func(b *testing.B) {
+		query := buildSelectQuery(model.ListingFields, whereClause, buildOrderBy(&model.SearchOptions{SortBy: model.SortNewest, NullsOrder: model.NullsLast}), 0, 2)
+		for i := 0; i < b.N; i++ {
+			var listings []model.Listing
+			if err := repo.db.SelectContext(ctx, &listings, query, bedrooms, options.TopK, 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestSearchColumnsHighlight(t *testing.T) {
+	columns := searchColumns(&model.SearchOptions{Highlight: true}, 2)
+	last := columns[len(columns)-1]
+	if !strings.Contains(last, "ts_headline('english'") || !strings.Contains(last, "plainto_tsquery('english', $2)") ||
+		!strings.HasSuffix(last, "AS highlight") {
+		t.Errorf("Expected a ts_headline column on $2, got %q", last)
+	}
+	if len(model.ListingFields) != len(columns)-1 {
+		t.Error("Expected the shared ListingFields to be left untouched")
+	}
+
+	for _, tt := range []struct {
+		name    string
+		options *model.SearchOptions
+		rankArg int
+	}{
+		{"not requested", &model.SearchOptions{}, 2},
+		{"no search text", &model.SearchOptions{Highlight: true}, 0},
+	} {
+		for _, column := range searchColumns(tt.options, tt.rankArg) {
+			if strings.Contains(column, "ts_headline") {
+				t.Errorf("%s: expected no highlight column", tt.name)
+			}
+		}
+	}
+}
+
+// Needs PostgreSQL: TEST_DATABASE_URL=postgres://... go test -run MRTStationKey ./internal/repository
+func TestMRTStationKeyExprMatchesStationKey(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	repo, err := NewPostgresRepository(dsn, 1, 1, 5*time.Minute, 2*time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer repo.Close()
+
+	query := "SELECT " + mrtStationKeyExpr + " FROM (SELECT $1::text AS mrt_station) s"
+	for _, label := range []string{"NS9/TE2 Woodlands MRT Station", "TE1 Woodlands North MRT", "PE6 Oasis LRT", "Dhoby Ghaut"} {
+		var key string
+		if err := repo.db.GetContext(context.Background(), &key, query, label); err != nil {
+			t.Fatalf("Query failed for %q: %v", label, err)
+		}
+		if want := utils.StationKey(label); key != want {
+			t.Errorf("mrtStationKeyExpr(%q) = %q, StationKey = %q", label, key, want)
+		}
+	}
+}
+
+// Needs PostgreSQL with pgvector: TEST_DATABASE_URL=postgres://... go test -run BatchUpdateEmbeddings ./internal/repository
+func TestBatchUpdateEmbeddingsCommitsAroundFailedItems(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	repo, err := NewPostgresRepository(dsn, 1, 1, 5*time.Minute, 2*time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		`CREATE EXTENSION IF NOT EXISTS vector`,
+		`CREATE TEMP TABLE listing_info (listing_id bigint PRIMARY KEY, embedding vector(3),
+			updated_at timestamptz NOT NULL DEFAULT now())`,
+		`CREATE TEMP TABLE listing_embedding_chunks (listing_id bigint, chunk_index int, content text, embedding vector(3))`,
+		`INSERT INTO listing_info (listing_id) VALUES (1), (2), (3), (4)`,
+	} {
+		if _, err := repo.db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("Failed to set up embedding tables: %v\n%s", err, stmt)
+		}
+	}
+
+	// 2 has the wrong dimensions; 3 updates fine but one of its chunks doesn't, which must
+	// undo its listing update too
+	succeeded, failures := repo.BatchUpdateEmbeddingsDetailed(ctx, []model.EmbeddingItem{
+		{ListingID: 1, Embedding: []float32{1, 0, 0}},
+		{ListingID: 2, Embedding: []float32{1, 0}},
+		{ListingID: 3, Embedding: []float32{0, 1, 0}, Chunks: []model.EmbeddingChunk{
+			{Content: "ok", Embedding: []float32{0, 1, 0}}, {Content: "bad", Embedding: []float32{0, 1}},
+		}},
+		{ListingID: 4, Embedding: []float32{0, 0, 1}, Chunks: []model.EmbeddingChunk{
+			{Content: "ok", Embedding: []float32{0, 0, 1}},
+		}},
+	})
+	if !reflect.DeepEqual(succeeded, []int64{1, 4}) {
+		t.Errorf("Expected listings 1 and 4 to succeed, got %v", succeeded)
+	}
+	if len(failures) != 2 || failures[0].ListingID != 2 || failures[1].ListingID != 3 {
+		t.Errorf("Expected listings 2 and 3 to fail, got %+v", failures)
+	}
+
+	var embedded []int64
+	if err := repo.db.SelectContext(ctx, &embedded, `SELECT listing_id FROM listing_info WHERE embedding IS NOT NULL ORDER BY listing_id`); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if !reflect.DeepEqual(embedded, []int64{1, 4}) {
+		t.Errorf("Expected only listings 1 and 4 committed, got %v", embedded)
+	}
+	var chunked []int64
+	if err := repo.db.SelectContext(ctx, &chunked, `SELECT listing_id FROM listing_embedding_chunks ORDER BY listing_id`); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if !reflect.DeepEqual(chunked, []int64{4}) {
+		t.Errorf("Expected only listing 4's chunk committed, got %v", chunked)
+	}
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Transient transaction failures that succeed when the transaction is simply re-run
+const (
+	pqSerializationFailure = "40001"
+	pqDeadlockDetected     = "40P01"
+)
+
+// Retry policy for transactions that hit transient failures
+var (
+	txMaxAttempts  = 3
+	txRetryBackoff = 50 * time.Millisecond // Doubled after each failed attempt
+)
+
+// isRetriableTxError reports whether err is a serialization failure or deadlock
+func isRetriableTxError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == pqSerializationFailure || pqErr.Code == pqDeadlockDetected
+}
+
+// retryTransient runs fn, re-running it with exponential backoff while it fails with a
+// retriable transaction error. fn must run a complete transaction so each attempt starts clean.
+func retryTransient(ctx context.Context, maxAttempts int, backoff time.Duration, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || !isRetriableTxError(err) || attempt >= maxAttempts {
+			return err
+		}
+
+		log.Printf("⚠️  Transient transaction failure (attempt %d/%d), retrying in %v: %v", attempt, maxAttempts, backoff, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestRetryTransientRetriesSerializationFailure(t *testing.T) {
+	attempts := 0
+	err := retryTransient(context.Background(), 3, 0, func() error {
+		attempts++
+		if attempts == 1 {
+			return fmt.Errorf("listing_id 1: %w", &pq.Error{Code: pqSerializationFailure})
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Expected success on second attempt, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryTransientGivesUpAfterMaxAttempts(t *testing.T) {
+	attempts := 0
+	err := retryTransient(context.Background(), 3, 0, func() error {
+		attempts++
+		return &pq.Error{Code: pqDeadlockDetected}
+	})
+
+	if !isRetriableTxError(err) {
+		t.Errorf("Expected the deadlock error to be returned, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryTransientDoesNotRetryOtherErrors(t *testing.T) {
+	attempts := 0
+	uniqueViolation := &pq.Error{Code: "23505"}
+	err := retryTransient(context.Background(), 3, 0, func() error {
+		attempts++
+		return uniqueViolation
+	})
+
+	if !errors.Is(err, uniqueViolation) {
+		t.Errorf("Expected the original error, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts)
+	}
+}
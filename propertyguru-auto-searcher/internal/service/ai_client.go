@@ -2,16 +2,19 @@ package service
 
 import (
 	"context"
+
+	"core/internal/model"
 )
 
 // AIClient is the interface for AI service providers
 type AIClient interface {
-	// ParseIntentWithAI parses user query into structured intent (non-streaming)
-	ParseIntentWithAI(ctx context.Context, query string) (*AIIntentResponse, error)
+	// ParseIntentWithAI parses user query into structured intent (non-streaming).
+	// locale is the user's language tag for number formats ("" = SGD defaults)
+	ParseIntentWithAI(ctx context.Context, query, locale string) (*AIIntentResponse, error)
 
 	// ParseIntentWithAIStream parses user query with streaming support
 	// The callback receives (thinkingContent, regularContent) for each chunk
-	ParseIntentWithAIStream(ctx context.Context, query string, callback func(thinking, content string) error) (*AIIntentResponse, error)
+	ParseIntentWithAIStream(ctx context.Context, query, locale string, callback func(thinking, content string) error) (*AIIntentResponse, error)
 
 	// CreateEmbeddings generates embeddings for texts
 	CreateEmbeddings(ctx context.Context, texts []string) ([][]float32, error)
@@ -34,28 +37,70 @@ type StreamChunk struct {
 	// Whether this is the final chunk
 	Done bool
 
+	// Total tokens reported by the usage chunk (0 when not present)
+	TotalTokens int
+
 	// Provider-specific metadata
 	Metadata map[string]interface{}
 }
 
 // AIIntentResponse represents the parsed intent from AI
 type AIIntentResponse struct {
-	PriceMin        *float64 `json:"price_min,omitempty"`
-	PriceMax        *float64 `json:"price_max,omitempty"`
-	Bedrooms        *int     `json:"bedrooms,omitempty"`
-	Bathrooms       *int     `json:"bathrooms,omitempty"`
-	AreaSqftMin     *float64 `json:"area_sqft_min,omitempty"`    // 最小面积（平方英尺）
-	AreaSqftMax     *float64 `json:"area_sqft_max,omitempty"`    // 最大面积（平方英尺）
-	UnitType        *string  `json:"unit_type,omitempty"`
-	Location        *string  `json:"location,omitempty"`
-	MRTDistanceMax  *int     `json:"mrt_distance_max,omitempty"`
-	BuildYearMin    *int     `json:"build_year_min,omitempty"`
-	Amenities       []string `json:"amenities,omitempty"`        // 房源设施需求
-	Facilities      []string `json:"facilities,omitempty"`       // 公共设施需求
-	Keywords        []string `json:"keywords,omitempty"`
-	Confidence      float64  `json:"confidence,omitempty"`
-	ThinkingProcess string   `json:"thinking_process,omitempty"` // Full thinking process
+	PriceMin        *float64           `json:"price_min,omitempty"`
+	PriceMax        *float64           `json:"price_max,omitempty"`
+	PriceTarget     *float64           `json:"price_target,omitempty"` // "around $1.2M"
+	Bedrooms        *int               `json:"bedrooms,omitempty"`
+	Bathrooms       *int               `json:"bathrooms,omitempty"`
+	BedroomsMin     *int               `json:"bedrooms_min,omitempty"`  // "at least 3 bedrooms"
+	BathroomsMin    *int               `json:"bathrooms_min,omitempty"` // "2+ bathrooms"
+	AreaSqftMin     *float64           `json:"area_sqft_min,omitempty"` // 最小面积（平方英尺）
+	AreaSqftMax     *float64           `json:"area_sqft_max,omitempty"` // 最大面积（平方英尺）
+	UnitType        model.StringOrList `json:"unit_type,omitempty"`     // A string, or a list when the model returns several
+	UnitTypes       []string           `json:"unit_types,omitempty"`    // Several types ("condo or EC")
+	Location        *string            `json:"location,omitempty"`
+	Locations       []string           `json:"locations,omitempty"` // Several areas ("Punggol or Sengkang")
+	District        *string            `json:"district,omitempty"`  // Postal district ("D9", "district 10")
+	MRTDistanceMax  *int               `json:"mrt_distance_max,omitempty"`
+	MRTStation      *string            `json:"mrt_station,omitempty"`
+	MRTLine         *string            `json:"mrt_line,omitempty"`
+	MRTLines        []string           `json:"mrt_lines,omitempty"` // Several lines ("NEL or Circle Line")
+	BuildYearMin    *int               `json:"build_year_min,omitempty"`
+	BuildYearMax    *int               `json:"build_year_max,omitempty"` // "older than 2000"
+	Amenities       []string           `json:"amenities,omitempty"`      // 房源设施需求
+	Facilities      []string           `json:"facilities,omitempty"`     // 公共设施需求
+	Keywords        []string           `json:"keywords,omitempty"`
+	Confidence      float64            `json:"confidence,omitempty"`
+	ThinkingProcess string             `json:"thinking_process,omitempty"` // Full thinking process
+
+	LeaseRemainingMin *int `json:"lease_remaining_min,omitempty"` // "at least 80 years left"
+
+	PricePerSqftMin *float64 `json:"price_per_sqft_min,omitempty"`
+	PricePerSqftMax *float64 `json:"price_per_sqft_max,omitempty"` // "under $1500 psf"
+
+	GreenScoreMin *float64 `json:"green_score_min,omitempty"` // "eco-friendly"
+
+	ExcludeUnitTypes []string `json:"exclude_unit_types,omitempty"` // "anything except HDB"
+	ExcludeLocations []string `json:"exclude_locations,omitempty"` // "not in Woodlands"
+	ExcludeKeywords  []string `json:"exclude_keywords,omitempty"`  // "not near a highway"
+
+	RawContent     string      `json:"-"` // LLM content before JSON repair
+	ParseStrategy  string      `json:"-"` // utils.JSONStrategy* that parsed RawContent
+	StreamRecovery string      `json:"-"` // StreamRecovery* when a broken stream was recovered
+	Usage          *TokenUsage `json:"-"` // Tokens spent, when the provider reported them
 }
 
+// How ParseIntentWithAIStream recovered an intent from a stream that didn't finish cleanly
+const (
+	StreamRecoveryPartial = "partial" // The stream broke off; the content received so far parsed
+	StreamRecoveryRetry   = "retry"   // The streamed content was unusable; a non-streaming retry parsed
+)
+
+// Provider names accepted in OPENAI_PROVIDER
+const (
+	ProviderOpenAI = "openai"
+	ProviderNVIDIA = "nvidia"
+	ProviderOllama = "ollama"
+)
+
 // Ensure OpenAIClient implements AIClient
 var _ AIClient = (*OpenAIClient)(nil)
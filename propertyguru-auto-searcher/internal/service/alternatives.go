@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+
+	"core/internal/model"
+	"core/internal/utils"
+)
+
+// findAlternativeUnitTypes counts the other unit types matching the remaining filters when
+// a unit type search found fewer results than the configured minimum, so the response can
+// point out e.g. HDB flats within a budget that fits no condos. Like broader matches it only
+// runs for the first page; failed counts are logged and skipped.
+func (s *SearchService) findAlternativeUnitTypes(
+	ctx context.Context,
+	filters *model.SearchFilters,
+	options *model.SearchOptions,
+	total int,
+) []model.UnitTypeAlternative {
+	if s.config.MinResults <= 0 || total >= s.config.MinResults || options.Offset > 0 || options.CountOnly {
+		return nil
+	}
+	// Only a single-type search has an obvious "other" type to suggest
+	unitTypes := filters.AllUnitTypes()
+	if len(unitTypes) != 1 {
+		return nil
+	}
+	requested := utils.NormalizeUnitType(unitTypes[0])
+	if requested == "" {
+		return nil
+	}
+
+	var alternatives []model.UnitTypeAlternative
+	for _, unitType := range utils.CanonicalUnitTypes {
+		if unitType == requested || filters.ExcludesUnitType(unitType) {
+			continue
+		}
+		alternative := *filters
+		alternative.UnitType = model.StringOrList{unitType}
+		alternative.UnitTypes = nil
+		count, err := s.repo.CountWithFilters(ctx, &alternative)
+		if err != nil {
+			log.Printf("⚠️  Alternative unit type count failed for %s: %v", unitType, err)
+			continue
+		}
+		if count <= total {
+			continue
+		}
+		alternatives = append(alternatives, model.UnitTypeAlternative{
+			UnitType: unitType,
+			Count:    count,
+			Message:  alternativeMessage(requested, total, unitType, count, filters),
+		})
+	}
+
+	sort.SliceStable(alternatives, func(i, j int) bool {
+		return alternatives[i].Count > alternatives[j].Count
+	})
+	return alternatives
+}
+
+// alternativeMessage describes an alternative unit type, e.g.
+// "No Condo listings under $600K, but 40 HDB listings match"
+func alternativeMessage(requested string, total int, unitType string, count int, filters *model.SearchFilters) string {
+	found := "No " + requested + " listings"
+	if total > 0 {
+		found = fmt.Sprintf("Only %d %s %s", total, requested, pluralize(total, "listing"))
+	}
+	if budget := describeBudget(filters); budget != "" {
+		found += " " + budget
+	}
+	return fmt.Sprintf("%s, but %d %s %s match", found, count, unitType, pluralize(count, "listing"))
+}
+
+// describeBudget renders the price filters as "under $600K", "over $1.2M", or "between ... and ..."
+func describeBudget(filters *model.SearchFilters) string {
+	switch {
+	case filters.PriceMin != nil && filters.PriceMax != nil:
+		return fmt.Sprintf("between %s and %s", formatPrice(*filters.PriceMin), formatPrice(*filters.PriceMax))
+	case filters.PriceMax != nil:
+		return "under " + formatPrice(*filters.PriceMax)
+	case filters.PriceMin != nil:
+		return "over " + formatPrice(*filters.PriceMin)
+	}
+	return ""
+}
+
+// formatPrice abbreviates a price the way users write it: $3500, $600K, $1.25M
+func formatPrice(price float64) string {
+	switch {
+	case price >= 1e6:
+		return "$" + strconv.FormatFloat(math.Round(price/1e4)/100, 'f', -1, 64) + "M"
+	case price >= 1e4:
+		return "$" + strconv.FormatFloat(math.Round(price/100)/10, 'f', -1, 64) + "K"
+	}
+	return "$" + strconv.FormatFloat(math.Round(price), 'f', -1, 64)
+}
+
+// pluralize appends an "s" to noun unless n is 1
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return noun
+	}
+	return noun + "s"
+}
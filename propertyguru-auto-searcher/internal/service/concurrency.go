@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Overload modes for searches beyond the in-flight limit
+const (
+	OverloadQueue  = "queue"  // Wait for a free slot, up to the queue timeout
+	OverloadReject = "reject" // Fail immediately with ErrSearchBusy
+)
+
+// ErrSearchBusy is returned when every search slot is taken and the search couldn't queue
+var ErrSearchBusy = errors.New("too many searches in flight, try again shortly")
+
+// SearchConcurrencyStats reports the in-flight limiter for the metrics endpoint
+type SearchConcurrencyStats struct {
+	MaxInFlight int    `json:"max_in_flight"` // 0 = unlimited
+	Mode        string `json:"mode"`
+	InFlight    int64  `json:"in_flight"`
+	Queued      int64  `json:"queued"`
+	Rejected    int64  `json:"rejected"` // Since startup
+}
+
+// searchLimiter caps concurrent searches with a semaphore, so a burst can't run unbounded
+// LLM calls and database queries at once. A nil limiter admits everything.
+type searchLimiter struct {
+	slots        chan struct{}
+	mode         string
+	queueTimeout time.Duration // Longest a queued search waits (0 = until its context ends)
+
+	inFlight atomic.Int64
+	queued   atomic.Int64
+	rejected atomic.Int64
+}
+
+// newSearchLimiter returns a limiter admitting maxInFlight searches, or nil when maxInFlight <= 0
+func newSearchLimiter(maxInFlight int, mode string, queueTimeout time.Duration) *searchLimiter {
+	if maxInFlight <= 0 {
+		return nil
+	}
+	return &searchLimiter{
+		slots:        make(chan struct{}, maxInFlight),
+		mode:         mode,
+		queueTimeout: queueTimeout,
+	}
+}
+
+// acquire takes a slot, queueing or rejecting per the mode when none is free.
+// The returned release must be called once the search finishes.
+func (l *searchLimiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.admitted(), nil
+	default:
+	}
+	if l.mode == OverloadReject {
+		l.rejected.Add(1)
+		return nil, ErrSearchBusy
+	}
+
+	l.queued.Add(1)
+	defer l.queued.Add(-1)
+	var timeout <-chan time.Time
+	if l.queueTimeout > 0 {
+		timer := time.NewTimer(l.queueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return l.admitted(), nil
+	case <-timeout:
+		l.rejected.Add(1)
+		return nil, ErrSearchBusy
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// admitted counts a search in flight and returns its release func
+func (l *searchLimiter) admitted() func() {
+	l.inFlight.Add(1)
+	var once atomic.Bool
+	return func() {
+		if once.CompareAndSwap(false, true) {
+			l.inFlight.Add(-1)
+			<-l.slots
+		}
+	}
+}
+
+// stats returns the limiter's current state
+func (l *searchLimiter) stats() SearchConcurrencyStats {
+	if l == nil {
+		return SearchConcurrencyStats{Mode: OverloadQueue}
+	}
+	return SearchConcurrencyStats{
+		MaxInFlight: cap(l.slots),
+		Mode:        l.mode,
+		InFlight:    l.inFlight.Load(),
+		Queued:      l.queued.Load(),
+		Rejected:    l.rejected.Load(),
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSearchLimiterReject(t *testing.T) {
+	limiter := newSearchLimiter(1, OverloadReject, 0)
+	release, err := limiter.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Expected the first search admitted, got %v", err)
+	}
+	if _, err := limiter.acquire(context.Background()); !errors.Is(err, ErrSearchBusy) {
+		t.Errorf("Expected ErrSearchBusy at the limit, got %v", err)
+	}
+	if stats := limiter.stats(); stats.InFlight != 1 || stats.Rejected != 1 {
+		t.Errorf("Expected 1 in flight and 1 rejected, got %+v", stats)
+	}
+
+	release()
+	release() // Releasing twice must not free a second slot
+	if stats := limiter.stats(); stats.InFlight != 0 {
+		t.Errorf("Expected nothing in flight after release, got %+v", stats)
+	}
+	if _, err := limiter.acquire(context.Background()); err != nil {
+		t.Errorf("Expected a freed slot to be reusable, got %v", err)
+	}
+}
+
+func TestSearchLimiterQueue(t *testing.T) {
+	limiter := newSearchLimiter(1, OverloadQueue, 20*time.Millisecond)
+	release, _ := limiter.acquire(context.Background())
+
+	if _, err := limiter.acquire(context.Background()); !errors.Is(err, ErrSearchBusy) {
+		t.Errorf("Expected ErrSearchBusy after the queue timeout, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		release()
+	}()
+	if _, err := limiter.acquire(context.Background()); err != nil {
+		t.Errorf("Expected the queued search admitted once a slot freed, got %v", err)
+	}
+}
+
+func TestSearchLimiterUnlimited(t *testing.T) {
+	limiter := newSearchLimiter(0, OverloadReject, 0)
+	if limiter != nil {
+		t.Fatal("Expected no limiter for a zero limit")
+	}
+	release, err := limiter.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Expected a nil limiter to admit everything, got %v", err)
+	}
+	release()
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+
+	"core/internal/model"
+	"core/internal/utils"
+)
+
+// filterConflicts lists the fields where an explicit filter and the value inferred from the
+// query disagree. Explicit filters still win in mergeFilters; this only reports that the
+// typed query contradicted them. Overridden fields are skipped since the override replaced both,
+// and so are areas, which mergeFilters combines rather than picking one.
+func filterConflicts(explicit *model.SearchFilters, slots *model.IntentSlots, overrides model.FilterOverrides) []model.FilterConflict {
+	if explicit == nil || slots == nil {
+		return nil
+	}
+
+	var conflicts []model.FilterConflict
+	explicitValue := reflect.ValueOf(explicit).Elem()
+	slotValue := reflect.ValueOf(slots).Elem()
+	for i := 0; i < explicitValue.NumField(); i++ {
+		field := explicitValue.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if _, overridden := overrides[name]; overridden || combinedFilters[name] {
+			continue
+		}
+
+		e := explicitValue.Field(i)
+		// A single explicit value given as a string or a list compares like a pointer field;
+		// several values already allow more than one, so they can't contradict the query
+		if list, ok := e.Interface().(model.StringOrList); ok {
+			if len(list) != 1 {
+				continue
+			}
+			e = reflect.ValueOf(list.First())
+		}
+		if e.Kind() != reflect.Pointer {
+			continue
+		}
+
+		slotField, ok := slotValue.Type().FieldByName(field.Name)
+		if !ok || slotField.Type != e.Type() {
+			continue
+		}
+		s := slotValue.FieldByIndex(slotField.Index)
+		if e.IsNil() || s.IsNil() || sameFilterValue(name, e.Elem().Interface(), s.Elem().Interface()) {
+			continue
+		}
+		conflicts = append(conflicts, model.FilterConflict{
+			Field:    name,
+			Explicit: e.Elem().Interface(),
+			Inferred: s.Elem().Interface(),
+		})
+	}
+	return conflicts
+}
+
+// combinedFilters are filled from both the explicit filters and the query, so they can't conflict
+var combinedFilters = map[string]bool{"location": true, "district": true}
+
+// sameFilterValue compares an explicit and an inferred value the way the repository would
+// filter on them, so different spellings of the same unit type or line don't conflict
+func sameFilterValue(field string, explicit, inferred interface{}) bool {
+	// The explicit filter is metres, the inferred slot walking minutes
+	if field == "mrt_distance_max" {
+		meters, minutes := explicit.(int), inferred.(int)
+		return meters == utils.WalkMinutesToMeters(minutes)
+	}
+
+	a, aIsString := explicit.(string)
+	b, bIsString := inferred.(string)
+	if !aIsString || !bIsString {
+		return explicit == inferred
+	}
+
+	switch field {
+	case "unit_type":
+		if na, nb := utils.NormalizeUnitType(a), utils.NormalizeUnitType(b); na != "" || nb != "" {
+			return na == nb
+		}
+	case "mrt_line":
+		if la, lb := utils.ResolveMRTLine(a), utils.ResolveMRTLine(b); la != nil || lb != nil {
+			return la != nil && lb != nil && la.Code == lb.Code
+		}
+	}
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
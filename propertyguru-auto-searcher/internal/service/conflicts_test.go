@@ -0,0 +1,64 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"core/internal/model"
+)
+
+func TestFilterConflicts(t *testing.T) {
+	explicit := &model.SearchFilters{
+		PriceMax:  float64Ptr(1000000),
+		Bedrooms:  intPtr(3),
+		Bathrooms: intPtr(2),
+		UnitType:  model.StringOrList{"Condo"},
+		MRTLine:   stringPtr("North East Line"),
+		Location:  stringPtr("Tampines"),
+		District:  stringPtr("district 9"),
+
+		MRTDistanceMax: intPtr(1200),
+	}
+	slots := &model.IntentSlots{
+		PriceMax:  float64Ptr(2000000),
+		Bedrooms:  intPtr(3),
+		Bathrooms: intPtr(1),
+		UnitType:  stringPtr("condominium"),
+		MRTLine:   stringPtr("NEL"),
+		Location:  stringPtr("Bedok"),
+		District:  stringPtr("D09"),
+
+		MRTDistanceMax: intPtr(15), // Minutes; 1200 m at walking pace
+	}
+
+	conflicts := filterConflicts(explicit, slots, nil)
+	if len(conflicts) != 2 {
+		t.Fatalf("Expected price_max and bathrooms conflicts, got %+v", conflicts)
+	}
+	if conflicts[0].Field != "price_max" || conflicts[0].Explicit != 1000000.0 || conflicts[0].Inferred != 2000000.0 {
+		t.Errorf("Unexpected price conflict %+v", conflicts[0])
+	}
+	if conflicts[1].Field != "bathrooms" {
+		t.Errorf("Expected a bathrooms conflict, got %+v", conflicts[1])
+	}
+
+	// An overridden field replaced both values, so it no longer conflicts
+	overrides := model.FilterOverrides{"bathrooms": json.RawMessage(`2`)}
+	if conflicts := filterConflicts(explicit, slots, overrides); len(conflicts) != 1 || conflicts[0].Field != "price_max" {
+		t.Errorf("Expected only the price conflict with a bathrooms override, got %+v", conflicts)
+	}
+
+	// A single explicit unit type still conflicts; a list allows more than one, so it can't
+	explicit = &model.SearchFilters{UnitType: model.StringOrList{"HDB"}}
+	if conflicts := filterConflicts(explicit, slots, nil); len(conflicts) != 1 || conflicts[0].Field != "unit_type" || conflicts[0].Explicit != "HDB" {
+		t.Errorf("Expected a unit_type conflict, got %+v", conflicts)
+	}
+	explicit.UnitType = model.StringOrList{"HDB", "EC"}
+	if conflicts := filterConflicts(explicit, slots, nil); len(conflicts) != 0 {
+		t.Errorf("Expected no conflict for several explicit unit types, got %+v", conflicts)
+	}
+
+	if conflicts := filterConflicts(nil, slots, nil); conflicts != nil {
+		t.Errorf("Expected no conflicts without explicit filters, got %+v", conflicts)
+	}
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"math"
+
+	"core/internal/model"
+	"core/internal/utils"
+)
+
+// attachDistances fills distance_km on every result with coordinates (including broader
+// matches) when the search was limited to a radius
+func attachDistances(response *model.SearchResponse, filters *model.SearchFilters) {
+	lat, lng, _, ok := filters.Radius()
+	if !ok {
+		return
+	}
+
+	for i := range response.Results {
+		response.Results[i].DistanceKm = distanceFrom(&response.Results[i].Listing, lat, lng)
+	}
+	if response.BroaderMatches != nil {
+		for i := range response.BroaderMatches.Results {
+			result := &response.BroaderMatches.Results[i]
+			result.DistanceKm = distanceFrom(&result.Listing, lat, lng)
+		}
+	}
+}
+
+// distanceFrom returns the listing's distance from (lat, lng) rounded to 10 m, or nil when
+// the listing has no coordinates
+func distanceFrom(listing *model.Listing, lat, lng float64) *float64 {
+	if listing.Latitude == nil || listing.Longitude == nil {
+		return nil
+	}
+	km := math.Round(utils.HaversineKm(lat, lng, *listing.Latitude, *listing.Longitude)*100) / 100
+	return &km
+}
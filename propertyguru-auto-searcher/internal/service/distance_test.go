@@ -0,0 +1,36 @@
+package service
+
+import (
+	"testing"
+
+	"core/internal/model"
+)
+
+func TestAttachDistances(t *testing.T) {
+	response := &model.SearchResponse{
+		Results: []model.ListingSearchResult{
+			{Listing: model.Listing{ListingID: 1, Latitude: float64Ptr(1.3), Longitude: float64Ptr(103.8)}},
+			{Listing: model.Listing{ListingID: 2}},
+		},
+		BroaderMatches: &model.BroaderMatches{Results: []model.ListingSearchResult{
+			{Listing: model.Listing{ListingID: 3, Latitude: float64Ptr(1.31), Longitude: float64Ptr(103.8)}},
+		}},
+	}
+
+	attachDistances(response, &model.SearchFilters{LatCenter: float64Ptr(1.3), LngCenter: float64Ptr(103.8)})
+	if response.Results[0].DistanceKm != nil {
+		t.Fatal("Expected no distance without a radius search")
+	}
+
+	attachDistances(response, &model.SearchFilters{LatCenter: float64Ptr(1.3), LngCenter: float64Ptr(103.8), RadiusKm: float64Ptr(2)})
+	if got := response.Results[0].DistanceKm; got == nil || *got != 0 {
+		t.Errorf("Expected 0 km at the center, got %v", got)
+	}
+	if response.Results[1].DistanceKm != nil {
+		t.Errorf("Expected no distance without coordinates, got %v", *response.Results[1].DistanceKm)
+	}
+	// 0.01 degrees of latitude is about 1.11 km
+	if got := response.BroaderMatches.Results[0].DistanceKm; got == nil || *got != 1.11 {
+		t.Errorf("Expected 1.11 km for the broader match, got %v", got)
+	}
+}
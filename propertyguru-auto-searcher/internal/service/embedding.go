@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"core/internal/config"
+	"core/internal/model"
+	"core/internal/repository"
+	"core/internal/utils"
+)
+
+// EmbeddingService generates and stores listing embeddings
+type EmbeddingService struct {
+	repo     *repository.PostgresRepository
+	aiClient *OpenAIClient
+	config   *config.EmbeddingConfig
+}
+
+// NewEmbeddingService creates a new embedding service
+func NewEmbeddingService(repo *repository.PostgresRepository, aiClient *OpenAIClient, cfg *config.EmbeddingConfig) *EmbeddingService {
+	return &EmbeddingService{
+		repo:     repo,
+		aiClient: aiClient,
+		config:   cfg,
+	}
+}
+
+// BackfillEmbeddings embeds up to limit listings that have no embedding yet.
+// Descriptions longer than the chunk size are stored as chunk embeddings, and the
+// listing-level embedding becomes the normalized mean of its chunks. Listings are embedded
+// and committed in batches, so a provider failure on one batch is reported per listing
+// while the batches around it are still stored.
+func (s *EmbeddingService) BackfillEmbeddings(ctx context.Context, limit int) (*model.EmbeddingBackfillResponse, error) {
+	if s.aiClient == nil {
+		return nil, fmt.Errorf("embedding provider is not configured")
+	}
+
+	sources, err := s.repo.ListingsMissingEmbeddings(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.backfillBatches(ctx, sources, s.aiClient.CreateEmbeddings, s.repo.BatchUpdateEmbeddingsDetailed), nil
+}
+
+// embedFunc creates one embedding per text
+type embedFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+// storeFunc writes embeddings, returning the stored listing IDs and per-listing failures
+type storeFunc func(ctx context.Context, items []model.EmbeddingItem) ([]int64, []model.EmbeddingItemError)
+
+// backfillBatches embeds and stores sources batch by batch, collecting the outcome per listing
+func (s *EmbeddingService) backfillBatches(ctx context.Context, sources []repository.EmbeddingSource, embed embedFunc, store storeFunc) *model.EmbeddingBackfillResponse {
+	response := &model.EmbeddingBackfillResponse{Processed: len(sources), SucceededIDs: []int64{}}
+
+	batchSize := s.config.BackfillBatch
+	if batchSize <= 0 {
+		batchSize = len(sources)
+	}
+	for start := 0; start < len(sources); start += batchSize {
+		batch := sources[start:min(start+batchSize, len(sources))]
+
+		items, chunks, failures := s.embedBatch(ctx, batch, embed)
+		response.Chunks += chunks
+		response.GenerationErrors = append(response.GenerationErrors, failures...)
+		if len(items) == 0 {
+			continue
+		}
+
+		succeeded, writeFailures := store(ctx, items)
+		response.SucceededIDs = append(response.SucceededIDs, succeeded...)
+		response.WriteErrors = append(response.WriteErrors, writeFailures...)
+	}
+
+	response.Success = len(response.SucceededIDs)
+	response.Failed = len(sources) - response.Success
+	return response
+}
+
+// embedBatch embeds every chunk of every listing in one provider call. Listings without
+// text, or the whole batch when the provider fails, come back as generation failures.
+func (s *EmbeddingService) embedBatch(ctx context.Context, sources []repository.EmbeddingSource, embed embedFunc) ([]model.EmbeddingItem, int, []model.EmbeddingItemError) {
+	var failures []model.EmbeddingItemError
+	var embeddable []repository.EmbeddingSource
+	var chunked [][]string
+	var texts []string
+	for _, source := range sources {
+		chunks := utils.ChunkText(embeddingText(source), s.config.ChunkSize, s.config.ChunkOverlap)
+		if len(chunks) == 0 {
+			failures = append(failures, model.EmbeddingItemError{ListingID: source.ListingID, Error: "no text to embed"})
+			continue
+		}
+		embeddable = append(embeddable, source)
+		chunked = append(chunked, chunks)
+		texts = append(texts, chunks...)
+	}
+	if len(texts) == 0 {
+		return nil, 0, failures
+	}
+
+	vectors, err := embed(ctx, texts)
+	if err != nil {
+		for _, source := range embeddable {
+			failures = append(failures, model.EmbeddingItemError{ListingID: source.ListingID, Error: err.Error()})
+		}
+		return nil, 0, failures
+	}
+
+	items := make([]model.EmbeddingItem, 0, len(embeddable))
+	chunkCount := 0
+	offset := 0
+	for i, source := range embeddable {
+		chunks := chunked[i]
+		chunkVectors := vectors[offset : offset+len(chunks)]
+		offset += len(chunks)
+
+		item := model.EmbeddingItem{ListingID: source.ListingID, Embedding: chunkVectors[0]}
+		if len(chunks) > 1 {
+			item.Embedding = meanVector(chunkVectors)
+			item.Chunks = make([]model.EmbeddingChunk, len(chunks))
+			for j, content := range chunks {
+				item.Chunks[j] = model.EmbeddingChunk{Content: content, Embedding: chunkVectors[j]}
+			}
+			chunkCount += len(chunks)
+		}
+		items = append(items, item)
+	}
+	return items, chunkCount, failures
+}
+
+// embeddingText joins the listing text fields that describe it
+func embeddingText(source repository.EmbeddingSource) string {
+	var parts []string
+	for _, field := range []*string{source.Title, source.DescriptionTitle, source.Description} {
+		if field != nil && strings.TrimSpace(*field) != "" {
+			parts = append(parts, strings.TrimSpace(*field))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// meanVector returns the unit-length mean of vectors
+func meanVector(vectors [][]float32) []float32 {
+	mean := make([]float32, len(vectors[0]))
+	for _, v := range vectors {
+		for i, x := range v {
+			mean[i] += x
+		}
+	}
+	var norm float64
+	for _, x := range mean {
+		norm += float64(x) * float64(x)
+	}
+	if norm == 0 {
+		return mean
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range mean {
+		mean[i] *= scale
+	}
+	return mean
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"container/list"
+	"strconv"
+	"sync"
+)
+
+// EmbeddingCacheStats is a point-in-time snapshot of embedding cache usage
+type EmbeddingCacheStats struct {
+	Size       int     `json:"size"`
+	MaxEntries int     `json:"max_entries"`
+	Hits       int64   `json:"hits"`
+	Misses     int64   `json:"misses"`
+	HitRate    float64 `json:"hit_rate"`
+}
+
+// embeddingCacheEntry is one cached vector
+type embeddingCacheEntry struct {
+	key    string
+	vector []float32
+}
+
+// embeddingCache is an in-process LRU of embeddings keyed by model, dimensions, and input
+// text, so repeated queries skip the provider. Vectors are copied in and out, so callers
+// can't mutate the cached copy. Safe for concurrent use.
+type embeddingCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // Front = most recently used
+	entries    map[string]*list.Element
+	hits       int64
+	misses     int64
+}
+
+// newEmbeddingCache creates an embedding cache holding up to maxEntries vectors; nil when
+// maxEntries is 0 or less, which disables caching
+func newEmbeddingCache(maxEntries int) *embeddingCache {
+	if maxEntries <= 0 {
+		return nil
+	}
+	return &embeddingCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// embeddingCacheKey keys a text by the model and dimensions it was embedded with
+func embeddingCacheKey(model string, dims int, text string) string {
+	return model + "\x00" + strconv.Itoa(dims) + "\x00" + text
+}
+
+// get returns the cached vector for key, if present
+func (c *embeddingCache) get(key string) ([]float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	c.hits++
+	return append([]float32(nil), elem.Value.(*embeddingCacheEntry).vector...), true
+}
+
+// set stores a vector, evicting the least recently used entry when full
+func (c *embeddingCache) set(key string, vector []float32) {
+	entry := &embeddingCacheEntry{key: key, vector: append([]float32(nil), vector...)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*embeddingCacheEntry).key)
+	}
+}
+
+// stats returns the cache size and hit rate
+func (c *embeddingCache) stats() EmbeddingCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return EmbeddingCacheStats{
+		Size:       c.order.Len(),
+		MaxEntries: c.maxEntries,
+		Hits:       c.hits,
+		Misses:     c.misses,
+		HitRate:    hitRate(c.hits, c.misses),
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"core/internal/config"
+)
+
+// countingTransport answers embeddings requests with one vector per input, [len(input), 1],
+// and counts the requests and inputs it saw
+type countingTransport struct {
+	mu       sync.Mutex
+	requests int
+	inputs   []string
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body EmbeddingRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	t.requests++
+	t.inputs = append(t.inputs, body.Input...)
+	t.mu.Unlock()
+
+	data := make([]map[string]any, len(body.Input))
+	for i, input := range body.Input {
+		data[i] = map[string]any{"index": i, "embedding": []float32{float32(len(input)), 1}}
+	}
+	payload, _ := json.Marshal(map[string]any{"data": data, "model": body.Model})
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(string(payload))),
+		Request:    req,
+	}, nil
+}
+
+func newCachingEmbeddingClient(transport http.RoundTripper, cacheSize int) *OpenAIClient {
+	client := NewOpenAIClient(&config.OpenAIConfig{
+		APIBase:             "http://embeddings.test",
+		APIKey:              "test",
+		EmbeddingModel:      "test-embed",
+		EmbeddingDimensions: 2,
+		EmbeddingCacheSize:  cacheSize,
+		BatchSize:           10,
+		Timeout:             5,
+		Enabled:             true,
+	})
+	client.httpClient.Transport = transport
+	return client
+}
+
+func TestCreateEmbeddingsServesRepeatsFromCache(t *testing.T) {
+	transport := &countingTransport{}
+	client := newCachingEmbeddingClient(transport, 10)
+	ctx := context.Background()
+
+	first, err := client.CreateEmbeddings(ctx, []string{"3 bedroom condo"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	first[0][0] = -1 // Callers mutating a result must not corrupt the cache
+
+	second, err := client.CreateEmbeddings(ctx, []string{"3 bedroom condo"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if transport.requests != 1 {
+		t.Fatalf("Expected the repeat served from cache, got %d provider requests", transport.requests)
+	}
+	if second[0][0] != float32(len("3 bedroom condo")) {
+		t.Errorf("Expected the cached vector, got %v", second[0])
+	}
+
+	// Only the uncached text goes to the provider, and results keep the input order
+	mixed, err := client.CreateEmbeddings(ctx, []string{"hdb", "3 bedroom condo"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if transport.requests != 2 || len(transport.inputs) != 2 || transport.inputs[1] != "hdb" {
+		t.Errorf("Expected one request for just the new text, got %d requests with %v", transport.requests, transport.inputs)
+	}
+	if mixed[0][0] != 3 || mixed[1][0] != float32(len("3 bedroom condo")) {
+		t.Errorf("Expected results in input order, got %v", mixed)
+	}
+
+	if stats, ok := client.EmbeddingCacheStats(); !ok || stats.Size != 2 || stats.Hits != 2 || stats.Misses != 2 {
+		t.Errorf("Unexpected cache stats: %+v, %v", stats, ok)
+	}
+}
+
+func TestCreateEmbeddingsWithoutCache(t *testing.T) {
+	transport := &countingTransport{}
+	client := newCachingEmbeddingClient(transport, 0)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.CreateEmbeddings(context.Background(), []string{"condo"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if transport.requests != 2 {
+		t.Errorf("Expected every call to reach the provider with the cache disabled, got %d", transport.requests)
+	}
+	if _, ok := client.EmbeddingCacheStats(); ok {
+		t.Error("Expected no cache stats when EMBEDDING_CACHE_SIZE is 0")
+	}
+}
+
+func TestEmbeddingCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newEmbeddingCache(2)
+	cache.set("a", []float32{1})
+	cache.set("b", []float32{2})
+	cache.get("a")
+	cache.set("c", []float32{3})
+
+	if _, ok := cache.get("b"); ok {
+		t.Error("Expected b to be evicted")
+	}
+	if _, ok := cache.get("a"); !ok {
+		t.Error("Expected a to survive as recently used")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := string(rune('a' + i%3))
+			cache.set(key, []float32{float32(i)})
+			cache.get(key)
+		}(i)
+	}
+	wg.Wait()
+	if size := cache.stats().Size; size != 2 {
+		t.Errorf("Expected the cache capped at 2 entries, got %d", size)
+	}
+}
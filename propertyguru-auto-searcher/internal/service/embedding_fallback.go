@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"core/internal/config"
+	"core/internal/utils"
+)
+
+// embeddingEndpoint is one provider that can serve embedding requests
+type embeddingEndpoint struct {
+	Name    string // "primary" or "fallback", for logs
+	APIBase string
+	APIKey  string
+	Model   string
+}
+
+// newEmbeddingFallback returns the secondary embedding provider, or nil when none is
+// configured or it can't produce vectors compatible with the primary's. Embeddings from
+// different models don't share a vector space, so a different model is only accepted
+// when EMBEDDING_FALLBACK_ALLOW_MODEL_MISMATCH says the operator knows they do.
+func newEmbeddingFallback(cfg *config.OpenAIConfig) *embeddingEndpoint {
+	if cfg.EmbeddingFallbackAPIBase == "" {
+		return nil
+	}
+
+	fallback := &embeddingEndpoint{
+		Name:    "fallback",
+		APIBase: cfg.EmbeddingFallbackAPIBase,
+		APIKey:  cfg.EmbeddingFallbackAPIKey,
+		Model:   cfg.EmbeddingFallbackModel,
+	}
+	if fallback.APIKey == "" {
+		fallback.APIKey = cfg.APIKey
+	}
+	if fallback.Model == "" {
+		fallback.Model = cfg.EmbeddingModel
+	}
+	dims := cfg.EmbeddingFallbackDimensions
+	if dims == 0 {
+		dims = cfg.EmbeddingDimensions
+	}
+
+	if dims != cfg.EmbeddingDimensions {
+		log.Printf("❌ Embedding fallback disabled: %s returns %d dimensions but the primary %s returns %d",
+			fallback.Model, dims, cfg.EmbeddingModel, cfg.EmbeddingDimensions)
+		return nil
+	}
+	if fallback.Model != cfg.EmbeddingModel {
+		if !cfg.EmbeddingFallbackAllowModelMismatch {
+			log.Printf("❌ Embedding fallback disabled: model %s differs from the primary %s and their vectors are not comparable "+
+				"(set EMBEDDING_FALLBACK_ALLOW_MODEL_MISMATCH=true only if both share one vector space)",
+				fallback.Model, cfg.EmbeddingModel)
+			return nil
+		}
+		log.Printf("⚠️  WARNING: embedding fallback model %s differs from the primary %s. "+
+			"Vectors it produces are stored alongside the primary's and may rank poorly; re-embed them once the primary recovers",
+			fallback.Model, cfg.EmbeddingModel)
+	}
+
+	log.Printf("✅ Embedding fallback enabled: %s (%s) after %d failed primary attempts",
+		utils.RedactURL(fallback.APIBase), fallback.Model, primaryAttempts(cfg))
+	return fallback
+}
+
+// primaryAttempts is how many times a batch is tried on the primary before falling back
+func primaryAttempts(cfg *config.OpenAIConfig) int {
+	return max(1, cfg.EmbeddingPrimaryAttempts)
+}
+
+// embedBatchWithFallback embeds a batch on the primary provider, retrying it and then
+// switching to the fallback provider when one is configured. The fallback only serves
+// the default embedding model, since that is the one it was checked against. It also
+// returns the model that produced the vectors, which differs from model when a
+// mismatched fallback served them.
+func (c *OpenAIClient) embedBatchWithFallback(ctx context.Context, model string, dims int, texts []string) ([][]float32, string, error) {
+	primary := embeddingEndpoint{Name: "primary", APIBase: c.config.APIBase, APIKey: c.config.APIKey, Model: model}
+	if c.embeddingFallback == nil || model != c.config.EmbeddingModel {
+		embeddings, err := c.createEmbeddingBatch(ctx, primary, dims, texts)
+		return embeddings, model, err
+	}
+
+	attempts := primaryAttempts(c.config)
+	var primaryErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		embeddings, err := c.createEmbeddingBatch(ctx, primary, dims, texts)
+		if err == nil {
+			return embeddings, model, nil
+		}
+		if ctx.Err() != nil {
+			return nil, "", err
+		}
+		primaryErr = err
+		log.Printf("⚠️  Primary embedding provider failed (attempt %d/%d): %v", attempt, attempts, err)
+		if attempt < attempts {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	fallback := *c.embeddingFallback
+	if fallback.Model != model {
+		log.Printf("⚠️  WARNING: serving embeddings from fallback model %s instead of %s; these vectors are not from the primary model",
+			fallback.Model, model)
+	} else {
+		log.Printf("↪️  Falling back to %s for %d embeddings", utils.RedactURL(fallback.APIBase), len(texts))
+	}
+	embeddings, err := c.createEmbeddingBatch(ctx, fallback, dims, texts)
+	if err != nil {
+		return nil, "", fmt.Errorf("primary provider failed (%v) and fallback failed: %w", primaryErr, err)
+	}
+	return embeddings, fallback.Model, nil
+}
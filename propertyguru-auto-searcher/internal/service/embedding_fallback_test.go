@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"core/internal/config"
+)
+
+func embeddingServer(t *testing.T, status int, calls *int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"data":  []map[string]any{{"index": 0, "embedding": make([]float32, 1024)}},
+			"model": "baai/bge-m3",
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCreateEmbeddingsFallsBackAfterPrimaryAttempts(t *testing.T) {
+	var primaryCalls, fallbackCalls int
+	primary := embeddingServer(t, http.StatusServiceUnavailable, &primaryCalls)
+	fallback := embeddingServer(t, http.StatusOK, &fallbackCalls)
+
+	client := NewOpenAIClient(&config.OpenAIConfig{
+		APIBase:                  primary.URL,
+		APIKey:                   "test",
+		EmbeddingModel:           "baai/bge-m3",
+		EmbeddingDimensions:      1024,
+		BatchSize:                10,
+		Timeout:                  5,
+		Enabled:                  true,
+		EmbeddingPrimaryAttempts: 2,
+		EmbeddingFallbackAPIBase: fallback.URL,
+	})
+
+	embeddings, err := client.CreateEmbeddings(context.Background(), []string{"hello"})
+	if err != nil {
+		t.Fatalf("Expected fallback to serve the batch, got %v", err)
+	}
+	if len(embeddings) != 1 || len(embeddings[0]) != 1024 {
+		t.Errorf("Expected one 1024-dim embedding, got %d", len(embeddings))
+	}
+	if primaryCalls != 2 || fallbackCalls != 1 {
+		t.Errorf("Expected 2 primary and 1 fallback calls, got %d and %d", primaryCalls, fallbackCalls)
+	}
+}
+
+func TestNewEmbeddingFallbackRejectsIncompatibleProviders(t *testing.T) {
+	base := config.OpenAIConfig{
+		EmbeddingModel:           "baai/bge-m3",
+		EmbeddingDimensions:      1024,
+		EmbeddingFallbackAPIBase: "http://fallback",
+	}
+
+	tests := []struct {
+		name   string
+		modify func(cfg *config.OpenAIConfig)
+		want   bool
+	}{
+		{"same model", func(cfg *config.OpenAIConfig) {}, true},
+		{"not configured", func(cfg *config.OpenAIConfig) { cfg.EmbeddingFallbackAPIBase = "" }, false},
+		{"dimension mismatch", func(cfg *config.OpenAIConfig) { cfg.EmbeddingFallbackDimensions = 1536 }, false},
+		{"different model", func(cfg *config.OpenAIConfig) { cfg.EmbeddingFallbackModel = "nvidia/nv-embedqa-e5-v5" }, false},
+		{"different model allowed", func(cfg *config.OpenAIConfig) {
+			cfg.EmbeddingFallbackModel = "nvidia/nv-embedqa-e5-v5"
+			cfg.EmbeddingFallbackAllowModelMismatch = true
+		}, true},
+	}
+
+	for _, tt := range tests {
+		cfg := base
+		tt.modify(&cfg)
+		if got := newEmbeddingFallback(&cfg) != nil; got != tt.want {
+			t.Errorf("%s: fallback enabled = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCreateEmbeddingsCachesOnlyPrimaryModelVectors(t *testing.T) {
+	var primaryCalls, fallbackCalls int
+	primary := embeddingServer(t, http.StatusServiceUnavailable, &primaryCalls)
+	fallback := embeddingServer(t, http.StatusOK, &fallbackCalls)
+
+	cfg := &config.OpenAIConfig{
+		APIBase:                  primary.URL,
+		APIKey:                   "test",
+		EmbeddingModel:           "baai/bge-m3",
+		EmbeddingDimensions:      1024,
+		EmbeddingCacheSize:       10,
+		BatchSize:                10,
+		Timeout:                  5,
+		Enabled:                  true,
+		EmbeddingPrimaryAttempts: 1,
+		EmbeddingFallbackAPIBase: fallback.URL,
+		EmbeddingFallbackModel:   "nvidia/nv-embedqa-e5-v5",
+
+		EmbeddingFallbackAllowModelMismatch: true,
+	}
+	client := NewOpenAIClient(cfg)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := client.CreateEmbeddings(ctx, []string{"hello"}); err != nil {
+			t.Fatalf("Expected fallback to serve the batch, got %v", err)
+		}
+	}
+	// The fallback's vectors aren't cached under the primary model, so the repeat retries the primary
+	if primaryCalls != 2 || fallbackCalls != 2 {
+		t.Errorf("Expected 2 primary and 2 fallback calls, got %d and %d", primaryCalls, fallbackCalls)
+	}
+	if stats, _ := client.EmbeddingCacheStats(); stats.Size != 0 {
+		t.Errorf("Expected nothing cached from a mismatched fallback, got %+v", stats)
+	}
+
+	// A fallback serving the same model is interchangeable, so its vectors are cached
+	primaryCalls, fallbackCalls = 0, 0
+	cfg.EmbeddingFallbackModel = ""
+	client = NewOpenAIClient(cfg)
+	for i := 0; i < 2; i++ {
+		if _, err := client.CreateEmbeddings(ctx, []string{"hello"}); err != nil {
+			t.Fatalf("Expected fallback to serve the batch, got %v", err)
+		}
+	}
+	if primaryCalls != 1 || fallbackCalls != 1 {
+		t.Errorf("Expected the repeat served from cache, got %d primary and %d fallback calls", primaryCalls, fallbackCalls)
+	}
+}
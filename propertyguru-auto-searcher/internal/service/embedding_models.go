@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrEmbeddingModelNotAllowed is returned when a request names a model outside EMBEDDING_MODEL_ALLOWLIST
+var ErrEmbeddingModelNotAllowed = errors.New("embedding model not allowed")
+
+// AllowedEmbeddingModels returns the allowlisted embedding models, sorted
+func (c *OpenAIClient) AllowedEmbeddingModels() []string {
+	models := make([]string, 0, len(c.config.EmbeddingModels))
+	for model := range c.config.EmbeddingModels {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
+
+// ResolveEmbeddingModel returns the model to use and its expected dimensions.
+// An empty name selects the configured default model.
+func (c *OpenAIClient) ResolveEmbeddingModel(model string) (string, int, error) {
+	if model == "" {
+		model = c.config.EmbeddingModel
+	}
+	dims, ok := c.config.EmbeddingModels[model]
+	if !ok {
+		if model == c.config.EmbeddingModel {
+			return model, c.config.EmbeddingDimensions, nil
+		}
+		return "", 0, fmt.Errorf("%w: %q (allowed: %s)", ErrEmbeddingModelNotAllowed, model, strings.Join(c.AllowedEmbeddingModels(), ", "))
+	}
+	return model, dims, nil
+}
+
+// CreateEmbeddingsWithModel creates embeddings with an allowlisted model, rejecting
+// disallowed models before calling the provider and any vector of the wrong dimension after
+func (c *OpenAIClient) CreateEmbeddingsWithModel(ctx context.Context, model string, texts []string) ([][]float32, error) {
+	model, dims, err := c.ResolveEmbeddingModel(model)
+	if err != nil {
+		return nil, err
+	}
+	return c.createEmbeddings(ctx, model, dims, texts)
+}
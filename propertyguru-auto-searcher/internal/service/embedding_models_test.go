@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"core/internal/config"
+)
+
+func newEmbeddingTestClient(apiBase string) *OpenAIClient {
+	return NewOpenAIClient(&config.OpenAIConfig{
+		APIBase:             apiBase,
+		APIKey:              "test",
+		EmbeddingModel:      "baai/bge-m3",
+		EmbeddingDimensions: 1024,
+		EmbeddingModels:     map[string]int{"baai/bge-m3": 1024, "nvidia/nv-embedqa-e5-v5": 1024},
+		BatchSize:           10,
+		Timeout:             5,
+		Enabled:             true,
+	})
+}
+
+func TestResolveEmbeddingModel(t *testing.T) {
+	client := newEmbeddingTestClient("http://unused")
+
+	model, dims, err := client.ResolveEmbeddingModel("")
+	if err != nil || model != "baai/bge-m3" || dims != 1024 {
+		t.Errorf("Expected default model baai/bge-m3/1024, got %s/%d (err %v)", model, dims, err)
+	}
+
+	model, dims, err = client.ResolveEmbeddingModel("nvidia/nv-embedqa-e5-v5")
+	if err != nil || model != "nvidia/nv-embedqa-e5-v5" || dims != 1024 {
+		t.Errorf("Expected nvidia/nv-embedqa-e5-v5/1024, got %s/%d (err %v)", model, dims, err)
+	}
+
+	_, _, err = client.ResolveEmbeddingModel("text-embedding-3-large")
+	if !errors.Is(err, ErrEmbeddingModelNotAllowed) {
+		t.Fatalf("Expected ErrEmbeddingModelNotAllowed, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "baai/bge-m3, nvidia/nv-embedqa-e5-v5") {
+		t.Errorf("Expected error to list allowed models, got %q", err)
+	}
+}
+
+func TestCreateEmbeddingsWithModelRejectsBeforeCallingProvider(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	client := newEmbeddingTestClient(server.URL)
+	if _, err := client.CreateEmbeddingsWithModel(context.Background(), "unknown-model", []string{"hello"}); err == nil {
+		t.Fatal("Expected disallowed model to be rejected")
+	}
+	if called {
+		t.Error("Expected provider not to be called for a disallowed model")
+	}
+}
+
+func TestCreateEmbeddingsRejectsWrongDimensions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"data":  []map[string]any{{"index": 0, "embedding": make([]float32, 768)}},
+			"model": "baai/bge-m3",
+		})
+	}))
+	defer server.Close()
+
+	client := newEmbeddingTestClient(server.URL)
+	_, err := client.CreateEmbeddings(context.Background(), []string{"hello"})
+	if err == nil || !strings.Contains(err.Error(), "expected 1024") {
+		t.Errorf("Expected dimension mismatch error, got %v", err)
+	}
+}
+
+func TestCreateEmbeddingsSplitsOversizedBatches(t *testing.T) {
+	var requestSizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req EmbeddingRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		requestSizes = append(requestSizes, len(req.Input))
+		data := make([]map[string]any, len(req.Input))
+		for i := range data {
+			data[i] = map[string]any{"index": i, "embedding": make([]float32, 1024)}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data, "model": "baai/bge-m3"})
+	}))
+	defer server.Close()
+
+	client := newEmbeddingTestClient(server.URL)
+	client.config.BatchSize = 1000 // Misconfigured far above the provider limit
+	client.config.EmbeddingMaxInputs = 2
+	embeddings, err := client.CreateEmbeddings(context.Background(), []string{"a", "b", "c", "d", "e"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(embeddings) != 5 || len(requestSizes) != 3 || requestSizes[0] != 2 || requestSizes[2] != 1 {
+		t.Errorf("Expected 5 embeddings from requests of 2, 2 and 1 inputs, got %d from %v", len(embeddings), requestSizes)
+	}
+}
+
+func TestCreateEmbeddingsRejectsOversizedInput(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	client := newEmbeddingTestClient(server.URL)
+	client.config.EmbeddingMaxInputChars = 10
+	_, err := client.CreateEmbeddings(context.Background(), []string{"short", strings.Repeat("x", 11)})
+	if err == nil || !strings.Contains(err.Error(), "embedding input 1") {
+		t.Errorf("Expected the oversized input to be named, got %v", err)
+	}
+	if called {
+		t.Error("Expected provider not to be called with an oversized input")
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"core/internal/config"
+	"core/internal/model"
+	"core/internal/repository"
+)
+
+func TestMeanVector(t *testing.T) {
+	mean := meanVector([][]float32{{1, 0}, {0, 1}})
+	want := float32(1 / math.Sqrt2)
+	for i, x := range mean {
+		if math.Abs(float64(x-want)) > 1e-6 {
+			t.Errorf("mean[%d] = %v, want %v", i, x, want)
+		}
+	}
+
+	zero := meanVector([][]float32{{1, -1}, {-1, 1}})
+	if zero[0] != 0 || zero[1] != 0 {
+		t.Errorf("opposite vectors should average to zero, got %v", zero)
+	}
+}
+
+func TestEmbeddingText(t *testing.T) {
+	source := repository.EmbeddingSource{
+		ListingID:   1,
+		Title:       stringPtr("Cosy 2BR"),
+		Description: stringPtr("  Near MRT  "),
+	}
+	if got := embeddingText(source); got != "Cosy 2BR\n\nNear MRT" {
+		t.Errorf("embeddingText = %q", got)
+	}
+}
+
+func TestBackfillBatchesReportsPartialFailures(t *testing.T) {
+	s := &EmbeddingService{config: &config.EmbeddingConfig{BackfillBatch: 2}}
+	sources := []repository.EmbeddingSource{
+		{ListingID: 1, Title: stringPtr("one")},
+		{ListingID: 2, Title: stringPtr("two")},
+		{ListingID: 3, Title: stringPtr("three")},
+		{ListingID: 4},
+	}
+
+	calls := 0
+	embed := func(ctx context.Context, texts []string) ([][]float32, error) {
+		calls++
+		if calls == 2 {
+			return nil, errors.New("provider unavailable")
+		}
+		vectors := make([][]float32, len(texts))
+		for i := range vectors {
+			vectors[i] = []float32{1, 0}
+		}
+		return vectors, nil
+	}
+	var stored []int64
+	store := func(ctx context.Context, items []model.EmbeddingItem) ([]int64, []model.EmbeddingItemError) {
+		var succeeded []int64
+		var failures []model.EmbeddingItemError
+		for _, item := range items {
+			if item.ListingID == 2 {
+				failures = append(failures, model.EmbeddingItemError{ListingID: 2, Error: "write failed"})
+				continue
+			}
+			succeeded = append(succeeded, item.ListingID)
+		}
+		stored = append(stored, succeeded...)
+		return succeeded, failures
+	}
+
+	response := s.backfillBatches(context.Background(), sources, embed, store)
+	if response.Processed != 4 || response.Success != 1 || response.Failed != 3 {
+		t.Errorf("Expected 4 processed, 1 success, 3 failed, got %+v", response)
+	}
+	if len(stored) != 1 || stored[0] != 1 || len(response.SucceededIDs) != 1 {
+		t.Errorf("Expected the first batch to be stored despite later failures, got %v", stored)
+	}
+	if len(response.WriteErrors) != 1 || response.WriteErrors[0].ListingID != 2 {
+		t.Errorf("Expected a write error for listing 2, got %v", response.WriteErrors)
+	}
+	if len(response.GenerationErrors) != 2 {
+		t.Fatalf("Expected generation errors for listings 3 and 4, got %v", response.GenerationErrors)
+	}
+	if response.GenerationErrors[0].ListingID != 4 || response.GenerationErrors[1].ListingID != 3 {
+		t.Errorf("Expected listing 4 (no text) then 3 (provider error), got %v", response.GenerationErrors)
+	}
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"core/internal/model"
+)
+
+// facetCounts counts the matches per value of each requested facet. Each facet drops the
+// filters on its own dimension, so a 3-bedroom search still shows how many 2- and 4-bedroom
+// listings match everything else. Failed counts are logged and skipped.
+func (s *SearchService) facetCounts(ctx context.Context, filters *model.SearchFilters, options *model.SearchOptions) map[string]map[string]int {
+	if options == nil || len(options.Facets) == 0 {
+		return nil
+	}
+
+	facets := make(map[string]map[string]int, len(options.Facets))
+	for _, facet := range options.Facets {
+		if _, done := facets[facet]; done {
+			continue
+		}
+		counts, err := s.repo.FacetCounts(ctx, filters.WithoutFacet(facet), facet)
+		if err != nil {
+			log.Printf("⚠️  Facet count failed for %s: %v", facet, err)
+			continue
+		}
+		facets[facet] = counts
+	}
+	return facets
+}
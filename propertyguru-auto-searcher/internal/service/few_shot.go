@@ -0,0 +1,123 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FewShotExample is one worked example for the intent prompt: a query and the JSON the
+// model should answer with
+type FewShotExample struct {
+	Query    string          `json:"query"`
+	Response json.RawMessage `json:"response"`
+}
+
+// defaultIntentExamples are the worked examples used when no few-shot file is configured
+const defaultIntentExamples = `Query: "3 bedroom condo in Punggol under 1.5M"
+Response: {"bedrooms": 3, "unit_type": "Condo", "location": "Punggol", "price_max": 1500000, "keywords": ["condo", "punggol"]}
+
+Query: "HDB near MRT with good view and spacious layout"
+Response: {"unit_type": "HDB", "mrt_distance_max": 15, "keywords": ["view", "spacious", "near mrt", "hdb"]}
+
+Query: "Condo with swimming pool and gym, 2 bedrooms, at least 1000 sqft"
+Response: {"bedrooms": 2, "unit_type": "Condo", "area_sqft_min": 1000, "facilities": ["Swimming pool", "Gym"], "keywords": ["condo", "pool", "gym"]}
+
+Query: "Apartment with balcony and air conditioning, fully furnished, 800-1200 sqft"
+Response: {"area_sqft_min": 800, "area_sqft_max": 1200, "amenities": ["Balcony", "Air conditioner"], "keywords": ["furnished", "balcony", "aircon"]}
+
+Query: "Large 4-bedroom landed house, minimum 2500 sqft"
+Response: {"bedrooms": 4, "unit_type": "Landed", "area_sqft_min": 2500, "keywords": ["large", "landed", "house"]}
+
+Query: "At least 3 bedrooms and 2+ bathrooms in Sengkang"
+Response: {"bedrooms_min": 3, "bathrooms_min": 2, "location": "Sengkang", "keywords": ["sengkang"]}
+
+Query: "Landed property in Bukit Timah, 4 bed 3 bath, modern"
+Response: {"unit_type": "Landed", "location": "Bukit Timah", "bedrooms": 4, "bathrooms": 3, "keywords": ["modern", "landed", "bukit timah"]}
+
+Query: "2 bedroom near Dhoby Ghaut MRT"
+Response: {"bedrooms": 2, "mrt_station": "Dhoby Ghaut", "keywords": ["dhoby ghaut", "mrt"]}
+
+Query: "HDB in Tampines around $600k"
+Response: {"unit_type": "HDB", "location": "Tampines", "price_target": 600000, "keywords": ["hdb", "tampines"]}
+
+Query: "Condo on the Circle Line under 1.8M"
+Response: {"unit_type": "Condo", "mrt_line": "CCL", "price_max": 1800000, "keywords": ["condo", "circle line"]}
+
+Query: "2 bedroom condo in District 9 under 2.5M"
+Response: {"bedrooms": 2, "unit_type": "Condo", "district": "D09", "price_max": 2500000, "keywords": ["district 9", "condo"]}
+
+Query: "3 bedroom near the North East Line or Circle Line"
+Response: {"bedrooms": 3, "mrt_lines": ["NEL", "CCL"], "keywords": ["north east line", "circle line"]}
+
+Query: "Condo or EC under 1.5M"
+Response: {"unit_types": ["Condo", "EC"], "price_max": 1500000, "keywords": ["condo", "ec"]}
+
+Query: "Anything except HDB in Bishan, not near a highway"
+Response: {"location": "Bishan", "exclude_unit_types": ["HDB"], "exclude_keywords": ["highway"], "keywords": ["bishan", "quiet"]}
+
+Query: "New condo near Orchard, budget 2M max"
+Response: {"unit_type": "Condo", "location": "Orchard", "price_max": 2000000, "build_year_min": 2015, "keywords": ["new", "condo", "orchard"]}`
+
+// LoadFewShotExamples reads a JSON array of {"query", "response"} examples. Every response
+// must parse into AIIntentResponse without unknown fields and pass the same validation as
+// a live model answer, so a typo in the file fails at startup rather than teaching the
+// model a field we ignore.
+func LoadFewShotExamples(path string) ([]FewShotExample, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read few-shot examples: %w", err)
+	}
+
+	var examples []FewShotExample
+	if err := json.Unmarshal(data, &examples); err != nil {
+		return nil, fmt.Errorf("failed to parse few-shot examples: %w", err)
+	}
+	if len(examples) == 0 {
+		return nil, fmt.Errorf("no few-shot examples in %s", path)
+	}
+
+	for i, example := range examples {
+		if strings.TrimSpace(example.Query) == "" {
+			return nil, fmt.Errorf("example %d: query is empty", i)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(example.Response))
+		decoder.DisallowUnknownFields()
+		var intent AIIntentResponse
+		if err := decoder.Decode(&intent); err != nil {
+			return nil, fmt.Errorf("example %d (%q): invalid response: %w", i, example.Query, err)
+		}
+		if err := validateIntentResponse(&intent); err != nil {
+			return nil, fmt.Errorf("example %d (%q): %w", i, example.Query, err)
+		}
+	}
+	return examples, nil
+}
+
+// SetFewShotExamples replaces the built-in examples in the intent prompt
+func (c *OpenAIClient) SetFewShotExamples(examples []FewShotExample) {
+	c.fewShotExamples = renderFewShotExamples(examples)
+}
+
+// promptExamples returns the configured examples, or defaults when none were loaded
+func (c *OpenAIClient) promptExamples(defaults string) string {
+	if c.fewShotExamples != "" {
+		return c.fewShotExamples
+	}
+	return defaults
+}
+
+// renderFewShotExamples formats examples as the prompt's Query:/Response: pairs
+func renderFewShotExamples(examples []FewShotExample) string {
+	blocks := make([]string, len(examples))
+	for i, example := range examples {
+		var response bytes.Buffer
+		if err := json.Compact(&response, example.Response); err != nil {
+			response.Write(example.Response)
+		}
+		blocks[i] = fmt.Sprintf("Query: \"%s\"\nResponse: %s", example.Query, response.String())
+	}
+	return strings.Join(blocks, "\n\n")
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFewShotExamplesShippedFile(t *testing.T) {
+	examples, err := LoadFewShotExamples("../../config/prompts/few_shot_examples.json")
+	if err != nil {
+		t.Fatalf("Expected shipped examples to load, got %v", err)
+	}
+	if len(examples) == 0 {
+		t.Fatal("Expected at least one example")
+	}
+
+	// The examples must follow the prompt rules they teach by example
+	for _, example := range examples {
+		var response AIIntentResponse
+		if err := json.Unmarshal(example.Response, &response); err != nil {
+			t.Fatalf("%q: %v", example.Query, err)
+		}
+		if strings.Contains(strings.ToLower(example.Query), "psf") && (response.PriceMax != nil || response.PricePerSqftMax == nil) {
+			t.Errorf("%q: expected a psf budget as price_per_sqft_max, not price_max", example.Query)
+		}
+	}
+}
+
+func TestLoadFewShotExamplesRejectsInvalidResponses(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"unknown field", `[{"query": "cheap condo", "response": {"unit_type": "Condo", "psf_max": 1500}}]`},
+		{"invalid unit type", `[{"query": "castle", "response": {"unit_type": "Castle"}}]`},
+		{"empty query", `[{"query": " ", "response": {"bedrooms": 2}}]`},
+		{"no examples", `[]`},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		path := filepath.Join(dir, "examples.json")
+		if err := os.WriteFile(path, []byte(tt.body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadFewShotExamples(path); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestPromptExamplesUsesLoadedExamples(t *testing.T) {
+	client := &OpenAIClient{}
+	if got := client.promptExamples(defaultIntentExamples); got != defaultIntentExamples {
+		t.Error("Expected built-in examples when none are loaded")
+	}
+
+	client.SetFewShotExamples([]FewShotExample{
+		{Query: "EC in Punggol", Response: []byte(`{"unit_type": "EC",  "location": "Punggol"}`)},
+		{Query: "2 bed", Response: []byte(`{"bedrooms": 2}`)},
+	})
+	want := "Query: \"EC in Punggol\"\nResponse: {\"unit_type\":\"EC\",\"location\":\"Punggol\"}\n\nQuery: \"2 bed\"\nResponse: {\"bedrooms\":2}"
+	if got := client.promptExamples(defaultIntentExamples); got != want {
+		t.Errorf("promptExamples() = %q, want %q", got, want)
+	}
+	if strings.Contains(client.intentSystemPrompt(""), "Punggol under 1.5M") {
+		t.Error("Expected loaded examples to replace the defaults in the intent prompt")
+	}
+}
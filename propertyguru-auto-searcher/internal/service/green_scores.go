@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"core/internal/model"
+	"core/internal/utils"
+)
+
+// greenScoreCache holds the most recent per-location green score aggregate
+type greenScoreCache struct {
+	mu      sync.Mutex
+	items   []model.LocationGreenScore
+	expires time.Time
+}
+
+// GreenScoresByLocation returns the average green score per canonical area, greenest first.
+// Listing addresses are grouped by the area they name, so "Tampines Street 86" and
+// "Tampines Ave 5" count towards Tampines; addresses naming no known area stay on their own.
+// Areas with fewer than GREEN_SCORES_MIN_LISTINGS scored listings are left out so small
+// samples don't top the list. The aggregate is cached for GREEN_SCORES_CACHE_TTL.
+func (s *SearchService) GreenScoresByLocation(ctx context.Context, limit int) ([]model.LocationGreenScore, error) {
+	s.greenScores.mu.Lock()
+	defer s.greenScores.mu.Unlock()
+
+	if s.greenScores.items == nil || time.Now().After(s.greenScores.expires) {
+		scores, err := s.repo.GreenScoresByLocation(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.greenScores.items = groupGreenScoresByArea(scores, s.config.GreenScoreMinListings)
+		s.greenScores.expires = time.Now().Add(time.Duration(s.config.GreenScoreCacheTTL) * time.Second)
+	}
+
+	items := s.greenScores.items
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return append([]model.LocationGreenScore{}, items...), nil
+}
+
+// groupGreenScoresByArea combines per-address averages into listing-weighted averages per
+// canonical area, rounded to two decimals, greenest first and then by name
+func groupGreenScoresByArea(scores []model.LocationGreenScore, minListings int) []model.LocationGreenScore {
+	totals := make(map[string]*model.LocationGreenScore)
+	for _, score := range scores {
+		area := utils.AreaOfAddress(score.Location)
+		if area == "" {
+			area = strings.TrimSpace(score.Location)
+		}
+		total, ok := totals[area]
+		if !ok {
+			total = &model.LocationGreenScore{Location: area}
+			totals[area] = total
+		}
+		total.AverageScore += score.AverageScore * float64(score.Listings)
+		total.Listings += score.Listings
+	}
+
+	grouped := []model.LocationGreenScore{}
+	for _, total := range totals {
+		if total.Listings == 0 || total.Listings < minListings {
+			continue
+		}
+		total.AverageScore = math.Round(total.AverageScore/float64(total.Listings)*100) / 100
+		grouped = append(grouped, *total)
+	}
+	sort.Slice(grouped, func(i, j int) bool {
+		if grouped[i].AverageScore != grouped[j].AverageScore {
+			return grouped[i].AverageScore > grouped[j].AverageScore
+		}
+		return grouped[i].Location < grouped[j].Location
+	})
+	return grouped
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"core/internal/config"
+	"core/internal/model"
+)
+
+// fakeGreenScoreRepository serves per-address green score averages and counts the queries
+type fakeGreenScoreRepository struct {
+	searchRepository
+	scores []model.LocationGreenScore
+	calls  int
+}
+
+func (r *fakeGreenScoreRepository) GreenScoresByLocation(ctx context.Context) ([]model.LocationGreenScore, error) {
+	r.calls++
+	return r.scores, nil
+}
+
+func TestGreenScoresByLocationGroupsByArea(t *testing.T) {
+	repo := &fakeGreenScoreRepository{scores: []model.LocationGreenScore{
+		{Location: "Tampines Street 86", AverageScore: 4, Listings: 3},
+		{Location: "Tampines Ave 5", AverageScore: 3, Listings: 1},
+		{Location: "Punggol Field", AverageScore: 4.5, Listings: 2},
+		{Location: "Punggol Walk", AverageScore: 4.2, Listings: 2},
+		{Location: "Bt Batok West Ave 8", AverageScore: 5, Listings: 1}, // Too few listings
+		{Location: "Zzyzx Road", AverageScore: 2, Listings: 4},
+	}}
+	s := &SearchService{repo: repo, config: &config.SearchConfig{GreenScoreMinListings: 4, GreenScoreCacheTTL: 3600}}
+
+	ctx := context.Background()
+	scores, err := s.GreenScoresByLocation(ctx, 0)
+	if err != nil {
+		t.Fatalf("GreenScoresByLocation failed: %v", err)
+	}
+	want := []model.LocationGreenScore{
+		{Location: "Punggol", AverageScore: 4.35, Listings: 4},
+		{Location: "Tampines", AverageScore: 3.75, Listings: 4},
+		{Location: "Zzyzx Road", AverageScore: 2, Listings: 4},
+	}
+	if !reflect.DeepEqual(scores, want) {
+		t.Errorf("Expected %v, got %v", want, scores)
+	}
+
+	// The limit applies to the cached aggregate without querying again
+	scores, err = s.GreenScoresByLocation(ctx, 1)
+	if err != nil {
+		t.Fatalf("GreenScoresByLocation failed: %v", err)
+	}
+	if len(scores) != 1 || scores[0].Location != "Punggol" {
+		t.Errorf("Expected only the greenest area, got %v", scores)
+	}
+	scores[0].Location = "mutated"
+	if repo.calls != 1 {
+		t.Errorf("Expected the aggregate cached after one query, got %d queries", repo.calls)
+	}
+	if again, _ := s.GreenScoresByLocation(ctx, 1); again[0].Location != "Punggol" {
+		t.Errorf("Expected callers not to mutate the cache, got %v", again)
+	}
+
+	// Without a TTL every request queries again
+	s.config.GreenScoreCacheTTL = 0
+	s.greenScores.items = nil
+	s.GreenScoresByLocation(ctx, 0)
+	s.GreenScoresByLocation(ctx, 0)
+	if repo.calls != 3 {
+		t.Errorf("Expected a query per request without a cache TTL, got %d queries", repo.calls)
+	}
+}
@@ -2,31 +2,123 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 
 	"core/internal/model"
+	"core/internal/utils"
 )
 
 // IntentParser parses natural language queries into structured filters using AI
 type IntentParser struct {
-	aiClient *OpenAIClient
+	aiClient     *OpenAIClient
+	cache        IntentCache // Optional; nil disables caching
+	reparseStale bool        // Treat cached intents from an older schema as misses instead of upgrading them
 }
 
-// NewIntentParser creates a new intent parser
-func NewIntentParser(aiClient *OpenAIClient) *IntentParser {
+// Intent cache schema mismatch modes (INTENT_CACHE_SCHEMA_MISMATCH)
+const (
+	SchemaMismatchUpgrade = "upgrade"
+	SchemaMismatchReparse = "reparse"
+)
+
+// NewIntentParser creates a new intent parser. schemaMismatch (SchemaMismatchUpgrade or
+// SchemaMismatchReparse) decides how cached intents from an older slot schema are reused.
+func NewIntentParser(aiClient *OpenAIClient, cache IntentCache, schemaMismatch string) *IntentParser {
 	return &IntentParser{
-		aiClient: aiClient,
+		aiClient:     aiClient,
+		cache:        cache,
+		reparseStale: schemaMismatch == SchemaMismatchReparse,
+	}
+}
+
+// CacheStats returns intent cache statistics, or nil when caching is disabled
+func (p *IntentParser) CacheStats(ctx context.Context) *IntentCacheStats {
+	if p.cache == nil {
+		return nil
 	}
+	stats := p.cache.Stats(ctx)
+	return &stats
 }
 
-// Parse extracts structured information from a natural language query using AI
-func (p *IntentParser) Parse(query string) *model.IntentResult {
+// ClearCache drops all cached intents, e.g. after the system prompt changes
+func (p *IntentParser) ClearCache(ctx context.Context) (int, error) {
+	if p.cache == nil {
+		return 0, nil
+	}
+	return p.cache.Clear(ctx)
+}
+
+// ErrIntentCacheDisabled is returned when warming the cache while caching is off
+var ErrIntentCacheDisabled = errors.New("intent cache is disabled")
+
+// WarmCache parses a query with AI and stores the result in the intent cache, reporting
+// whether it was cached already. Unlike Parse, failures are returned instead of
+// falling back, so a warm-up run shows exactly which queries didn't make it into the cache.
+func (p *IntentParser) WarmCache(ctx context.Context, query string) (bool, error) {
+	if p.cache == nil {
+		return false, ErrIntentCacheDisabled
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return false, errors.New("query is empty")
+	}
+	if _, ok := p.cachedIntent(ctx, query, ""); ok {
+		return true, nil
+	}
+	if p.aiClient == nil || !p.aiClient.config.Enabled {
+		return false, errors.New("AI parsing is not enabled")
+	}
+	if p.aiClient.TokenBudgetExhausted() {
+		return false, errors.New("LLM token budget exhausted")
+	}
+
+	result, err := p.parseWithAI(query, "")
+	if err != nil {
+		return false, err
+	}
+	p.cacheIntent(ctx, query, "", result)
+	return false, nil
+}
+
+// cachedIntent looks up a previously parsed query
+func (p *IntentParser) cachedIntent(ctx context.Context, query, locale string) (*model.IntentResult, bool) {
+	if p.cache == nil {
+		return nil, false
+	}
+	cached, ok := p.cache.Get(ctx, localizedCacheKey(query, locale))
+	if !ok || cached.Slots == nil || cached.Slots.Current() {
+		return cached, ok
+	}
+
+	// Parsed under an older slot schema: re-parse, or upgrade when that's enough
+	if p.reparseStale {
+		return nil, false
+	}
+	if err := cached.Slots.Upgrade(); err != nil {
+		log.Printf("⚠️  Ignoring cached intent for %q: %v", query, err)
+		return nil, false
+	}
+	return cached, true
+}
+
+// cacheIntent stores a successful AI parse; fallback results are never cached
+func (p *IntentParser) cacheIntent(ctx context.Context, query, locale string, result *model.IntentResult) {
+	if p.cache == nil {
+		return
+	}
+	p.cache.Set(ctx, localizedCacheKey(query, locale), result)
+}
+
+// Parse extracts structured information from a natural language query using AI.
+// locale ("de-DE", "en-IN") is how the user writes numbers; "" keeps the SGD defaults.
+func (p *IntentParser) Parse(query, locale string) *model.IntentResult {
 	query = strings.TrimSpace(query)
 	if query == "" {
 		return &model.IntentResult{
-			Slots:            &model.IntentSlots{},
+			Slots:            model.NewIntentSlots(),
 			SemanticKeywords: []string{},
 			Confidence:       0.0,
 		}
@@ -34,38 +126,75 @@ func (p *IntentParser) Parse(query string) *model.IntentResult {
 
 	// Check if AI is enabled
 	if p.aiClient == nil || !p.aiClient.config.Enabled {
-		log.Printf("OpenAI is not enabled, returning empty result. Please set OPENAI_API_KEY environment variable.")
-		return &model.IntentResult{
-			Slots:            &model.IntentSlots{},
-			SemanticKeywords: []string{query}, // At least include the original query
-			Confidence:       0.0,
-		}
+		log.Printf("OpenAI is not enabled, using rule-based parsing. Please set OPENAI_API_KEY environment variable.")
+		return p.fallbackResult(query, "")
+	}
+
+	// Serve repeated queries from cache without calling the AI provider
+	if cached, ok := p.cachedIntent(context.Background(), query, locale); ok {
+		return cached
+	}
+
+	// Skip the AI call once the token budget for this window is spent
+	if p.aiClient.TokenBudgetExhausted() {
+		log.Printf("⚠️  LLM token budget exhausted, skipping AI parsing")
+		return p.fallbackResult(query, model.AISkippedTokenBudget)
 	}
 
 	// Use AI to parse the query
-	result, err := p.parseWithAI(query)
+	result, err := p.parseWithAI(query, locale)
 	if err != nil {
-		log.Printf("AI parsing failed: %v, returning empty result", err)
-		return &model.IntentResult{
-			Slots:            &model.IntentSlots{},
-			SemanticKeywords: []string{query},
-			Confidence:       0.0,
-		}
+		log.Printf("AI parsing failed: %v, using rule-based parsing", err)
+		return p.fallbackResult(query, "")
 	}
 
+	p.cacheIntent(context.Background(), query, locale, result)
 	return result
 }
 
+// fallbackResult builds the intent result used when AI parsing is unavailable or skipped.
+// Slots come from the rule-based parser, so obvious filters still apply without an LLM.
+func (p *IntentParser) fallbackResult(query, skippedReason string) *model.IntentResult {
+	return &model.IntentResult{
+		Slots:            parseIntentRules(query),
+		SemanticKeywords: []string{query}, // At least include the original query
+		Confidence:       0.0,
+		AISkippedReason:  skippedReason,
+	}
+}
+
+// intentDebug records the raw LLM output and how it was repaired
+func intentDebug(aiResult *AIIntentResponse) *model.IntentDebug {
+	if aiResult.RawContent == "" {
+		return nil
+	}
+	return &model.IntentDebug{
+		RawJSON:        aiResult.RawContent,
+		Repaired:       aiResult.ParseStrategy != utils.JSONStrategyDirect,
+		RepairStrategy: aiResult.ParseStrategy,
+		StreamRecovery: aiResult.StreamRecovery,
+	}
+}
+
+// splitUnitTypes maps the AI's unit_type, which may be a list, onto the single unit_type slot
+// and the unit_types list; extra entries go ahead of the existing unit_types
+func splitUnitTypes(unitType model.StringOrList, unitTypes []string) (*string, []string) {
+	if len(unitType) <= 1 {
+		return unitType.First(), unitTypes
+	}
+	return unitType.First(), append(append([]string{}, unitType[1:]...), unitTypes...)
+}
+
 // parseWithAI uses OpenAI to parse the query with strict validation
-func (p *IntentParser) parseWithAI(query string) (*model.IntentResult, error) {
+func (p *IntentParser) parseWithAI(query, locale string) (*model.IntentResult, error) {
 	ctx := context.Background()
-	aiResult, err := p.aiClient.ParseIntentWithAI(ctx, query)
+	aiResult, err := p.aiClient.ParseIntentWithAI(ctx, query, locale)
 	if err != nil {
 		return nil, fmt.Errorf("OpenAI parsing error: %w", err)
 	}
 
 	result := &model.IntentResult{
-		Slots:            &model.IntentSlots{},
+		Slots:            model.NewIntentSlots(),
 		SemanticKeywords: []string{},
 		Confidence:       0.95, // High confidence for validated AI results
 	}
@@ -73,34 +202,50 @@ func (p *IntentParser) parseWithAI(query string) (*model.IntentResult, error) {
 	// Map validated AI response to IntentSlots
 	result.Slots.PriceMin = aiResult.PriceMin
 	result.Slots.PriceMax = aiResult.PriceMax
+	result.Slots.PriceTarget = aiResult.PriceTarget
 	result.Slots.Bedrooms = aiResult.Bedrooms
 	result.Slots.Bathrooms = aiResult.Bathrooms
+	result.Slots.BedroomsMin = aiResult.BedroomsMin
+	result.Slots.BathroomsMin = aiResult.BathroomsMin
 	result.Slots.AreaSqftMin = aiResult.AreaSqftMin
 	result.Slots.AreaSqftMax = aiResult.AreaSqftMax
-	result.Slots.UnitType = aiResult.UnitType
+	result.Slots.UnitType, result.Slots.UnitTypes = splitUnitTypes(aiResult.UnitType, aiResult.UnitTypes)
 	result.Slots.Location = aiResult.Location
+	result.Slots.Locations = aiResult.Locations
+	result.Slots.District = aiResult.District
 	result.Slots.MRTDistanceMax = aiResult.MRTDistanceMax
+	result.Slots.MRTStation = aiResult.MRTStation
+	result.Slots.MRTLine = aiResult.MRTLine
+	result.Slots.MRTLines = aiResult.MRTLines
 	result.Slots.BuildYearMin = aiResult.BuildYearMin
+	result.Slots.BuildYearMax = aiResult.BuildYearMax
 	result.Slots.Amenities = aiResult.Amenities
 	result.Slots.Facilities = aiResult.Facilities
+	result.Slots.LeaseRemainingMin = aiResult.LeaseRemainingMin
+	result.Slots.PricePerSqftMin = aiResult.PricePerSqftMin
+	result.Slots.PricePerSqftMax = aiResult.PricePerSqftMax
+	result.Slots.GreenScoreMin = aiResult.GreenScoreMin
+	result.Slots.ExcludeUnitTypes = aiResult.ExcludeUnitTypes
+	result.Slots.ExcludeLocations = aiResult.ExcludeLocations
+	result.Slots.ExcludeKeywords = aiResult.ExcludeKeywords
 
-	// Add AI-extracted keywords
+	// Add AI-extracted keywords along with the original query for full-text search. A query
+	// the slots fully cover ("3 bed condo") has no keywords, so the search skips ts_rank.
 	if len(aiResult.Keywords) > 0 {
 		result.SemanticKeywords = append(result.SemanticKeywords, aiResult.Keywords...)
+		result.SemanticKeywords = append(result.SemanticKeywords, query)
 	}
-
-	// Always include the original query for full-text search
-	result.SemanticKeywords = append(result.SemanticKeywords, query)
+	result.Debug = intentDebug(aiResult)
 
 	return result, nil
 }
 
 // ParseStream extracts structured information with streaming progress updates
-func (p *IntentParser) ParseStream(ctx context.Context, query string, callback func(thinking, content string) error) (*model.IntentResult, error) {
+func (p *IntentParser) ParseStream(ctx context.Context, query, locale string, callback func(thinking, content string) error) (*model.IntentResult, error) {
 	query = strings.TrimSpace(query)
 	if query == "" {
 		return &model.IntentResult{
-			Slots:            &model.IntentSlots{},
+			Slots:            model.NewIntentSlots(),
 			SemanticKeywords: []string{},
 			Confidence:       0.0,
 		}, nil
@@ -108,55 +253,55 @@ func (p *IntentParser) ParseStream(ctx context.Context, query string, callback f
 
 	// Check if AI is enabled
 	if p.aiClient == nil {
-		log.Printf("⚠️  AI client is nil, returning empty result")
-		return &model.IntentResult{
-			Slots:            &model.IntentSlots{},
-			SemanticKeywords: []string{query},
-			Confidence:       0.0,
-		}, nil
+		log.Printf("⚠️  AI client is nil, using rule-based parsing")
+		return p.fallbackResult(query, ""), nil
 	}
 
 	if !p.aiClient.IsEnabled() {
 		log.Printf("⚠️  OpenAI API is not enabled. Please check:")
 		log.Printf("   - OPENAI_API_KEY environment variable is set")
 		log.Printf("   - OPENAI_API_BASE is configured (current: %s)", p.aiClient.config.APIBase)
-		return &model.IntentResult{
-			Slots:            &model.IntentSlots{},
-			SemanticKeywords: []string{query},
-			Confidence:       0.0,
-		}, nil
+		return p.fallbackResult(query, ""), nil
+	}
+
+	// Serve repeated queries from cache without calling the AI provider
+	if cached, ok := p.cachedIntent(ctx, query, locale); ok {
+		return cached, nil
+	}
+
+	// Skip the AI call once the token budget for this window is spent
+	if p.aiClient.TokenBudgetExhausted() {
+		log.Printf("⚠️  LLM token budget exhausted, skipping AI streaming parsing")
+		return p.fallbackResult(query, model.AISkippedTokenBudget), nil
 	}
 
 	// Use AI to parse the query with streaming
-	result, err := p.parseWithAIStream(ctx, query, callback)
+	result, err := p.parseWithAIStream(ctx, query, locale, callback)
+	if err != nil && ctx.Err() != nil {
+		// The caller went away; don't fall back and keep working for nobody
+		return nil, ctx.Err()
+	}
 	if err != nil {
 		log.Printf("AI streaming parsing failed: %v", err)
-		return &model.IntentResult{
-			Slots:            &model.IntentSlots{},
-			SemanticKeywords: []string{query},
-			Confidence:       0.0,
-		}, nil
+		return p.fallbackResult(query, ""), nil
 	}
 
+	p.cacheIntent(ctx, query, locale, result)
 	return result, nil
 }
 
 // parseWithAIStream uses OpenAI streaming to parse the query
-func (p *IntentParser) parseWithAIStream(ctx context.Context, query string, callback func(thinking, content string) error) (*model.IntentResult, error) {
-	log.Printf("[DEBUG] 🚀 Starting AI stream parsing for query: %s", query)
+func (p *IntentParser) parseWithAIStream(ctx context.Context, query, locale string, callback func(thinking, content string) error) (*model.IntentResult, error) {
+	logger := utils.LoggerFrom(ctx)
+	logger.Debug("starting AI stream intent parsing", "locale", locale)
 
-	aiResult, err := p.aiClient.ParseIntentWithAIStream(ctx, query, callback)
+	aiResult, err := p.aiClient.ParseIntentWithAIStream(ctx, query, locale, callback)
 	if err != nil {
-		log.Printf("[DEBUG] ❌ AI stream parsing failed: %v", err)
 		return nil, fmt.Errorf("OpenAI streaming parsing error: %w", err)
 	}
 
-	log.Printf("[DEBUG] ✅ AI stream parsing completed successfully")
-	log.Printf("[DEBUG] 📊 AI Result - Bedrooms: %v, UnitType: %v, PriceMax: %v, Confidence: %.2f",
-		aiResult.Bedrooms, aiResult.UnitType, aiResult.PriceMax, aiResult.Confidence)
-
 	result := &model.IntentResult{
-		Slots:            &model.IntentSlots{},
+		Slots:            model.NewIntentSlots(),
 		SemanticKeywords: []string{},
 		Confidence:       0.95,
 	}
@@ -164,26 +309,42 @@ func (p *IntentParser) parseWithAIStream(ctx context.Context, query string, call
 	// Map validated AI response to IntentSlots
 	result.Slots.PriceMin = aiResult.PriceMin
 	result.Slots.PriceMax = aiResult.PriceMax
+	result.Slots.PriceTarget = aiResult.PriceTarget
 	result.Slots.Bedrooms = aiResult.Bedrooms
 	result.Slots.Bathrooms = aiResult.Bathrooms
+	result.Slots.BedroomsMin = aiResult.BedroomsMin
+	result.Slots.BathroomsMin = aiResult.BathroomsMin
 	result.Slots.AreaSqftMin = aiResult.AreaSqftMin
 	result.Slots.AreaSqftMax = aiResult.AreaSqftMax
-	result.Slots.UnitType = aiResult.UnitType
+	result.Slots.UnitType, result.Slots.UnitTypes = splitUnitTypes(aiResult.UnitType, aiResult.UnitTypes)
 	result.Slots.Location = aiResult.Location
+	result.Slots.Locations = aiResult.Locations
+	result.Slots.District = aiResult.District
 	result.Slots.MRTDistanceMax = aiResult.MRTDistanceMax
+	result.Slots.MRTStation = aiResult.MRTStation
+	result.Slots.MRTLine = aiResult.MRTLine
+	result.Slots.MRTLines = aiResult.MRTLines
 	result.Slots.BuildYearMin = aiResult.BuildYearMin
+	result.Slots.BuildYearMax = aiResult.BuildYearMax
 	result.Slots.Amenities = aiResult.Amenities
 	result.Slots.Facilities = aiResult.Facilities
+	result.Slots.LeaseRemainingMin = aiResult.LeaseRemainingMin
+	result.Slots.PricePerSqftMin = aiResult.PricePerSqftMin
+	result.Slots.PricePerSqftMax = aiResult.PricePerSqftMax
+	result.Slots.GreenScoreMin = aiResult.GreenScoreMin
+	result.Slots.ExcludeUnitTypes = aiResult.ExcludeUnitTypes
+	result.Slots.ExcludeLocations = aiResult.ExcludeLocations
+	result.Slots.ExcludeKeywords = aiResult.ExcludeKeywords
 
-	// Add AI-extracted keywords
+	// Add AI-extracted keywords along with the original query, as in parseWithAI
 	if len(aiResult.Keywords) > 0 {
 		result.SemanticKeywords = append(result.SemanticKeywords, aiResult.Keywords...)
+		result.SemanticKeywords = append(result.SemanticKeywords, query)
 	}
+	result.Debug = intentDebug(aiResult)
 
-	// Always include the original query
-	result.SemanticKeywords = append(result.SemanticKeywords, query)
-
-	log.Printf("[DEBUG] 🎯 Final intent result - Slots: %+v, Keywords: %v", result.Slots, result.SemanticKeywords)
+	logger.Debug("AI stream intent parsed",
+		"slots", result.Slots, "keywords", result.SemanticKeywords, "confidence", aiResult.Confidence)
 
 	return result, nil
 }
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"core/internal/model"
+)
+
+// IntentCache stores parsed intents keyed by normalized query
+type IntentCache interface {
+	Get(ctx context.Context, key string) (*model.IntentResult, bool)
+	Set(ctx context.Context, key string, result *model.IntentResult)
+	Stats(ctx context.Context) IntentCacheStats
+	Clear(ctx context.Context) (int, error)
+}
+
+// IntentCacheStats is a point-in-time snapshot of intent cache usage
+type IntentCacheStats struct {
+	Backend    string  `json:"backend"`
+	Size       int     `json:"size"`
+	MaxEntries int     `json:"max_entries,omitempty"`
+	TTLSeconds int     `json:"ttl_seconds"`
+	Hits       int64   `json:"hits"`
+	Misses     int64   `json:"misses"`
+	HitRate    float64 `json:"hit_rate"`
+}
+
+// intentCacheKey normalizes a query so trivially different spellings share an entry
+func intentCacheKey(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// localizedCacheKey keys a query parsed with a locale number rule separately, since "1,5M"
+// means different things to different users. Locales without a rule share the plain key.
+func localizedCacheKey(query, locale string) string {
+	if numberLocaleHint(locale) == "" {
+		return intentCacheKey(query)
+	}
+	return intentCacheKey(query) + " @" + locale
+}
+
+// hitRate returns hits / (hits + misses), or 0 before any lookups
+func hitRate(hits, misses int64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// memoryIntentEntry is one cached intent, stored as JSON so callers can't mutate the cached copy
+type memoryIntentEntry struct {
+	key     string
+	data    []byte
+	expires time.Time
+}
+
+// MemoryIntentCache is an in-process LRU intent cache with per-entry TTL
+type MemoryIntentCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	order      *list.List // Front = most recently used
+	entries    map[string]*list.Element
+	hits       int64
+	misses     int64
+}
+
+// NewMemoryIntentCache creates an in-memory intent cache
+func NewMemoryIntentCache(maxEntries int, ttl time.Duration) *MemoryIntentCache {
+	return &MemoryIntentCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the cached intent for key, if present and not expired
+func (c *MemoryIntentCache) Get(ctx context.Context, key string) (*model.IntentResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	entry := elem.Value.(*memoryIntentEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		c.misses++
+		return nil, false
+	}
+
+	var result model.IntentResult
+	if err := json.Unmarshal(entry.data, &result); err != nil {
+		c.misses++
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	c.hits++
+	return &result, true
+}
+
+// Set stores an intent, evicting the least recently used entry when full
+func (c *MemoryIntentCache) Set(ctx context.Context, key string, result *model.IntentResult) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &memoryIntentEntry{key: key, data: data, expires: time.Now().Add(c.ttl)}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryIntentEntry).key)
+	}
+}
+
+// Stats returns the cache size and hit rate
+func (c *MemoryIntentCache) Stats(ctx context.Context) IntentCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return IntentCacheStats{
+		Backend:    "memory",
+		Size:       c.order.Len(),
+		MaxEntries: c.maxEntries,
+		TTLSeconds: int(c.ttl.Seconds()),
+		Hits:       c.hits,
+		Misses:     c.misses,
+		HitRate:    hitRate(c.hits, c.misses),
+	}
+}
+
+// Clear removes all cached intents and returns how many were dropped
+func (c *MemoryIntentCache) Clear(ctx context.Context) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cleared := c.order.Len()
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+	return cleared, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"core/internal/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisScanBatch is the SCAN page size used when counting or clearing cached intents
+const redisScanBatch = 500
+
+// RedisIntentCache is an intent cache shared by every server instance through Redis.
+// Entries expire with Redis TTLs; eviction beyond that is left to the Redis maxmemory policy.
+// Redis errors are logged and treated as misses, so an outage only costs AI calls.
+type RedisIntentCache struct {
+	client *redis.Client
+	prefix string // Namespaces the keys, e.g. "intent:"
+	ttl    time.Duration
+	hits   atomic.Int64 // Hits and misses are counted per process
+	misses atomic.Int64
+}
+
+// NewRedisIntentCache creates an intent cache storing entries under prefix in Redis
+func NewRedisIntentCache(client *redis.Client, prefix string, ttl time.Duration) *RedisIntentCache {
+	return &RedisIntentCache{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+	}
+}
+
+// Get returns the cached intent for key, if present
+func (c *RedisIntentCache) Get(ctx context.Context, key string) (*model.IntentResult, bool) {
+	data, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("⚠️  Intent cache read failed: %v", err)
+		}
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	var result model.IntentResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return &result, true
+}
+
+// Set stores an intent with the cache TTL
+func (c *RedisIntentCache) Set(ctx context.Context, key string, result *model.IntentResult) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	if err := c.client.Set(ctx, c.prefix+key, data, c.ttl).Err(); err != nil {
+		log.Printf("⚠️  Intent cache write failed: %v", err)
+	}
+}
+
+// Stats returns the number of cached intents and this process's hit rate.
+// Size is -1 when Redis can't be reached.
+func (c *RedisIntentCache) Stats(ctx context.Context) IntentCacheStats {
+	size := 0
+	err := c.scanKeys(ctx, func(keys []string) error {
+		size += len(keys)
+		return nil
+	})
+	if err != nil {
+		log.Printf("⚠️  Intent cache stats failed: %v", err)
+		size = -1
+	}
+
+	hits, misses := c.hits.Load(), c.misses.Load()
+	return IntentCacheStats{
+		Backend:    "redis",
+		Size:       size,
+		TTLSeconds: int(c.ttl.Seconds()),
+		Hits:       hits,
+		Misses:     misses,
+		HitRate:    hitRate(hits, misses),
+	}
+}
+
+// Clear removes every cached intent under the prefix and returns how many were dropped
+func (c *RedisIntentCache) Clear(ctx context.Context) (int, error) {
+	cleared := 0
+	err := c.scanKeys(ctx, func(keys []string) error {
+		deleted, err := c.client.Del(ctx, keys...).Result()
+		cleared += int(deleted)
+		return err
+	})
+	if err != nil {
+		return cleared, fmt.Errorf("failed to clear intent cache: %w", err)
+	}
+	return cleared, nil
+}
+
+// scanKeys walks the cached intent keys a page at a time, without blocking Redis like KEYS would
+func (c *RedisIntentCache) scanKeys(ctx context.Context, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, c.prefix+"*", redisScanBatch).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"core/internal/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestMemoryIntentCache_HitMissAndEviction(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryIntentCache(2, time.Hour)
+
+	if _, ok := cache.Get(ctx, "a"); ok {
+		t.Fatal("Expected miss on empty cache")
+	}
+
+	cache.Set(ctx, "a", &model.IntentResult{Confidence: 0.9})
+	cache.Set(ctx, "b", &model.IntentResult{Confidence: 0.8})
+
+	got, ok := cache.Get(ctx, "a")
+	if !ok || got.Confidence != 0.9 {
+		t.Fatalf("Expected hit for a, got %+v, %v", got, ok)
+	}
+
+	// "b" is now least recently used and is evicted
+	cache.Set(ctx, "c", &model.IntentResult{Confidence: 0.7})
+	if _, ok := cache.Get(ctx, "b"); ok {
+		t.Error("Expected b to be evicted")
+	}
+
+	stats := cache.Stats(ctx)
+	if stats.Size != 2 || stats.Hits != 1 || stats.Misses != 2 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	cleared, err := cache.Clear(ctx)
+	if err != nil || cleared != 2 {
+		t.Errorf("Clear() = %d, %v; want 2, nil", cleared, err)
+	}
+	if cache.Stats(ctx).Size != 0 {
+		t.Error("Expected empty cache after Clear")
+	}
+}
+
+func TestMemoryIntentCache_Expiry(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryIntentCache(10, -time.Second)
+
+	cache.Set(ctx, "a", &model.IntentResult{})
+	if _, ok := cache.Get(ctx, "a"); ok {
+		t.Error("Expected expired entry to miss")
+	}
+}
+
+func TestIntentCacheKey(t *testing.T) {
+	if got := intentCacheKey("  3 Bedroom   Condo "); got != "3 bedroom condo" {
+		t.Errorf("intentCacheKey() = %q", got)
+	}
+}
+
+func TestIntentParser_WarmCache(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := NewIntentParser(nil, nil, SchemaMismatchUpgrade).WarmCache(ctx, "2br condo"); err != ErrIntentCacheDisabled {
+		t.Errorf("Expected ErrIntentCacheDisabled without a cache, got %v", err)
+	}
+
+	cache := NewMemoryIntentCache(10, time.Hour)
+	parser := NewIntentParser(nil, cache, SchemaMismatchUpgrade)
+	cache.Set(ctx, intentCacheKey("2br condo"), &model.IntentResult{Confidence: 0.95})
+
+	cached, err := parser.WarmCache(ctx, "  2br condo ")
+	if err != nil || !cached {
+		t.Errorf("Expected an already cached query to succeed without parsing, got %v, %v", cached, err)
+	}
+
+	// Without an AI client the query can't be parsed, and the failure is reported
+	if _, err := parser.WarmCache(ctx, "3br hdb"); err == nil {
+		t.Error("Expected an error when AI parsing is unavailable")
+	}
+	if stats := cache.Stats(ctx); stats.Size != 1 {
+		t.Errorf("Expected the failed query not to be cached, got size %d", stats.Size)
+	}
+}
+
+func TestIntentParser_CachedIntentFromOlderSchema(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryIntentCache(10, time.Hour)
+	key := intentCacheKey("condo on the circle line")
+	cache.Set(ctx, key, &model.IntentResult{Slots: &model.IntentSlots{
+		UnitType: stringPtr("Condominium"),
+		MRTLine:  stringPtr("Circle Line"),
+	}})
+
+	cached, ok := NewIntentParser(nil, cache, SchemaMismatchUpgrade).cachedIntent(ctx, "condo on the circle line", "")
+	if !ok || !cached.Slots.Current() {
+		t.Fatalf("Expected the cached intent upgraded to the current schema, got %+v, %v", cached, ok)
+	}
+	if *cached.Slots.UnitType != "Condo" || *cached.Slots.MRTLine != "CCL" {
+		t.Errorf("Expected canonical unit type and line, got %q, %q", *cached.Slots.UnitType, *cached.Slots.MRTLine)
+	}
+
+	if _, ok := NewIntentParser(nil, cache, SchemaMismatchReparse).cachedIntent(ctx, "condo on the circle line", ""); ok {
+		t.Error("Expected an older cached intent to miss when re-parsing is configured")
+	}
+
+	cache.Set(ctx, key, &model.IntentResult{Slots: model.NewIntentSlots()})
+	if _, ok := NewIntentParser(nil, cache, SchemaMismatchReparse).cachedIntent(ctx, "condo on the circle line", ""); !ok {
+		t.Error("Expected a current cached intent to hit")
+	}
+}
+
+func TestRedisIntentCache_UnreachableIsMiss(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	defer client.Close()
+	cache := NewRedisIntentCache(client, "intent:", time.Hour)
+
+	cache.Set(ctx, "a", &model.IntentResult{Confidence: 0.9})
+	if _, ok := cache.Get(ctx, "a"); ok {
+		t.Fatal("Expected a miss when Redis is down")
+	}
+	if stats := cache.Stats(ctx); stats.Backend != "redis" || stats.Size != -1 || stats.Misses != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if _, err := cache.Clear(ctx); err == nil {
+		t.Error("Expected Clear to report the connection error")
+	}
+}
+
+// TestRedisIntentCache_RoundTrip needs a Redis server: TEST_REDIS_URL=redis://localhost:6379/15 go test -run RedisIntentCache ./internal/service
+// Only keys under a test-specific prefix are written and cleared.
+func TestRedisIntentCache_RoundTrip(t *testing.T) {
+	url := os.Getenv("TEST_REDIS_URL")
+	if url == "" {
+		t.Skip("TEST_REDIS_URL not set")
+	}
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		t.Fatalf("Invalid TEST_REDIS_URL: %v", err)
+	}
+	ctx := context.Background()
+	client := redis.NewClient(opts)
+	defer client.Close()
+	cache := NewRedisIntentCache(client, "intent-test:", time.Minute)
+	defer cache.Clear(ctx)
+
+	cache.Set(ctx, "3 bedroom condo", &model.IntentResult{Confidence: 0.9, SemanticKeywords: []string{"condo"}})
+	got, ok := cache.Get(ctx, "3 bedroom condo")
+	if !ok || got.Confidence != 0.9 || len(got.SemanticKeywords) != 1 {
+		t.Fatalf("Expected a hit, got %+v, %v", got, ok)
+	}
+	if ttl := client.TTL(ctx, "intent-test:3 bedroom condo").Val(); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("Expected the entry to expire within the TTL, got %v", ttl)
+	}
+	if stats := cache.Stats(ctx); stats.Size != 1 || stats.Hits != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if cleared, err := cache.Clear(ctx); err != nil || cleared != 1 {
+		t.Errorf("Clear() = %d, %v; want 1, nil", cleared, err)
+	}
+}
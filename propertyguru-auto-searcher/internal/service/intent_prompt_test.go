@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"core/internal/config"
+)
+
+func TestShippedIntentPromptMatchesDefault(t *testing.T) {
+	prompt, err := LoadIntentPrompt("../../config/prompts/intent_prompt.txt")
+	if err != nil {
+		t.Fatalf("Expected shipped prompt to load, got %v", err)
+	}
+	if prompt != defaultIntentPrompt {
+		t.Error("config/prompts/intent_prompt.txt has drifted from defaultIntentPrompt")
+	}
+}
+
+func TestLoadIntentPrompt(t *testing.T) {
+	dir := t.TempDir()
+
+	prompt, err := LoadIntentPrompt(filepath.Join(dir, "missing.txt"))
+	if err != nil || prompt != defaultIntentPrompt {
+		t.Errorf("Expected a missing file to fall back to the built-in prompt, got err=%v", err)
+	}
+
+	empty := filepath.Join(dir, "empty.txt")
+	if err := os.WriteFile(empty, []byte("  \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadIntentPrompt(empty); err == nil {
+		t.Error("Expected an error for an empty prompt file")
+	}
+
+	custom := filepath.Join(dir, "custom.txt")
+	if err := os.WriteFile(custom, []byte("Parse Singapore property searches.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prompt, err = LoadIntentPrompt(custom)
+	if err != nil || prompt != "Parse Singapore property searches." {
+		t.Errorf("LoadIntentPrompt() = %q, %v", prompt, err)
+	}
+}
+
+func TestIntentPromptSharedByStreamingAndNonStreaming(t *testing.T) {
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompts = append(prompts, req.Messages[0].Content)
+
+		content := `{"bedrooms": 2}`
+		if req.Stream {
+			data, _ := json.Marshal(map[string]any{
+				"choices": []map[string]any{{"delta": map[string]string{"content": content}, "finish_reason": "stop"}},
+			})
+			w.Write([]byte("data: " + string(data) + "\n\ndata: [DONE]\n\n"))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": content}}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(&config.OpenAIConfig{Enabled: true, APIKey: "test", APIBase: server.URL, Timeout: 5})
+	client.SetIntentPrompt("Custom intent instructions.")
+
+	if _, err := client.ParseIntentWithAI(context.Background(), "2 bed", ""); err != nil {
+		t.Fatalf("ParseIntentWithAI failed: %v", err)
+	}
+	if _, err := client.ParseIntentWithAIStream(context.Background(), "2 bed", "", func(thinking, content string) error { return nil }); err != nil {
+		t.Fatalf("ParseIntentWithAIStream failed: %v", err)
+	}
+
+	if len(prompts) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(prompts))
+	}
+	if prompts[0] != prompts[1] {
+		t.Error("Expected streaming and non-streaming parsing to send the same system prompt")
+	}
+	if !strings.HasPrefix(prompts[0], "Custom intent instructions.") || !strings.Contains(prompts[0], "Examples:") {
+		t.Errorf("Expected the override followed by examples, got %q", prompts[0])
+	}
+}
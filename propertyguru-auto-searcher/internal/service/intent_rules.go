@@ -0,0 +1,147 @@
+package service
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"core/internal/model"
+	"core/internal/utils"
+)
+
+// Patterns for the rule-based fallback parser. They only catch the unambiguous phrasings;
+// anything subtler is left to full-text search on the query.
+var (
+	// "3 bedroom", "3-bed", "3br", "3 bdr"
+	ruleBedroomsRegexp = regexp.MustCompile(`(?i)\b(\d{1,2})\s*-?\s*(?:bedrooms?|beds?|br|bdr|bhk)\b`)
+
+	// "at least 3 bedrooms", "min 3 beds", "3+ bedrooms", "3 or more bedrooms"
+	ruleBedroomsMinRegexp = regexp.MustCompile(`(?i)(?:\b(?:at least|min(?:imum)?)\s+(\d{1,2})|\b(\d{1,2})\s*(?:\+|or more))\s*-?\s*(?:bedrooms?|beds?|br|bdr|bhk)\b`)
+
+	// "under 1.5M", "below $800k", "max S$2,000,000"; psf budgets and non-price units are excluded below
+	rulePriceMaxRegexp = regexp.MustCompile(`(?i)\b(?:under|below|less than|max(?:imum)?|up to|within|budget(?: of)?)\s*(s?\$)?\s*(\d[\d,]*(?:\.\d+)?)\s*(k|m|mil|million)?\b(\s*(?:psf|sqft|sq ?ft|square feet|min(?:ute)?s?|m(?:eters?)?\b|years?))?`)
+
+	// "near MRT", "close to the MRT", "walking distance to MRT"
+	ruleNearMRTRegexp = regexp.MustCompile(`(?i)\b(?:near|close to|next to|walking distance (?:to|from))\s+(?:the\s+|an?\s+)?mrt\b`)
+
+	// "D9", "D09", "district 10"
+	ruleDistrictRegexp = regexp.MustCompile(`(?i)\b(?:d|district\s*)(0?[1-9]|1\d|2[0-8])\b`)
+
+	// A unit type right after "no", "not", "except", "without", "anything but", or "non-" is
+	// excluded rather than wanted: "no HDB", "anything except a condo", "non-landed"
+	ruleNegationRegexp = regexp.MustCompile(`(?i)\b(?:no|not|except|excluding|without|anything but|non)(?:\s+an?)?[\s-]*$`)
+
+	// Checked in order, each match blanked out before the next pattern, so "executive condo"
+	// becomes EC rather than Executive and Condo
+	ruleUnitTypes = []struct {
+		pattern  *regexp.Regexp
+		unitType string
+	}{
+		{regexp.MustCompile(`(?i)\b(?:executive condo(?:minium)?s?|ecs?)\b`), "EC"},
+		{regexp.MustCompile(`(?i)\bhdbs?\b`), "HDB"},
+		{regexp.MustCompile(`(?i)\bcondo(?:minium)?s?\b`), "Condo"},
+		{regexp.MustCompile(`(?i)\blanded\b`), "Landed"},
+		{regexp.MustCompile(`(?i)\bexecutive\b`), "Executive"},
+	}
+)
+
+// ruleMinPrice is the smallest bare number read as a price; "under 15" is more likely minutes than dollars
+const ruleMinPrice = 10000
+
+// ruleMaxMillions bounds amounts written in millions; larger "m" amounts are metres
+const ruleMaxMillions = 100
+
+// ruleNearMRTMinutes matches the AI prompt's default walking time for "near MRT"
+const ruleNearMRTMinutes = 15
+
+// parseIntentRules extracts the obvious slots from a query with regular expressions.
+// It is the degraded path when AI parsing is unavailable, so it prefers leaving a slot
+// empty over guessing.
+func parseIntentRules(query string) *model.IntentSlots {
+	slots := model.NewIntentSlots()
+
+	if m := ruleBedroomsMinRegexp.FindStringSubmatch(query); m != nil {
+		if bedrooms, err := strconv.Atoi(m[1] + m[2]); err == nil && bedrooms > 0 && bedrooms <= 10 {
+			slots.BedroomsMin = &bedrooms
+		}
+	} else if m := ruleBedroomsRegexp.FindStringSubmatch(query); m != nil {
+		if bedrooms, err := strconv.Atoi(m[1]); err == nil && bedrooms > 0 && bedrooms <= 10 {
+			slots.Bedrooms = &bedrooms
+		}
+	}
+
+	for _, m := range rulePriceMaxRegexp.FindAllStringSubmatch(query, -1) {
+		if price, ok := rulePrice(m[1], m[2], m[3], m[4]); ok {
+			slots.PriceMax = &price
+			break
+		}
+	}
+
+	// "condo or EC" names several types; any of them matches. Negated ones are excluded.
+	var unitTypes, excludedUnitTypes []string
+	remaining := query
+	for _, rule := range ruleUnitTypes {
+		wanted, excluded := false, false
+		for _, loc := range rule.pattern.FindAllStringIndex(remaining, -1) {
+			if ruleNegationRegexp.MatchString(remaining[:loc[0]]) {
+				excluded = true
+			} else {
+				wanted = true
+			}
+		}
+		if wanted {
+			unitTypes = append(unitTypes, rule.unitType)
+		} else if excluded {
+			excludedUnitTypes = append(excludedUnitTypes, rule.unitType)
+		}
+		remaining = rule.pattern.ReplaceAllString(remaining, " ")
+	}
+	slots.ExcludeUnitTypes = excludedUnitTypes
+	switch len(unitTypes) {
+	case 0:
+	case 1:
+		slots.UnitType = &unitTypes[0]
+	default:
+		slots.UnitTypes = unitTypes
+	}
+
+	if m := ruleDistrictRegexp.FindStringSubmatch(query); m != nil {
+		if code := utils.CanonicalDistrict(m[1]); code != "" {
+			slots.District = &code
+		}
+	}
+
+	if ruleNearMRTRegexp.MatchString(query) {
+		minutes := ruleNearMRTMinutes
+		slots.MRTDistanceMax = &minutes
+	}
+
+	return slots
+}
+
+// rulePrice converts a matched amount to SGD. It rejects amounts followed by another unit
+// (psf, sqft, minutes, metres, years) and bare numbers too small to be a property price.
+func rulePrice(currency, amount, suffix, unit string) (float64, bool) {
+	if strings.TrimSpace(unit) != "" {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(strings.ReplaceAll(amount, ",", ""), 64)
+	if err != nil || value <= 0 {
+		return 0, false
+	}
+
+	switch strings.ToLower(suffix) {
+	case "k":
+		value *= 1_000
+	case "m", "mil", "million":
+		if value >= ruleMaxMillions {
+			return 0, false // "under 500m" is a distance, not half a billion dollars
+		}
+		value *= 1_000_000
+	default:
+		if currency == "" && value < ruleMinPrice {
+			return 0, false
+		}
+	}
+	return value, true
+}
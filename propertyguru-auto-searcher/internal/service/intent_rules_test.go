@@ -0,0 +1,218 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIntentRules_Bedrooms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  *int
+	}{
+		{"3 bedroom condo", intPtr(3)},
+		{"3br in Bishan", intPtr(3)},
+		{"2-bed apartment", intPtr(2)},
+		{"4 Bedrooms landed", intPtr(4)},
+		{"1 bdr studio", intPtr(1)},
+		{"condo with 2 bathrooms", nil},
+		{"0 bedroom", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := parseIntentRules(tt.query).Bedrooms
+			if !equalIntPtr(got, tt.want) {
+				t.Errorf("Bedrooms = %v, want %v", derefInt(got), derefInt(tt.want))
+			}
+		})
+	}
+}
+
+func TestParseIntentRules_BedroomsMin(t *testing.T) {
+	tests := []struct {
+		query     string
+		wantMin   *int
+		wantExact *int
+	}{
+		{"at least 3 bedrooms", intPtr(3), nil},
+		{"3+ bedroom condo", intPtr(3), nil},
+		{"4 or more beds in Bishan", intPtr(4), nil},
+		{"minimum 2 br", intPtr(2), nil},
+		{"3 bedroom condo", nil, intPtr(3)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			slots := parseIntentRules(tt.query)
+			if !equalIntPtr(slots.BedroomsMin, tt.wantMin) || !equalIntPtr(slots.Bedrooms, tt.wantExact) {
+				t.Errorf("BedroomsMin = %v, Bedrooms = %v, want %v, %v",
+					derefInt(slots.BedroomsMin), derefInt(slots.Bedrooms), derefInt(tt.wantMin), derefInt(tt.wantExact))
+			}
+		})
+	}
+}
+
+func TestParseIntentRules_PriceMax(t *testing.T) {
+	tests := []struct {
+		query string
+		want  *float64
+	}{
+		{"condo under 1.5M", float64Ptr(1500000)},
+		{"HDB below $800k", float64Ptr(800000)},
+		{"less than S$1,200,000", float64Ptr(1200000)},
+		{"budget 2 million", float64Ptr(2000000)},
+		{"under 950000", float64Ptr(950000)},
+		{"under $1500 psf", nil},
+		{"under 15 min to MRT", nil},
+		{"under 500m from MRT", nil},
+		{"below 1200 sqft", nil},
+		{"under 99", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := parseIntentRules(tt.query).PriceMax
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("PriceMax = %v, want %v", derefFloat(got), derefFloat(tt.want))
+			}
+		})
+	}
+}
+
+func TestParseIntentRules_UnitType(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"hdb in Tampines", "HDB"},
+		{"Condo with pool", "Condo"},
+		{"condominium near Orchard", "Condo"},
+		{"landed house", "Landed"},
+		{"Executive flat in Woodlands", "Executive"},
+		{"executive condo in Punggol", "EC"},
+		{"EC with 3 bedrooms", "EC"},
+		{"apartment with balcony", ""},
+		{"second-hand recondo", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := ""
+			if unitType := parseIntentRules(tt.query).UnitType; unitType != nil {
+				got = *unitType
+			}
+			if got != tt.want {
+				t.Errorf("UnitType = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIntentRules_UnitTypes(t *testing.T) {
+	slots := parseIntentRules("condo or EC under 1.5M")
+	if slots.UnitType != nil || !reflect.DeepEqual(slots.UnitTypes, []string{"EC", "Condo"}) {
+		t.Errorf("UnitType = %v, UnitTypes = %v, want EC and Condo", slots.UnitType, slots.UnitTypes)
+	}
+
+	// "executive condo" is one type, not EC plus Executive plus Condo
+	slots = parseIntentRules("executive condo in Punggol")
+	if len(slots.UnitTypes) != 0 || slots.UnitType == nil || *slots.UnitType != "EC" {
+		t.Errorf("UnitType = %v, UnitTypes = %v, want EC only", slots.UnitType, slots.UnitTypes)
+	}
+}
+
+func TestParseIntentRules_ExcludedUnitTypes(t *testing.T) {
+	tests := []struct {
+		query    string
+		want     []string
+		excluded []string
+	}{
+		{"anything except HDB in Bishan", nil, []string{"HDB"}},
+		{"condo, no HDB", []string{"Condo"}, []string{"HDB"}},
+		{"non-landed under 2M", nil, []string{"Landed"}},
+		{"not a condo or EC", []string{"EC"}, []string{"Condo"}},
+		{"HDB near MRT", []string{"HDB"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			slots := parseIntentRules(tt.query)
+			got := slots.UnitTypes
+			if slots.UnitType != nil {
+				got = []string{*slots.UnitType}
+			}
+			if !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(slots.ExcludeUnitTypes, tt.excluded) {
+				t.Errorf("unit types = %v excluded = %v, want %v and %v", got, slots.ExcludeUnitTypes, tt.want, tt.excluded)
+			}
+		})
+	}
+}
+
+func TestParseIntentRules_District(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"2 bedroom condo in D9 under 2M", "D09"},
+		{"landed in district 10", "D10"},
+		{"D15 apartment", "D15"},
+		{"D30 condo", ""},
+		{"3d tour available", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := ""
+			if district := parseIntentRules(tt.query).District; district != nil {
+				got = *district
+			}
+			if got != tt.want {
+				t.Errorf("District = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIntentRules_NearMRT(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"HDB near MRT", true},
+		{"close to the MRT", true},
+		{"walking distance to MRT", true},
+		{"MRT line extension", false},
+		{"condo near Orchard", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := parseIntentRules(tt.query).MRTDistanceMax
+			if (got != nil) != tt.want {
+				t.Errorf("MRTDistanceMax = %v, want set=%v", derefInt(got), tt.want)
+			}
+			if got != nil && *got != ruleNearMRTMinutes {
+				t.Errorf("MRTDistanceMax = %d, want %d", *got, ruleNearMRTMinutes)
+			}
+		})
+	}
+}
+
+func equalIntPtr(a, b *int) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func derefInt(v *int) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func derefFloat(v *float64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
@@ -1,16 +1,19 @@
 package service
 
 import (
+	"context"
 	"testing"
+
+	"core/internal/model"
 )
 
-// NOTE: These tests are for the old regex-based parser which has been replaced by AI.
-// The tests have been updated to work with the new AI-based parser (without OpenAI client).
-// For full integration testing with AI, set up a test with actual OpenAI API key.
+// NOTE: Without an OpenAI client the parser falls back to the rule-based parser
+// (see intent_rules_test.go). For full integration testing with AI, set up a test
+// with actual OpenAI API key.
 
 func TestIntentParser_WithoutAI(t *testing.T) {
-	// Create parser without AI client (will return empty results)
-	parser := NewIntentParser(nil)
+	// Create parser without AI client (falls back to rule-based parsing)
+	parser := NewIntentParser(nil, nil, SchemaMismatchUpgrade)
 
 	tests := []struct {
 		name  string
@@ -32,9 +35,8 @@ func TestIntentParser_WithoutAI(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := parser.Parse(tt.query)
+			result := parser.Parse(tt.query, "")
 
-			// Without AI client, should return empty slots
 			if result.Slots == nil {
 				t.Fatal("Expected slots to be non-nil")
 			}
@@ -52,11 +54,39 @@ func TestIntentParser_WithoutAI(t *testing.T) {
 	}
 }
 
+func TestIntentParser_RuleFallbackWithoutAI(t *testing.T) {
+	parser := NewIntentParser(nil, nil, SchemaMismatchUpgrade)
+	query := "3 bedroom HDB near MRT, below $1M"
+
+	streamed, err := parser.ParseStream(context.Background(), query, "", func(thinking, content string) error { return nil })
+	if err != nil {
+		t.Fatalf("ParseStream failed: %v", err)
+	}
+
+	for name, slots := range map[string]*model.IntentSlots{
+		"Parse":       parser.Parse(query, "").Slots,
+		"ParseStream": streamed.Slots,
+	} {
+		if slots.Bedrooms == nil || *slots.Bedrooms != 3 {
+			t.Errorf("%s: expected 3 bedrooms, got %v", name, derefInt(slots.Bedrooms))
+		}
+		if slots.UnitType == nil || *slots.UnitType != "HDB" {
+			t.Errorf("%s: expected unit type HDB, got %v", name, slots.UnitType)
+		}
+		if slots.PriceMax == nil || *slots.PriceMax != 1000000 {
+			t.Errorf("%s: expected price max 1000000, got %v", name, derefFloat(slots.PriceMax))
+		}
+		if slots.MRTDistanceMax == nil {
+			t.Errorf("%s: expected an MRT distance for \"near MRT\"", name)
+		}
+	}
+}
+
 // TestIntentParser_BasicStructure verifies the basic structure is correct
 func TestIntentParser_BasicStructure(t *testing.T) {
-	parser := NewIntentParser(nil)
+	parser := NewIntentParser(nil, nil, SchemaMismatchUpgrade)
 
-	result := parser.Parse("test query")
+	result := parser.Parse("test query", "")
 
 	if result == nil {
 		t.Fatal("Expected result to be non-nil")
@@ -84,3 +114,7 @@ func float64Ptr(v float64) *float64 {
 func intPtr(v int) *int {
 	return &v
 }
+
+func stringPtr(v string) *string {
+	return &v
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"sync"
+)
+
+// PhaseLatency summarizes one search phase across recorded searches
+type PhaseLatency struct {
+	AvgMs  float64 `json:"avg_ms"`
+	MaxMs  int64   `json:"max_ms"`
+	LastMs int64   `json:"last_ms"`
+}
+
+// SearchLatencyStats breaks recorded search latency down into intent parsing (LLM)
+// and database search + ranking
+type SearchLatencyStats struct {
+	Searches int64        `json:"searches"`
+	Intent   PhaseLatency `json:"intent"`
+	Search   PhaseLatency `json:"search"`
+}
+
+// phaseTotals accumulates one phase's timings
+type phaseTotals struct {
+	sum  int64
+	max  int64
+	last int64
+}
+
+func (p *phaseTotals) add(ms int64) {
+	p.sum += ms
+	p.last = ms
+	if ms > p.max {
+		p.max = ms
+	}
+}
+
+func (p *phaseTotals) summary(count int64) PhaseLatency {
+	latency := PhaseLatency{MaxMs: p.max, LastMs: p.last}
+	if count > 0 {
+		latency.AvgMs = float64(p.sum) / float64(count)
+	}
+	return latency
+}
+
+// latencyRecorder tracks per-phase search latency for the metrics endpoint
+type latencyRecorder struct {
+	mu     sync.Mutex
+	count  int64
+	intent phaseTotals
+	search phaseTotals
+}
+
+// record adds one search's phase timings
+func (r *latencyRecorder) record(intentMs, searchMs int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count++
+	r.intent.add(intentMs)
+	r.search.add(searchMs)
+}
+
+// stats returns a snapshot of the recorded timings
+func (r *latencyRecorder) stats() SearchLatencyStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return SearchLatencyStats{
+		Searches: r.count,
+		Intent:   r.intent.summary(r.count),
+		Search:   r.search.summary(r.count),
+	}
+}
@@ -0,0 +1,24 @@
+package service
+
+import "testing"
+
+func TestLatencyRecorder(t *testing.T) {
+	var r latencyRecorder
+	if stats := r.stats(); stats.Searches != 0 || stats.Intent.AvgMs != 0 {
+		t.Errorf("Expected empty stats, got %+v", stats)
+	}
+
+	r.record(900, 40)
+	r.record(100, 60)
+
+	stats := r.stats()
+	if stats.Searches != 2 {
+		t.Errorf("Expected 2 searches, got %d", stats.Searches)
+	}
+	if stats.Intent.AvgMs != 500 || stats.Intent.MaxMs != 900 || stats.Intent.LastMs != 100 {
+		t.Errorf("Unexpected intent latency: %+v", stats.Intent)
+	}
+	if stats.Search.AvgMs != 50 || stats.Search.MaxMs != 60 || stats.Search.LastMs != 60 {
+		t.Errorf("Unexpected search latency: %+v", stats.Search)
+	}
+}
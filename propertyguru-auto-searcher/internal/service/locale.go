@@ -0,0 +1,38 @@
+package service
+
+import (
+	"fmt"
+
+	"core/internal/utils"
+)
+
+// decimalCommaLanguages write "1,5M" for 1.5 million and group thousands with "." or a space
+var decimalCommaLanguages = map[string]bool{
+	"de": true, "fr": true, "es": true, "it": true, "nl": true, "pt": true, "ru": true,
+	"pl": true, "cs": true, "sv": true, "da": true, "nb": true, "fi": true, "tr": true,
+	"id": true, "vi": true,
+}
+
+// numberLocaleHint returns a prompt rule telling the LLM how the user's locale writes
+// numbers, or "" for locales that write them the Singapore way ("1,500,000", "1.5M")
+func numberLocaleHint(locale string) string {
+	if locale == "" {
+		return ""
+	}
+	language, region := utils.LocaleLanguage(locale), utils.LocaleRegion(locale)
+	switch {
+	case region == "IN" || (language == "hi" && region == ""):
+		return fmt.Sprintf(`- The user's locale is %s: "lakh" = 100000 and "crore" = 10000000, and digits may be grouped in twos ("12,50,000" = 1250000, "1.2 crore" = 12000000)`, locale)
+	case decimalCommaLanguages[language] && region != "SG":
+		return fmt.Sprintf(`- The user's locale is %s: "," is the decimal separator and "." or a space groups thousands ("1,5M" = 1500000, "1.200.000" = 1200000, "850 000" = 850000)`, locale)
+	}
+	return ""
+}
+
+// localizedPromptRules appends the locale's number rule, if any, to the prompt's rules
+func localizedPromptRules(locale string) string {
+	if hint := numberLocaleHint(locale); hint != "" {
+		return "\n" + hint + "\n- Prices are still in SGD unless the user names another currency"
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNumberLocaleHint(t *testing.T) {
+	if hint := numberLocaleHint("de-DE"); !strings.Contains(hint, `"1,5M" = 1500000`) {
+		t.Errorf("Expected a decimal comma rule for de-DE, got %q", hint)
+	}
+	if hint := numberLocaleHint("en-IN"); !strings.Contains(hint, "lakh") {
+		t.Errorf("Expected a lakh/crore rule for en-IN, got %q", hint)
+	}
+	for _, locale := range []string{"", "en-SG", "zh-Hans-SG", "ta-SG", "en-US"} {
+		if hint := numberLocaleHint(locale); hint != "" {
+			t.Errorf("Expected the default number format for %q, got %q", locale, hint)
+		}
+	}
+
+	if localizedCacheKey("1,5M condo", "fr-FR") == localizedCacheKey("1,5M condo", "") {
+		t.Error("Expected a locale with a number rule to get its own cache key")
+	}
+	if localizedCacheKey("2br condo", "en-SG") != intentCacheKey("2br condo") {
+		t.Error("Expected locales without a number rule to share the plain cache key")
+	}
+}
+
+func TestValidateIntentResponseDropsImplausibleValues(t *testing.T) {
+	resp := &AIIntentResponse{
+		PriceMin:    float64Ptr(15),
+		PriceMax:    float64Ptr(1500000),
+		AreaSqftMin: float64Ptr(1e6),
+	}
+	if err := validateIntentResponse(resp); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.PriceMin != nil || resp.AreaSqftMin != nil {
+		t.Errorf("Expected implausible values dropped, got price_min %v, area_sqft_min %v", resp.PriceMin, resp.AreaSqftMin)
+	}
+	if resp.PriceMax == nil || *resp.PriceMax != 1500000 {
+		t.Errorf("Expected price_max kept, got %v", resp.PriceMax)
+	}
+}
+
+func TestValidateIntentResponseBuildYears(t *testing.T) {
+	if err := validateIntentResponse(&AIIntentResponse{BuildYearMin: intPtr(2016), BuildYearMax: intPtr(2020)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := validateIntentResponse(&AIIntentResponse{BuildYearMax: intPtr(1850)}); err == nil {
+		t.Error("Expected an error for build_year_max before 1900")
+	}
+	if err := validateIntentResponse(&AIIntentResponse{BuildYearMin: intPtr(2020), BuildYearMax: intPtr(2000)}); err == nil {
+		t.Error("Expected an error when build_year_min exceeds build_year_max")
+	}
+}
+
+func TestValidateIntentResponseUnitTypes(t *testing.T) {
+	resp := &AIIntentResponse{UnitTypes: []string{"condominium", "Executive Condo"}}
+	if err := validateIntentResponse(resp); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.UnitTypes[0] != "Condo" || resp.UnitTypes[1] != "EC" {
+		t.Errorf("Expected canonical unit types, got %v", resp.UnitTypes)
+	}
+	if err := validateIntentResponse(&AIIntentResponse{UnitTypes: []string{"Condo", "Castle"}}); err == nil {
+		t.Error("Expected an error for an unknown unit type")
+	}
+}
+
+func TestAIIntentResponseUnitTypeStringOrList(t *testing.T) {
+	for _, tt := range []struct {
+		content   string
+		unitType  string
+		unitTypes []string
+	}{
+		{`{"unit_type": "condominium"}`, "Condo", nil},
+		{`{"unit_type": ["condominium", "Executive Condo"], "unit_types": ["HDB"]}`, "Condo", []string{"EC", "HDB"}},
+	} {
+		var resp AIIntentResponse
+		if err := json.Unmarshal([]byte(tt.content), &resp); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.content, err)
+		}
+		if err := validateIntentResponse(&resp); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.content, err)
+		}
+		unitType, unitTypes := splitUnitTypes(resp.UnitType, resp.UnitTypes)
+		if unitType == nil || *unitType != tt.unitType || !reflect.DeepEqual(unitTypes, tt.unitTypes) {
+			t.Errorf("%s: expected %s and %v, got %v and %v", tt.content, tt.unitType, tt.unitTypes, unitType, unitTypes)
+		}
+	}
+}
+
+func TestValidateIntentResponseExclusions(t *testing.T) {
+	resp := &AIIntentResponse{ExcludeUnitTypes: []string{"hdb flat"}, ExcludeLocations: []string{"Tg Pagar"}}
+	if err := validateIntentResponse(resp); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.ExcludeUnitTypes[0] != "HDB" || resp.ExcludeLocations[0] != "Tanjong Pagar" {
+		t.Errorf("Expected canonical exclusions, got %v and %v", resp.ExcludeUnitTypes, resp.ExcludeLocations)
+	}
+	if err := validateIntentResponse(&AIIntentResponse{ExcludeUnitTypes: []string{"Castle"}}); err == nil {
+		t.Error("Expected an error for an unknown excluded unit type")
+	}
+}
+
+func TestValidateIntentResponseMRTLines(t *testing.T) {
+	resp := &AIIntentResponse{MRTLines: []string{"North East Line", "Hogwarts Line", "circle line"}}
+	if err := validateIntentResponse(resp); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.MRTLines) != 2 || resp.MRTLines[0] != "NEL" || resp.MRTLines[1] != "CCL" {
+		t.Errorf("Expected canonical codes with the unknown line dropped, got %v", resp.MRTLines)
+	}
+}
+
+func TestValidateIntentResponseDistrict(t *testing.T) {
+	resp := &AIIntentResponse{District: stringPtr("district 9")}
+	if err := validateIntentResponse(resp); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.District == nil || *resp.District != "D09" {
+		t.Errorf("Expected the canonical district code, got %v", resp.District)
+	}
+
+	resp = &AIIntentResponse{District: stringPtr("D42")}
+	if err := validateIntentResponse(resp); err != nil || resp.District != nil {
+		t.Errorf("Expected an unknown district to be dropped, got %v and %v", resp.District, err)
+	}
+}
+
+func TestValidateIntentResponseGreenScore(t *testing.T) {
+	if err := validateIntentResponse(&AIIntentResponse{GreenScoreMin: float64Ptr(4)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := validateIntentResponse(&AIIntentResponse{GreenScoreMin: float64Ptr(80)}); err == nil {
+		t.Error("Expected an error for a green_score_min above 5")
+	}
+}
+
+func TestValidateIntentResponsePricePerSqft(t *testing.T) {
+	resp := &AIIntentResponse{PricePerSqftMin: float64Ptr(0.5), PricePerSqftMax: float64Ptr(1500)}
+	if err := validateIntentResponse(resp); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.PricePerSqftMin != nil || resp.PricePerSqftMax == nil || *resp.PricePerSqftMax != 1500 {
+		t.Errorf("Expected only the implausible psf dropped, got min %v, max %v", resp.PricePerSqftMin, resp.PricePerSqftMax)
+	}
+
+	inverted := &AIIntentResponse{PricePerSqftMin: float64Ptr(2000), PricePerSqftMax: float64Ptr(1500)}
+	if err := validateIntentResponse(inverted); err == nil {
+		t.Error("Expected an error when price_per_sqft_min exceeds price_per_sqft_max")
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"math"
+
+	"core/internal/model"
+	"core/internal/utils"
+)
+
+// mortgageTerms resolves the requested terms against the configured defaults
+func (s *SearchService) mortgageTerms(options *model.MortgageOptions) model.MortgageTerms {
+	terms := model.MortgageTerms{
+		InterestRate:       s.config.MortgageInterestRate,
+		TenureYears:        s.config.MortgageTenureYears,
+		DownPaymentPercent: s.config.MortgageDownPaymentPercent,
+	}
+	if options.InterestRate != nil {
+		terms.InterestRate = *options.InterestRate
+	}
+	if options.TenureYears != nil {
+		terms.TenureYears = *options.TenureYears
+	}
+	if options.DownPaymentPercent != nil {
+		terms.DownPaymentPercent = *options.DownPaymentPercent
+	}
+	return terms
+}
+
+// attachMortgages fills monthly_mortgage on every priced result (including broader matches)
+// when the request asked for it, and reports the terms used
+func (s *SearchService) attachMortgages(response *model.SearchResponse, options *model.MortgageOptions) {
+	if options == nil {
+		return
+	}
+	terms := s.mortgageTerms(options)
+	response.Mortgage = &terms
+
+	for i := range response.Results {
+		response.Results[i].MonthlyMortgage = monthlyMortgage(response.Results[i].Price, terms)
+	}
+	if response.BroaderMatches != nil {
+		for i := range response.BroaderMatches.Results {
+			result := &response.BroaderMatches.Results[i]
+			result.MonthlyMortgage = monthlyMortgage(result.Price, terms)
+		}
+	}
+}
+
+// monthlyMortgage estimates the repayment on price less the down payment, rounded to cents.
+// Unpriced listings get no estimate.
+func monthlyMortgage(price *float64, terms model.MortgageTerms) *float64 {
+	if price == nil || *price <= 0 {
+		return nil
+	}
+	principal := *price * (1 - terms.DownPaymentPercent/100)
+	payment := math.Round(utils.MonthlyMortgage(principal, terms.InterestRate, terms.TenureYears)*100) / 100
+	return &payment
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"testing"
+
+	"core/internal/config"
+	"core/internal/model"
+)
+
+func TestAttachMortgages(t *testing.T) {
+	s := &SearchService{config: &config.SearchConfig{
+		MortgageInterestRate:       3.5,
+		MortgageTenureYears:        25,
+		MortgageDownPaymentPercent: 25,
+	}}
+	response := &model.SearchResponse{
+		Results: []model.ListingSearchResult{
+			{Listing: model.Listing{ListingID: 1, Price: float64Ptr(1000000)}},
+			{Listing: model.Listing{ListingID: 2}},
+		},
+		BroaderMatches: &model.BroaderMatches{Results: []model.ListingSearchResult{
+			{Listing: model.Listing{ListingID: 3, Price: float64Ptr(800000)}},
+		}},
+	}
+
+	s.attachMortgages(response, nil)
+	if response.Mortgage != nil || response.Results[0].MonthlyMortgage != nil {
+		t.Fatal("Expected no estimate unless options.mortgage is set")
+	}
+
+	s.attachMortgages(response, &model.MortgageOptions{InterestRate: float64Ptr(0)})
+	if response.Mortgage == nil || response.Mortgage.InterestRate != 0 || response.Mortgage.TenureYears != 25 {
+		t.Fatalf("Expected the override merged with defaults, got %+v", response.Mortgage)
+	}
+	// 750,000 borrowed interest-free over 300 months
+	if got := response.Results[0].MonthlyMortgage; got == nil || *got != 2500 {
+		t.Errorf("Expected 2500/month, got %v", got)
+	}
+	if response.Results[1].MonthlyMortgage != nil {
+		t.Errorf("Expected no estimate for an unpriced listing, got %v", *response.Results[1].MonthlyMortgage)
+	}
+	if got := response.BroaderMatches.Results[0].MonthlyMortgage; got == nil || *got != 2000 {
+		t.Errorf("Expected broader matches estimated too, got %v", got)
+	}
+}
@@ -5,52 +5,132 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"core/internal/config"
 	"core/internal/utils"
 )
 
+// errStreamCallback marks stream errors raised by the caller's callback rather than the provider
+var errStreamCallback = errors.New("callback error")
+
 // StreamChunkParser is the interface for provider-specific chunk parsing
 type StreamChunkParser interface {
 	ParseChunk(data []byte) (*StreamChunk, error)
 }
 
+// streamScopedParser is implemented by parsers that keep state across the chunks of one
+// stream; ChatCompletionStream gets a fresh parser per stream from ForStream
+type streamScopedParser interface {
+	ForStream() StreamChunkParser
+}
+
 // OpenAIClient handles OpenAI-compatible API interactions
 type OpenAIClient struct {
 	config      *config.OpenAIConfig
 	httpClient  *http.Client
 	chunkParser StreamChunkParser // Provider-specific chunk parser
+	budget      *TokenBudget      // Token spending cap shared across requests
+
+	embeddingFallback *embeddingEndpoint // Secondary embedding provider (nil = none or incompatible)
+	embeddingCache    *embeddingCache    // Recently embedded texts (nil = EMBEDDING_CACHE_SIZE is 0)
+	fewShotExamples   string             // Rendered examples from OPENAI_FEW_SHOT_FILE (empty = built-in examples)
+	intentPrompt      string             // System prompt from INTENT_PROMPT_PATH (empty = built-in prompt)
 }
 
-// NewOpenAIClient creates a new OpenAI-compatible client with auto-detection of provider
-func NewOpenAIClient(cfg *config.OpenAIConfig) *OpenAIClient {
-	// Auto-detect provider based on base URL
-	var parser StreamChunkParser
-	if IsNVIDIAProvider(cfg.APIBase) {
-		parser = &NVIDIAStreamChunkParser{}
+// newChunkParser returns the stream parser for the configured provider,
+// auto-detecting it from the base URL when no provider is set. extractThinkTags enables
+// moving inline <think> content out of the answer for OpenAI-format streams.
+func newChunkParser(provider, baseURL string, extractThinkTags bool) StreamChunkParser {
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case ProviderNVIDIA:
+		log.Printf("🔧 Using NVIDIA API provider (configured)")
+		return &NVIDIAStreamChunkParser{}
+	case ProviderOpenAI:
+		log.Printf("🔧 Using OpenAI API provider (configured)")
+		return &OpenAIStreamChunkParser{ExtractThinkTags: extractThinkTags}
+	case ProviderOllama:
+		log.Printf("🔧 Using Ollama API provider (configured)")
+		return &OllamaStreamChunkParser{}
+	case "":
+	default:
+		log.Printf("⚠️  Unknown OPENAI_PROVIDER %q, auto-detecting from base URL", provider)
+	}
+
+	if IsNVIDIAProvider(baseURL) {
 		log.Printf("🔧 Detected NVIDIA API provider (supports reasoning/thinking)")
-	} else if IsOpenAIProvider(cfg.APIBase) {
-		parser = &OpenAIStreamChunkParser{}
+		return &NVIDIAStreamChunkParser{}
+	}
+	if IsOllamaProvider(baseURL) {
+		log.Printf("🔧 Detected Ollama API provider (native /api/chat)")
+		return &OllamaStreamChunkParser{}
+	}
+	if IsOpenAIProvider(baseURL) {
 		log.Printf("🔧 Detected OpenAI API provider")
-	} else {
-		// Default to OpenAI format for unknown providers
-		parser = &OpenAIStreamChunkParser{}
-		log.Printf("🔧 Using standard OpenAI format for: %s", cfg.APIBase)
+		return &OpenAIStreamChunkParser{ExtractThinkTags: extractThinkTags}
 	}
+	// Default to OpenAI format for unknown providers
+	log.Printf("🔧 Using standard OpenAI format for: %s", baseURL)
+	return &OpenAIStreamChunkParser{ExtractThinkTags: extractThinkTags}
+}
+
+// NewOpenAIClient creates a new OpenAI-compatible client with auto-detection of provider
+func NewOpenAIClient(cfg *config.OpenAIConfig) *OpenAIClient {
+	parser := newChunkParser(cfg.Provider, cfg.APIBase, cfg.ExtractThinkTags)
 
 	return &OpenAIClient{
 		config:      cfg,
 		chunkParser: parser,
-		httpClient: &http.Client{
-			Timeout: time.Duration(cfg.Timeout) * time.Second,
-		},
+		// No client-wide timeout: each call sets its own deadline on the request context,
+		// so long streams aren't cut off at the chat or embedding deadline
+		httpClient:        &http.Client{},
+		budget:            NewTokenBudget(cfg.TokenBudget, time.Duration(cfg.TokenBudgetWindow)*time.Second),
+		embeddingFallback: newEmbeddingFallback(cfg),
+		embeddingCache:    newEmbeddingCache(cfg.EmbeddingCacheSize),
+	}
+}
+
+// withCallTimeout bounds one API call by seconds, or by the default OPENAI_TIMEOUT when
+// seconds is 0. The deadline covers reading the response body, streamed or not.
+func (c *OpenAIClient) withCallTimeout(ctx context.Context, seconds int) (context.Context, context.CancelFunc) {
+	if seconds <= 0 {
+		seconds = c.config.Timeout
+	}
+	if seconds <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(seconds)*time.Second)
+}
+
+// isOllama reports whether chat requests go to Ollama's native /api/chat instead of /chat/completions
+func (c *OpenAIClient) isOllama() bool {
+	_, ok := c.chunkParser.(*OllamaStreamChunkParser)
+	return ok
+}
+
+// Warmup primes DNS resolution, the TLS handshake, and the connection pool with a
+// lightweight HEAD to the API base. Any HTTP response counts as success; no tokens are spent.
+func (c *OpenAIClient) Warmup(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, strings.TrimRight(c.config.APIBase, "/")+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.APIKey))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", c.config.APIBase, err)
 	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return nil
 }
 
 // IsEnabled returns whether the client is configured and ready
@@ -58,6 +138,24 @@ func (c *OpenAIClient) IsEnabled() bool {
 	return c.config.Enabled
 }
 
+// TokenBudgetExhausted reports whether the configured token budget is spent for the current window
+func (c *OpenAIClient) TokenBudgetExhausted() bool {
+	return c.budget.Exhausted()
+}
+
+// EmbeddingCacheStats returns embedding cache usage; ok is false when the cache is disabled
+func (c *OpenAIClient) EmbeddingCacheStats() (EmbeddingCacheStats, bool) {
+	if c.embeddingCache == nil {
+		return EmbeddingCacheStats{}, false
+	}
+	return c.embeddingCache.stats(), true
+}
+
+// TokenBudgetStatus returns the current token budget usage
+func (c *OpenAIClient) TokenBudgetStatus() TokenBudgetStatus {
+	return c.budget.Status()
+}
+
 // ChatCompletionRequest represents a chat completion request
 type ChatCompletionRequest struct {
 	Model          string          `json:"model"`
@@ -66,8 +164,14 @@ type ChatCompletionRequest struct {
 	TopP           float64         `json:"top_p,omitempty"` // For DeepSeek/NVIDIA API
 	MaxTokens      int             `json:"max_tokens,omitempty"`
 	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
-	Stream         bool            `json:"stream,omitempty"`     // For streaming responses
-	ExtraBody      map[string]any  `json:"extra_body,omitempty"` // For DeepSeek: {"chat_template_kwargs": {"thinking":True}}
+	Stream         bool            `json:"stream,omitempty"`         // For streaming responses
+	StreamOptions  *StreamOptions  `json:"stream_options,omitempty"` // Ask for a final usage chunk when streaming
+	ExtraBody      map[string]any  `json:"extra_body,omitempty"`     // For DeepSeek: {"chat_template_kwargs": {"thinking":True}}
+}
+
+// StreamOptions configures streaming behaviour
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 // ChatMessage represents a single message in the conversation
@@ -92,11 +196,14 @@ type ChatCompletionResponse struct {
 		Message      ChatMessage `json:"message"`
 		FinishReason string      `json:"finish_reason"`
 	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage"`
+	Usage TokenUsage `json:"usage"`
+}
+
+// TokenUsage is the token count the provider reports for a completion
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 // StreamCallback is called for each chunk in streaming mode
@@ -133,6 +240,9 @@ func (c *OpenAIClient) ChatCompletion(ctx context.Context, req ChatCompletionReq
 		return nil, fmt.Errorf("OpenAI API is not enabled (missing API key)")
 	}
 
+	ctx, cancel := c.withCallTimeout(ctx, c.config.ChatTimeout)
+	defer cancel()
+
 	// Use configured model if not specified
 	if req.Model == "" {
 		req.Model = c.config.ChatModel
@@ -154,18 +264,25 @@ func (c *OpenAIClient) ChatCompletion(ctx context.Context, req ChatCompletionReq
 		var extraBody map[string]any
 		if err := json.Unmarshal([]byte(c.config.ChatExtraBody), &extraBody); err == nil {
 			req.ExtraBody = extraBody
-			log.Printf("[DEBUG] ✅ ChatExtraBody parsed successfully: %+v", extraBody)
+			utils.LoggerFrom(ctx).Debug("applying chat extra_body from config", "extra_body", utils.RedactJSON([]byte(c.config.ChatExtraBody)))
 		} else {
 			log.Printf("Warning: Failed to parse OPENAI_CHAT_EXTRA_BODY: %v", err)
 		}
 	}
 
-	reqBody, err := json.Marshal(req)
+	var reqBody []byte
+	var err error
+	url := fmt.Sprintf("%s/chat/completions", c.config.APIBase)
+	if c.isOllama() {
+		reqBody, err = json.Marshal(newOllamaChatRequest(req, false))
+		url = ollamaChatURL(c.config.APIBase)
+	} else {
+		reqBody, err = json.Marshal(req)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/chat/completions", c.config.APIBase)
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -186,7 +303,16 @@ func (c *OpenAIClient) ChatCompletion(ctx context.Context, req ChatCompletionReq
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, utils.RedactSecrets(string(body), c.config.APIKey))
+	}
+
+	if c.isOllama() {
+		result, err := parseOllamaChatResponse(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		c.budget.Record(result.Usage.TotalTokens)
+		return result, nil
 	}
 
 	var result ChatCompletionResponse
@@ -194,6 +320,8 @@ func (c *OpenAIClient) ChatCompletion(ctx context.Context, req ChatCompletionReq
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
+	c.budget.Record(result.Usage.TotalTokens)
+
 	return &result, nil
 }
 
@@ -203,6 +331,9 @@ func (c *OpenAIClient) ChatCompletionStream(ctx context.Context, req ChatComplet
 		return fmt.Errorf("OpenAI API is not enabled (missing API key)")
 	}
 
+	ctx, cancel := c.withCallTimeout(ctx, c.config.StreamTimeout)
+	defer cancel()
+
 	// Use configured model if not specified
 	if req.Model == "" {
 		req.Model = c.config.ChatModel
@@ -220,30 +351,40 @@ func (c *OpenAIClient) ChatCompletionStream(ctx context.Context, req ChatComplet
 	}
 
 	// Parse and apply extra_body from config if not already set
+	logger := utils.LoggerFrom(ctx)
 	if req.ExtraBody == nil && c.config.ChatExtraBody != "" {
-		log.Printf("[DEBUG] 🔧 Applying ChatExtraBody from config: %s", c.config.ChatExtraBody)
 		var extraBody map[string]any
 		if err := json.Unmarshal([]byte(c.config.ChatExtraBody), &extraBody); err == nil {
 			req.ExtraBody = extraBody
-			log.Printf("[DEBUG] ✅ ChatExtraBody parsed successfully: %+v", extraBody)
+			logger.Debug("applying chat extra_body from config", "extra_body", utils.RedactJSON([]byte(c.config.ChatExtraBody)))
 		} else {
-			log.Printf("[DEBUG] ❌ Failed to parse OPENAI_CHAT_EXTRA_BODY: %v", err)
+			log.Printf("Warning: Failed to parse OPENAI_CHAT_EXTRA_BODY: %v", err)
 		}
-	} else {
-		log.Printf("[DEBUG] ⚠️  No ChatExtraBody: ExtraBody=%v, ChatExtraBody='%s'", req.ExtraBody, c.config.ChatExtraBody)
 	}
 
-	// Enable streaming
+	// Enable streaming, with a trailing usage chunk for token budget tracking
 	req.Stream = true
+	req.StreamOptions = &StreamOptions{IncludeUsage: true}
 
-	reqBody, err := json.Marshal(req)
+	// Ollama's native API streams newline-delimited JSON rather than SSE
+	ndjson := c.isOllama()
+
+	var reqBody []byte
+	var err error
+	url := fmt.Sprintf("%s/chat/completions", c.config.APIBase)
+	if ndjson {
+		reqBody, err = json.Marshal(newOllamaChatRequest(req, true))
+		url = ollamaChatURL(c.config.APIBase)
+	} else {
+		reqBody, err = json.Marshal(req)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	log.Printf("[DEBUG] 📤 Streaming request body: %s", string(reqBody))
+	// extra_body comes from config and may carry provider credentials
+	logger.Debug("sending streaming chat request", "url", utils.RedactURL(url), "body", utils.RedactJSON(reqBody, c.config.APIKey))
 
-	url := fmt.Sprintf("%s/chat/completions", c.config.APIBase)
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqBody))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -261,18 +402,29 @@ func (c *OpenAIClient) ChatCompletionStream(ctx context.Context, req ChatComplet
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, utils.RedactSecrets(string(body), c.config.APIKey))
+	}
+
+	parser := c.chunkParser
+	if scoped, ok := parser.(streamScopedParser); ok {
+		parser = scoped.ForStream()
 	}
 
 	// Process streaming response
 	reader := bufio.NewReader(resp.Body)
-	for {
+	done := false
+	for !done {
 		line, err := reader.ReadBytes('\n')
-		if err != nil {
-			if err == io.EOF {
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read stream: %w", err)
+		}
+		// The last line may arrive without a trailing newline; process it before stopping
+		if err == io.EOF {
+			if len(bytes.TrimSpace(line)) == 0 {
+				log.Printf("⚠️  Stream ended without a [DONE] marker")
 				break
 			}
-			return fmt.Errorf("failed to read stream: %w", err)
+			done = true
 		}
 
 		// Skip empty lines
@@ -281,25 +433,46 @@ func (c *OpenAIClient) ChatCompletionStream(ctx context.Context, req ChatComplet
 			continue
 		}
 
+		// NDJSON: every line is a chunk, and the one with "done": true ends the stream
+		if ndjson {
+			chunk, err := parser.ParseChunk(line)
+			if err != nil {
+				log.Printf("Warning: Failed to parse stream chunk: %v", err)
+				continue
+			}
+
+			c.budget.Record(chunk.TotalTokens)
+
+			if err := callback(chunk); err != nil {
+				return fmt.Errorf("%w: %w", errStreamCallback, err)
+			}
+			if chunk.Done {
+				return nil
+			}
+			continue
+		}
+
 		// Parse SSE format: "data: {...}"
 		if bytes.HasPrefix(line, []byte("data: ")) {
 			data := bytes.TrimPrefix(line, []byte("data: "))
 
 			// Check for [DONE] marker
 			if bytes.Equal(data, []byte("[DONE]")) {
-				break
+				return nil
 			}
 
 			// Parse chunk using provider-specific parser
-			chunk, err := c.chunkParser.ParseChunk(data)
+			chunk, err := parser.ParseChunk(data)
 			if err != nil {
 				log.Printf("Warning: Failed to parse stream chunk: %v", err)
 				continue
 			}
 
+			c.budget.Record(chunk.TotalTokens)
+
 			// Call callback with generic chunk
 			if err := callback(chunk); err != nil {
-				return fmt.Errorf("callback error: %w", err)
+				return fmt.Errorf("%w: %w", errStreamCallback, err)
 			}
 		}
 	}
@@ -307,8 +480,14 @@ func (c *OpenAIClient) ChatCompletionStream(ctx context.Context, req ChatComplet
 	return nil
 }
 
-// CreateEmbeddings creates embeddings for the given texts
+// CreateEmbeddings creates embeddings for the given texts with the configured model
 func (c *OpenAIClient) CreateEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	return c.CreateEmbeddingsWithModel(ctx, "", texts)
+}
+
+// createEmbeddings creates embeddings with a resolved model and dimension. Texts found in
+// the embedding cache are served from it; only the rest go to the provider.
+func (c *OpenAIClient) createEmbeddings(ctx context.Context, model string, dims int, texts []string) ([][]float32, error) {
 	if !c.config.Enabled {
 		return nil, fmt.Errorf("OpenAI API is not enabled (missing API key)")
 	}
@@ -316,10 +495,55 @@ func (c *OpenAIClient) CreateEmbeddings(ctx context.Context, texts []string) ([]
 	if len(texts) == 0 {
 		return [][]float32{}, nil
 	}
+	// An input the provider would reject even on its own fails the same way on every retry
+	if err := validateEmbeddingInputs(texts, c.config.EmbeddingMaxInputChars); err != nil {
+		return nil, err
+	}
+	if c.embeddingCache == nil {
+		embeddings, _, err := c.embedBatches(ctx, model, dims, texts)
+		return embeddings, err
+	}
+
+	embeddings := make([][]float32, len(texts))
+	var missing []string
+	var missingAt []int
+	for i, text := range texts {
+		if vector, ok := c.embeddingCache.get(embeddingCacheKey(model, dims, text)); ok {
+			embeddings[i] = vector
+			continue
+		}
+		missing = append(missing, text)
+		missingAt = append(missingAt, i)
+	}
+	if len(missing) == 0 {
+		return embeddings, nil
+	}
+
+	created, servedBy, err := c.embedBatches(ctx, model, dims, missing)
+	if err != nil {
+		return nil, err
+	}
+	for j, vector := range created {
+		embeddings[missingAt[j]] = vector
+		// A fallback on another model isn't what the key promises; the next request retries the primary
+		if servedBy[j] == model {
+			c.embeddingCache.set(embeddingCacheKey(model, dims, missing[j]), vector)
+		}
+	}
+	return embeddings, nil
+}
+
+// embedBatches creates embeddings for texts in batches of BatchSize, along with the model
+// that produced each one
+func (c *OpenAIClient) embedBatches(ctx context.Context, model string, dims int, texts []string) ([][]float32, []string, error) {
 
 	// Process in batches
 	allEmbeddings := make([][]float32, 0, len(texts))
+	servedBy := make([]string, 0, len(texts))
 	batchSize := c.config.BatchSize
+	if batchSize <= 0 {
+		batchSize = len(texts)
+	}
 
 	for i := 0; i < len(texts); i += batchSize {
 		end := i + batchSize
@@ -328,12 +552,15 @@ func (c *OpenAIClient) CreateEmbeddings(ctx context.Context, texts []string) ([]
 		}
 		batch := texts[i:end]
 
-		embeddings, err := c.createEmbeddingBatch(ctx, batch)
+		embeddings, batchModel, err := c.embedBatchWithFallback(ctx, model, dims, batch)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create embeddings for batch %d: %w", i/batchSize, err)
+			return nil, nil, fmt.Errorf("failed to create embeddings for batch %d: %w", i/batchSize, err)
 		}
 
 		allEmbeddings = append(allEmbeddings, embeddings...)
+		for range embeddings {
+			servedBy = append(servedBy, batchModel)
+		}
 
 		// Rate limiting: small delay between batches
 		if end < len(texts) {
@@ -341,15 +568,60 @@ func (c *OpenAIClient) CreateEmbeddings(ctx context.Context, texts []string) ([]
 		}
 	}
 
-	return allEmbeddings, nil
+	return allEmbeddings, servedBy, nil
+}
+
+// EmbeddingInputTooLongError reports an input over OPENAI_EMBEDDING_MAX_INPUT_CHARS
+type EmbeddingInputTooLongError struct {
+	Index    int // Position of the input in the request
+	Chars    int
+	MaxChars int
+}
+
+func (e *EmbeddingInputTooLongError) Error() string {
+	return fmt.Sprintf("embedding input %d is %d characters, over the %d-character limit per input (OPENAI_EMBEDDING_MAX_INPUT_CHARS); chunk it first", e.Index, e.Chars, e.MaxChars)
+}
+
+// validateEmbeddingInputs rejects inputs longer than maxChars characters (0 = unchecked)
+func validateEmbeddingInputs(texts []string, maxChars int) error {
+	if maxChars <= 0 {
+		return nil
+	}
+	for i, text := range texts {
+		if n := utf8.RuneCountInString(text); n > maxChars {
+			return &EmbeddingInputTooLongError{Index: i, Chars: n, MaxChars: maxChars}
+		}
+	}
+	return nil
 }
 
-// createEmbeddingBatch creates embeddings for a single batch
-func (c *OpenAIClient) createEmbeddingBatch(ctx context.Context, texts []string) ([][]float32, error) {
+// createEmbeddingBatch creates embeddings for a single batch on one provider, splitting it
+// into requests of at most EmbeddingMaxInputs inputs so an oversized BatchSize can't get
+// the whole request rejected
+func (c *OpenAIClient) createEmbeddingBatch(ctx context.Context, endpoint embeddingEndpoint, dims int, texts []string) ([][]float32, error) {
+	maxInputs := c.config.EmbeddingMaxInputs
+	if maxInputs <= 0 || len(texts) <= maxInputs {
+		return c.postEmbeddingBatch(ctx, endpoint, dims, texts)
+	}
+
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += maxInputs {
+		part, err := c.postEmbeddingBatch(ctx, endpoint, dims, texts[start:min(start+maxInputs, len(texts))])
+		if err != nil {
+			return nil, fmt.Errorf("inputs %d-%d: %w", start, min(start+maxInputs, len(texts))-1, err)
+		}
+		embeddings = append(embeddings, part...)
+	}
+	return embeddings, nil
+}
+
+// postEmbeddingBatch sends one embeddings request
+func (c *OpenAIClient) postEmbeddingBatch(ctx context.Context, endpoint embeddingEndpoint, dims int, texts []string) ([][]float32, error) {
+	model := endpoint.Model
 	req := EmbeddingRequest{
-		Model:          c.config.EmbeddingModel,
+		Model:          model,
 		Input:          texts,
-		Dimensions:     c.config.EmbeddingDimensions,
+		Dimensions:     dims,
 		EncodingFormat: "float", // For NVIDIA API compatibility
 	}
 
@@ -368,14 +640,17 @@ func (c *OpenAIClient) createEmbeddingBatch(ctx context.Context, texts []string)
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/embeddings", c.config.APIBase)
+	ctx, cancel := c.withCallTimeout(ctx, c.config.EmbeddingTimeout)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/embeddings", endpoint.APIBase)
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.APIKey))
+	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", endpoint.APIKey))
 
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
@@ -389,7 +664,7 @@ func (c *OpenAIClient) createEmbeddingBatch(ctx context.Context, texts []string)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, utils.RedactSecrets(string(body), endpoint.APIKey))
 	}
 
 	var result EmbeddingResponse
@@ -404,71 +679,28 @@ func (c *OpenAIClient) createEmbeddingBatch(ctx context.Context, texts []string)
 			embeddings[item.Index] = item.Embedding
 		}
 	}
+	// Never hand back vectors that don't fit the stored column
+	for i, embedding := range embeddings {
+		if len(embedding) != dims {
+			return nil, fmt.Errorf("model %s returned %d dimensions for input %d, expected %d", model, len(embedding), i, dims)
+		}
+	}
 
-	log.Printf("Created %d embeddings using model %s (tokens: %d)", len(embeddings), result.Model, result.Usage.TotalTokens)
+	log.Printf("Created %d embeddings via %s provider using model %s (tokens: %d)", len(embeddings), endpoint.Name, result.Model, result.Usage.TotalTokens)
 
 	return embeddings, nil
 }
 
 // Note: AIIntentResponse is now defined in ai_client.go for better abstraction
 
-// ParseIntentWithAI uses OpenAI to parse natural language query into structured filters
-func (c *OpenAIClient) ParseIntentWithAI(ctx context.Context, query string) (*AIIntentResponse, error) {
+// ParseIntentWithAI uses OpenAI to parse natural language query into structured filters.
+// locale ("de-DE", "en-IN") tells the LLM how the user writes numbers; "" means the SGD defaults.
+func (c *OpenAIClient) ParseIntentWithAI(ctx context.Context, query, locale string) (*AIIntentResponse, error) {
 	if !c.config.Enabled {
 		return nil, fmt.Errorf("OpenAI API is not enabled")
 	}
 
-	systemPrompt := `You are a real estate search assistant in Singapore. Parse the user's natural language query into structured filters.
-
-Extract the following information if present:
-- price_min: minimum price in SGD (number)
-- price_max: maximum price in SGD (number)
-- bedrooms: number of bedrooms (integer)
-- bathrooms: number of bathrooms (integer)
-- area_sqft_min: minimum area in square feet (number)
-- area_sqft_max: maximum area in square feet (number)
-- unit_type: property type - must be one of: "HDB", "Condo", "Landed", "Executive" (string)
-- location: Singapore district or area name (string)
-- mrt_distance_max: maximum walking time to MRT station in minutes (integer, default 15 if "near MRT" mentioned)
-- build_year_min: minimum build year (integer)
-- amenities: array of required amenities/features (e.g., ["Air conditioner", "Balcony", "Washer/dryer"])
-- facilities: array of required facilities (e.g., ["Swimming pool", "Gym", "BBQ pits", "Playground"])
-- keywords: array of important keywords for semantic search (e.g., "spacious", "view", "renovated", "quiet")
-
-Common amenities: Air conditioner, Balcony, Built-in wardrobe, Curtains, Fridge, Washer/dryer, Water heater, Dining table, Bed frame, Study table
-Common facilities: Swimming pool, Gym, Tennis court, BBQ pits, Playground, Function room, 24-hour security, Covered parking
-
-Important rules:
-- All property data is in English
-- Respond ONLY with valid JSON
-- If a field is not mentioned, omit it
-- For prices: "1.5M" = 1500000, "800K" = 800000
-- For areas: "1000 sqft" = 1000, "1200 square feet" = 1200
-- Common terms: "bright" (natural light), "spacious" (large area), "view" (good scenery)
-- When user mentions facilities like "pool", "gym", "tennis", add them to facilities array
-- When user mentions appliances/features like "aircon", "balcony", add them to amenities array
-
-Examples:
-Query: "3 bedroom condo in Punggol under 1.5M"
-Response: {"bedrooms": 3, "unit_type": "Condo", "location": "Punggol", "price_max": 1500000, "keywords": ["condo", "punggol"]}
-
-Query: "HDB near MRT with good view and spacious layout"
-Response: {"unit_type": "HDB", "mrt_distance_max": 15, "keywords": ["view", "spacious", "near mrt", "hdb"]}
-
-Query: "Condo with swimming pool and gym, 2 bedrooms, at least 1000 sqft"
-Response: {"bedrooms": 2, "unit_type": "Condo", "area_sqft_min": 1000, "facilities": ["Swimming pool", "Gym"], "keywords": ["condo", "pool", "gym"]}
-
-Query: "Apartment with balcony and air conditioning, fully furnished, 800-1200 sqft"
-Response: {"area_sqft_min": 800, "area_sqft_max": 1200, "amenities": ["Balcony", "Air conditioner"], "keywords": ["furnished", "balcony", "aircon"]}
-
-Query: "Large 4-bedroom landed house, minimum 2500 sqft"
-Response: {"bedrooms": 4, "unit_type": "Landed", "area_sqft_min": 2500, "keywords": ["large", "landed", "house"]}
-
-Query: "Landed property in Bukit Timah, 4 bed 3 bath, modern"
-Response: {"unit_type": "Landed", "location": "Bukit Timah", "bedrooms": 4, "bathrooms": 3, "keywords": ["modern", "landed", "bukit timah"]}
-
-Query: "New condo near Orchard, budget 2M max"
-Response: {"unit_type": "Condo", "location": "Orchard", "price_max": 2000000, "build_year_min": 2015, "keywords": ["new", "condo", "orchard"]}`
+	systemPrompt := c.intentSystemPrompt(locale)
 
 	req := ChatCompletionRequest{
 		Model: c.config.ChatModel,
@@ -492,21 +724,61 @@ Response: {"unit_type": "Condo", "location": "Orchard", "price_max": 2000000, "b
 	// Use robust JSON parser to handle various AI output formats
 	var result AIIntentResponse
 	content := resp.Choices[0].Message.Content
-	if err := utils.ParseAIJSON(content, &result); err != nil {
+	strategy, err := utils.ParseAIJSONStrategy(content, &result)
+	if err != nil {
 		log.Printf("Failed to parse AI response, content: %s", content)
 		return nil, fmt.Errorf("failed to parse AI response: %w", err)
 	}
+	result.RawContent = content
+	result.ParseStrategy = strategy
+	result.Usage = &resp.Usage
 
 	// Validate the response structure
-	if err := c.validateIntentResponse(&result); err != nil {
+	if err := validateIntentResponse(&result); err != nil {
 		return nil, fmt.Errorf("AI response validation failed: %w", err)
 	}
 
 	return &result, nil
 }
 
+// Plausible bounds for extracted prices (SGD, monthly rent up to sale price) and areas (sqft)
+const (
+	minPlausiblePrice = 100
+	maxPlausiblePrice = 100_000_000
+	minPlausibleArea  = 100
+	maxPlausibleArea  = 50_000
+
+	minPlausiblePricePerSqft = 1
+	maxPlausiblePricePerSqft = 10_000
+)
+
+// Sane build years, for extracted slots and for filters after overrides
+const (
+	minBuildYear = 1900
+	maxBuildYear = 2100
+)
+
+// plausibleValue drops a value outside [min, max], logging the field it came from
+func plausibleValue(field string, value *float64, min, max float64) *float64 {
+	if value != nil && (*value < min || *value > max) {
+		log.Printf("⚠️  Ignoring implausible %s: %v", field, *value)
+		return nil
+	}
+	return value
+}
+
 // validateIntentResponse validates the AI response using business rules
-func (c *OpenAIClient) validateIntentResponse(resp *AIIntentResponse) error {
+func validateIntentResponse(resp *AIIntentResponse) error {
+	// Drop prices and areas outside plausible bounds; these are usually misread number
+	// formats ("1,5M" taken as 15) rather than real budgets
+	resp.PriceMin = plausibleValue("price_min", resp.PriceMin, minPlausiblePrice, maxPlausiblePrice)
+	resp.PriceMax = plausibleValue("price_max", resp.PriceMax, minPlausiblePrice, maxPlausiblePrice)
+	resp.PriceTarget = plausibleValue("price_target", resp.PriceTarget, minPlausiblePrice, maxPlausiblePrice)
+	resp.AreaSqftMin = plausibleValue("area_sqft_min", resp.AreaSqftMin, minPlausibleArea, maxPlausibleArea)
+	resp.AreaSqftMax = plausibleValue("area_sqft_max", resp.AreaSqftMax, minPlausibleArea, maxPlausibleArea)
+	resp.PricePerSqftMin = plausibleValue("price_per_sqft_min", resp.PricePerSqftMin, minPlausiblePricePerSqft, maxPlausiblePricePerSqft)
+	resp.PricePerSqftMax = plausibleValue("price_per_sqft_max", resp.PricePerSqftMax, minPlausiblePricePerSqft, maxPlausiblePricePerSqft)
+
 	// Validate price range
 	if resp.PriceMin != nil && resp.PriceMax != nil {
 		if *resp.PriceMin > *resp.PriceMax {
@@ -514,13 +786,79 @@ func (c *OpenAIClient) validateIntentResponse(resp *AIIntentResponse) error {
 		}
 	}
 
-	// Validate unit type enum
-	if resp.UnitType != nil {
-		validTypes := map[string]bool{"HDB": true, "Condo": true, "Landed": true, "Executive": true}
-		if !validTypes[*resp.UnitType] {
-			return fmt.Errorf("invalid unit_type: %s, must be one of: HDB, Condo, Landed, Executive", *resp.UnitType)
+	// Validate price per sqft range
+	if resp.PricePerSqftMin != nil && resp.PricePerSqftMax != nil {
+		if *resp.PricePerSqftMin > *resp.PricePerSqftMax {
+			return fmt.Errorf("price_per_sqft_min (%f) cannot be greater than price_per_sqft_max (%f)", *resp.PricePerSqftMin, *resp.PricePerSqftMax)
+		}
+	}
+
+	if resp.PriceTarget != nil && *resp.PriceTarget <= 0 {
+		return fmt.Errorf("price_target must be positive")
+	}
+
+	// Validate unit type enum, accepting raw variants such as "Condominium"
+	for i, unitType := range resp.UnitType {
+		normalized := utils.NormalizeUnitType(unitType)
+		if normalized == "" {
+			return fmt.Errorf("invalid unit_type: %s, must be one of: %s", unitType, strings.Join(utils.CanonicalUnitTypes, ", "))
+		}
+		resp.UnitType[i] = normalized
+	}
+	for i, unitType := range resp.UnitTypes {
+		normalized := utils.NormalizeUnitType(unitType)
+		if normalized == "" {
+			return fmt.Errorf("invalid unit_types entry: %s, must be one of: %s", unitType, strings.Join(utils.CanonicalUnitTypes, ", "))
+		}
+		resp.UnitTypes[i] = normalized
+	}
+	for i, unitType := range resp.ExcludeUnitTypes {
+		normalized := utils.NormalizeUnitType(unitType)
+		if normalized == "" {
+			return fmt.Errorf("invalid exclude_unit_types entry: %s, must be one of: %s", unitType, strings.Join(utils.CanonicalUnitTypes, ", "))
+		}
+		resp.ExcludeUnitTypes[i] = normalized
+	}
+
+	// Canonicalize MRT line names to codes, dropping lines we can't map to stations
+	if resp.MRTLine != nil {
+		if line := utils.ResolveMRTLine(*resp.MRTLine); line != nil {
+			resp.MRTLine = &line.Code
+		} else {
+			log.Printf("⚠️  Ignoring unknown MRT line: %s", *resp.MRTLine)
+			resp.MRTLine = nil
+		}
+	}
+	var lines []string
+	for _, name := range resp.MRTLines {
+		if line := utils.ResolveMRTLine(name); line != nil {
+			lines = append(lines, line.Code)
+		} else {
+			log.Printf("⚠️  Ignoring unknown MRT line: %s", name)
 		}
 	}
+	resp.MRTLines = lines
+
+	// Canonicalize location aliases and misspellings ("Tg Pagar" -> "Tanjong Pagar")
+	if resp.Location != nil {
+		canonical := utils.CanonicalArea(*resp.Location)
+		resp.Location = &canonical
+	}
+	for i, location := range resp.Locations {
+		resp.Locations[i] = utils.CanonicalArea(location)
+	}
+	// Canonicalize postal districts to codes ("district 9" -> "D09"), dropping unknown ones
+	if resp.District != nil {
+		if code := utils.CanonicalDistrict(*resp.District); code != "" {
+			resp.District = &code
+		} else {
+			log.Printf("⚠️  Ignoring unknown district: %s", *resp.District)
+			resp.District = nil
+		}
+	}
+	for i, location := range resp.ExcludeLocations {
+		resp.ExcludeLocations[i] = utils.CanonicalArea(location)
+	}
 
 	// Validate numeric ranges
 	if resp.Bedrooms != nil && (*resp.Bedrooms < 0 || *resp.Bedrooms > 10) {
@@ -529,59 +867,44 @@ func (c *OpenAIClient) validateIntentResponse(resp *AIIntentResponse) error {
 	if resp.Bathrooms != nil && (*resp.Bathrooms < 0 || *resp.Bathrooms > 10) {
 		return fmt.Errorf("bathrooms must be between 0 and 10")
 	}
+	if resp.BedroomsMin != nil && (*resp.BedroomsMin < 0 || *resp.BedroomsMin > 10) {
+		return fmt.Errorf("bedrooms_min must be between 0 and 10")
+	}
+	if resp.BathroomsMin != nil && (*resp.BathroomsMin < 0 || *resp.BathroomsMin > 10) {
+		return fmt.Errorf("bathrooms_min must be between 0 and 10")
+	}
 	if resp.MRTDistanceMax != nil && (*resp.MRTDistanceMax < 0 || *resp.MRTDistanceMax > 60) {
 		return fmt.Errorf("mrt_distance_max must be between 0 and 60 minutes")
 	}
-	if resp.BuildYearMin != nil && (*resp.BuildYearMin < 1900 || *resp.BuildYearMin > 2100) {
-		return fmt.Errorf("build_year_min must be between 1900 and 2100")
+	if resp.BuildYearMin != nil && (*resp.BuildYearMin < minBuildYear || *resp.BuildYearMin > maxBuildYear) {
+		return fmt.Errorf("build_year_min must be between %d and %d", minBuildYear, maxBuildYear)
+	}
+	if resp.BuildYearMax != nil && (*resp.BuildYearMax < minBuildYear || *resp.BuildYearMax > maxBuildYear) {
+		return fmt.Errorf("build_year_max must be between %d and %d", minBuildYear, maxBuildYear)
+	}
+	if resp.BuildYearMin != nil && resp.BuildYearMax != nil && *resp.BuildYearMin > *resp.BuildYearMax {
+		return fmt.Errorf("build_year_min (%d) cannot be greater than build_year_max (%d)", *resp.BuildYearMin, *resp.BuildYearMax)
+	}
+	if resp.GreenScoreMin != nil && (*resp.GreenScoreMin < 0 || *resp.GreenScoreMin > 5) {
+		return fmt.Errorf("green_score_min must be between 0 and 5")
+	}
+	if resp.LeaseRemainingMin != nil && (*resp.LeaseRemainingMin < 1 || *resp.LeaseRemainingMin > 999) {
+		return fmt.Errorf("lease_remaining_min must be between 1 and 999 years")
 	}
 
 	return nil
 }
 
 // ParseIntentWithAIStream uses OpenAI streaming to parse natural language query
-func (c *OpenAIClient) ParseIntentWithAIStream(ctx context.Context, query string, callback func(thinking, content string) error) (*AIIntentResponse, error) {
-	log.Printf("[DEBUG] 🤖 ParseIntentWithAIStream called for query: %s", query)
-
+func (c *OpenAIClient) ParseIntentWithAIStream(ctx context.Context, query, locale string, callback func(thinking, content string) error) (*AIIntentResponse, error) {
 	if !c.config.Enabled {
-		log.Printf("[DEBUG] ❌ OpenAI API is not enabled")
 		return nil, fmt.Errorf("OpenAI API is not enabled")
 	}
 
-	log.Printf("[DEBUG] ✅ OpenAI API enabled, model: %s, base: %s", c.config.ChatModel, c.config.APIBase)
+	logger := utils.LoggerFrom(ctx)
+	logger.Debug("parsing intent with AI stream", "model", c.config.ChatModel, "base", utils.RedactURL(c.config.APIBase))
 
-	systemPrompt := `You are a real estate search assistant in Singapore. Parse the user's natural language query into structured filters.
-
-Extract the following information if present:
-- price_min: minimum price in SGD (number)
-- price_max: maximum price in SGD (number)
-- bedrooms: number of bedrooms (integer)
-- bathrooms: number of bathrooms (integer)
-- area_sqft_min: minimum area in square feet (number)
-- area_sqft_max: maximum area in square feet (number)
-- unit_type: property type - must be one of: "HDB", "Condo", "Landed", "Executive" (string)
-- location: Singapore district or area name (string)
-- mrt_distance_max: maximum walking time to MRT station in minutes (integer, default 15 if "near MRT" mentioned)
-- build_year_min: minimum build year (integer)
-- amenities: array of required amenities/features (e.g., ["Air conditioner", "Balcony"])
-- facilities: array of required facilities (e.g., ["Swimming pool", "Gym"])
-- keywords: array of important keywords for semantic search
-
-Important rules:
-- All property data is in English
-- Respond ONLY with valid JSON
-- If a field is not mentioned, omit it
-- For prices: "1.5M" = 1500000, "800K" = 800000
-- For areas: "1000 sqft" = 1000, "1200 square feet" = 1200
-
-Examples:
-Query: "3 bedroom condo under 1.5M"
-Response: {"bedrooms": 3, "unit_type": "Condo", "price_max": 1500000, "keywords": ["condo"]}
-
-Query: "2 bed with pool and gym, at least 1000 sqft"
-Response: {"bedrooms": 2, "area_sqft_min": 1000, "facilities": ["Swimming pool", "Gym"], "keywords": ["pool", "gym"]}
-
-Now parse the following query into JSON format:`
+	systemPrompt := c.intentSystemPrompt(locale)
 
 	req := ChatCompletionRequest{
 		Model: c.config.ChatModel,
@@ -594,8 +917,6 @@ Now parse the following query into JSON format:`
 		},
 	}
 
-	log.Printf("[DEBUG] 📤 Sending request to AI API...")
-
 	// Accumulate the response
 	var fullContent strings.Builder
 	var fullThinking strings.Builder
@@ -607,7 +928,7 @@ Now parse the following query into JSON format:`
 		// Handle thinking content (provider-specific, e.g., DeepSeek)
 		if chunk.ThinkingContent != "" {
 			fullThinking.WriteString(chunk.ThinkingContent)
-			log.Printf("[DEBUG] 💭 Thinking chunk #%d: %d chars", chunkCount, len(chunk.ThinkingContent))
+			logger.Debug("thinking chunk", "chunk", chunkCount, "chars", len(chunk.ThinkingContent))
 			if err := callback(chunk.ThinkingContent, ""); err != nil {
 				return err
 			}
@@ -616,7 +937,7 @@ Now parse the following query into JSON format:`
 		// Handle regular content
 		if chunk.Content != "" {
 			fullContent.WriteString(chunk.Content)
-			log.Printf("[DEBUG] 📝 Content chunk #%d: %s", chunkCount, chunk.Content)
+			logger.Debug("content chunk", "chunk", chunkCount, "content", chunk.Content)
 			if err := callback("", chunk.Content); err != nil {
 				return err
 			}
@@ -625,25 +946,51 @@ Now parse the following query into JSON format:`
 		return nil
 	})
 
+	// A stream that broke off mid-way may still have delivered a usable object; a caller
+	// that went away (cancelled context or failing callback) gets the error as before
+	recovery := ""
 	if err != nil {
-		log.Printf("[DEBUG] ❌ Streaming error: %v", err)
-		return nil, fmt.Errorf("streaming error: %w", err)
+		logger.Debug("intent stream failed", "error", err)
+		if ctx.Err() != nil || errors.Is(err, errStreamCallback) || fullContent.Len() == 0 {
+			return nil, fmt.Errorf("streaming error: %w", err)
+		}
+		recovery = StreamRecoveryPartial
 	}
 
-	log.Printf("[DEBUG] 🎉 Streaming completed. Total chunks: %d", chunkCount)
-	log.Printf("[DEBUG] 📊 Full thinking length: %d chars", fullThinking.Len())
-	log.Printf("[DEBUG] 📊 Full content length: %d chars", fullContent.Len())
+	logger.Debug("intent stream completed",
+		"chunks", chunkCount, "thinking_chars", fullThinking.Len(), "content_chars", fullContent.Len())
 
-	// Parse the accumulated JSON response using robust parser
+	// Parse the accumulated JSON response with the full repair chain (markdown, balanced
+	// braces, cleanup, truncation)
 	content := fullContent.String()
-	log.Printf("[DEBUG] 🔍 Attempting to parse JSON: %s", content)
+	logger.Debug("parsing streamed intent JSON", "content", content)
 
 	var result AIIntentResponse
-	if err := utils.ParseAIJSON(content, &result); err != nil {
-		log.Printf("[DEBUG] ❌ JSON parse failed: %v", err)
-		return nil, fmt.Errorf("failed to parse AI response: %w (content: %s)", err, content)
+	strategy, parseErr := utils.ParseAIJSONStrategy(content, &result)
+	if parseErr != nil {
+		logger.Debug("streamed intent JSON parse failed", "error", parseErr)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to parse AI response: %w (content: %s)", parseErr, content)
+		}
+
+		// Retry once without streaming before the caller falls back to an empty intent
+		log.Printf("⚠️  Streamed intent JSON was unrecoverable, retrying without streaming")
+		retried, retryErr := c.ParseIntentWithAI(ctx, query, locale)
+		if retryErr != nil {
+			return nil, fmt.Errorf("failed to parse AI response: %w (content: %s); retry failed: %v", parseErr, content, retryErr)
+		}
+		retried.StreamRecovery = StreamRecoveryRetry
+		return retried, nil
+	}
+	result.RawContent = content
+	result.ParseStrategy = strategy
+	result.StreamRecovery = recovery
+	if recovery != "" {
+		log.Printf("⚠️  Streamed intent recovered from a broken stream (json: %s)", strategy)
+	} else if strategy != utils.JSONStrategyDirect {
+		log.Printf("⚠️  Streamed intent JSON repaired (json: %s)", strategy)
 	}
 
-	log.Printf("[DEBUG] ✅ Streaming AI intent parsed successfully: %+v", result)
+	logger.Debug("streamed intent parsed", "strategy", strategy, "confidence", result.Confidence)
 	return &result, nil
 }
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"core/internal/config"
+)
+
+func TestWarmupAcceptsAnyHTTPResponse(t *testing.T) {
+	var method, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(&config.OpenAIConfig{APIBase: server.URL + "/v1/", Timeout: 5})
+	if err := client.Warmup(context.Background()); err != nil {
+		t.Fatalf("Expected warmup to succeed on a 404, got %v", err)
+	}
+	if method != http.MethodHead || path != "/v1/models" {
+		t.Errorf("Expected HEAD /v1/models, got %s %s", method, path)
+	}
+}
+
+func TestWarmupReportsUnreachableProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	client := NewOpenAIClient(&config.OpenAIConfig{APIBase: server.URL, Timeout: 5})
+	if err := client.Warmup(context.Background()); err == nil {
+		t.Error("Expected an error for an unreachable provider")
+	}
+}
+
+func TestWithCallTimeout(t *testing.T) {
+	client := NewOpenAIClient(&config.OpenAIConfig{Timeout: 30, StreamTimeout: 300})
+	if client.httpClient.Timeout != 0 {
+		t.Errorf("Expected no client-wide timeout, got %v", client.httpClient.Timeout)
+	}
+
+	tests := []struct {
+		name    string
+		seconds int
+		want    time.Duration
+	}{
+		{"unset falls back to OPENAI_TIMEOUT", 0, 30 * time.Second},
+		{"stream deadline", client.config.StreamTimeout, 300 * time.Second},
+	}
+	for _, tt := range tests {
+		ctx, cancel := client.withCallTimeout(context.Background(), tt.seconds)
+		deadline, ok := ctx.Deadline()
+		cancel()
+		if remaining := time.Until(deadline); !ok || remaining > tt.want || remaining < tt.want-time.Second {
+			t.Errorf("%s: expected a %v deadline, got %v (set=%v)", tt.name, tt.want, remaining, ok)
+		}
+	}
+
+	unbounded := NewOpenAIClient(&config.OpenAIConfig{})
+	ctx, cancel := unbounded.withCallTimeout(context.Background(), 0)
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("Expected no deadline when no timeout is configured")
+	}
+}
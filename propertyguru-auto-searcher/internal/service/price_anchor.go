@@ -0,0 +1,117 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"core/internal/model"
+)
+
+// priceAnchorEntry is one cached segment summary
+type priceAnchorEntry struct {
+	key     string
+	anchor  model.PriceAnchor
+	expires time.Time
+}
+
+// priceAnchorCache is an LRU of segment summaries, each kept for PRICE_ANCHOR_CACHE_TTL.
+// The zero value is an empty cache.
+type priceAnchorCache struct {
+	mu      sync.Mutex
+	order   *list.List // Front = most recently used
+	entries map[string]*list.Element
+}
+
+// get returns the cached summary for key, if present and not expired
+func (c *priceAnchorCache) get(key string) (model.PriceAnchor, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return model.PriceAnchor{}, false
+	}
+	entry := elem.Value.(*priceAnchorEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return model.PriceAnchor{}, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.anchor, true
+}
+
+// set stores a summary, evicting the least recently used entries beyond maxEntries (0 = unlimited)
+func (c *priceAnchorCache) set(key string, anchor model.PriceAnchor, ttl time.Duration, maxEntries int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.order = list.New()
+		c.entries = make(map[string]*list.Element)
+	}
+
+	entry := &priceAnchorEntry{key: key, anchor: anchor, expires: time.Now().Add(ttl)}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for maxEntries > 0 && c.order.Len() > maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*priceAnchorEntry).key)
+	}
+}
+
+// priceAnchorKey identifies a segment case-insensitively
+func priceAnchorKey(req *model.PriceAnchorRequest) string {
+	parts := make([]string, 3)
+	if req.Location != nil {
+		parts[0] = strings.ToLower(strings.TrimSpace(*req.Location))
+	}
+	if req.UnitType != nil {
+		parts[1] = strings.ToLower(strings.TrimSpace(*req.UnitType))
+	}
+	if req.Bedrooms != nil {
+		parts[2] = strconv.Itoa(*req.Bedrooms)
+	}
+	return strings.Join(parts, "|")
+}
+
+// PriceAnchor returns price and psf quartiles for a location / unit type / bedrooms segment,
+// using the same matching as search filters. Segments with fewer than
+// PRICE_ANCHOR_MIN_LISTINGS listings are flagged low_confidence.
+func (s *SearchService) PriceAnchor(ctx context.Context, req *model.PriceAnchorRequest) (*model.PriceAnchor, error) {
+	key := priceAnchorKey(req)
+	ttl := time.Duration(s.config.PriceAnchorCacheTTL) * time.Second
+
+	if anchor, ok := s.priceAnchors.get(key); ok {
+		return &anchor, nil
+	}
+
+	filters := &model.SearchFilters{
+		Location: req.Location,
+		Bedrooms: req.Bedrooms,
+	}
+	if req.UnitType != nil {
+		filters.UnitType = model.StringOrList{*req.UnitType}
+	}
+	anchor, err := s.repo.PriceAnchor(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	anchor.Location = req.Location
+	anchor.UnitType = req.UnitType
+	anchor.Bedrooms = req.Bedrooms
+	anchor.LowConfidence = anchor.Listings < s.config.PriceAnchorMinListings
+
+	if ttl > 0 {
+		s.priceAnchors.set(key, *anchor, ttl, s.config.PriceAnchorMaxEntries)
+	}
+
+	return anchor, nil
+}
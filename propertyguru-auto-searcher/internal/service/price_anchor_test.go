@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"core/internal/model"
+)
+
+func TestPriceAnchorKey(t *testing.T) {
+	a := priceAnchorKey(&model.PriceAnchorRequest{Location: stringPtr(" Punggol "), UnitType: stringPtr("Condo"), Bedrooms: intPtr(3)})
+	b := priceAnchorKey(&model.PriceAnchorRequest{Location: stringPtr("punggol"), UnitType: stringPtr("CONDO"), Bedrooms: intPtr(3)})
+	if a != b {
+		t.Errorf("Expected case/space-insensitive keys, got %q and %q", a, b)
+	}
+
+	c := priceAnchorKey(&model.PriceAnchorRequest{Location: stringPtr("punggol"), UnitType: stringPtr("condo")})
+	if a == c {
+		t.Errorf("Expected bedrooms to be part of the key, got %q for both", a)
+	}
+}
+
+func TestPriceAnchorCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	var cache priceAnchorCache
+	if _, ok := cache.get("a"); ok {
+		t.Fatal("Expected an empty zero-value cache")
+	}
+
+	cache.set("a", model.PriceAnchor{Listings: 1}, time.Minute, 2)
+	cache.set("b", model.PriceAnchor{Listings: 2}, time.Minute, 2)
+	cache.get("a") // "b" is now the least recently used
+	cache.set("c", model.PriceAnchor{Listings: 3}, time.Minute, 2)
+
+	if _, ok := cache.get("b"); ok {
+		t.Error("Expected the least recently used segment evicted")
+	}
+	if anchor, ok := cache.get("a"); !ok || anchor.Listings != 1 {
+		t.Errorf("Expected segment a kept, got %+v, %v", anchor, ok)
+	}
+	if _, ok := cache.get("c"); !ok || cache.order.Len() != 2 {
+		t.Errorf("Expected the cache capped at 2 segments, got %d", cache.order.Len())
+	}
+
+	cache.set("a", model.PriceAnchor{Listings: 1}, -time.Second, 2)
+	if _, ok := cache.get("a"); ok {
+		t.Error("Expected an expired segment to be dropped")
+	}
+	if _, ok := cache.entries["a"]; ok {
+		t.Error("Expected the expired segment removed from the cache")
+	}
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"encoding/json"
+	"net/url"
+	"strings"
 )
 
 // NVIDIAStreamChunkParser parses NVIDIA-specific streaming chunks
@@ -19,6 +21,9 @@ func (p *NVIDIAStreamChunkParser) ParseChunk(data []byte) (*StreamChunk, error)
 			} `json:"delta"`
 			FinishReason string `json:"finish_reason,omitempty"`
 		} `json:"choices"`
+		Usage *struct {
+			TotalTokens int `json:"total_tokens"`
+		} `json:"usage,omitempty"`
 	}
 
 	if err := json.Unmarshal(data, &rawChunk); err != nil {
@@ -42,10 +47,42 @@ func (p *NVIDIAStreamChunkParser) ParseChunk(data []byte) (*StreamChunk, error)
 		chunk.Done = rawChunk.Choices[0].FinishReason != ""
 	}
 
+	if rawChunk.Usage != nil {
+		chunk.TotalTokens = rawChunk.Usage.TotalTokens
+	}
+
 	return chunk, nil
 }
 
-// IsNVIDIAProvider checks if the base URL is NVIDIA API
+// IsNVIDIAProvider checks if the base URL points at an NVIDIA API host.
+// Matching is host-based, so scheme, port, trailing slashes, and path suffixes don't matter
+// and all *.nvidia.com variants (integrate.api, ai.api, regional hosts) are detected.
 func IsNVIDIAProvider(baseURL string) bool {
-	return baseURL == "https://integrate.api.nvidia.com/v1"
+	host := providerHost(baseURL)
+	return host == "nvidia.com" || strings.HasSuffix(host, ".nvidia.com")
+}
+
+// providerHost extracts the lowercased hostname from a base URL, tolerating a missing scheme
+func providerHost(baseURL string) string {
+	u := parseProviderURL(baseURL)
+	if u == nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+}
+
+// parseProviderURL parses a base URL, tolerating a missing scheme; nil when empty or invalid
+func parseProviderURL(baseURL string) *url.URL {
+	baseURL = strings.TrimSpace(baseURL)
+	if baseURL == "" {
+		return nil
+	}
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "https://" + baseURL
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+	return u
 }
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ollamaDefaultPort is the port a local Ollama server listens on
+const ollamaDefaultPort = "11434"
+
+// ollamaJSONOnlyInstruction is appended to the system prompt for Ollama, whose models
+// don't reliably honour response_format; utils.ParseAIJSON tolerates any leftover prose
+const ollamaJSONOnlyInstruction = "\n\nIMPORTANT: Your entire reply must be a single JSON object. " +
+	"Do not add explanations, markdown code fences, or any text before or after the JSON."
+
+// OllamaStreamChunkParser parses newline-delimited JSON chunks from Ollama's native /api/chat
+type OllamaStreamChunkParser struct{}
+
+// ollamaChatChunk is one /api/chat response object, streamed or not.
+// Token counts are only present on the final (done) object.
+type ollamaChatChunk struct {
+	Model   string `json:"model"`
+	Message struct {
+		Role     string `json:"role"`
+		Content  string `json:"content"`
+		Thinking string `json:"thinking,omitempty"` // Reasoning models with think enabled
+	} `json:"message"`
+	Done            bool   `json:"done"`
+	DoneReason      string `json:"done_reason,omitempty"`
+	PromptEvalCount int    `json:"prompt_eval_count,omitempty"`
+	EvalCount       int    `json:"eval_count,omitempty"`
+}
+
+// ParseChunk converts an Ollama chunk to a generic StreamChunk
+func (p *OllamaStreamChunkParser) ParseChunk(data []byte) (*StreamChunk, error) {
+	var raw ollamaChatChunk
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	chunk := &StreamChunk{
+		Content:         raw.Message.Content,
+		ThinkingContent: raw.Message.Thinking,
+		Role:            raw.Message.Role,
+		Done:            raw.Done,
+		Metadata:        make(map[string]interface{}),
+	}
+	if raw.Done {
+		chunk.TotalTokens = raw.PromptEvalCount + raw.EvalCount
+	}
+
+	return chunk, nil
+}
+
+// IsOllamaProvider checks if the base URL points at an Ollama server: the default
+// port 11434 (e.g. http://localhost:11434/v1) or a native /api/chat endpoint
+func IsOllamaProvider(baseURL string) bool {
+	u := parseProviderURL(baseURL)
+	if u == nil {
+		return false
+	}
+	return u.Port() == ollamaDefaultPort || strings.HasSuffix(strings.TrimRight(u.Path, "/"), "/api/chat")
+}
+
+// ollamaChatURL returns the native chat endpoint for an Ollama base URL. The base may be the
+// server root, its OpenAI-compatible /v1 path (still used for embeddings), /api, or /api/chat.
+func ollamaChatURL(baseURL string) string {
+	base := strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	switch {
+	case strings.HasSuffix(base, "/api/chat"):
+		return base
+	case strings.HasSuffix(base, "/api"):
+		return base + "/chat"
+	case strings.HasSuffix(base, "/v1"):
+		base = strings.TrimSuffix(base, "/v1")
+	}
+	return base + "/api/chat"
+}
+
+// ollamaChatRequest is the request body of Ollama's native /api/chat
+type ollamaChatRequest struct {
+	Model    string         `json:"model"`
+	Messages []ChatMessage  `json:"messages"`
+	Stream   bool           `json:"stream"` // Ollama streams unless told otherwise
+	Format   string         `json:"format,omitempty"`
+	Options  map[string]any `json:"options,omitempty"`
+}
+
+// newOllamaChatRequest translates an OpenAI-style request for /api/chat. A JSON response
+// format becomes format "json" plus an explicit JSON-only instruction in the system prompt.
+func newOllamaChatRequest(req ChatCompletionRequest, stream bool) ollamaChatRequest {
+	out := ollamaChatRequest{
+		Model:    req.Model,
+		Messages: req.Messages,
+		Stream:   stream,
+	}
+
+	if req.ResponseFormat != nil && req.ResponseFormat.Type == "json_object" {
+		out.Format = "json"
+		out.Messages = withJSONOnlyInstruction(req.Messages)
+	}
+
+	options := make(map[string]any)
+	if req.Temperature > 0 {
+		options["temperature"] = req.Temperature
+	}
+	if req.TopP > 0 {
+		options["top_p"] = req.TopP
+	}
+	if req.MaxTokens > 0 {
+		options["num_predict"] = req.MaxTokens
+	}
+	if len(options) > 0 {
+		out.Options = options
+	}
+
+	return out
+}
+
+// withJSONOnlyInstruction returns a copy of messages with the JSON-only instruction appended
+// to the system prompt, or prepended as one when there is none
+func withJSONOnlyInstruction(messages []ChatMessage) []ChatMessage {
+	out := make([]ChatMessage, len(messages))
+	copy(out, messages)
+	for i := range out {
+		if out[i].Role == "system" {
+			out[i].Content += ollamaJSONOnlyInstruction
+			return out
+		}
+	}
+	system := ChatMessage{Role: "system", Content: strings.TrimSpace(ollamaJSONOnlyInstruction)}
+	return append([]ChatMessage{system}, out...)
+}
+
+// parseOllamaChatResponse converts a non-streaming /api/chat response to the OpenAI shape
+func parseOllamaChatResponse(body []byte) (*ChatCompletionResponse, error) {
+	var raw ollamaChatChunk
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+
+	result := &ChatCompletionResponse{
+		Object: "chat.completion",
+		Model:  raw.Model,
+		Usage: TokenUsage{
+			PromptTokens:     raw.PromptEvalCount,
+			CompletionTokens: raw.EvalCount,
+			TotalTokens:      raw.PromptEvalCount + raw.EvalCount,
+		},
+	}
+	result.Choices = make([]struct {
+		Index        int         `json:"index"`
+		Message      ChatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	}, 1)
+	result.Choices[0].Message = ChatMessage{Role: raw.Message.Role, Content: raw.Message.Content}
+	result.Choices[0].FinishReason = raw.DoneReason
+
+	return result, nil
+}
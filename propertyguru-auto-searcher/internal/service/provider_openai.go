@@ -5,8 +5,27 @@ import (
 	"strings"
 )
 
-// OpenAIStreamChunkParser parses standard OpenAI-format streaming chunks
-type OpenAIStreamChunkParser struct{}
+// Inline reasoning markers some OpenAI-compatible providers put in the content delta
+const (
+	thinkOpenTag  = "<think>"
+	thinkCloseTag = "</think>"
+)
+
+// OpenAIStreamChunkParser parses standard OpenAI-format streaming chunks.
+// With ExtractThinkTags, inline <think>...</think> content is moved to ThinkingContent so it
+// never reaches the JSON parser. Tags may be split across chunks, so the parser keeps
+// per-stream state; use ForStream to get a parser for each stream.
+type OpenAIStreamChunkParser struct {
+	ExtractThinkTags bool
+
+	inThink bool   // Inside <think> ... </think>
+	pending string // Trailing text that may be the start of a tag
+}
+
+// ForStream returns a fresh parser for a single stream
+func (p *OpenAIStreamChunkParser) ForStream() StreamChunkParser {
+	return &OpenAIStreamChunkParser{ExtractThinkTags: p.ExtractThinkTags}
+}
 
 // ParseChunk converts standard OpenAI chunk to generic StreamChunk
 func (p *OpenAIStreamChunkParser) ParseChunk(data []byte) (*StreamChunk, error) {
@@ -19,6 +38,9 @@ func (p *OpenAIStreamChunkParser) ParseChunk(data []byte) (*StreamChunk, error)
 			} `json:"delta"`
 			FinishReason string `json:"finish_reason,omitempty"`
 		} `json:"choices"`
+		Usage *struct {
+			TotalTokens int `json:"total_tokens"`
+		} `json:"usage,omitempty"`
 	}
 
 	if err := json.Unmarshal(data, &rawChunk); err != nil {
@@ -36,9 +58,62 @@ func (p *OpenAIStreamChunkParser) ParseChunk(data []byte) (*StreamChunk, error)
 		chunk.Done = rawChunk.Choices[0].FinishReason != ""
 	}
 
+	if p.ExtractThinkTags {
+		chunk.Content, chunk.ThinkingContent = p.splitThinking(chunk.Content, chunk.Done)
+	}
+
+	if rawChunk.Usage != nil {
+		chunk.TotalTokens = rawChunk.Usage.TotalTokens
+	}
+
 	return chunk, nil
 }
 
+// splitThinking separates a content delta into answer content and <think> content.
+// A trailing partial tag is held back until the next delta; flush releases it.
+func (p *OpenAIStreamChunkParser) splitThinking(delta string, flush bool) (content, thinking string) {
+	text := p.pending + delta
+	p.pending = ""
+
+	var contentBuf, thinkingBuf strings.Builder
+	for text != "" {
+		tag := thinkOpenTag
+		out := &contentBuf
+		if p.inThink {
+			tag = thinkCloseTag
+			out = &thinkingBuf
+		}
+
+		if i := strings.Index(text, tag); i >= 0 {
+			out.WriteString(text[:i])
+			text = text[i+len(tag):]
+			p.inThink = !p.inThink
+			continue
+		}
+
+		// Hold back a suffix that could be the beginning of the tag
+		keep := partialTagSuffix(text, tag)
+		if flush {
+			keep = 0
+		}
+		out.WriteString(text[:len(text)-keep])
+		p.pending = text[len(text)-keep:]
+		break
+	}
+
+	return contentBuf.String(), thinkingBuf.String()
+}
+
+// partialTagSuffix returns the length of the longest suffix of text that is a proper prefix of tag
+func partialTagSuffix(text, tag string) int {
+	for n := len(tag) - 1; n > 0; n-- {
+		if strings.HasSuffix(text, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
 // IsOpenAIProvider checks if the base URL is official OpenAI API
 func IsOpenAIProvider(baseURL string) bool {
 	return strings.Contains(baseURL, "api.openai.com")
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProviderOverride selects a provider, chat model, and endpoint for a one-off intent parse.
+// Empty fields keep the server's configured values.
+type ProviderOverride struct {
+	Provider string
+	Model    string
+	APIBase  string
+	APIKey   string
+}
+
+// WithProvider returns a temporary client for comparing providers and models. It copies the
+// server's configuration and prompt examples but has its own HTTP client and token budget,
+// so using it never touches the configured client; callers drop it after the call.
+// The configured API key is only reused for the configured endpoint, so it can't be sent
+// to another host.
+func (c *OpenAIClient) WithProvider(override ProviderOverride) (*OpenAIClient, error) {
+	cfg := *c.config
+	if override.APIBase != "" && strings.TrimRight(override.APIBase, "/") != strings.TrimRight(c.config.APIBase, "/") {
+		if override.APIKey == "" {
+			return nil, fmt.Errorf("api_key is required when api_base differs from the configured endpoint")
+		}
+		// Provider detection and extra_body are specific to the configured endpoint
+		cfg.APIBase = override.APIBase
+		cfg.Provider = ""
+		cfg.ChatExtraBody = ""
+	}
+	if override.APIKey != "" {
+		cfg.APIKey = override.APIKey
+	}
+	if override.Provider != "" {
+		cfg.Provider = override.Provider
+	}
+	if override.Model != "" {
+		cfg.ChatModel = override.Model
+	}
+	cfg.Enabled = cfg.APIKey != ""
+	cfg.TokenBudget = 0
+	cfg.EmbeddingFallbackAPIBase = ""
+
+	client := NewOpenAIClient(&cfg)
+	client.fewShotExamples = c.fewShotExamples
+	client.intentPrompt = c.intentPrompt
+	return client, nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+
+	"core/internal/config"
+)
+
+func TestWithProviderLeavesConfiguredClientUntouched(t *testing.T) {
+	base := NewOpenAIClient(&config.OpenAIConfig{
+		APIBase:   "https://integrate.api.nvidia.com/v1",
+		APIKey:    "server-key",
+		ChatModel: "deepseek-ai/deepseek-v3.1-terminus",
+		Enabled:   true,
+	})
+	base.SetFewShotExamples([]FewShotExample{{Query: "2 bed", Response: []byte(`{"bedrooms": 2}`)}})
+
+	client, err := base.WithProvider(ProviderOverride{Model: "meta/llama-3.1-70b-instruct"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if client.config.ChatModel != "meta/llama-3.1-70b-instruct" || client.config.APIKey != "server-key" {
+		t.Errorf("Expected overridden model on the configured endpoint, got %s", client.config.ChatModel)
+	}
+	if client.fewShotExamples != base.fewShotExamples {
+		t.Error("Expected the temporary client to use the configured prompt examples")
+	}
+	if base.config.ChatModel != "deepseek-ai/deepseek-v3.1-terminus" {
+		t.Errorf("Expected configured client unchanged, got model %s", base.config.ChatModel)
+	}
+}
+
+func TestWithProviderDoesNotSendServerKeyElsewhere(t *testing.T) {
+	base := NewOpenAIClient(&config.OpenAIConfig{APIBase: "https://integrate.api.nvidia.com/v1", APIKey: "server-key", Enabled: true})
+
+	if _, err := base.WithProvider(ProviderOverride{APIBase: "https://api.openai.com/v1"}); err == nil {
+		t.Error("Expected an error when a different api_base has no api_key")
+	}
+
+	client, err := base.WithProvider(ProviderOverride{APIBase: "https://api.openai.com/v1", APIKey: "other-key"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if client.config.APIKey != "other-key" || client.config.APIBase != "https://api.openai.com/v1" {
+		t.Errorf("Expected the supplied key and endpoint, got %s", client.config.APIBase)
+	}
+
+	// The configured endpoint, spelled with a trailing slash, may reuse the server key
+	if _, err := base.WithProvider(ProviderOverride{APIBase: "https://integrate.api.nvidia.com/v1/"}); err != nil {
+		t.Errorf("Expected the configured endpoint to reuse the server key, got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"core/internal/config"
+	"core/internal/utils"
+)
+
+func TestIsNVIDIAProvider(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    bool
+	}{
+		{"https://integrate.api.nvidia.com/v1", true},
+		{"https://integrate.api.nvidia.com/v1/", true},
+		{"http://integrate.api.nvidia.com/v1", true},
+		{"https://integrate.api.nvidia.com/v1/chat/completions", true},
+		{"https://INTEGRATE.API.NVIDIA.COM", true},
+		{"integrate.api.nvidia.com/v1", true},
+		{"https://ai.api.nvidia.com:443/v1", true},
+		{"https://api.openai.com/v1", false},
+		{"https://nvidia.com.evil.example/v1", false},
+		{"https://notnvidia.com/v1", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.baseURL, func(t *testing.T) {
+			if got := IsNVIDIAProvider(tt.baseURL); got != tt.want {
+				t.Errorf("IsNVIDIAProvider(%q) = %v, want %v", tt.baseURL, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewChunkParser(t *testing.T) {
+	if _, ok := newChunkParser("", "https://integrate.api.nvidia.com/v1/", false).(*NVIDIAStreamChunkParser); !ok {
+		t.Error("Expected NVIDIA parser to be auto-detected")
+	}
+	if _, ok := newChunkParser("nvidia", "https://my-proxy.internal/v1", false).(*NVIDIAStreamChunkParser); !ok {
+		t.Error("Expected configured NVIDIA parser")
+	}
+	if _, ok := newChunkParser("openai", "https://integrate.api.nvidia.com/v1", false).(*OpenAIStreamChunkParser); !ok {
+		t.Error("Expected configured provider to override detection")
+	}
+	if _, ok := newChunkParser("bogus", "https://api.openai.com/v1", false).(*OpenAIStreamChunkParser); !ok {
+		t.Error("Expected unknown provider to fall back to detection")
+	}
+	if _, ok := newChunkParser("", "http://localhost:11434/v1", false).(*OllamaStreamChunkParser); !ok {
+		t.Error("Expected Ollama parser to be auto-detected")
+	}
+	if _, ok := newChunkParser("ollama", "http://gpu-box.lan:8000", false).(*OllamaStreamChunkParser); !ok {
+		t.Error("Expected configured Ollama parser")
+	}
+}
+
+func TestIsOllamaProvider(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    bool
+	}{
+		{"http://localhost:11434", true},
+		{"http://localhost:11434/v1", true},
+		{"http://127.0.0.1:11434/api/chat", true},
+		{"localhost:11434", true},
+		{"https://ollama.example.com/api/chat/", true},
+		{"http://localhost:8080/v1", false},
+		{"https://api.openai.com/v1", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.baseURL, func(t *testing.T) {
+			if got := IsOllamaProvider(tt.baseURL); got != tt.want {
+				t.Errorf("IsOllamaProvider(%q) = %v, want %v", tt.baseURL, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOllamaChatURL(t *testing.T) {
+	tests := map[string]string{
+		"http://localhost:11434":           "http://localhost:11434/api/chat",
+		"http://localhost:11434/":          "http://localhost:11434/api/chat",
+		"http://localhost:11434/v1":        "http://localhost:11434/api/chat",
+		"http://localhost:11434/api":       "http://localhost:11434/api/chat",
+		"http://localhost:11434/api/chat/": "http://localhost:11434/api/chat",
+	}
+	for base, want := range tests {
+		if got := ollamaChatURL(base); got != want {
+			t.Errorf("ollamaChatURL(%q) = %q, want %q", base, got, want)
+		}
+	}
+}
+
+func TestOllamaChatCompletionStream(t *testing.T) {
+	var path string
+	var body ollamaChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte(`{"model":"qwen2.5","message":{"role":"assistant","content":"{\"bedrooms\": "},"done":false}` + "\n"))
+		w.Write([]byte(`{"model":"qwen2.5","message":{"role":"assistant","content":"3}"},"done":false}` + "\n"))
+		w.Write([]byte(`{"model":"qwen2.5","message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":120,"eval_count":8}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(&config.OpenAIConfig{
+		Enabled:   true,
+		APIKey:    "ollama",
+		APIBase:   server.URL + "/v1",
+		Provider:  ProviderOllama,
+		ChatModel: "qwen2.5",
+		Timeout:   5,
+	})
+
+	req := ChatCompletionRequest{
+		Messages: []ChatMessage{
+			{Role: "system", Content: "Parse the query."},
+			{Role: "user", Content: "3 bedroom condo"},
+		},
+		ResponseFormat: &ResponseFormat{Type: "json_object"},
+	}
+	var content strings.Builder
+	tokens := 0
+	err := client.ChatCompletionStream(context.Background(), req, func(chunk *StreamChunk) error {
+		content.WriteString(chunk.Content)
+		tokens += chunk.TotalTokens
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+
+	if path != "/api/chat" {
+		t.Errorf("Expected request to /api/chat, got %s", path)
+	}
+	if !body.Stream || body.Format != "json" {
+		t.Errorf("Expected a streaming JSON-format request, got stream=%v format=%q", body.Stream, body.Format)
+	}
+	if len(body.Messages) != 2 || !strings.HasSuffix(body.Messages[0].Content, ollamaJSONOnlyInstruction) {
+		t.Errorf("Expected the JSON-only instruction on the system prompt, got %+v", body.Messages)
+	}
+	if got := content.String(); got != `{"bedrooms": 3}` {
+		t.Errorf("Expected streamed content to be joined, got %q", got)
+	}
+	if tokens != 128 {
+		t.Errorf("Expected 128 tokens from the final chunk, got %d", tokens)
+	}
+}
+
+func TestOpenAIStreamChunkParser_ExtractThinkTags(t *testing.T) {
+	deltas := []string{
+		`<thi`,
+		`nk>The user wants `,
+		`a condo.</th`,
+		`ink>{"bedrooms": 3, `,
+		`"unit_type": "Condo"}`,
+	}
+
+	parser := (&OpenAIStreamChunkParser{ExtractThinkTags: true}).ForStream()
+	var content, thinking strings.Builder
+	for i, delta := range deltas {
+		finish := ""
+		if i == len(deltas)-1 {
+			finish = "stop"
+		}
+		data, _ := json.Marshal(map[string]any{
+			"choices": []map[string]any{{"delta": map[string]string{"content": delta}, "finish_reason": finish}},
+		})
+		chunk, err := parser.ParseChunk(data)
+		if err != nil {
+			t.Fatalf("ParseChunk(%q) failed: %v", delta, err)
+		}
+		content.WriteString(chunk.Content)
+		thinking.WriteString(chunk.ThinkingContent)
+	}
+
+	if got := content.String(); got != `{"bedrooms": 3, "unit_type": "Condo"}` {
+		t.Errorf("content = %q", got)
+	}
+	if got := thinking.String(); got != "The user wants a condo." {
+		t.Errorf("thinking = %q", got)
+	}
+	var parsed AIIntentResponse
+	if err := utils.ParseAIJSON(content.String(), &parsed); err != nil {
+		t.Errorf("Expected content to parse as JSON, got %v", err)
+	}
+}
+
+func TestOpenAIStreamChunkParser_ThinkTagsDisabled(t *testing.T) {
+	parser := &OpenAIStreamChunkParser{}
+	chunk, err := parser.ParseChunk([]byte(`{"choices":[{"delta":{"content":"<think>hmm</think>{}"}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chunk.Content != "<think>hmm</think>{}" || chunk.ThinkingContent != "" {
+		t.Errorf("Expected content untouched, got content=%q thinking=%q", chunk.Content, chunk.ThinkingContent)
+	}
+}
+
+func TestOpenAIStreamChunkParser_FlushesPartialTagOnDone(t *testing.T) {
+	parser := &OpenAIStreamChunkParser{ExtractThinkTags: true}
+	chunk, _ := parser.ParseChunk([]byte(`{"choices":[{"delta":{"content":"price <"}}]}`))
+	if chunk.Content != "price " {
+		t.Errorf("Expected a possible tag start held back, got %q", chunk.Content)
+	}
+	chunk, _ = parser.ParseChunk([]byte(`{"choices":[{"delta":{"content":"thi"},"finish_reason":"stop"}]}`))
+	if chunk.Content != "<thi" {
+		t.Errorf("Expected held-back text released, got %q", chunk.Content)
+	}
+}
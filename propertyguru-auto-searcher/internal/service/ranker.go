@@ -1,11 +1,15 @@
 package service
 
 import (
+	"fmt"
 	"math"
 	"sort"
+	"strings"
 	"time"
 
+	"core/internal/config"
 	"core/internal/model"
+	"core/internal/utils"
 )
 
 // Match reason constants
@@ -17,33 +21,148 @@ const (
 	ReasonLocationMatch   = "Location match"
 	ReasonPriceMatch      = "Price within budget"
 	ReasonContentRelevant = "Content relevant"
+	ReasonTitleMatch      = "Title match"
+	ReasonAmenitiesMatch  = "Has requested amenities"
 	ReasonNewlyListed     = "Newly listed"
 	ReasonHighGreenScore  = "High green score"
+	ReasonCompleteListing = "Complete listing"
+	ReasonLeaseRemaining  = "Lease remaining"
+	ReasonGoodValue       = "Good value"
 	ReasonGeneralMatch    = "General match"
 )
 
+// reasonPriority orders reasons from most to least informative; when reasons are
+// capped, the ones listed first are kept
+var reasonPriority = []string{
+	ReasonUnitTypeMatch,
+	ReasonLocationMatch,
+	ReasonBedroomsMatch,
+	ReasonPriceMatch,
+	ReasonGoodValue,
+	ReasonNearMRT,
+	ReasonLeaseRemaining,
+	ReasonAmenitiesMatch,
+	ReasonTitleMatch,
+	ReasonBathroomsMatch,
+	ReasonNewlyListed,
+	ReasonHighGreenScore,
+	ReasonCompleteListing,
+	ReasonContentRelevant,
+	ReasonGeneralMatch,
+}
+
 // Ranker handles ranking and scoring of search results
 type Ranker struct {
 	weightText    float64
 	weightPrice   float64
 	weightRecency float64
+	weightTitle   float64 // Max boost for keywords found verbatim in the title
+	weightAmenity float64 // Max boost for matching every requested amenity/facility (match_mode "any" only)
+	maxReasons    int     // Max matched reasons per result (0 = unlimited)
+
+	weightCompleteness float64 // Max boost for listings with every key field populated
+	weightValue        float64 // Max boost for a low price per sqft on budget-conscious searches
+
+	vectorRecencyWeight float64 // Share of a vector search score taken by recency (0 = pure similarity)
+	thresholds          ReasonThresholds
 }
 
-// NewRanker creates a new ranker with specified weights
-func NewRanker(weightText, weightPrice, weightRecency float64) *Ranker {
+// ReasonThresholds are the cutoffs at which score-based matched reasons are shown
+type ReasonThresholds struct {
+	TextScore     float64 // "Content relevant" when the normalized text score is above this
+	PriceScore    float64 // "Price within budget" when the price score is above this
+	NewListedDays int     // "Newly listed" when listed fewer than this many days ago
+	GreenScore    float64 // "High green score" when the green score is at least this
+}
+
+// NewRanker creates a new ranker with the configured weights and reason thresholds
+func NewRanker(cfg *config.RankingConfig) *Ranker {
 	return &Ranker{
-		weightText:    weightText,
-		weightPrice:   weightPrice,
-		weightRecency: weightRecency,
+		weightText:          cfg.WeightText,
+		weightPrice:         cfg.WeightPrice,
+		weightRecency:       cfg.WeightRecency,
+		weightTitle:         cfg.WeightTitle,
+		weightAmenity:       cfg.WeightAmenities,
+		weightCompleteness:  cfg.WeightCompleteness,
+		weightValue:         cfg.WeightValue,
+		maxReasons:          cfg.MaxReasons,
+		vectorRecencyWeight: cfg.VectorRecencyWeight,
+		thresholds: ReasonThresholds{
+			TextScore:     cfg.ReasonTextScore,
+			PriceScore:    cfg.ReasonPriceScore,
+			NewListedDays: cfg.ReasonNewListedDays,
+			GreenScore:    cfg.ReasonGreenScore,
+		},
+	}
+}
+
+// WithWeights returns a copy of the ranker with the text, price, and recency weights
+// overridden where given and the three normalized to sum to 1. Without overrides, or when
+// every weight comes out zero, the ranker itself is returned.
+func (r *Ranker) WithWeights(text, price, recency *float64) *Ranker {
+	if text == nil && price == nil && recency == nil {
+		return r
 	}
+
+	weighted := *r
+	if text != nil {
+		weighted.weightText = *text
+	}
+	if price != nil {
+		weighted.weightPrice = *price
+	}
+	if recency != nil {
+		weighted.weightRecency = *recency
+	}
+	total := weighted.weightText + weighted.weightPrice + weighted.weightRecency
+	if total <= 0 {
+		return r
+	}
+	weighted.weightText /= total
+	weighted.weightPrice /= total
+	weighted.weightRecency /= total
+	return &weighted
 }
 
-// RankResults scores and ranks search results
+// lowValueKeywords are too generic to count as a title match
+var lowValueKeywords = map[string]bool{
+	"the": true, "and": true, "with": true, "for": true, "near": true,
+	"new": true, "good": true, "nice": true, "unit": true, "house": true,
+}
+
+// maxTitleKeywordWords skips long phrases such as the raw query appended to the keywords
+const maxTitleKeywordWords = 3
+
+// RankResults scores and ranks search results. Equal scores are ordered by listing_id, so
+// the same listings always come back in the same order.
 func (r *Ranker) RankResults(
 	listings []model.Listing,
 	textRanks map[int64]float64,
 	filters *model.SearchFilters,
+	keywords []string,
 ) []model.ListingSearchResult {
+	results := r.ScoreResults(listings, textRanks, filters, keywords)
+
+	// Sort by score descending, then listing_id for a total order
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ListingID < results[j].ListingID
+	})
+
+	return results
+}
+
+// ScoreResults scores search results and generates matched reasons, preserving input order
+func (r *Ranker) ScoreResults(
+	listings []model.Listing,
+	textRanks map[int64]float64,
+	filters *model.SearchFilters,
+	keywords []string,
+) []model.ListingSearchResult {
+	titleKeywords := highValueKeywords(keywords)
+	valueReference := r.valueReference(listings, filters, keywords)
 	results := make([]model.ListingSearchResult, 0, len(listings))
 
 	for _, listing := range listings {
@@ -62,25 +181,99 @@ func (r *Ranker) RankResults(
 		// Calculate recency score (normalized to 0-1)
 		recencyScore := r.calculateRecencyScore(listing.ListedDate)
 
-		// Combined weighted score
+		// Calculate title keyword score (fraction of high-value keywords in the title, 0-1)
+		titleScore := r.calculateTitleScore(listing.Title, titleKeywords)
+
+		// Calculate amenity score (fraction of requested amenities/facilities present, 0-1)
+		amenityScore := r.calculateAmenityScore(listing, filters)
+
+		// Calculate data completeness score (fraction of key fields populated, 0-1)
+		completenessScore := calculateCompletenessScore(listing)
+
+		// Calculate value score (how far the psf is below the reference, 0-1; 0 unless budget-conscious)
+		valueScore := calculateValueScore(listing.PricePerSqft, valueReference)
+
+		// Combined weighted score; the title, amenity, completeness, and value boosts are bounded by their weights
 		result.Score = (r.weightText * textScore) +
 			(r.weightPrice * priceScore) +
-			(r.weightRecency * recencyScore)
+			(r.weightRecency * recencyScore) +
+			(r.weightTitle * titleScore) +
+			(r.weightAmenity * amenityScore) +
+			(r.weightCompleteness * completenessScore) +
+			(r.weightValue * valueScore)
 
 		// Generate matched reasons
-		result.MatchedReasons = r.generateMatchedReasons(listing, filters, textScore, priceScore)
+		reasons := r.generateMatchedReasons(listing, filters, textScore, priceScore)
+		if titleScore > 0 {
+			reasons = append(reasons, ReasonTitleMatch)
+		}
+		if amenityScore > 0 {
+			reasons = append(reasons, ReasonAmenitiesMatch)
+		}
+		if r.weightCompleteness > 0 && completenessScore == 1 {
+			reasons = append(reasons, ReasonCompleteListing)
+		}
+		if valueScore >= goodValueScore {
+			reasons = append(reasons, ReasonGoodValue)
+		}
+		result.MatchedReasons = r.finalizeReasons(reasons)
 
 		results = append(results, result)
 	}
 
-	// Sort by score descending
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score > results[j].Score
+	return results
+}
+
+// vectorRelevantSimilarity is the similarity above which a vector match counts as content relevant
+const vectorRelevantSimilarity = 0.75
+
+// RankVectorResults scores vector search results by similarity to the query, nudged by
+// recency per vectorRecencyWeight so equally similar listings favor newer ones.
+// Ties fall back to the newer listing, then listing_id, so the order is stable.
+func (r *Ranker) RankVectorResults(listings []model.Listing, filters *model.SearchFilters) []model.ListingSearchResult {
+	results := make([]model.ListingSearchResult, 0, len(listings))
+	for _, listing := range listings {
+		similarity := vectorSimilarity(listing.VectorDistance)
+		recencyScore := r.calculateRecencyScore(listing.ListedDate)
+		priceScore := r.calculatePriceScore(listing.Price, filters)
+
+		reasons := r.generateMatchedReasons(listing, filters, 0, priceScore)
+		if similarity >= vectorRelevantSimilarity {
+			reasons = append(reasons, ReasonContentRelevant)
+		}
+
+		results = append(results, model.ListingSearchResult{
+			Listing:        listing,
+			Score:          (1-r.vectorRecencyWeight)*similarity + r.vectorRecencyWeight*recencyScore,
+			MatchedReasons: r.finalizeReasons(reasons),
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		a, b := results[i].ListedDate, results[j].ListedDate
+		if a != nil && b != nil && !a.Equal(*b) {
+			return a.After(*b)
+		}
+		if (a == nil) != (b == nil) {
+			return a != nil
+		}
+		return results[i].ListingID < results[j].ListingID
 	})
 
 	return results
 }
 
+// vectorSimilarity maps a cosine distance (0-2) to a 0-1 similarity; a missing distance scores 0
+func vectorSimilarity(distance *float64) float64 {
+	if distance == nil {
+		return 0
+	}
+	return math.Max(0, math.Min(1, 1-*distance/2))
+}
+
 // normalizeTextScore normalizes PostgreSQL ts_rank score to 0-1 range
 func (r *Ranker) normalizeTextScore(rank float64) float64 {
 	// ts_rank typically returns values between 0 and 1, but can go higher
@@ -91,12 +284,144 @@ func (r *Ranker) normalizeTextScore(rank float64) float64 {
 	return rank
 }
 
-// calculatePriceScore calculates how well the price matches user's budget
+// highValueKeywords lowercases and filters keywords down to specific terms worth matching in titles
+func highValueKeywords(keywords []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, keyword := range keywords {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+		if len(keyword) < 3 || lowValueKeywords[keyword] || seen[keyword] {
+			continue
+		}
+		if len(strings.Fields(keyword)) > maxTitleKeywordWords {
+			continue
+		}
+		seen[keyword] = true
+		result = append(result, keyword)
+	}
+	return result
+}
+
+// minCompleteDescriptionChars is the description length that counts as a full description
+// rather than a one-line blurb
+const minCompleteDescriptionChars = 200
+
+// calculateCompletenessScore returns the fraction of key fields populated: photos, a full
+// description, coordinates, a green score, and the floor area. Fields left out of a sparse
+// fieldset count as missing, which affects every listing in the search alike.
+func calculateCompletenessScore(listing model.Listing) float64 {
+	populated := []bool{
+		len(listing.Images) > 0,
+		listing.Description != nil && len(strings.TrimSpace(*listing.Description)) >= minCompleteDescriptionChars,
+		listing.Latitude != nil && listing.Longitude != nil,
+		listing.GreenScoreValue != nil,
+		listing.AreaSqft != nil && *listing.AreaSqft > 0,
+	}
+	count := 0
+	for _, ok := range populated {
+		if ok {
+			count++
+		}
+	}
+	return float64(count) / float64(len(populated))
+}
+
+// calculateTitleScore returns the fraction of keywords that appear in the title (case-insensitive)
+func (r *Ranker) calculateTitleScore(title *string, keywords []string) float64 {
+	if len(keywords) == 0 || title == nil || *title == "" {
+		return 0
+	}
+	lowerTitle := strings.ToLower(*title)
+	matched := 0
+	for _, keyword := range keywords {
+		if strings.Contains(lowerTitle, keyword) {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(keywords))
+}
+
+// calculateAmenityScore returns the fraction of requested amenities and facilities the
+// listing has. Only "any" match mode is scored: in "all" mode every result has them all.
+func (r *Ranker) calculateAmenityScore(listing model.Listing, filters *model.SearchFilters) float64 {
+	if !filters.MatchesAnyAmenity() {
+		return 0
+	}
+	requested := len(filters.Amenities) + len(filters.Facilities)
+	if requested == 0 {
+		return 0
+	}
+	matched := utils.CountAmenityMatches(filters.Amenities, listing.Amenities) +
+		utils.CountAmenityMatches(filters.Facilities, listing.Facilities)
+	return float64(matched) / float64(requested)
+}
+
+// budgetKeywords mark a search as budget-conscious, turning on the price per sqft value boost
+var budgetKeywords = map[string]bool{
+	"cheap": true, "affordable": true, "budget": true, "value": true, "good value": true,
+	"value for money": true, "bargain": true, "good deal": true, "low psf": true, "undervalued": true,
+}
+
+// goodValueScore is the value score (psf at least this fraction below the reference) at
+// which "Good value" is shown
+const goodValueScore = 0.2
+
+// valueReference returns the price per sqft that value scores are measured against, or 0
+// when the search isn't budget-conscious: the psf cap when one is set, else the highest psf
+// among listings when the keywords ask for a bargain
+func (r *Ranker) valueReference(listings []model.Listing, filters *model.SearchFilters, keywords []string) float64 {
+	if r.weightValue <= 0 {
+		return 0
+	}
+	if filters != nil && filters.PricePerSqftMax != nil {
+		return *filters.PricePerSqftMax
+	}
+
+	budgetConscious := false
+	for _, keyword := range keywords {
+		if budgetKeywords[strings.ToLower(strings.TrimSpace(keyword))] {
+			budgetConscious = true
+			break
+		}
+	}
+	if !budgetConscious {
+		return 0
+	}
+	reference := 0.0
+	for _, listing := range listings {
+		if listing.PricePerSqft != nil && *listing.PricePerSqft > reference {
+			reference = *listing.PricePerSqft
+		}
+	}
+	return reference
+}
+
+// calculateValueScore returns how far a listing's price per sqft is below the reference,
+// as a fraction of it (0-1). Listings without a psf, or searches without a reference, score 0.
+func calculateValueScore(pricePerSqft *float64, reference float64) float64 {
+	if reference <= 0 || pricePerSqft == nil || *pricePerSqft <= 0 {
+		return 0
+	}
+	return math.Max(0, math.Min(1, 1-*pricePerSqft/reference))
+}
+
+// priceTargetSpread is the standard deviation of the price target curve as a fraction of
+// the target: a listing 10% off the target scores about 0.61, 20% off about 0.14
+const priceTargetSpread = 0.1
+
+// calculatePriceScore calculates how well the price matches user's budget. A price target
+// scores by closeness to it (a Gaussian around the target) instead of the range midpoint.
 func (r *Ranker) calculatePriceScore(price *float64, filters *model.SearchFilters) float64 {
 	if price == nil {
 		return 0.5 // Neutral score if no price
 	}
 
+	if filters != nil && filters.PriceTarget != nil && *filters.PriceTarget > 0 {
+		sigma := *filters.PriceTarget * priceTargetSpread
+		distance := *price - *filters.PriceTarget
+		return math.Exp(-distance * distance / (2 * sigma * sigma))
+	}
+
 	if filters == nil || (filters.PriceMin == nil && filters.PriceMax == nil) {
 		return 1.0 // Full score if no price filter
 	}
@@ -188,15 +513,15 @@ func (r *Ranker) generateMatchedReasons(
 
 	// Check filter matches
 	if filters != nil {
-		if filters.Bedrooms != nil && listing.Bedrooms != nil && *listing.Bedrooms == *filters.Bedrooms {
+		if roomCountMatches(listing.Bedrooms, filters.Bedrooms, filters.BedroomsMin) {
 			reasons = append(reasons, ReasonBedroomsMatch)
 		}
 
-		if filters.Bathrooms != nil && listing.Bathrooms != nil && *listing.Bathrooms == *filters.Bathrooms {
+		if roomCountMatches(listing.Bathrooms, filters.Bathrooms, filters.BathroomsMin) {
 			reasons = append(reasons, ReasonBathroomsMatch)
 		}
 
-		if filters.UnitType != nil && listing.UnitType != nil {
+		if listing.UnitType != nil && anyUnitTypeMatches(filters.AllUnitTypes(), *listing.UnitType) {
 			reasons = append(reasons, ReasonUnitTypeMatch)
 		}
 
@@ -204,30 +529,38 @@ func (r *Ranker) generateMatchedReasons(
 			reasons = append(reasons, ReasonNearMRT)
 		}
 
-		if filters.Location != nil && listing.Location != nil {
-			reasons = append(reasons, ReasonLocationMatch)
+		if listing.Location != nil {
+			if reason := locationReason(filters.AllLocations(), *listing.Location); reason != "" {
+				reasons = append(reasons, reason)
+			} else if reason := districtReason(filters.District, *listing.Location); reason != "" {
+				reasons = append(reasons, reason)
+			}
+		}
+
+		if reason := leaseReason(filters.LeaseRemainingMin, listing); reason != "" {
+			reasons = append(reasons, reason)
 		}
 
-		if priceScore > 0.8 {
+		if priceScore > r.thresholds.PriceScore {
 			reasons = append(reasons, ReasonPriceMatch)
 		}
 	}
 
 	// Check text relevance
-	if textScore > 0.1 {
+	if textScore > r.thresholds.TextScore {
 		reasons = append(reasons, ReasonContentRelevant)
 	}
 
 	// Check recency
 	if listing.ListedDate != nil {
 		daysSince := time.Since(*listing.ListedDate).Hours() / 24
-		if daysSince < 7 {
+		if daysSince < float64(r.thresholds.NewListedDays) {
 			reasons = append(reasons, ReasonNewlyListed)
 		}
 	}
 
 	// Check special features
-	if listing.GreenScoreValue != nil && *listing.GreenScoreValue >= 4.0 {
+	if listing.GreenScoreValue != nil && *listing.GreenScoreValue >= r.thresholds.GreenScore {
 		reasons = append(reasons, ReasonHighGreenScore)
 	}
 
@@ -237,3 +570,126 @@ func (r *Ranker) generateMatchedReasons(
 
 	return reasons
 }
+
+// roomCountMatches reports whether a listing's bedroom or bathroom count meets the filter:
+// equal to the exact count and at least the minimum, whichever are set
+func roomCountMatches(listing, exact, minimum *int) bool {
+	if listing == nil || (exact == nil && minimum == nil) {
+		return false
+	}
+	return (exact == nil || *listing == *exact) && (minimum == nil || *listing >= *minimum)
+}
+
+// anyUnitTypeMatches reports whether a listing's unit type is any of the requested ones
+func anyUnitTypeMatches(filters []string, listing string) bool {
+	for _, filter := range filters {
+		if unitTypeMatches(filter, listing) {
+			return true
+		}
+	}
+	return false
+}
+
+// unitTypeMatches reports whether a listing's unit type is the one the filter asked for.
+// Mirrors the repository filter: canonical types are compared when the filter normalizes,
+// otherwise the raw value must contain the filter (the ILIKE fallback).
+func unitTypeMatches(filter, listing string) bool {
+	if filterType := utils.NormalizeUnitType(filter); filterType != "" {
+		return utils.NormalizeUnitType(listing) == filterType
+	}
+	return containsFold(listing, filter)
+}
+
+// locationMatches reports whether a listing's location contains any spelling of the
+// filter's area, the same patterns the repository's location ILIKE filter uses
+func locationMatches(filter, listing string) bool {
+	for _, pattern := range utils.ExpandLocation(filter) {
+		if containsFold(listing, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// locationReason returns the location matched reason for a listing. With several requested
+// areas it names the one matched ("Location match: Punggol"); otherwise it's ReasonLocationMatch.
+func locationReason(locations []string, listing string) string {
+	for _, location := range locations {
+		if !locationMatches(location, listing) {
+			continue
+		}
+		if len(locations) > 1 {
+			return ReasonLocationMatch + ": " + location
+		}
+		return ReasonLocationMatch
+	}
+	return ""
+}
+
+// districtReason returns the location matched reason naming the district ("Location match:
+// D09") for a listing in one of the requested district's areas
+func districtReason(district *string, listing string) string {
+	if district == nil {
+		return ""
+	}
+	for _, pattern := range utils.ExpandDistrict(*district) {
+		if containsFold(listing, pattern) {
+			return ReasonLocationMatch + ": " + utils.CanonicalDistrict(*district)
+		}
+	}
+	return ""
+}
+
+// leaseReason returns the lease matched reason for a listing that meets a minimum remaining
+// lease, naming what is left ("Lease remaining: 85 years", "Lease remaining: freehold")
+func leaseReason(minYears *int, listing model.Listing) string {
+	if minYears == nil {
+		return ""
+	}
+	if listing.IsFreehold() {
+		return ReasonLeaseRemaining + ": freehold"
+	}
+	if listing.LeaseRemainingYears != nil && *listing.LeaseRemainingYears >= *minYears {
+		return fmt.Sprintf("%s: %d years", ReasonLeaseRemaining, *listing.LeaseRemainingYears)
+	}
+	return ""
+}
+
+// containsFold reports whether substr is within s, ignoring case
+func containsFold(s, substr string) bool {
+	substr = strings.TrimSpace(substr)
+	return substr != "" && strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// finalizeReasons dedupes reasons, orders them by reasonPriority, and applies the cap
+func (r *Ranker) finalizeReasons(reasons []string) []string {
+	seen := make(map[string]bool, len(reasons))
+	unique := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		if !seen[reason] {
+			seen[reason] = true
+			unique = append(unique, reason)
+		}
+	}
+
+	sort.SliceStable(unique, func(i, j int) bool {
+		return reasonRank(unique[i]) < reasonRank(unique[j])
+	})
+
+	if r.maxReasons > 0 && len(unique) > r.maxReasons {
+		unique = unique[:r.maxReasons]
+	}
+	return unique
+}
+
+// reasonRank returns a reason's position in reasonPriority; unknown reasons sort last.
+// Reasons naming a value ("Location match: Punggol") rank with their base reason.
+func reasonRank(reason string) int {
+	reason, _, _ = strings.Cut(reason, ": ")
+	for i, known := range reasonPriority {
+		if reason == known {
+			return i
+		}
+	}
+	return len(reasonPriority)
+}
@@ -0,0 +1,501 @@
+package service
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"core/internal/config"
+	"core/internal/model"
+)
+
+// testRankingConfig returns the weights and reason thresholds the ranker tests start from
+func testRankingConfig() *config.RankingConfig {
+	return &config.RankingConfig{
+		WeightText:          0.5,
+		WeightPrice:         0.3,
+		WeightRecency:       0.2,
+		WeightTitle:         0.15,
+		WeightAmenities:     0.2,
+		ReasonTextScore:     0.1,
+		ReasonPriceScore:    0.8,
+		ReasonNewListedDays: 7,
+		ReasonGreenScore:    4.0,
+	}
+}
+
+func TestRanker_TitleMatchBoost(t *testing.T) {
+	ranker := NewRanker(testRankingConfig())
+
+	titled := "The Sail @ Marina Bay Penthouse"
+	other := "Spacious Unit With Great Amenities"
+	listings := []model.Listing{
+		{ListingID: 1, Title: &other},
+		{ListingID: 2, Title: &titled},
+	}
+	textRanks := map[int64]float64{1: 0.8, 2: 0.8}
+
+	results := ranker.RankResults(listings, textRanks, nil, []string{"penthouse", "the", "penthouse with marina bay view please"})
+	if results[0].ListingID != 2 {
+		t.Fatalf("Expected titled listing first, got %d", results[0].ListingID)
+	}
+	if !containsReason(results[0].MatchedReasons, ReasonTitleMatch) {
+		t.Errorf("Expected %q in %v", ReasonTitleMatch, results[0].MatchedReasons)
+	}
+	if containsReason(results[1].MatchedReasons, ReasonTitleMatch) {
+		t.Errorf("Unexpected %q in %v", ReasonTitleMatch, results[1].MatchedReasons)
+	}
+
+	// Boost is bounded by the title weight
+	if diff := results[0].Score - results[1].Score; diff > 0.15+1e-9 {
+		t.Errorf("Title boost %.3f exceeds weight 0.15", diff)
+	}
+}
+
+func TestHighValueKeywords(t *testing.T) {
+	got := highValueKeywords([]string{"Penthouse", "penthouse", "the", "go", "sea view", "a very long raw user query"})
+	want := []string{"penthouse", "sea view"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("highValueKeywords() = %v, want %v", got, want)
+	}
+}
+
+func TestRanker_MatchedReasonsDedupedAndCapped(t *testing.T) {
+	cfg := testRankingConfig()
+	cfg.MaxReasons = 3
+	ranker := NewRanker(cfg)
+
+	bedrooms := 3
+	unitType := "Condominium"
+	location := "Tampines"
+	mrtDistance := 200
+	listedDate := time.Now()
+	listings := []model.Listing{{
+		ListingID: 1, Title: &location, Bedrooms: &bedrooms, UnitType: &unitType,
+		Location: &location, MRTDistanceM: &mrtDistance, ListedDate: &listedDate,
+	}}
+	maxDistance := 500
+	filters := &model.SearchFilters{
+		Bedrooms: &bedrooms, UnitType: model.StringOrList{"condo"}, Location: &location, MRTDistanceMax: &maxDistance,
+	}
+
+	results := ranker.ScoreResults(listings, map[int64]float64{1: 0.9}, filters, []string{"tampines"})
+	reasons := results[0].MatchedReasons
+
+	if len(reasons) != 3 {
+		t.Fatalf("Expected reasons capped at 3, got %v", reasons)
+	}
+	seen := make(map[string]bool)
+	for _, reason := range reasons {
+		if seen[reason] {
+			t.Errorf("Duplicate reason %q in %v", reason, reasons)
+		}
+		seen[reason] = true
+	}
+	// Most informative reasons are kept
+	want := []string{ReasonUnitTypeMatch, ReasonLocationMatch, ReasonBedroomsMatch}
+	for i, reason := range want {
+		if reasons[i] != reason {
+			t.Errorf("Expected reasons %v, got %v", want, reasons)
+			break
+		}
+	}
+}
+
+func TestFinalizeReasonsRemovesDuplicates(t *testing.T) {
+	ranker := NewRanker(testRankingConfig())
+	got := ranker.finalizeReasons([]string{ReasonNearMRT, ReasonContentRelevant, ReasonNearMRT, ReasonBedroomsMatch})
+	want := []string{ReasonBedroomsMatch, ReasonNearMRT, ReasonContentRelevant}
+	if len(got) != len(want) {
+		t.Fatalf("finalizeReasons() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("finalizeReasons() = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestRanker_UnitTypeReasonRequiresMatch(t *testing.T) {
+	ranker := NewRanker(testRankingConfig())
+
+	condo := "Condominium"
+	landed := "Semi-Detached House"
+	listings := []model.Listing{
+		{ListingID: 1, UnitType: &condo},
+		{ListingID: 2, UnitType: &landed},
+	}
+	filters := &model.SearchFilters{UnitType: model.StringOrList{"Condo"}}
+
+	results := ranker.ScoreResults(listings, nil, filters, nil)
+	if !containsReason(results[0].MatchedReasons, ReasonUnitTypeMatch) {
+		t.Errorf("Expected %q for a condo listing, got %v", ReasonUnitTypeMatch, results[0].MatchedReasons)
+	}
+	if containsReason(results[1].MatchedReasons, ReasonUnitTypeMatch) {
+		t.Errorf("Unexpected %q for a landed listing, got %v", ReasonUnitTypeMatch, results[1].MatchedReasons)
+	}
+}
+
+func TestUnitTypeMatches(t *testing.T) {
+	tests := []struct {
+		filter  string
+		listing string
+		want    bool
+	}{
+		{"Condo", "Condominium", true},
+		{"condo", "Apartment", true},
+		{"Condo", "Landed", false},
+		{"HDB", "Executive Condominium", false},
+		{"Studio", "Studio Loft", true}, // Not a canonical type: ILIKE-style containment
+		{"Studio", "Condominium", false},
+	}
+
+	for _, tt := range tests {
+		if got := unitTypeMatches(tt.filter, tt.listing); got != tt.want {
+			t.Errorf("unitTypeMatches(%q, %q) = %v, want %v", tt.filter, tt.listing, got, tt.want)
+		}
+	}
+}
+
+func TestLocationMatches(t *testing.T) {
+	tests := []struct {
+		filter  string
+		listing string
+		want    bool
+	}{
+		{"Tampines", "Tampines Street 81", true},
+		{"tampines", "12 TAMPINES AVE", true},
+		{"Tampines", "Jurong West Street 52", false},
+	}
+
+	for _, tt := range tests {
+		if got := locationMatches(tt.filter, tt.listing); got != tt.want {
+			t.Errorf("locationMatches(%q, %q) = %v, want %v", tt.filter, tt.listing, got, tt.want)
+		}
+	}
+}
+
+func TestRanker_LocationReasonRequiresMatch(t *testing.T) {
+	ranker := NewRanker(testRankingConfig())
+
+	inArea := "Tampines Street 81"
+	elsewhere := "Jurong West Street 52"
+	listings := []model.Listing{
+		{ListingID: 1, Location: &inArea},
+		{ListingID: 2, Location: &elsewhere},
+	}
+	filters := &model.SearchFilters{Location: stringPtr("Tampines")}
+
+	results := ranker.ScoreResults(listings, nil, filters, nil)
+	if !containsReason(results[0].MatchedReasons, ReasonLocationMatch) {
+		t.Errorf("Expected %q, got %v", ReasonLocationMatch, results[0].MatchedReasons)
+	}
+	if containsReason(results[1].MatchedReasons, ReasonLocationMatch) {
+		t.Errorf("Unexpected %q, got %v", ReasonLocationMatch, results[1].MatchedReasons)
+	}
+}
+
+func TestRanker_MultiLocationReasonNamesArea(t *testing.T) {
+	ranker := NewRanker(testRankingConfig())
+
+	sengkang := "Sengkang East Way"
+	elsewhere := "Jurong West Street 52"
+	listings := []model.Listing{
+		{ListingID: 1, Location: &sengkang},
+		{ListingID: 2, Location: &elsewhere},
+	}
+	filters := &model.SearchFilters{Locations: []string{"Punggol", "Sengkang"}}
+
+	results := ranker.ScoreResults(listings, nil, filters, nil)
+	if !containsReason(results[0].MatchedReasons, ReasonLocationMatch+": Sengkang") {
+		t.Errorf("Expected the matched area in the reason, got %v", results[0].MatchedReasons)
+	}
+	if len(results[1].MatchedReasons) > 0 && strings.HasPrefix(results[1].MatchedReasons[0], ReasonLocationMatch) {
+		t.Errorf("Unexpected location reason, got %v", results[1].MatchedReasons)
+	}
+	if reasonRank(ReasonLocationMatch+": Sengkang") != reasonRank(ReasonLocationMatch) {
+		t.Error("Expected a named location reason to rank like ReasonLocationMatch")
+	}
+}
+
+func TestRanker_PriceTargetGaussian(t *testing.T) {
+	ranker := NewRanker(testRankingConfig())
+	filters := &model.SearchFilters{PriceTarget: float64Ptr(1200000)}
+
+	onTarget := ranker.calculatePriceScore(float64Ptr(1200000), filters)
+	if math.Abs(onTarget-1) > 1e-9 {
+		t.Errorf("Expected full score at the target, got %v", onTarget)
+	}
+
+	below := ranker.calculatePriceScore(float64Ptr(1080000), filters)
+	above := ranker.calculatePriceScore(float64Ptr(1320000), filters)
+	if math.Abs(below-above) > 1e-9 {
+		t.Errorf("Expected symmetric scores 10%% either side, got %v and %v", below, above)
+	}
+	if math.Abs(below-math.Exp(-0.5)) > 1e-9 {
+		t.Errorf("Expected exp(-1/2) one spread away from the target, got %v", below)
+	}
+
+	far := ranker.calculatePriceScore(float64Ptr(1800000), filters)
+	if far >= below || far > 0.01 {
+		t.Errorf("Expected scores to fall off away from the target, got %v at +50%%", far)
+	}
+
+	// The target replaces the range midpoint: a listing at the midpoint but off target
+	// loses to one on target near the edge of the range
+	filters.PriceMin, filters.PriceMax = float64Ptr(1000000), float64Ptr(1300000)
+	midpoint := ranker.calculatePriceScore(float64Ptr(1150000), filters)
+	nearEdge := ranker.calculatePriceScore(float64Ptr(1200000), filters)
+	if nearEdge <= midpoint {
+		t.Errorf("Expected the target to beat the range midpoint, got %v vs %v", nearEdge, midpoint)
+	}
+}
+
+func containsReason(reasons []string, reason string) bool {
+	for _, r := range reasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRanker_RankVectorResultsRecencyNudge(t *testing.T) {
+	cfg := testRankingConfig()
+	cfg.VectorRecencyWeight = 0.05
+	ranker := NewRanker(cfg)
+
+	distance := 0.2
+	closer := 0.05
+	old := time.Now().AddDate(-1, 0, 0)
+	recent := time.Now().AddDate(0, 0, -1)
+	listings := []model.Listing{
+		{ListingID: 1, VectorDistance: &distance, ListedDate: &old},
+		{ListingID: 2, VectorDistance: &distance, ListedDate: &recent},
+		{ListingID: 3, VectorDistance: &closer, ListedDate: &old},
+		{ListingID: 4, VectorDistance: &distance, ListedDate: &old},
+	}
+
+	results := ranker.RankVectorResults(listings, nil)
+	got := []int64{results[0].ListingID, results[1].ListingID, results[2].ListingID, results[3].ListingID}
+	// Similarity dominates; equally similar listings favor the newer one, then listing_id
+	want := []int64{3, 2, 1, 4}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected order %v, got %v", want, got)
+		}
+	}
+	if !containsReason(results[0].MatchedReasons, ReasonContentRelevant) {
+		t.Errorf("Expected %q in %v", ReasonContentRelevant, results[0].MatchedReasons)
+	}
+
+	// Without the nudge, ties keep the stable tiebreaker order
+	results = NewRanker(testRankingConfig()).RankVectorResults(listings, nil)
+	if results[1].ListingID != 2 || results[1].Score != results[2].Score {
+		t.Errorf("Expected tied scores broken by recency, got %d (%.4f vs %.4f)",
+			results[1].ListingID, results[1].Score, results[2].Score)
+	}
+}
+
+func TestRanker_ReasonThresholds(t *testing.T) {
+	cfg := testRankingConfig()
+	cfg.ReasonTextScore = 0.3
+	cfg.ReasonPriceScore = 0.5
+	cfg.ReasonNewListedDays = 3
+	cfg.ReasonGreenScore = 3.5
+	ranker := NewRanker(cfg)
+	filters := &model.SearchFilters{}
+
+	twoDaysAgo := time.Now().AddDate(0, 0, -2)
+	fourDaysAgo := time.Now().AddDate(0, 0, -4)
+	green := 3.5
+	lessGreen := 3.4
+
+	tests := []struct {
+		name       string
+		listing    model.Listing
+		textScore  float64
+		priceScore float64
+		reason     string
+		want       bool
+	}{
+		{"text at threshold", model.Listing{}, 0.3, 0, ReasonContentRelevant, false},
+		{"text above threshold", model.Listing{}, 0.31, 0, ReasonContentRelevant, true},
+		{"price at threshold", model.Listing{}, 0, 0.5, ReasonPriceMatch, false},
+		{"price above threshold", model.Listing{}, 0, 0.6, ReasonPriceMatch, true},
+		{"listed within window", model.Listing{ListedDate: &twoDaysAgo}, 0, 0, ReasonNewlyListed, true},
+		{"listed outside window", model.Listing{ListedDate: &fourDaysAgo}, 0, 0, ReasonNewlyListed, false},
+		{"green score at cutoff", model.Listing{GreenScoreValue: &green}, 0, 0, ReasonHighGreenScore, true},
+		{"green score below cutoff", model.Listing{GreenScoreValue: &lessGreen}, 0, 0, ReasonHighGreenScore, false},
+	}
+
+	for _, tt := range tests {
+		reasons := ranker.generateMatchedReasons(tt.listing, filters, tt.textScore, tt.priceScore)
+		if got := containsReason(reasons, tt.reason); got != tt.want {
+			t.Errorf("%s: %q present = %v, want %v (reasons %v)", tt.name, tt.reason, got, tt.want, reasons)
+		}
+	}
+}
+
+func TestRanker_AmenityMatchCountBoost(t *testing.T) {
+	ranker := NewRanker(testRankingConfig())
+
+	listings := []model.Listing{
+		{ListingID: 1, Amenities: model.JSONArray{"Gym"}},
+		{ListingID: 2, Amenities: model.JSONArray{"Swimming Pool", "Fitness corner"}, Facilities: model.JSONArray{"Covered parking"}},
+		{ListingID: 3, Amenities: model.JSONArray{"Swimming Pool"}},
+	}
+	filters := &model.SearchFilters{Amenities: []string{"pool", "gym"}, Facilities: []string{"parking"}, MatchMode: model.MatchAny}
+
+	results := ranker.RankResults(listings, nil, filters, nil)
+	if results[0].ListingID != 2 {
+		t.Fatalf("Expected the listing matching all three first, got %d", results[0].ListingID)
+	}
+	if diff := results[0].Score - results[2].Score; diff < 0.2*2/3-1e-9 || diff > 0.2+1e-9 {
+		t.Errorf("Expected a boost proportional to the match count, got a %.3f gap", diff)
+	}
+	if !containsReason(results[0].MatchedReasons, ReasonAmenitiesMatch) {
+		t.Errorf("Expected %q in %v", ReasonAmenitiesMatch, results[0].MatchedReasons)
+	}
+
+	// In the default "all" mode every result matched them all, so there is no boost
+	filters.MatchMode = ""
+	for _, result := range ranker.ScoreResults(listings, nil, filters, nil) {
+		if containsReason(result.MatchedReasons, ReasonAmenitiesMatch) {
+			t.Errorf("Unexpected %q in all mode: %v", ReasonAmenitiesMatch, result.MatchedReasons)
+		}
+	}
+}
+
+func TestRanker_CompletenessBoost(t *testing.T) {
+	cfg := testRankingConfig()
+	cfg.WeightCompleteness = 0.05
+	ranker := NewRanker(cfg)
+
+	description := strings.Repeat("Bright unit with an open kitchen. ", 10)
+	lat, lng, green, area := 1.35, 103.94, 3.0, 850.0
+	complete := model.Listing{
+		ListingID:       1,
+		Images:          []string{"https://img.example.com/1.jpg"},
+		Description:     &description,
+		Latitude:        &lat,
+		Longitude:       &lng,
+		GreenScoreValue: &green,
+		AreaSqft:        &area,
+	}
+	blurb := "Call now"
+	sparse := model.Listing{ListingID: 2, Description: &blurb, Latitude: &lat}
+
+	results := ranker.RankResults([]model.Listing{sparse, complete}, nil, nil, nil)
+	if results[0].ListingID != 1 {
+		t.Fatalf("Expected the complete listing first, got %d", results[0].ListingID)
+	}
+	if diff := results[0].Score - results[1].Score; math.Abs(diff-0.05) > 1e-9 {
+		t.Errorf("Expected a boost of the full weight, got %.3f", diff)
+	}
+	if !containsReason(results[0].MatchedReasons, ReasonCompleteListing) {
+		t.Errorf("Expected %q in %v", ReasonCompleteListing, results[0].MatchedReasons)
+	}
+	if containsReason(results[1].MatchedReasons, ReasonCompleteListing) {
+		t.Errorf("Unexpected %q for a sparse listing: %v", ReasonCompleteListing, results[1].MatchedReasons)
+	}
+
+	if got := calculateCompletenessScore(model.Listing{Images: complete.Images, AreaSqft: &area}); got != 0.4 {
+		t.Errorf("Expected 2 of 5 key fields, got %v", got)
+	}
+}
+
+func TestRanker_LeaseRemainingReason(t *testing.T) {
+	ranker := NewRanker(testRankingConfig())
+
+	freehold := "Freehold"
+	listings := []model.Listing{
+		{ListingID: 1, LeaseRemainingYears: intPtr(85)},
+		{ListingID: 2, Tenure: &freehold},
+		{ListingID: 3, LeaseRemainingYears: intPtr(60)},
+	}
+
+	results := ranker.ScoreResults(listings, nil, &model.SearchFilters{LeaseRemainingMin: intPtr(80)}, nil)
+	if !containsReason(results[0].MatchedReasons, ReasonLeaseRemaining+": 85 years") {
+		t.Errorf("Expected the remaining years in the reason, got %v", results[0].MatchedReasons)
+	}
+	if !containsReason(results[1].MatchedReasons, ReasonLeaseRemaining+": freehold") {
+		t.Errorf("Expected freehold to satisfy the lease filter, got %v", results[1].MatchedReasons)
+	}
+	if len(results[2].MatchedReasons) > 0 && strings.HasPrefix(results[2].MatchedReasons[0], ReasonLeaseRemaining) {
+		t.Errorf("Unexpected lease reason for a short lease, got %v", results[2].MatchedReasons)
+	}
+
+	results = ranker.ScoreResults(listings[:1], nil, &model.SearchFilters{}, nil)
+	if containsReason(results[0].MatchedReasons, ReasonLeaseRemaining+": 85 years") {
+		t.Errorf("Expected no lease reason without the filter, got %v", results[0].MatchedReasons)
+	}
+}
+
+func TestRanker_WithWeights(t *testing.T) {
+	ranker := NewRanker(testRankingConfig())
+	if ranker.WithWeights(nil, nil, nil) != ranker {
+		t.Error("Expected the configured ranker without overrides")
+	}
+	if ranker.WithWeights(float64Ptr(0), float64Ptr(0), float64Ptr(0)) != ranker {
+		t.Error("Expected the configured ranker when every weight is zero")
+	}
+
+	// Recency 0.8 joins the default text 0.5 and price 0.3, then all three are scaled by 1/1.6
+	weighted := ranker.WithWeights(nil, nil, float64Ptr(0.8))
+	if math.Abs(weighted.weightText-0.3125) > 1e-9 || math.Abs(weighted.weightPrice-0.1875) > 1e-9 || math.Abs(weighted.weightRecency-0.5) > 1e-9 {
+		t.Errorf("Expected normalized weights 0.3125/0.1875/0.5, got %v/%v/%v", weighted.weightText, weighted.weightPrice, weighted.weightRecency)
+	}
+	if ranker.weightRecency != 0.2 || weighted.weightTitle != ranker.weightTitle {
+		t.Error("Expected only a re-weighted copy of the text, price, and recency weights")
+	}
+
+	fresh := time.Now().AddDate(0, 0, -1)
+	stale := time.Now().AddDate(-1, 0, 0)
+	listings := []model.Listing{
+		{ListingID: 1, ListedDate: &stale},
+		{ListingID: 2, ListedDate: &fresh},
+	}
+	textRanks := map[int64]float64{1: 1, 2: 0.2}
+	if results := ranker.RankResults(listings, textRanks, nil, nil); results[0].ListingID != 1 {
+		t.Fatalf("Expected the more relevant listing first by default, got %d", results[0].ListingID)
+	}
+	recent := ranker.WithWeights(float64Ptr(0.1), nil, float64Ptr(1))
+	if results := recent.RankResults(listings, textRanks, nil, nil); results[0].ListingID != 2 {
+		t.Errorf("Expected the newer listing first when emphasizing recency, got %d", results[0].ListingID)
+	}
+}
+
+func TestRanker_ValueBoost(t *testing.T) {
+	cfg := testRankingConfig()
+	cfg.WeightValue = 0.1
+	ranker := NewRanker(cfg)
+	listings := []model.Listing{
+		{ListingID: 1, PricePerSqft: float64Ptr(1500)},
+		{ListingID: 2, PricePerSqft: float64Ptr(1000)},
+		{ListingID: 3},
+	}
+	textRanks := map[int64]float64{1: 0.5, 2: 0.5, 3: 0.5}
+
+	plain := ranker.RankResults(listings, textRanks, nil, []string{"condo"})
+	if plain[0].Score != plain[1].Score || containsReason(plain[0].MatchedReasons, ReasonGoodValue) {
+		t.Fatalf("Expected no value boost without a budget-conscious search, got %+v", plain)
+	}
+
+	// The cheapest psf in the set is a third below the most expensive one
+	cheap := ranker.RankResults(listings, textRanks, nil, []string{"Affordable", "condo"})
+	if cheap[0].ListingID != 2 || !containsReason(cheap[0].MatchedReasons, ReasonGoodValue) {
+		t.Errorf("Expected the lower psf first as good value, got %d %v", cheap[0].ListingID, cheap[0].MatchedReasons)
+	}
+	if boost := cheap[0].Score - cheap[1].Score; math.Abs(boost-0.1/3) > 1e-9 {
+		t.Errorf("Expected a boost of weight * (1 - 1000/1500), got %v", boost)
+	}
+
+	// A psf cap is the reference: 1000 is 50% below 2000, 1500 only 25%
+	capped := ranker.ScoreResults(listings, textRanks, &model.SearchFilters{PricePerSqftMax: float64Ptr(2000)}, nil)
+	if math.Abs(capped[1].Score-capped[2].Score-0.05) > 1e-9 || !containsReason(capped[0].MatchedReasons, ReasonGoodValue) {
+		t.Errorf("Expected value scores against the psf cap, got %+v", capped)
+	}
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"core/internal/model"
+	"core/internal/utils"
+)
+
+// RecentSearches returns up to limit distinct queries the session searched within the
+// configured window, most recent first. Queries containing personal data are left out.
+func (s *SearchService) RecentSearches(ctx context.Context, sessionID string, limit int) ([]model.RecentSearch, error) {
+	since := time.Now().AddDate(0, 0, -s.config.RecentSearchDays)
+	// Over-fetch so dropping PII-bearing queries still leaves a full list
+	logged, err := s.repo.RecentSearches(ctx, sessionID, since, limit*2)
+	if err != nil {
+		return nil, err
+	}
+
+	searches := make([]model.RecentSearch, 0, limit)
+	for _, search := range logged {
+		if len(searches) >= limit {
+			break
+		}
+		if utils.ContainsPII(search.Query) {
+			continue
+		}
+		searches = append(searches, search)
+	}
+	return searches, nil
+}
+
+// ForgetSession unlinks the session from its logged searches so they no longer show up
+// as its recent searches
+func (s *SearchService) ForgetSession(ctx context.Context, sessionID string) (int64, error) {
+	return s.repo.ForgetSession(ctx, sessionID)
+}
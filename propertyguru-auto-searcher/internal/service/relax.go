@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+
+	"core/internal/model"
+)
+
+// relaxFilters widens the price range by ratio and drops the MRT distance cap.
+// Returns nil when there is nothing to relax.
+func relaxFilters(filters *model.SearchFilters, ratio float64) (*model.SearchFilters, []string) {
+	if filters == nil {
+		return nil, nil
+	}
+
+	relaxed := *filters
+	var applied []string
+
+	if ratio > 0 && (filters.PriceMin != nil || filters.PriceMax != nil) {
+		if filters.PriceMin != nil {
+			priceMin := math.Round(*filters.PriceMin * (1 - ratio))
+			relaxed.PriceMin = &priceMin
+		}
+		if filters.PriceMax != nil {
+			priceMax := math.Round(*filters.PriceMax * (1 + ratio))
+			relaxed.PriceMax = &priceMax
+		}
+		applied = append(applied, fmt.Sprintf("price range widened by %.0f%%", ratio*100))
+	}
+
+	if filters.MRTDistanceMax != nil {
+		relaxed.MRTDistanceMax = nil
+		applied = append(applied, "MRT distance limit removed")
+	}
+
+	if len(applied) == 0 {
+		return nil, nil
+	}
+	return &relaxed, applied
+}
+
+// findBroaderMatches runs a relaxed secondary search when the strict search returned fewer
+// than the configured minimum. Only the first page is supplemented; failures are logged
+// and leave the primary response untouched.
+func (s *SearchService) findBroaderMatches(
+	ctx context.Context,
+	filters *model.SearchFilters,
+	semanticKeywords []string,
+	options *model.SearchOptions,
+	primary []model.ListingSearchResult,
+	total int,
+) *model.BroaderMatches {
+	if s.config.MinResults <= 0 || total >= s.config.MinResults || options.Offset > 0 || options.CountOnly {
+		return nil
+	}
+
+	relaxed, applied := relaxFilters(filters, s.config.RelaxPriceRatio)
+	if relaxed == nil {
+		return nil
+	}
+
+	relaxedOptions := *options
+	relaxedOptions.Offset = 0
+	results, _, err := s.searchAndRank(ctx, relaxed, semanticKeywords, &relaxedOptions)
+	if err != nil {
+		log.Printf("⚠️  Relaxed search failed: %v", err)
+		return nil
+	}
+
+	seen := make(map[int64]bool, len(primary))
+	for _, r := range primary {
+		seen[r.ListingID] = true
+	}
+	limit := options.TopK - len(primary)
+	broader := make([]model.ListingSearchResult, 0, len(results))
+	for _, r := range results {
+		if len(broader) >= limit {
+			break
+		}
+		if !seen[r.ListingID] {
+			broader = append(broader, r)
+		}
+	}
+	if len(broader) == 0 {
+		return nil
+	}
+
+	return &model.BroaderMatches{
+		Results: broader,
+		Relaxed: applied,
+	}
+}
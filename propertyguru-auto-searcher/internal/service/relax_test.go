@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"core/internal/config"
+	"core/internal/model"
+)
+
+func TestRelaxFilters(t *testing.T) {
+	priceMin, priceMax := 1000000.0, 1500000.0
+	mrt := 500
+	location := "Punggol"
+
+	filters := &model.SearchFilters{
+		PriceMin:       &priceMin,
+		PriceMax:       &priceMax,
+		MRTDistanceMax: &mrt,
+		Location:       &location,
+	}
+
+	relaxed, applied := relaxFilters(filters, 0.2)
+	if relaxed == nil {
+		t.Fatal("Expected relaxed filters")
+	}
+	if *relaxed.PriceMin != 800000 || *relaxed.PriceMax != 1800000 {
+		t.Errorf("Price range = %v-%v, want 800000-1800000", *relaxed.PriceMin, *relaxed.PriceMax)
+	}
+	if relaxed.MRTDistanceMax != nil {
+		t.Error("Expected MRT distance cap to be dropped")
+	}
+	if relaxed.Location == nil || *relaxed.Location != location {
+		t.Error("Expected location to be kept")
+	}
+	if len(applied) != 2 {
+		t.Errorf("Expected 2 relaxations, got %v", applied)
+	}
+
+	// The original filters must not be modified
+	if *filters.PriceMax != 1500000 || filters.MRTDistanceMax == nil {
+		t.Error("Original filters were modified")
+	}
+}
+
+func TestRelaxFilters_NothingToRelax(t *testing.T) {
+	bedrooms := 3
+	if relaxed, _ := relaxFilters(&model.SearchFilters{Bedrooms: &bedrooms}, 0.2); relaxed != nil {
+		t.Errorf("Expected nil, got %+v", relaxed)
+	}
+}
+
+func TestFindBroaderMatches_SkippedForCountOnly(t *testing.T) {
+	priceMax := 1500000.0
+	s := &SearchService{config: &config.SearchConfig{MinResults: 3, RelaxPriceRatio: 0.2}}
+
+	// A nil repository would panic if a relaxed search ran
+	broader := s.findBroaderMatches(context.Background(), &model.SearchFilters{PriceMax: &priceMax}, nil,
+		&model.SearchOptions{TopK: 20, CountOnly: true}, []model.ListingSearchResult{}, 1)
+	if broader != nil {
+		t.Errorf("Expected no broader matches for count-only requests, got %+v", broader)
+	}
+}
+
+func TestAlternativeMessage(t *testing.T) {
+	priceMax := 600000.0
+	filters := &model.SearchFilters{PriceMax: &priceMax}
+
+	if got := alternativeMessage("Condo", 0, "HDB", 40, filters); got != "No Condo listings under $600K, but 40 HDB listings match" {
+		t.Errorf("alternativeMessage = %q", got)
+	}
+	priceMin := 1250000.0
+	filters = &model.SearchFilters{PriceMin: &priceMin}
+	if got := alternativeMessage("HDB", 1, "Condo", 12, filters); got != "Only 1 HDB listing over $1.25M, but 12 Condo listings match" {
+		t.Errorf("alternativeMessage = %q", got)
+	}
+	if got := formatPrice(3500); got != "$3500" {
+		t.Errorf("formatPrice(3500) = %q", got)
+	}
+}
+
+func TestFindAlternativeUnitTypes_SkippedWithoutUnitType(t *testing.T) {
+	s := &SearchService{config: &config.SearchConfig{MinResults: 3}}
+
+	// A nil repository would panic if any count ran
+	if got := s.findAlternativeUnitTypes(context.Background(), &model.SearchFilters{}, &model.SearchOptions{TopK: 20}, 0); got != nil {
+		t.Errorf("Expected no alternatives without a unit type, got %v", got)
+	}
+	if got := s.findAlternativeUnitTypes(context.Background(), &model.SearchFilters{UnitType: model.StringOrList{"condo"}}, &model.SearchOptions{TopK: 20}, 5); got != nil {
+		t.Errorf("Expected no alternatives when results aren't sparse, got %v", got)
+	}
+}
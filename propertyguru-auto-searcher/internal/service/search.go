@@ -2,18 +2,56 @@ package service
 
 import (
 	"context"
+	"errors"
+	"log"
 	"math"
+	"strings"
 	"time"
 
+	"core/internal/config"
 	"core/internal/model"
 	"core/internal/repository"
+	"core/internal/utils"
 )
 
+// searchRepository is the database dependency of SearchService, implemented by
+// *repository.PostgresRepository
+type searchRepository interface {
+	SearchWithFilters(ctx context.Context, filters *model.SearchFilters, semanticKeywords []string, options *model.SearchOptions) ([]model.Listing, int, error)
+	StreamWithFilters(ctx context.Context, filters *model.SearchFilters, columns []string, limit int, fn func(*model.Listing) error) error
+	CountWithFilters(ctx context.Context, filters *model.SearchFilters) (int, error)
+	FacetCounts(ctx context.Context, filters *model.SearchFilters, facet string) (map[string]int, error)
+	FullTextEnabled() bool
+	VectorSearch(ctx context.Context, queryEmbedding []float32, limit int, filters *model.SearchFilters, aggregate string) ([]model.Listing, error)
+	ListingEmbedding(ctx context.Context, listingID int64) (embedding []float32, found bool, err error)
+	GetListingByID(ctx context.Context, listingID int64) (*model.Listing, error)
+	BatchUpdateEmbeddings(ctx context.Context, items []model.EmbeddingItem) (int, []string)
+	PurgeStaleListings(ctx context.Context, olderThan time.Duration, mode string, clearEmbeddings bool, dryRun bool) (*repository.StaleListingPurge, error)
+	DeleteListing(ctx context.Context, listingID int64) (*repository.ListingDeletion, error)
+	LatestListingUpdate(ctx context.Context) (*time.Time, error)
+	LogSearch(ctx context.Context, searchID, sessionID string, query string, slots *model.IntentSlots, keywords []string, resultCount int, listingIDs []int64, responseTimeMs int) error
+	LogFeedback(ctx context.Context, searchID string, listingID int64, action string) error
+	SeenListingIDs(ctx context.Context, sessionID string, since time.Time, limit int) ([]int64, error)
+	RecentSearches(ctx context.Context, sessionID string, since time.Time, limit int) ([]model.RecentSearch, error)
+	ForgetSession(ctx context.Context, sessionID string) (int64, error)
+	PopularQueries(ctx context.Context, since time.Time, minCount, limit int) ([]model.Suggestion, error)
+	PriceAnchor(ctx context.Context, filters *model.SearchFilters) (*model.PriceAnchor, error)
+	GreenScoresByLocation(ctx context.Context) ([]model.LocationGreenScore, error)
+	PoolStats() repository.PoolStats
+}
+
 // SearchService handles search business logic
 type SearchService struct {
-	repo   *repository.PostgresRepository
+	repo   searchRepository
 	intent *IntentParser
 	ranker *Ranker
+	config *config.SearchConfig // Never nil; NewSearchService substitutes the zero config
+
+	latency      latencyRecorder
+	limiter      *searchLimiter // Caps concurrent searches; nil = unlimited
+	suggestions  suggestionCache
+	priceAnchors priceAnchorCache
+	greenScores  greenScoreCache
 }
 
 // NewSearchService creates a new search service
@@ -21,19 +59,53 @@ func NewSearchService(
 	repo *repository.PostgresRepository,
 	intentParser *IntentParser,
 	ranker *Ranker,
+	cfg *config.SearchConfig,
 ) *SearchService {
+	if cfg == nil {
+		cfg = &config.SearchConfig{}
+	}
 	return &SearchService{
-		repo:   repo,
-		intent: intentParser,
-		ranker: ranker,
+		repo:    repo,
+		intent:  intentParser,
+		ranker:  ranker,
+		config:  cfg,
+		limiter: newSearchLimiter(cfg.MaxInFlight, cfg.OverloadMode, time.Duration(cfg.QueueTimeoutMs)*time.Millisecond),
 	}
 }
 
+// LatencyStats returns the intent/search latency breakdown of searches so far
+func (s *SearchService) LatencyStats() SearchLatencyStats {
+	return s.latency.stats()
+}
+
+// ConcurrencyStats returns the in-flight search limiter state
+func (s *SearchService) ConcurrencyStats() SearchConcurrencyStats {
+	return s.limiter.stats()
+}
+
+// AcquireSearchSlot takes one of the in-flight search slots, waiting or failing with
+// ErrSearchBusy per SEARCH_OVERLOAD_MODE. Search, SearchWithFilters, and SearchByVector
+// take their own; streaming callers take one before the stream starts so a busy server can
+// still answer with a status code. Call the returned release when done.
+func (s *SearchService) AcquireSearchSlot(ctx context.Context) (func(), error) {
+	return s.limiter.acquire(ctx)
+}
+
+// PoolStats returns the database connection pool statistics
+func (s *SearchService) PoolStats() repository.PoolStats {
+	return s.repo.PoolStats()
+}
+
 // SearchEventCallback is called for streaming search events
 type SearchEventCallback func(event string, data any) error
 
 // SearchWithFilters performs a search using pre-parsed filters without AI intent parsing
 func (s *SearchService) SearchWithFilters(ctx context.Context, filters *model.SearchFilters, options *model.SearchOptions) (*model.SearchResponse, error) {
+	release, err := s.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 	startTime := time.Now()
 
 	// Set default options
@@ -48,66 +120,43 @@ func (s *SearchService) SearchWithFilters(ctx context.Context, filters *model.Se
 	// Use empty semantic keywords since we're not doing AI parsing
 	var semanticKeywords []string
 
-	// Search database with filters and full-text search
-	listings, total, err := s.repo.SearchWithFilters(
-		ctx,
-		filters,
-		semanticKeywords,
-		options.TopK,
-		options.Offset,
-	)
+	results, total, err := s.searchAndRank(ctx, filters, semanticKeywords, options)
 	if err != nil {
 		return nil, err
 	}
 
-	// Build text rank map (from PostgreSQL ts_rank)
-	textRanks := make(map[int64]float64)
-	for i, listing := range listings {
-		// Higher rank for earlier results (simulated from ORDER BY text_rank DESC)
-		textRanks[listing.ListingID] = 1.0 - (float64(i) / float64(len(listings)))
-	}
-
-	// Rank and score results
-	results := s.ranker.RankResults(listings, textRanks, filters)
-
 	// Calculate response time
 	took := time.Since(startTime).Milliseconds()
 
-	pageSize := options.TopK
-	if pageSize <= 0 {
-		pageSize = len(results)
-		if pageSize == 0 {
-			pageSize = 1
-		}
-	}
-	page := (options.Offset / pageSize) + 1
-	totalPages := 0
-	if pageSize > 0 {
-		totalPages = int(math.Ceil(float64(total) / float64(pageSize)))
-	}
-	hasMore := options.Offset+len(results) < total
-
-	return &model.SearchResponse{
-		Results:    results,
-		Total:      total,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalPages: totalPages,
-		HasMore:    hasMore,
-		Intent:     nil, // No intent since we're not doing AI parsing
-		Took:       took,
-	}, nil
+	// No intent since we're not doing AI parsing
+	response := buildSearchResponse(results, total, options, nil, took)
+	response.Facets = s.facetCounts(ctx, filters, options)
+	s.attachShareURLs(response, "")
+	s.attachMortgages(response, options.Mortgage)
+	attachDistances(response, filters)
+	applyFieldset(response, options.Fields)
+	s.stampFreshness(ctx, response)
+	return response, nil
 }
 
 // Search performs a complete search with intent parsing, filtering, and ranking
 func (s *SearchService) Search(ctx context.Context, req *model.SearchRequest) (*model.SearchResponse, error) {
+	release, err := s.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 	startTime := time.Now()
 
 	// Parse intent from natural language query
-	intentResult := s.intent.Parse(req.Query)
+	intentResult := s.intent.Parse(req.Query, req.Locale)
+	intentMs := time.Since(startTime).Milliseconds()
+	if !req.Debug {
+		intentResult.Debug = nil
+	}
 
 	// Merge explicit filters with extracted slots
-	filters := s.mergeFilters(req.Filters, intentResult.Slots)
+	filters := s.mergeFilters(req.Filters, intentResult.Slots, req.FilterOverrides)
 
 	// Set default options
 	options := req.Options
@@ -118,69 +167,55 @@ func (s *SearchService) Search(ctx context.Context, req *model.SearchRequest) (*
 			Semantic: true,
 		}
 	}
+	s.excludeSeen(ctx, filters, req.SessionID, options)
 
-	// Search database with filters and full-text search
-	listings, total, err := s.repo.SearchWithFilters(
-		ctx,
-		filters,
-		intentResult.SemanticKeywords,
-		options.TopK,
-		options.Offset,
-	)
+	searchStart := time.Now()
+	results, total, err := s.searchAndRank(ctx, filters, intentResult.SemanticKeywords, options)
 	if err != nil {
 		return nil, err
 	}
 
-	// Build text rank map (from PostgreSQL ts_rank)
-	// Note: In production, we'd extract this from the query result
-	textRanks := make(map[int64]float64)
-	for i, listing := range listings {
-		// Higher rank for earlier results (simulated from ORDER BY text_rank DESC)
-		textRanks[listing.ListingID] = 1.0 - (float64(i) / float64(len(listings)))
-	}
-
-	// Rank and score results
-	results := s.ranker.RankResults(listings, textRanks, filters)
+	// Supplement narrow searches with clearly separated relaxed matches
+	broader := s.findBroaderMatches(ctx, filters, intentResult.SemanticKeywords, options, results, total)
+	alternatives := s.findAlternativeUnitTypes(ctx, filters, options, total)
+	searchMs := time.Since(searchStart).Milliseconds()
+	s.latency.record(intentMs, searchMs)
 
 	// Calculate response time
 	took := time.Since(startTime).Milliseconds()
-
-	// Log search (non-blocking)
-	go func() {
-		listingIDs := make([]int64, len(results))
-		for i, r := range results {
-			listingIDs[i] = r.ListingID
-		}
-		_ = s.repo.LogSearch(context.Background(), req.Query, intentResult.Slots, intentResult.SemanticKeywords, total, listingIDs, int(took))
-	}()
-
-	pageSize := options.TopK
-	if pageSize <= 0 {
-		pageSize = len(results)
-		if pageSize == 0 {
-			pageSize = 1
-		}
-	}
-	page := (options.Offset / pageSize) + 1
-	totalPages := 0
-	if pageSize > 0 {
-		totalPages = int(math.Ceil(float64(total) / float64(pageSize)))
+	searchID := newSearchID()
+	utils.LoggerFrom(ctx).Info("search completed",
+		"search_id", searchID, "total", total, "took_ms", took, "intent_ms", intentMs, "search_ms", searchMs)
+
+	// Log search (non-blocking); count-only requests show no results, so they aren't logged
+	if !options.CountOnly {
+		go func() {
+			listingIDs := make([]int64, len(results))
+			for i, r := range results {
+				listingIDs[i] = r.ListingID
+			}
+			_ = s.repo.LogSearch(context.Background(), searchID, req.SessionID, req.Query, intentResult.Slots, intentResult.SemanticKeywords, total, listingIDs, int(took))
+		}()
 	}
-	hasMore := options.Offset+len(results) < total
 
-	return &model.SearchResponse{
-		Results:    results,
-		Total:      total,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalPages: totalPages,
-		HasMore:    hasMore,
-		Intent:     intentResult,
-		Took:       took,
-	}, nil
+	response := buildSearchResponse(results, total, options, intentResult, took)
+	response.IntentMs = intentMs
+	response.SearchMs = searchMs
+	response.SearchID = searchID
+	response.BroaderMatches = broader
+	response.AlternativeUnitTypes = alternatives
+	response.Facets = s.facetCounts(ctx, filters, options)
+	response.Conflicts = filterConflicts(req.Filters, intentResult.Slots, req.FilterOverrides)
+	s.attachShareURLs(response, searchID)
+	s.attachMortgages(response, options.Mortgage)
+	attachDistances(response, filters)
+	applyFieldset(response, options.Fields)
+	s.stampFreshness(ctx, response)
+	return response, nil
 }
 
-// SearchStream performs a search with streaming intent parsing
+// SearchStream performs a search with streaming intent parsing. Callers hold a slot from
+// AcquireSearchSlot for its duration.
 func (s *SearchService) SearchStream(ctx context.Context, req *model.SearchRequest, callback SearchEventCallback) (*model.SearchResponse, error) {
 	startTime := time.Now()
 
@@ -192,7 +227,8 @@ func (s *SearchService) SearchStream(ctx context.Context, req *model.SearchReque
 	}
 
 	// Parse intent from natural language query with streaming
-	intentResult, err := s.intent.ParseStream(ctx, req.Query, func(thinking, content string) error {
+	intentStart := time.Now()
+	intentResult, err := s.intent.ParseStream(ctx, req.Query, req.Locale, func(thinking, content string) error {
 		// Send thinking progress
 		if thinking != "" {
 			return callback("thinking", map[string]any{
@@ -211,6 +247,11 @@ func (s *SearchService) SearchStream(ctx context.Context, req *model.SearchReque
 	if err != nil {
 		return nil, err
 	}
+	// Covers every streamed chunk, since ParseStream returns only once the stream ends
+	intentMs := time.Since(intentStart).Milliseconds()
+	if !req.Debug {
+		intentResult.Debug = nil
+	}
 
 	// Send intent parsed event
 	if err := callback("intent", intentResult); err != nil {
@@ -218,7 +259,7 @@ func (s *SearchService) SearchStream(ctx context.Context, req *model.SearchReque
 	}
 
 	// Merge explicit filters with extracted slots
-	filters := s.mergeFilters(req.Filters, intentResult.Slots)
+	filters := s.mergeFilters(req.Filters, intentResult.Slots, req.FilterOverrides)
 
 	// Set default options
 	options := req.Options
@@ -229,6 +270,7 @@ func (s *SearchService) SearchStream(ctx context.Context, req *model.SearchReque
 			Semantic: true,
 		}
 	}
+	s.excludeSeen(ctx, filters, req.SessionID, options)
 
 	// Send searching event
 	if err := callback("searching", map[string]any{
@@ -237,39 +279,350 @@ func (s *SearchService) SearchStream(ctx context.Context, req *model.SearchReque
 		return nil, err
 	}
 
+	searchStart := time.Now()
+	results, total, err := s.searchAndRank(ctx, filters, intentResult.SemanticKeywords, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Supplement narrow searches with clearly separated relaxed matches
+	broader := s.findBroaderMatches(ctx, filters, intentResult.SemanticKeywords, options, results, total)
+	alternatives := s.findAlternativeUnitTypes(ctx, filters, options, total)
+	searchMs := time.Since(searchStart).Milliseconds()
+	s.latency.record(intentMs, searchMs)
+
+	// Calculate response time
+	took := time.Since(startTime).Milliseconds()
+	searchID := newSearchID()
+	utils.LoggerFrom(ctx).Info("search completed",
+		"search_id", searchID, "total", total, "took_ms", took, "intent_ms", intentMs, "search_ms", searchMs)
+
+	// Log search (non-blocking); count-only requests show no results, so they aren't logged
+	if !options.CountOnly {
+		go func() {
+			listingIDs := make([]int64, len(results))
+			for i, r := range results {
+				listingIDs[i] = r.ListingID
+			}
+			_ = s.repo.LogSearch(context.Background(), searchID, req.SessionID, req.Query, intentResult.Slots, intentResult.SemanticKeywords, total, listingIDs, int(took))
+		}()
+	}
+
+	response := buildSearchResponse(results, total, options, intentResult, took)
+	response.IntentMs = intentMs
+	response.SearchMs = searchMs
+	response.SearchID = searchID
+	response.BroaderMatches = broader
+	response.AlternativeUnitTypes = alternatives
+	response.Facets = s.facetCounts(ctx, filters, options)
+	response.Conflicts = filterConflicts(req.Filters, intentResult.Slots, req.FilterOverrides)
+	s.attachShareURLs(response, searchID)
+	s.attachMortgages(response, options.Mortgage)
+	attachDistances(response, filters)
+	applyFieldset(response, options.Fields)
+	s.stampFreshness(ctx, response)
+	return response, nil
+}
+
+// searchAndRank queries the database and scores the returned listings.
+// Relevance searches are re-ranked by score; explicit sorts keep the database order.
+func (s *SearchService) searchAndRank(
+	ctx context.Context,
+	filters *model.SearchFilters,
+	semanticKeywords []string,
+	options *model.SearchOptions,
+) ([]model.ListingSearchResult, int, error) {
+	if options.CountOnly {
+		total, err := s.repo.CountWithFilters(ctx, filters)
+		if err != nil {
+			return nil, 0, err
+		}
+		return []model.ListingSearchResult{}, total, nil
+	}
+
+	options = s.noKeywordOptions(options, semanticKeywords)
+	s.resolveSortNulls(options)
+
+	if !options.IsDBSort() && options.Offset < s.config.RankWindow {
+		return s.rankWindowPage(ctx, filters, semanticKeywords, options)
+	}
+
 	// Search database with filters and full-text search
-	listings, total, err := s.repo.SearchWithFilters(
-		ctx,
-		filters,
-		intentResult.SemanticKeywords,
-		options.TopK,
-		options.Offset,
-	)
+	listings, total, err := s.repo.SearchWithFilters(ctx, filters, semanticKeywords, options)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.deriveListingFields(listings)
+
+	// Database sorts, and relevance pages past the rank window, keep the database order
+	textRanks := scannedTextRanks(listings)
+	ranker := s.rankerFor(options)
+	if options.IsDBSort() || s.config.RankWindow > 0 {
+		return ranker.ScoreResults(listings, textRanks, filters, semanticKeywords), total, nil
+	}
+	return ranker.RankResults(listings, textRanks, filters, semanticKeywords), total, nil
+}
+
+// rankerFor returns the ranker for a request: the configured one, re-weighted when the
+// options override ranking weights
+func (s *SearchService) rankerFor(options *model.SearchOptions) *Ranker {
+	if !options.HasRankingWeights() {
+		return s.ranker
+	}
+	return s.ranker.WithWeights(options.WeightText, options.WeightPrice, options.WeightRecency)
+}
+
+// rankWindowPage ranks the first RankWindow relevance candidates as one set and returns
+// the requested page of that order, so every page within the window is a slice of the
+// same score-then-listing_id ordering: no listing shows up on two pages or on none.
+// Past the window, pages follow the database order (text rank, then listing_id).
+// The order is stable only within one snapshot of the data.
+//
+// Every page inside the window fetches and scores the whole window, not just its own
+// rows, so a larger RankWindow costs every request that pages within it.
+func (s *SearchService) rankWindowPage(
+	ctx context.Context,
+	filters *model.SearchFilters,
+	semanticKeywords []string,
+	options *model.SearchOptions,
+) ([]model.ListingSearchResult, int, error) {
+	window := s.config.RankWindow
+	windowOptions := *options
+	windowOptions.Offset = 0
+	windowOptions.TopK = window
+
+	listings, total, err := s.repo.SearchWithFilters(ctx, filters, semanticKeywords, &windowOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.deriveListingFields(listings)
+	ranker := s.rankerFor(options)
+	ranked := ranker.RankResults(listings, scannedTextRanks(listings), filters, semanticKeywords)
+	page := pageResults(ranked, options.Offset, options.TopK)
+
+	// A page straddling the end of the window continues in database order past it
+	if remaining := options.Offset + options.TopK - window; remaining > 0 && len(listings) == window {
+		tailOptions := *options
+		tailOptions.Offset = window
+		tailOptions.TopK = remaining
+		tail, _, err := s.repo.SearchWithFilters(ctx, filters, semanticKeywords, &tailOptions)
+		if err != nil {
+			return nil, 0, err
+		}
+		s.deriveListingFields(tail)
+		page = append(page, ranker.ScoreResults(tail, scannedTextRanks(tail), filters, semanticKeywords)...)
+	}
+
+	return page, total, nil
+}
+
+// scannedTextRanks maps each listing to the ts_rank scanned from the query. The ranker caps
+// it at 1 but doesn't rescale it, so a listing's text score doesn't depend on which other
+// listings share its result set or page.
+func scannedTextRanks(listings []model.Listing) map[int64]float64 {
+	textRanks := make(map[int64]float64, len(listings))
+	for _, listing := range listings {
+		if listing.TextRank != nil {
+			textRanks[listing.ListingID] = *listing.TextRank
+		}
+	}
+	return textRanks
+}
+
+// pageResults returns results[offset:offset+limit], clamped to the slice
+func pageResults(results []model.ListingSearchResult, offset, limit int) []model.ListingSearchResult {
+	if offset >= len(results) {
+		return []model.ListingSearchResult{}
+	}
+	return results[offset:min(offset+limit, len(results))]
+}
+
+// VectorSearch ranks the topK listings nearest to queryEmbedding for semantic-only queries.
+// aggregate selects how chunk distances combine per listing ("max" or "mean").
+func (s *SearchService) VectorSearch(
+	ctx context.Context,
+	queryEmbedding []float32,
+	filters *model.SearchFilters,
+	topK int,
+	aggregate string,
+) ([]model.ListingSearchResult, error) {
+	listings, err := s.repo.VectorSearch(ctx, queryEmbedding, topK, filters, aggregate)
+	if err != nil {
+		return nil, err
+	}
+	s.deriveListingFields(listings)
+	return s.ranker.RankVectorResults(listings, filters), nil
+}
+
+// SearchByVector returns the listings nearest to a caller-supplied query embedding,
+// skipping intent parsing entirely. Results are one page of up to options.TopK neighbours.
+func (s *SearchService) SearchByVector(
+	ctx context.Context,
+	queryEmbedding []float32,
+	filters *model.SearchFilters,
+	options *model.SearchOptions,
+	aggregate string,
+) (*model.SearchResponse, error) {
+	release, err := s.limiter.acquire(ctx)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
+	startTime := time.Now()
 
-	// Build text rank map
-	textRanks := make(map[int64]float64)
-	for i, listing := range listings {
-		textRanks[listing.ListingID] = 1.0 - (float64(i) / float64(len(listings)))
+	merged := s.mergeFilters(filters, nil, nil)
+	results, err := s.VectorSearch(ctx, queryEmbedding, merged, options.TopK, aggregate)
+	if err != nil {
+		return nil, err
 	}
 
-	// Rank and score results
-	results := s.ranker.RankResults(listings, textRanks, filters)
+	response := buildSearchResponse(results, len(results), options, nil, time.Since(startTime).Milliseconds())
+	s.attachShareURLs(response, "")
+	s.attachMortgages(response, options.Mortgage)
+	attachDistances(response, merged)
+	applyFieldset(response, options.Fields)
+	s.stampFreshness(ctx, response)
+	return response, nil
+}
 
-	// Calculate response time
-	took := time.Since(startTime).Milliseconds()
+// Errors returned by SimilarListings for a listing that can't be compared
+var (
+	ErrListingNotFound    = errors.New("listing not found")
+	ErrListingNotEmbedded = errors.New("listing has no embedding yet")
+)
+
+// SimilarListings returns the listings nearest to a listing's own embedding ("more like
+// this"), excluding the listing itself. Results are one page of up to options.TopK.
+func (s *SearchService) SimilarListings(
+	ctx context.Context,
+	listingID int64,
+	options *model.SearchOptions,
+	aggregate string,
+) (*model.SearchResponse, error) {
+	release, err := s.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	startTime := time.Now()
+
+	embedding, found, err := s.repo.ListingEmbedding(ctx, listingID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrListingNotFound
+	}
+	if embedding == nil {
+		return nil, ErrListingNotEmbedded
+	}
 
-	// Log search (non-blocking)
-	go func() {
-		listingIDs := make([]int64, len(results))
-		for i, r := range results {
-			listingIDs[i] = r.ListingID
+	merged := s.mergeFilters(nil, nil, nil)
+	merged.ExcludeIDs = []int64{listingID}
+	results, err := s.VectorSearch(ctx, embedding, merged, options.TopK, aggregate)
+	if err != nil {
+		return nil, err
+	}
+
+	response := buildSearchResponse(results, len(results), options, nil, time.Since(startTime).Milliseconds())
+	s.attachShareURLs(response, "")
+	s.attachMortgages(response, options.Mortgage)
+	applyFieldset(response, options.Fields)
+	s.stampFreshness(ctx, response)
+	return response, nil
+}
+
+// noKeywordOptions swaps a relevance sort for the configured default when there are no
+// keywords, or no full-text column to rank them with: every text rank would be zero, so
+// the repository skips ts_rank and orders by that column instead. The caller's options are
+// left untouched.
+func (s *SearchService) noKeywordOptions(options *model.SearchOptions, semanticKeywords []string) *model.SearchOptions {
+	hasKeywords := strings.TrimSpace(strings.Join(semanticKeywords, " ")) != ""
+	if s.repo != nil && !s.repo.FullTextEnabled() {
+		hasKeywords = false
+	}
+	if options.IsDBSort() || hasKeywords {
+		return options
+	}
+	if _, ok := model.SortColumns[s.config.NoKeywordSort]; !ok {
+		return options
+	}
+	sorted := *options
+	sorted.SortBy = s.config.NoKeywordSort
+	sorted.NullsOrder = ""
+	return &sorted
+}
+
+// resolveSortNulls fills in the configured NULL placement for the sorted column
+// when the request doesn't specify one
+func (s *SearchService) resolveSortNulls(options *model.SearchOptions) {
+	if !options.IsDBSort() || options.NullsOrder != "" {
+		return
+	}
+	options.NullsOrder = model.NullsLast
+	if nulls, ok := s.config.SortNulls[model.SortColumns[options.SortBy].Column]; ok {
+		options.NullsOrder = nulls
+	}
+}
+
+// stampFreshness records when the response was generated, when it should be refreshed,
+// and how old the underlying listing data is
+func (s *SearchService) stampFreshness(ctx context.Context, response *model.SearchResponse) {
+	now := time.Now()
+	response.GeneratedAt = now
+	response.ExpiresAt = now.Add(time.Duration(s.config.ResultTTL) * time.Second)
+
+	// Prefer the newest result; fall back to the global last update when nothing matched
+	var lastUpdated *time.Time
+	for i := range response.Results {
+		updatedAt := response.Results[i].UpdatedAt
+		if lastUpdated == nil || updatedAt.After(*lastUpdated) {
+			lastUpdated = &updatedAt
 		}
-		_ = s.repo.LogSearch(context.Background(), req.Query, intentResult.Slots, intentResult.SemanticKeywords, total, listingIDs, int(took))
-	}()
+	}
+	if lastUpdated == nil {
+		latest, err := s.repo.LatestListingUpdate(ctx)
+		if err != nil {
+			log.Printf("Warning: failed to determine data freshness: %v", err)
+			return
+		}
+		lastUpdated = latest
+	}
+	if lastUpdated == nil {
+		return
+	}
 
+	ageHours := now.Sub(*lastUpdated).Hours()
+	response.DataFreshness = &model.DataFreshness{
+		LastUpdatedAt: lastUpdated,
+		AgeHours:      math.Round(ageHours*10) / 10,
+		Stale:         s.config.StaleAfterHours > 0 && ageHours > float64(s.config.StaleAfterHours),
+	}
+}
+
+// applyFieldset limits every result (including broader matches) to the requested listing fields
+func applyFieldset(response *model.SearchResponse, fields []string) {
+	if len(fields) == 0 {
+		return
+	}
+	for i := range response.Results {
+		response.Results[i].SetFields(fields)
+	}
+	if response.BroaderMatches != nil {
+		for i := range response.BroaderMatches.Results {
+			response.BroaderMatches.Results[i].SetFields(fields)
+		}
+	}
+}
+
+// buildSearchResponse computes pagination fields and assembles the response
+func buildSearchResponse(
+	results []model.ListingSearchResult,
+	total int,
+	options *model.SearchOptions,
+	intentResult *model.IntentResult,
+	took int64,
+) *model.SearchResponse {
 	pageSize := options.TopK
 	if pageSize <= 0 {
 		pageSize = len(results)
@@ -293,12 +646,42 @@ func (s *SearchService) SearchStream(ctx context.Context, req *model.SearchReque
 		HasMore:    hasMore,
 		Intent:     intentResult,
 		Took:       took,
-	}, nil
+	}
 }
 
 // GetListing retrieves a single listing by ID
 func (s *SearchService) GetListing(ctx context.Context, listingID int64) (*model.Listing, error) {
-	return s.repo.GetListingByID(ctx, listingID)
+	listing, err := s.repo.GetListingByID(ctx, listingID)
+	if err != nil || listing == nil {
+		return listing, err
+	}
+	deriveFields(listing, s.imagePaths(), time.Now())
+	return listing, nil
+}
+
+// deriveListingFields fills in price_per_sqft wherever it can be computed from price and area,
+// the typed images extracted from property_details, the lease left on leasehold listings, and
+// the walking minutes to MRT
+func (s *SearchService) deriveListingFields(listings []model.Listing) {
+	paths := s.imagePaths()
+	now := time.Now()
+	for i := range listings {
+		deriveFields(&listings[i], paths, now)
+	}
+}
+
+// deriveFields fills in one listing's derived fields
+func deriveFields(listing *model.Listing, paths model.ImagePaths, now time.Time) {
+	listing.DerivePricePerSqft()
+	listing.ExtractImages(paths)
+	listing.DeriveLeaseRemaining(now)
+	listing.DeriveMRTWalkMinutes()
+	listing.EscapeHighlight()
+}
+
+// imagePaths returns where listing images live in property_details
+func (s *SearchService) imagePaths() model.ImagePaths {
+	return model.ImagePaths{Images: s.config.ImagesPath, Primary: s.config.PrimaryImagePath}
 }
 
 // UpdateEmbeddings updates embeddings for multiple listings
@@ -306,13 +689,59 @@ func (s *SearchService) UpdateEmbeddings(ctx context.Context, items []model.Embe
 	return s.repo.BatchUpdateEmbeddings(ctx, items)
 }
 
+// PurgeStaleListings marks or deletes listings the crawler hasn't updated within olderThan
+func (s *SearchService) PurgeStaleListings(ctx context.Context, olderThan time.Duration, mode string, clearEmbeddings, dryRun bool) (*repository.StaleListingPurge, error) {
+	return s.repo.PurgeStaleListings(ctx, olderThan, mode, clearEmbeddings, dryRun)
+}
+
+// ExportListings streams every searchable listing matching filters to fn, one at a time and
+// with derived fields filled in, for bulk exports. limit caps the rows (0 = all).
+func (s *SearchService) ExportListings(ctx context.Context, filters *model.SearchFilters, fields []string, limit int, fn func(*model.Listing) error) error {
+	paths := s.imagePaths()
+	now := time.Now()
+	return s.repo.StreamWithFilters(ctx, filters, model.SelectColumns(fields), limit, func(listing *model.Listing) error {
+		deriveFields(listing, paths, now)
+		return fn(listing)
+	})
+}
+
+// DeleteListing deletes a listing with its embeddings and log references; nil when it doesn't exist
+func (s *SearchService) DeleteListing(ctx context.Context, listingID int64) (*repository.ListingDeletion, error) {
+	return s.repo.DeleteListing(ctx, listingID)
+}
+
 // LogFeedback logs user feedback/action
 func (s *SearchService) LogFeedback(ctx context.Context, searchID string, listingID int64, action string) error {
 	return s.repo.LogFeedback(ctx, searchID, listingID, action)
 }
 
-// mergeFilters merges explicit filters with extracted intent slots
-func (s *SearchService) mergeFilters(explicit *model.SearchFilters, slots *model.IntentSlots) *model.SearchFilters {
+// excludeSeen leaves out listings the session already gave feedback on when the request
+// opts in. Lookup failures are logged and the search proceeds unfiltered.
+func (s *SearchService) excludeSeen(ctx context.Context, filters *model.SearchFilters, sessionID string, options *model.SearchOptions) {
+	if !options.ExcludeSeen || sessionID == "" || s.config.SeenMaxIDs <= 0 {
+		return
+	}
+	since := time.Now().AddDate(0, 0, -s.config.SeenLookbackDays)
+	seen, err := s.repo.SeenListingIDs(ctx, sessionID, since, s.config.SeenMaxIDs)
+	if err != nil {
+		log.Printf("⚠️  Failed to load seen listings: %v", err)
+		return
+	}
+	filters.ExcludeIDs = append(filters.ExcludeIDs, seen...)
+}
+
+// saneBuildYear drops a build year outside [minBuildYear, maxBuildYear], logging the field
+func saneBuildYear(field string, year *int) *int {
+	if year != nil && (*year < minBuildYear || *year > maxBuildYear) {
+		log.Printf("⚠️  Ignoring out-of-range %s: %d", field, *year)
+		return nil
+	}
+	return year
+}
+
+// mergeFilters merges explicit filters with extracted intent slots. Explicit filters win
+// over slots, and overrides win over both for the fields they list.
+func (s *SearchService) mergeFilters(explicit *model.SearchFilters, slots *model.IntentSlots, overrides model.FilterOverrides) *model.SearchFilters {
 	// Start with explicit filters
 	merged := &model.SearchFilters{}
 	if explicit != nil {
@@ -327,11 +756,17 @@ func (s *SearchService) mergeFilters(explicit *model.SearchFilters, slots *model
 		if merged.PriceMax == nil && slots.PriceMax != nil {
 			merged.PriceMax = slots.PriceMax
 		}
-		if merged.Bedrooms == nil && slots.Bedrooms != nil {
+		if merged.PriceTarget == nil && slots.PriceTarget != nil {
+			merged.PriceTarget = slots.PriceTarget
+		}
+		// Exact and minimum counts fill in together, so an explicit one isn't narrowed by the other
+		if merged.Bedrooms == nil && merged.BedroomsMin == nil {
 			merged.Bedrooms = slots.Bedrooms
+			merged.BedroomsMin = slots.BedroomsMin
 		}
-		if merged.Bathrooms == nil && slots.Bathrooms != nil {
+		if merged.Bathrooms == nil && merged.BathroomsMin == nil {
 			merged.Bathrooms = slots.Bathrooms
+			merged.BathroomsMin = slots.BathroomsMin
 		}
 		if merged.AreaSqftMin == nil && slots.AreaSqftMin != nil {
 			merged.AreaSqftMin = slots.AreaSqftMin
@@ -339,14 +774,40 @@ func (s *SearchService) mergeFilters(explicit *model.SearchFilters, slots *model
 		if merged.AreaSqftMax == nil && slots.AreaSqftMax != nil {
 			merged.AreaSqftMax = slots.AreaSqftMax
 		}
-		if merged.UnitType == nil && slots.UnitType != nil {
-			merged.UnitType = slots.UnitType
+		// Unit types fill in together, like locations
+		if merged.UnitType == nil && len(merged.UnitTypes) == 0 {
+			if slots.UnitType != nil {
+				merged.UnitType = model.StringOrList{*slots.UnitType}
+			}
+			merged.UnitTypes = slots.UnitTypes
 		}
+		// The slot is walking minutes; the filter is metres, like mrt_distance_m
 		if merged.MRTDistanceMax == nil && slots.MRTDistanceMax != nil {
-			merged.MRTDistanceMax = slots.MRTDistanceMax
+			meters := utils.WalkMinutesToMeters(*slots.MRTDistanceMax)
+			merged.MRTDistanceMax = &meters
 		}
-		if merged.Location == nil && slots.Location != nil {
+		if merged.MRTStation == nil && slots.MRTStation != nil {
+			merged.MRTStation = slots.MRTStation
+		}
+		// MRT lines fill in together, like unit types
+		if merged.MRTLine == nil && len(merged.MRTLines) == 0 {
+			merged.MRTLine = slots.MRTLine
+			merged.MRTLines = slots.MRTLines
+		}
+		// Explicit and inferred areas combine: a listing in any of them matches
+		if merged.Location == nil && len(merged.Locations) == 0 {
 			merged.Location = slots.Location
+			merged.Locations = slots.Locations
+		} else if slots.Location != nil || len(slots.Locations) > 0 {
+			inferred := slots.Locations
+			if slots.Location != nil {
+				inferred = append([]string{*slots.Location}, inferred...)
+			}
+			merged.Locations = model.DistinctLocations(append(merged.AllLocations(), inferred...)...)
+			merged.Location = nil
+		}
+		if merged.District == nil {
+			merged.District = slots.District
 		}
 		if len(merged.Amenities) == 0 && len(slots.Amenities) > 0 {
 			merged.Amenities = slots.Amenities
@@ -354,11 +815,94 @@ func (s *SearchService) mergeFilters(explicit *model.SearchFilters, slots *model
 		if len(merged.Facilities) == 0 && len(slots.Facilities) > 0 {
 			merged.Facilities = slots.Facilities
 		}
+		if merged.LeaseRemainingMin == nil && slots.LeaseRemainingMin != nil {
+			merged.LeaseRemainingMin = slots.LeaseRemainingMin
+		}
+		if merged.BuildYearMin == nil && slots.BuildYearMin != nil {
+			merged.BuildYearMin = slots.BuildYearMin
+		}
+		if merged.BuildYearMax == nil && slots.BuildYearMax != nil {
+			merged.BuildYearMax = slots.BuildYearMax
+		}
+		if merged.PricePerSqftMin == nil && slots.PricePerSqftMin != nil {
+			merged.PricePerSqftMin = slots.PricePerSqftMin
+		}
+		if merged.PricePerSqftMax == nil && slots.PricePerSqftMax != nil {
+			merged.PricePerSqftMax = slots.PricePerSqftMax
+		}
+		if merged.GreenScoreMin == nil && slots.GreenScoreMin != nil {
+			merged.GreenScoreMin = slots.GreenScoreMin
+		}
+		if len(merged.ExcludeUnitTypes) == 0 && len(slots.ExcludeUnitTypes) > 0 {
+			merged.ExcludeUnitTypes = slots.ExcludeUnitTypes
+		}
+		if len(merged.ExcludeLocations) == 0 && len(slots.ExcludeLocations) > 0 {
+			merged.ExcludeLocations = slots.ExcludeLocations
+		}
+		if len(merged.ExcludeKeywords) == 0 && len(slots.ExcludeKeywords) > 0 {
+			merged.ExcludeKeywords = slots.ExcludeKeywords
+		}
 	}
 
+	// User corrections to individual filters; validated by the handler
+	// Correcting any location, unit type, or MRT line field replaces the whole set
+	if overridesAny(overrides, "location", "locations", "district") {
+		merged.Location, merged.Locations, merged.District = nil, nil, nil
+	}
+	if overridesOnly(overrides, "unit_type", "unit_types") {
+		merged.UnitTypes = nil
+	}
+	if overridesOnly(overrides, "unit_types", "unit_type") {
+		merged.UnitType = nil
+	}
+	if overridesOnly(overrides, "mrt_line", "mrt_lines") {
+		merged.MRTLines = nil
+	}
+	if overridesOnly(overrides, "mrt_lines", "mrt_line") {
+		merged.MRTLine = nil
+	}
+	// Likewise an exact or minimum room count replaces the other
+	if overridesOnly(overrides, "bedrooms", "bedrooms_min") {
+		merged.BedroomsMin = nil
+	}
+	if overridesOnly(overrides, "bedrooms_min", "bedrooms") {
+		merged.Bedrooms = nil
+	}
+	if overridesOnly(overrides, "bathrooms", "bathrooms_min") {
+		merged.BathroomsMin = nil
+	}
+	if overridesOnly(overrides, "bathrooms_min", "bathrooms") {
+		merged.Bathrooms = nil
+	}
+	if err := overrides.Apply(merged); err != nil {
+		log.Printf("⚠️  Ignoring invalid filter overrides: %v", err)
+	}
+
+	// Overrides skip request binding, so build years are range-checked here
+	merged.BuildYearMin = saneBuildYear("build_year_min", merged.BuildYearMin)
+	merged.BuildYearMax = saneBuildYear("build_year_max", merged.BuildYearMax)
+
 	// Always ensure completed listings only
 	trueVal := true
 	merged.IsCompleted = &trueVal
 
 	return merged
 }
+
+// overridesAny reports whether the overrides correct any of fields; the overridden ones are
+// set again when the overrides are applied
+func overridesAny(overrides model.FilterOverrides, fields ...string) bool {
+	for _, field := range fields {
+		if _, ok := overrides[field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// overridesOnly reports whether the overrides correct field but leave its counterpart alone
+func overridesOnly(overrides model.FilterOverrides, field, counterpart string) bool {
+	_, ok := overrides[field]
+	_, counterpartOK := overrides[counterpart]
+	return ok && !counterpartOK
+}
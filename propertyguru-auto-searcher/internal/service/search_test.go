@@ -0,0 +1,531 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"core/internal/config"
+	"core/internal/model"
+	"core/internal/repository"
+)
+
+// fakeRepository serves SearchWithFilters from a fixed set of listings sorted by listing_id
+// and records the calls. Methods it doesn't override panic through the nil searchRepository.
+type fakeRepository struct {
+	searchRepository
+
+	mu       sync.Mutex
+	listings []model.Listing
+	searches []fakeSearchCall
+
+	sessions map[string]string // search_id -> session_id, as in search_logs
+	feedback []fakeFeedback    // One row per action, as in user_feedback
+}
+
+type fakeSearchCall struct {
+	filters  model.SearchFilters
+	keywords []string
+	options  model.SearchOptions
+}
+
+type fakeFeedback struct {
+	sessionID string
+	listingID int64
+}
+
+func (r *fakeRepository) SearchWithFilters(ctx context.Context, filters *model.SearchFilters, semanticKeywords []string, options *model.SearchOptions) ([]model.Listing, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.searches = append(r.searches, fakeSearchCall{filters: *filters, keywords: semanticKeywords, options: *options})
+	start := min(options.Offset, len(r.listings))
+	end := min(start+options.TopK, len(r.listings))
+	return append([]model.Listing(nil), r.listings[start:end]...), len(r.listings), nil
+}
+
+func (r *fakeRepository) FullTextEnabled() bool { return true }
+
+func (r *fakeRepository) LogFeedback(ctx context.Context, searchID string, listingID int64, action string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.feedback = append(r.feedback, fakeFeedback{sessionID: r.sessions[searchID], listingID: listingID})
+	return nil
+}
+
+func (r *fakeRepository) SeenListingIDs(ctx context.Context, sessionID string, since time.Time, limit int) ([]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ids []int64
+	for i := len(r.feedback) - 1; i >= 0 && len(ids) < limit; i-- {
+		if r.feedback[i].sessionID == sessionID {
+			ids = append(ids, r.feedback[i].listingID)
+		}
+	}
+	return ids, nil
+}
+
+func (r *fakeRepository) LatestListingUpdate(ctx context.Context) (*time.Time, error) {
+	return nil, nil
+}
+
+func (r *fakeRepository) LogSearch(ctx context.Context, searchID, sessionID string, query string, slots *model.IntentSlots, keywords []string, resultCount int, listingIDs []int64, responseTimeMs int) error {
+	return nil
+}
+
+// fakeListings returns n listings with ids 1..n
+func fakeListings(n int) []model.Listing {
+	listings := make([]model.Listing, n)
+	for i := range listings {
+		listings[i].ListingID = int64(i + 1)
+	}
+	return listings
+}
+
+// newFakeIntentParser parses every query into the given AI response
+func newFakeIntentParser(t *testing.T, content string) *IntentParser {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": content}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	client := NewOpenAIClient(&config.OpenAIConfig{Enabled: true, APIKey: "test", APIBase: server.URL, Timeout: 5})
+	return NewIntentParser(client, nil, SchemaMismatchUpgrade)
+}
+
+func TestNoKeywordOptions(t *testing.T) {
+	s := &SearchService{config: &config.SearchConfig{NoKeywordSort: model.SortNewest}}
+
+	options := &model.SearchOptions{TopK: 20, SortBy: model.SortRelevance}
+	got := s.noKeywordOptions(options, []string{" "})
+	if got.SortBy != model.SortNewest {
+		t.Errorf("Expected sort_by %q without keywords, got %q", model.SortNewest, got.SortBy)
+	}
+	if options.SortBy != model.SortRelevance {
+		t.Error("Expected caller's options to be left untouched")
+	}
+
+	if got := s.noKeywordOptions(options, []string{"pool"}); got != options {
+		t.Error("Expected options unchanged when keywords are present")
+	}
+
+	priceSort := &model.SearchOptions{SortBy: model.SortPriceAsc}
+	if got := s.noKeywordOptions(priceSort, nil); got != priceSort {
+		t.Error("Expected explicit database sort to be kept")
+	}
+
+	s.config.NoKeywordSort = model.SortRelevance
+	if got := s.noKeywordOptions(options, nil); got != options {
+		t.Error("Expected relevance fallback to leave options unchanged")
+	}
+}
+
+func TestNoKeywordOptionsWithoutFullText(t *testing.T) {
+	repo := &repository.PostgresRepository{}
+	repo.SetFullText(false)
+	s := &SearchService{repo: repo, config: &config.SearchConfig{NoKeywordSort: model.SortNewest}}
+
+	options := &model.SearchOptions{TopK: 20, SortBy: model.SortRelevance}
+	if got := s.noKeywordOptions(options, []string{"pool"}); got.SortBy != model.SortNewest {
+		t.Errorf("Expected keywords to fall back to %q without search_vector, got %q", model.SortNewest, got.SortBy)
+	}
+}
+
+func TestSearchFilterOnlyQuerySkipsTextRank(t *testing.T) {
+	repo := &fakeRepository{listings: fakeListings(3)}
+	s := &SearchService{
+		repo:   repo,
+		intent: newFakeIntentParser(t, `{"bedrooms": 3, "unit_type": "Condo"}`),
+		ranker: NewRanker(&config.RankingConfig{WeightText: 0.5, WeightPrice: 0.3, WeightRecency: 0.2}),
+		config: &config.SearchConfig{NoKeywordSort: model.SortNewest, RankWindow: 200},
+	}
+
+	req := &model.SearchRequest{Query: "3 bed condo", Options: &model.SearchOptions{TopK: 20, SortBy: model.SortRelevance}}
+	response, err := s.Search(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(response.Intent.SemanticKeywords) != 0 {
+		t.Errorf("Expected no keywords for a filter-only query, got %v", response.Intent.SemanticKeywords)
+	}
+	if len(repo.searches) != 1 {
+		t.Fatalf("Expected one database search, got %d", len(repo.searches))
+	}
+	call := repo.searches[0]
+	if len(call.keywords) != 0 || call.options.SortBy != model.SortNewest {
+		t.Errorf("Expected a %q search without keywords, got %q with %v", model.SortNewest, call.options.SortBy, call.keywords)
+	}
+
+	// With free-text terms left over the query still ranks by relevance
+	repo.searches = nil
+	s.intent = newFakeIntentParser(t, `{"bedrooms": 3, "keywords": ["sea view"]}`)
+	if _, err := s.Search(context.Background(), &model.SearchRequest{Query: "3 bed with sea view"}); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if call := repo.searches[0]; call.options.IsDBSort() || !reflect.DeepEqual(call.keywords, []string{"sea view", "3 bed with sea view"}) {
+		t.Errorf("Expected a relevance search on the keywords and query, got %q with %v", call.options.SortBy, call.keywords)
+	}
+}
+
+func TestSearchExcludeSeenKeepsEveryActionOfASearch(t *testing.T) {
+	repo := &fakeRepository{listings: fakeListings(5), sessions: map[string]string{"search-1": "session-1"}}
+	s := &SearchService{
+		repo:   repo,
+		intent: newFakeIntentParser(t, `{"bedrooms": 3}`),
+		ranker: NewRanker(&config.RankingConfig{WeightText: 0.5, WeightPrice: 0.3, WeightRecency: 0.2}),
+		config: &config.SearchConfig{NoKeywordSort: model.SortNewest, SeenLookbackDays: 30, SeenMaxIDs: 500},
+	}
+
+	ctx := context.Background()
+	for _, listingID := range []int64{2, 4} {
+		if err := s.LogFeedback(ctx, "search-1", listingID, "dismiss"); err != nil {
+			t.Fatalf("LogFeedback failed: %v", err)
+		}
+	}
+
+	req := &model.SearchRequest{Query: "3 bed", SessionID: "session-1", Options: &model.SearchOptions{TopK: 20, ExcludeSeen: true}}
+	if _, err := s.Search(ctx, req); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	excluded := repo.searches[0].filters.ExcludeIDs
+	if len(excluded) != 2 || !slices.Contains(excluded, 2) || !slices.Contains(excluded, 4) {
+		t.Errorf("Expected both dismissed listings excluded, got %v", excluded)
+	}
+}
+
+func TestMergeFiltersOverridesBeatInference(t *testing.T) {
+	s := &SearchService{config: &config.SearchConfig{}}
+	slots := &model.IntentSlots{
+		Location: stringPtr("Tampines"),
+		Bedrooms: intPtr(3),
+		PriceMax: float64Ptr(3000),
+	}
+	explicit := &model.SearchFilters{PriceMax: float64Ptr(2500)}
+	overrides := model.FilterOverrides{
+		"location": []byte(`"Bedok"`),
+		"bedrooms": []byte(`null`),
+	}
+
+	merged := s.mergeFilters(explicit, slots, overrides)
+	if merged.Location == nil || *merged.Location != "Bedok" {
+		t.Errorf("Expected overridden location Bedok, got %v", merged.Location)
+	}
+	if merged.Bedrooms != nil {
+		t.Errorf("Expected null override to clear inferred bedrooms, got %d", *merged.Bedrooms)
+	}
+	if merged.PriceMax == nil || *merged.PriceMax != 2500 {
+		t.Errorf("Expected explicit price_max to still win over inference, got %v", merged.PriceMax)
+	}
+	if merged.IsCompleted == nil || !*merged.IsCompleted {
+		t.Error("Expected completed listings only")
+	}
+}
+
+func TestMergeFiltersLocations(t *testing.T) {
+	s := &SearchService{config: &config.SearchConfig{}}
+	slots := &model.IntentSlots{Locations: []string{"Punggol", "Sengkang"}}
+
+	merged := s.mergeFilters(nil, slots, nil)
+	if got := merged.AllLocations(); len(got) != 2 {
+		t.Errorf("Expected both inferred locations, got %v", got)
+	}
+
+	explicit := &model.SearchFilters{Location: stringPtr("Hougang"), Locations: []string{"sengkang"}}
+	merged = s.mergeFilters(explicit, slots, nil)
+	want := []string{"Hougang", "sengkang", "Punggol"}
+	if got := merged.AllLocations(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected explicit and inferred locations combined without duplicates, got %v, want %v", got, want)
+	}
+	if explicit.Locations[0] != "sengkang" || len(explicit.Locations) != 1 {
+		t.Errorf("Expected the explicit filters untouched, got %v", explicit.Locations)
+	}
+
+	merged = s.mergeFilters(&model.SearchFilters{District: stringPtr("D19")}, &model.IntentSlots{Location: stringPtr("Bishan")}, nil)
+	if got := merged.AllLocations(); len(got) != 1 || got[0] != "Bishan" || merged.District == nil || *merged.District != "D19" {
+		t.Errorf("Expected an inferred location alongside the explicit district, got %v and %v", got, merged.District)
+	}
+
+	overrides := model.FilterOverrides{"location": []byte(`"Bedok"`)}
+	merged = s.mergeFilters(nil, slots, overrides)
+	if got := merged.AllLocations(); len(got) != 1 || got[0] != "Bedok" {
+		t.Errorf("Expected a location override to replace every inferred area, got %v", got)
+	}
+}
+
+func TestMergeFiltersUnitTypes(t *testing.T) {
+	s := &SearchService{config: &config.SearchConfig{}}
+	slots := &model.IntentSlots{UnitTypes: []string{"Condo", "EC"}}
+
+	merged := s.mergeFilters(nil, slots, nil)
+	if got := merged.AllUnitTypes(); len(got) != 2 {
+		t.Errorf("Expected both inferred unit types, got %v", got)
+	}
+
+	merged = s.mergeFilters(&model.SearchFilters{UnitType: model.StringOrList{"HDB"}}, slots, nil)
+	if got := merged.AllUnitTypes(); len(got) != 1 || got[0] != "HDB" {
+		t.Errorf("Expected the explicit unit type to replace inferred ones, got %v", got)
+	}
+
+	overrides := model.FilterOverrides{"unit_type": []byte(`"Landed"`)}
+	merged = s.mergeFilters(nil, slots, overrides)
+	if got := merged.AllUnitTypes(); len(got) != 1 || got[0] != "Landed" {
+		t.Errorf("Expected a unit type override to replace every inferred type, got %v", got)
+	}
+}
+
+func TestMergeFiltersMRTDistanceInMeters(t *testing.T) {
+	s := &SearchService{config: &config.SearchConfig{}}
+	slots := &model.IntentSlots{MRTDistanceMax: intPtr(15)}
+
+	if merged := s.mergeFilters(nil, slots, nil); !equalIntPtr(merged.MRTDistanceMax, intPtr(1200)) {
+		t.Errorf("Expected 15 walking minutes as 1200 m, got %v", merged.MRTDistanceMax)
+	}
+	explicit := &model.SearchFilters{MRTDistanceMax: intPtr(500)}
+	if merged := s.mergeFilters(explicit, slots, nil); !equalIntPtr(merged.MRTDistanceMax, intPtr(500)) {
+		t.Errorf("Expected the explicit distance in metres kept, got %v", merged.MRTDistanceMax)
+	}
+}
+
+func TestMergeFiltersExclusions(t *testing.T) {
+	s := &SearchService{config: &config.SearchConfig{}}
+	slots := &model.IntentSlots{ExcludeUnitTypes: []string{"HDB"}, ExcludeKeywords: []string{"highway"}}
+
+	explicit := &model.SearchFilters{ExcludeKeywords: []string{"ground floor"}}
+	merged := s.mergeFilters(explicit, slots, nil)
+	if !reflect.DeepEqual(merged.ExcludeUnitTypes, []string{"HDB"}) || !reflect.DeepEqual(merged.ExcludeKeywords, []string{"ground floor"}) {
+		t.Errorf("Expected inferred unit type exclusions and explicit keyword exclusions, got %v and %v", merged.ExcludeUnitTypes, merged.ExcludeKeywords)
+	}
+	if merged.UnitType != nil || len(merged.UnitTypes) != 0 {
+		t.Errorf("Expected exclusions not to become unit type filters, got %+v", merged)
+	}
+}
+
+func TestMergeFiltersRoomCounts(t *testing.T) {
+	s := &SearchService{config: &config.SearchConfig{}}
+	slots := &model.IntentSlots{BedroomsMin: intPtr(3), Bathrooms: intPtr(2)}
+
+	merged := s.mergeFilters(nil, slots, nil)
+	if !equalIntPtr(merged.BedroomsMin, intPtr(3)) || merged.Bedrooms != nil || !equalIntPtr(merged.Bathrooms, intPtr(2)) {
+		t.Errorf("Expected the inferred room counts, got %+v", merged)
+	}
+
+	// An explicit exact count isn't narrowed by an inferred minimum
+	merged = s.mergeFilters(&model.SearchFilters{Bedrooms: intPtr(4)}, slots, nil)
+	if !equalIntPtr(merged.Bedrooms, intPtr(4)) || merged.BedroomsMin != nil {
+		t.Errorf("Expected only the explicit bedrooms, got %v and min %v", derefInt(merged.Bedrooms), derefInt(merged.BedroomsMin))
+	}
+
+	overrides := model.FilterOverrides{"bedrooms": []byte(`2`)}
+	merged = s.mergeFilters(nil, slots, overrides)
+	if !equalIntPtr(merged.Bedrooms, intPtr(2)) || merged.BedroomsMin != nil {
+		t.Errorf("Expected a bedrooms override to replace the inferred minimum, got %v and min %v", derefInt(merged.Bedrooms), derefInt(merged.BedroomsMin))
+	}
+}
+
+func TestMergeFiltersBuildYears(t *testing.T) {
+	s := &SearchService{config: &config.SearchConfig{}}
+	slots := &model.IntentSlots{BuildYearMin: intPtr(2016), BuildYearMax: intPtr(2020)}
+
+	merged := s.mergeFilters(&model.SearchFilters{BuildYearMax: intPtr(2022)}, slots, nil)
+	if merged.BuildYearMin == nil || *merged.BuildYearMin != 2016 || merged.BuildYearMax == nil || *merged.BuildYearMax != 2022 {
+		t.Errorf("Expected the inferred minimum and explicit maximum, got %v-%v", merged.BuildYearMin, merged.BuildYearMax)
+	}
+
+	overrides := model.FilterOverrides{"build_year_min": []byte(`15`)}
+	merged = s.mergeFilters(nil, slots, overrides)
+	if merged.BuildYearMin != nil {
+		t.Errorf("Expected an out-of-range override dropped, got %d", *merged.BuildYearMin)
+	}
+}
+
+func TestScannedTextRanksDriveRanking(t *testing.T) {
+	ranker := NewRanker(testRankingConfig())
+
+	// Database order puts the weak match first; only ts_rank should decide the order
+	weak, strong := 0.01, 0.08
+	listings := []model.Listing{
+		{ListingID: 1, TextRank: &weak},
+		{ListingID: 2},
+		{ListingID: 3, TextRank: &strong},
+	}
+
+	textRanks := scannedTextRanks(listings)
+	if textRanks[3] != strong || textRanks[1] != weak {
+		t.Errorf("Expected the scanned ts_rank values, got %v", textRanks)
+	}
+	if _, ok := textRanks[2]; ok {
+		t.Errorf("Expected no text rank for a listing without ts_rank, got %v", textRanks[2])
+	}
+
+	ranked := ranker.RankResults(listings, textRanks, nil, nil)
+	if ranked[0].ListingID != 3 || ranked[1].ListingID != 1 || ranked[2].ListingID != 2 {
+		t.Fatalf("Expected order 3, 1, 2 by ts_rank, got %d, %d, %d", ranked[0].ListingID, ranked[1].ListingID, ranked[2].ListingID)
+	}
+	// The same ts_rank scores the same whatever else is in the set
+	alone := ranker.RankResults(listings[:1], scannedTextRanks(listings[:1]), nil, nil)
+	if alone[0].Score != ranked[1].Score {
+		t.Errorf("Expected ts_rank %.2f to score %.3f on its own too, got %.3f", weak, ranked[1].Score, alone[0].Score)
+	}
+}
+
+func TestSearchRanksByScannedTextRank(t *testing.T) {
+	// Database order puts the weak match first, as positional ranks would keep it
+	weak, strong := 0.01, 0.08
+	listings := fakeListings(3)
+	listings[0].TextRank = &weak
+	listings[2].TextRank = &strong
+
+	for _, window := range []int{0, 200} {
+		s := &SearchService{
+			repo:   &fakeRepository{listings: listings},
+			intent: newFakeIntentParser(t, `{"keywords": ["pool"]}`),
+			ranker: NewRanker(&config.RankingConfig{WeightText: 0.5, WeightPrice: 0.3, WeightRecency: 0.2}),
+			config: &config.SearchConfig{RankWindow: window},
+		}
+		newRequest := func() *model.SearchRequest {
+			return &model.SearchRequest{Query: "pool", Options: &model.SearchOptions{TopK: 20, SortBy: model.SortRelevance}}
+		}
+
+		response, err := s.Search(context.Background(), newRequest())
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		streamed, err := s.SearchStream(context.Background(), newRequest(), func(string, any) error { return nil })
+		if err != nil {
+			t.Fatalf("SearchStream failed: %v", err)
+		}
+		for name, results := range map[string][]model.ListingSearchResult{"Search": response.Results, "SearchStream": streamed.Results} {
+			var order []int64
+			for _, result := range results {
+				order = append(order, result.ListingID)
+			}
+			if !reflect.DeepEqual(order, []int64{3, 1, 2}) {
+				t.Errorf("%s with rank window %d: expected order [3 1 2] by ts_rank, got %v", name, window, order)
+			}
+		}
+	}
+}
+
+func TestRankedPagesHaveNoOverlapsOrGaps(t *testing.T) {
+	ranker := NewRanker(testRankingConfig())
+
+	// Many ties: only three distinct text ranks and no prices or dates
+	listings := make([]model.Listing, 23)
+	for i := range listings {
+		rank := float64(i%3) / 10
+		listings[i] = model.Listing{ListingID: int64(100 - i), TextRank: &rank}
+	}
+	ranked := ranker.RankResults(listings, scannedTextRanks(listings), nil, nil)
+
+	// The order doesn't depend on the order the database returned rows in
+	reversed := make([]model.Listing, len(listings))
+	for i, listing := range listings {
+		reversed[len(listings)-1-i] = listing
+	}
+	again := ranker.RankResults(reversed, scannedTextRanks(reversed), nil, nil)
+	for i := range ranked {
+		if ranked[i].ListingID != again[i].ListingID {
+			t.Fatalf("Expected a deterministic order, position %d differs: %d vs %d", i, ranked[i].ListingID, again[i].ListingID)
+		}
+	}
+
+	seen := make(map[int64]bool)
+	var paged []int64
+	for offset := 0; offset < len(listings)+5; offset += 5 {
+		for _, result := range pageResults(ranked, offset, 5) {
+			if seen[result.ListingID] {
+				t.Fatalf("Listing %d appeared on more than one page", result.ListingID)
+			}
+			seen[result.ListingID] = true
+			paged = append(paged, result.ListingID)
+		}
+	}
+	if len(paged) != len(listings) {
+		t.Fatalf("Expected %d listings across pages, got %d", len(listings), len(paged))
+	}
+	for i := range ranked {
+		if paged[i] != ranked[i].ListingID {
+			t.Fatalf("Expected pages to follow the ranked order, position %d: %d vs %d", i, paged[i], ranked[i].ListingID)
+		}
+		if i > 0 && ranked[i-1].Score == ranked[i].Score && ranked[i-1].ListingID > ranked[i].ListingID {
+			t.Errorf("Expected tied scores ordered by listing_id at position %d", i)
+		}
+	}
+}
+
+func TestRankWindowPagesAcrossTheWindowBoundary(t *testing.T) {
+	// Text rank grows with listing_id, so ranking reverses the database order
+	listings := fakeListings(30)
+	for i := range listings {
+		rank := float64(i+1) / 100
+		listings[i].TextRank = &rank
+	}
+	repo := &fakeRepository{listings: listings}
+	s := &SearchService{
+		repo:   repo,
+		ranker: NewRanker(&config.RankingConfig{WeightText: 0.5, WeightPrice: 0.3, WeightRecency: 0.2}),
+		config: &config.SearchConfig{RankWindow: 12},
+	}
+
+	ctx := context.Background()
+	keywords := []string{"pool"}
+	seen := make(map[int64]bool)
+	var paged []int64
+	for offset := 0; offset < len(listings); offset += 5 {
+		repo.searches = nil
+		options := &model.SearchOptions{TopK: 5, Offset: offset, SortBy: model.SortRelevance}
+		results, total, err := s.searchAndRank(ctx, &model.SearchFilters{}, keywords, options)
+		if err != nil {
+			t.Fatalf("searchAndRank failed at offset %d: %v", offset, err)
+		}
+		if total != len(listings) {
+			t.Errorf("Expected total %d, got %d", len(listings), total)
+		}
+		for _, result := range results {
+			if seen[result.ListingID] {
+				t.Fatalf("Listing %d appeared on more than one page (offset %d)", result.ListingID, offset)
+			}
+			seen[result.ListingID] = true
+			paged = append(paged, result.ListingID)
+		}
+
+		// Pages inside the window fetch the whole window
+		if offset < 12 {
+			if call := repo.searches[0].options; call.Offset != 0 || call.TopK != 12 {
+				t.Errorf("Expected offset %d to fetch the whole window, got offset %d top_k %d", offset, call.Offset, call.TopK)
+			}
+		}
+	}
+	if len(paged) != len(listings) {
+		t.Fatalf("Expected all %d listings across pages, got %d: %v", len(listings), len(paged), paged)
+	}
+
+	// The window is ranked as one set (12 down to 1); past it, pages follow the database
+	// order even though text rank keeps growing, starting with the page straddling the boundary
+	want := []int64{12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+	for id := int64(13); id <= 30; id++ {
+		want = append(want, id)
+	}
+	if !reflect.DeepEqual(paged, want) {
+		t.Errorf("Expected %v, got %v", want, paged)
+	}
+}
+
+func TestNewSearchServiceWithoutConfig(t *testing.T) {
+	s := NewSearchService(nil, nil, nil, nil)
+	if s.config == nil {
+		t.Fatal("Expected a zero config in place of nil")
+	}
+
+	response := &model.SearchResponse{Results: []model.ListingSearchResult{{Listing: model.Listing{ListingID: 1, UpdatedAt: time.Now()}}}}
+	s.stampFreshness(context.Background(), response)
+	if !response.ExpiresAt.Equal(response.GeneratedAt) || response.DataFreshness == nil || response.DataFreshness.Stale {
+		t.Errorf("Expected an immediately expiring, never stale response, got %+v", response)
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"core/internal/model"
+)
+
+// newSearchID returns a random UUIDv4 identifying one search for feedback correlation
+func newSearchID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// buildShareURL returns {base}/listings/{id}, tagged with the originating search when known
+func buildShareURL(baseURL string, listingID int64, searchID string) string {
+	shareURL := strings.TrimRight(baseURL, "/") + "/listings/" + strconv.FormatInt(listingID, 10)
+	if searchID != "" {
+		shareURL += "?" + url.Values{"search_id": {searchID}}.Encode()
+	}
+	return shareURL
+}
+
+// attachShareURLs fills share_url on every result (including broader matches).
+// Does nothing when SHARE_BASE_URL is not configured.
+func (s *SearchService) attachShareURLs(response *model.SearchResponse, searchID string) {
+	if s.config.ShareBaseURL == "" {
+		return
+	}
+	for i := range response.Results {
+		response.Results[i].ShareURL = buildShareURL(s.config.ShareBaseURL, response.Results[i].ListingID, searchID)
+	}
+	if response.BroaderMatches != nil {
+		for i := range response.BroaderMatches.Results {
+			result := &response.BroaderMatches.Results[i]
+			result.ShareURL = buildShareURL(s.config.ShareBaseURL, result.ListingID, searchID)
+		}
+	}
+}
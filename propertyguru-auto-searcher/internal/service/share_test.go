@@ -0,0 +1,34 @@
+package service
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestBuildShareURL(t *testing.T) {
+	tests := []struct {
+		base     string
+		searchID string
+		want     string
+	}{
+		{"https://homes.example.com", "abc-123", "https://homes.example.com/listings/42?search_id=abc-123"},
+		{"https://homes.example.com/app/", "", "https://homes.example.com/app/listings/42"},
+	}
+
+	for _, tt := range tests {
+		if got := buildShareURL(tt.base, 42, tt.searchID); got != tt.want {
+			t.Errorf("buildShareURL(%q, 42, %q) = %q, want %q", tt.base, tt.searchID, got, tt.want)
+		}
+	}
+}
+
+func TestNewSearchID(t *testing.T) {
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	id := newSearchID()
+	if !uuidPattern.MatchString(id) {
+		t.Errorf("newSearchID() = %q, want a UUIDv4", id)
+	}
+	if id == newSearchID() {
+		t.Error("Expected unique search IDs")
+	}
+}
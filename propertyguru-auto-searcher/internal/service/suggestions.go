@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"core/internal/model"
+	"core/internal/utils"
+)
+
+// maxSuggestions caps how many suggestions are computed and cached
+const maxSuggestions = 50
+
+// suggestionCache holds the most recent popular-query suggestions
+type suggestionCache struct {
+	mu      sync.Mutex
+	items   []model.Suggestion
+	expires time.Time
+}
+
+// Suggestions returns up to limit example queries drawn from past searches that led to a
+// click, most frequent first. Rare queries and queries containing personal data are dropped.
+func (s *SearchService) Suggestions(ctx context.Context, limit int) ([]model.Suggestion, error) {
+	s.suggestions.mu.Lock()
+	defer s.suggestions.mu.Unlock()
+
+	if s.suggestions.items == nil || time.Now().After(s.suggestions.expires) {
+		since := time.Now().AddDate(0, 0, -s.config.SuggestionLookbackDays)
+		// Over-fetch so dropping PII-bearing queries still leaves a full list
+		popular, err := s.repo.PopularQueries(ctx, since, s.config.SuggestionMinCount, maxSuggestions*2)
+		if err != nil {
+			return nil, err
+		}
+		s.suggestions.items = filterSuggestions(popular, maxSuggestions)
+		s.suggestions.expires = time.Now().Add(time.Duration(s.config.SuggestionCacheTTL) * time.Second)
+	}
+
+	items := s.suggestions.items
+	if limit < len(items) {
+		items = items[:limit]
+	}
+	return append([]model.Suggestion(nil), items...), nil
+}
+
+// filterSuggestions drops queries that look like they contain personal data or are too
+// short to be a useful example, keeping at most limit
+func filterSuggestions(popular []model.Suggestion, limit int) []model.Suggestion {
+	filtered := make([]model.Suggestion, 0, limit)
+	for _, suggestion := range popular {
+		if len(filtered) >= limit {
+			break
+		}
+		if len([]rune(suggestion.Query)) < 3 || utils.ContainsPII(suggestion.Query) {
+			continue
+		}
+		filtered = append(filtered, suggestion)
+	}
+	return filtered
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"testing"
+
+	"core/internal/model"
+)
+
+func TestFilterSuggestions(t *testing.T) {
+	popular := []model.Suggestion{
+		{Query: "3 bedroom condo near mrt", Count: 12},
+		{Query: "call me 91234567", Count: 9},
+		{Query: "hi", Count: 8},
+		{Query: "hdb punggol under 600k", Count: 5},
+		{Query: "landed bukit timah", Count: 4},
+	}
+
+	got := filterSuggestions(popular, 2)
+	if len(got) != 2 || got[0].Query != "3 bedroom condo near mrt" || got[1].Query != "hdb punggol under 600k" {
+		t.Errorf("filterSuggestions() = %v", got)
+	}
+}
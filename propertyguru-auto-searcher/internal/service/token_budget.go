@@ -0,0 +1,94 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBudget tracks LLM token usage against a fixed-window spending cap.
+// A limit of 0 disables enforcement (usage is still tracked for reporting).
+type TokenBudget struct {
+	mu          sync.Mutex
+	limit       int
+	window      time.Duration
+	used        int
+	windowStart time.Time
+	now         func() time.Time
+}
+
+// TokenBudgetStatus is a point-in-time snapshot of the token budget
+type TokenBudgetStatus struct {
+	Enabled     bool      `json:"enabled"`
+	Limit       int       `json:"limit"`
+	Used        int       `json:"used"`
+	Remaining   int       `json:"remaining"`
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+}
+
+// NewTokenBudget creates a token budget with the given limit per window
+func NewTokenBudget(limit int, window time.Duration) *TokenBudget {
+	if window <= 0 {
+		window = time.Hour
+	}
+	return &TokenBudget{
+		limit:       limit,
+		window:      window,
+		windowStart: time.Now(),
+		now:         time.Now,
+	}
+}
+
+// Record adds consumed tokens to the current window
+func (b *TokenBudget) Record(tokens int) {
+	if b == nil || tokens <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollWindow()
+	b.used += tokens
+}
+
+// Exhausted reports whether the current window's budget has been spent
+func (b *TokenBudget) Exhausted() bool {
+	if b == nil || b.limit <= 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollWindow()
+	return b.used >= b.limit
+}
+
+// Status returns a snapshot of the budget for reporting
+func (b *TokenBudget) Status() TokenBudgetStatus {
+	if b == nil {
+		return TokenBudgetStatus{}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollWindow()
+
+	remaining := b.limit - b.used
+	if remaining < 0 {
+		remaining = 0
+	}
+	return TokenBudgetStatus{
+		Enabled:     b.limit > 0,
+		Limit:       b.limit,
+		Used:        b.used,
+		Remaining:   remaining,
+		WindowStart: b.windowStart,
+		WindowEnd:   b.windowStart.Add(b.window),
+	}
+}
+
+// rollWindow starts a new window once the current one has elapsed (caller holds the lock)
+func (b *TokenBudget) rollWindow() {
+	now := b.now()
+	if now.Sub(b.windowStart) >= b.window {
+		b.windowStart = now
+		b.used = 0
+	}
+}
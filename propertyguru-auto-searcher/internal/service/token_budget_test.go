@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBudget_ExhaustAndReset(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	budget := NewTokenBudget(100, time.Hour)
+	budget.now = func() time.Time { return now }
+	budget.windowStart = now
+
+	budget.Record(60)
+	if budget.Exhausted() {
+		t.Fatal("Expected budget to have tokens remaining after 60/100")
+	}
+
+	budget.Record(50)
+	if !budget.Exhausted() {
+		t.Fatal("Expected budget to be exhausted after 110/100")
+	}
+	if status := budget.Status(); status.Remaining != 0 || status.Used != 110 {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	// Move into the next window
+	now = now.Add(time.Hour)
+	if budget.Exhausted() {
+		t.Fatal("Expected budget to reset in a new window")
+	}
+	if status := budget.Status(); status.Remaining != 100 {
+		t.Errorf("Expected full budget after reset, got %+v", status)
+	}
+}
+
+func TestTokenBudget_Unlimited(t *testing.T) {
+	budget := NewTokenBudget(0, time.Hour)
+	budget.Record(1_000_000)
+
+	if budget.Exhausted() {
+		t.Error("Expected unlimited budget never to be exhausted")
+	}
+	if status := budget.Status(); status.Enabled {
+		t.Errorf("Expected budget to report disabled, got %+v", status)
+	}
+}
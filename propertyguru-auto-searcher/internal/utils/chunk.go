@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ChunkText splits text into chunks of at most size characters on word boundaries, each
+// chunk repeating up to overlap trailing characters of the previous one for context.
+// A single word longer than size becomes its own chunk. size <= 0 returns the whole text.
+func ChunkText(text string, size, overlap int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return nil
+	}
+	if size <= 0 {
+		return []string{strings.Join(words, " ")}
+	}
+	if overlap >= size {
+		overlap = size / 2
+	}
+
+	var chunks []string
+	var current []string
+	length := 0 // Characters in current, including separating spaces
+
+	for _, word := range words {
+		wordLen := utf8.RuneCountInString(word)
+		if length > 0 && length+1+wordLen > size {
+			chunks = append(chunks, strings.Join(current, " "))
+			current, length = overlapTail(current, overlap)
+		}
+		if length > 0 {
+			length++
+		}
+		current = append(current, word)
+		length += wordLen
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, strings.Join(current, " "))
+	}
+	return chunks
+}
+
+// overlapTail returns the trailing words of a chunk that fit within overlap characters
+func overlapTail(words []string, overlap int) ([]string, int) {
+	start := len(words)
+	length := 0
+	for start > 0 {
+		wordLen := utf8.RuneCountInString(words[start-1])
+		next := length + wordLen
+		if length > 0 {
+			next++
+		}
+		if next > overlap {
+			break
+		}
+		length = next
+		start--
+	}
+	tail := make([]string, len(words)-start)
+	copy(tail, words[start:])
+	return tail, length
+}
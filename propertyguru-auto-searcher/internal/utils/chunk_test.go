@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestChunkText(t *testing.T) {
+	text := "Spacious three bedroom condo with pool view near Tampines MRT and schools"
+
+	chunks := ChunkText(text, 30, 0)
+	if len(chunks) < 2 {
+		t.Fatalf("Expected several chunks, got %v", chunks)
+	}
+	for _, chunk := range chunks {
+		if utf8.RuneCountInString(chunk) > 30 {
+			t.Errorf("Chunk %q exceeds 30 characters", chunk)
+		}
+	}
+	if got := strings.Join(chunks, " "); got != text {
+		t.Errorf("Chunks without overlap should rejoin to the text, got %q", got)
+	}
+}
+
+func TestChunkTextOverlap(t *testing.T) {
+	chunks := ChunkText("alpha beta gamma delta epsilon zeta", 17, 8)
+	// Each chunk after the first starts with the last word(s) of the previous one
+	for i := 1; i < len(chunks); i++ {
+		prev := strings.Fields(chunks[i-1])
+		if first := strings.Fields(chunks[i])[0]; first != prev[len(prev)-1] {
+			t.Errorf("Chunk %q should start with %q from %q", chunks[i], prev[len(prev)-1], chunks[i-1])
+		}
+	}
+}
+
+func TestChunkTextEdgeCases(t *testing.T) {
+	if chunks := ChunkText("   ", 100, 10); chunks != nil {
+		t.Errorf("Expected no chunks for blank text, got %v", chunks)
+	}
+	if chunks := ChunkText("short  text", 0, 0); len(chunks) != 1 || chunks[0] != "short text" {
+		t.Errorf("Expected whole text when size is 0, got %v", chunks)
+	}
+	if chunks := ChunkText("supercalifragilistic word", 5, 0); chunks[0] != "supercalifragilistic" {
+		t.Errorf("Expected an oversized word to form its own chunk, got %v", chunks)
+	}
+}
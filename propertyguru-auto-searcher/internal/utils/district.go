@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DistrictCount is the number of Singapore postal districts (D01-D28)
+const DistrictCount = 28
+
+// districtAreas maps each postal district to the areas it covers, as they appear in listing
+// addresses. Names in the canonical area table also match their aliases when expanded.
+var districtAreas = [DistrictCount + 1][]string{
+	1:  {"Raffles Place", "Cecil", "Marina Bay", "People's Park"},
+	2:  {"Anson", "Tanjong Pagar", "Chinatown"},
+	3:  {"Queenstown", "Tiong Bahru", "Alexandra"},
+	4:  {"Telok Blangah", "Harbourfront", "Sentosa"},
+	5:  {"Pasir Panjang", "Buona Vista", "West Coast", "Clementi", "Dover"},
+	6:  {"City Hall", "High Street", "Beach Road"},
+	7:  {"Bugis", "Beach Road", "Middle Road", "Golden Mile"},
+	8:  {"Little India", "Farrer Park", "Lavender"},
+	9:  {"Orchard", "Cairnhill", "River Valley"},
+	10: {"Ardmore", "Bukit Timah", "Holland Road", "Holland Village", "Tanglin"},
+	11: {"Watten Estate", "Novena", "Thomson", "Newton"},
+	12: {"Balestier", "Toa Payoh", "Serangoon", "Whampoa"},
+	13: {"MacPherson", "Braddell", "Potong Pasir"},
+	14: {"Geylang", "Eunos", "Paya Lebar", "Sims"},
+	15: {"Katong", "Joo Chiat", "Amber Road", "Marine Parade", "Tanjong Rhu"},
+	16: {"Bedok", "Upper East Coast", "Siglap", "Kew Drive"},
+	17: {"Loyang", "Changi"},
+	18: {"Tampines", "Pasir Ris", "Simei"},
+	19: {"Serangoon Garden", "Hougang", "Punggol", "Sengkang"},
+	20: {"Bishan", "Ang Mo Kio"},
+	21: {"Upper Bukit Timah", "Clementi Park", "Ulu Pandan"},
+	22: {"Jurong", "Boon Lay", "Tuas"},
+	23: {"Hillview", "Dairy Farm", "Bukit Panjang", "Choa Chu Kang", "Bukit Batok"},
+	24: {"Lim Chu Kang", "Tengah"},
+	25: {"Kranji", "Woodgrove", "Woodlands"},
+	26: {"Upper Thomson", "Springleaf"},
+	27: {"Yishun", "Sembawang"},
+	28: {"Seletar", "Yio Chu Kang"},
+}
+
+// districtRegexp matches "D9", "d09", "D 9", "district 10", or a bare "10"
+var districtRegexp = regexp.MustCompile(`(?i)^\s*(?:d|district)?\s*0*(\d{1,2})\s*$`)
+
+// ParseDistrict reads a postal district code ("D9", "D09", "district 9", "9") as its number
+func ParseDistrict(code string) (int, bool) {
+	m := districtRegexp.FindStringSubmatch(code)
+	if m == nil {
+		return 0, false
+	}
+	district, err := strconv.Atoi(m[1])
+	if err != nil || district < 1 || district > DistrictCount {
+		return 0, false
+	}
+	return district, true
+}
+
+// DistrictCode formats a district number as its canonical code, e.g. 9 -> "D09"
+func DistrictCode(district int) string {
+	return fmt.Sprintf("D%02d", district)
+}
+
+// CanonicalDistrict returns the canonical code for a district code in any accepted spelling,
+// or "" when it isn't one of D01-D28
+func CanonicalDistrict(code string) string {
+	district, ok := ParseDistrict(code)
+	if !ok {
+		return ""
+	}
+	return DistrictCode(district)
+}
+
+// AreasForDistrict returns the areas a postal district covers ("D9" -> Orchard, Cairnhill,
+// River Valley), or nil for an unknown code
+func AreasForDistrict(code string) []string {
+	district, ok := ParseDistrict(code)
+	if !ok {
+		return nil
+	}
+	return districtAreas[district]
+}
+
+// ExpandDistrict returns the address substrings to OR together when filtering by a district:
+// each of its areas plus the aliases of areas in the canonical table. Unlike ExpandLocation
+// it never resolves partially or fuzzily, so "Lim Chu Kang" doesn't widen to Choa Chu Kang.
+func ExpandDistrict(code string) []string {
+	resolver := activeAreaResolver.Load()
+	var patterns []string
+	for _, name := range AreasForDistrict(code) {
+		patterns = append(patterns, name)
+		if i, ok := resolver.exact[strings.ToLower(name)]; ok && strings.EqualFold(resolver.areas[i].Name, name) {
+			patterns = append(patterns, resolver.areas[i].Aliases...)
+		}
+	}
+	return patterns
+}
+
+// DistrictCodes lists every canonical district code, D01 to D28
+func DistrictCodes() []string {
+	codes := make([]string, 0, DistrictCount)
+	for district := 1; district <= DistrictCount; district++ {
+		codes = append(codes, DistrictCode(district))
+	}
+	return codes
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDistrict(t *testing.T) {
+	tests := []struct {
+		code string
+		want int
+		ok   bool
+	}{
+		{"D9", 9, true},
+		{"d09", 9, true},
+		{"District 10", 10, true},
+		{" 28 ", 28, true},
+		{"D0", 0, false},
+		{"D29", 0, false},
+		{"Orchard", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			got, ok := ParseDistrict(tt.code)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("ParseDistrict(%q) = %d, %v, want %d, %v", tt.code, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestAreasForDistrict(t *testing.T) {
+	if got := AreasForDistrict("D9"); !reflect.DeepEqual(got, []string{"Orchard", "Cairnhill", "River Valley"}) {
+		t.Errorf("AreasForDistrict(D9) = %v", got)
+	}
+	if got := AreasForDistrict("D99"); got != nil {
+		t.Errorf("AreasForDistrict(D99) = %v, want nil", got)
+	}
+	for _, code := range DistrictCodes() {
+		if len(AreasForDistrict(code)) == 0 {
+			t.Errorf("district %s has no areas", code)
+		}
+	}
+	if got := CanonicalDistrict("district 3"); got != "D03" {
+		t.Errorf("CanonicalDistrict(district 3) = %q, want D03", got)
+	}
+}
+
+func TestExpandDistrict(t *testing.T) {
+	got := ExpandDistrict("D24")
+	if !reflect.DeepEqual(got, []string{"Lim Chu Kang", "Tengah"}) {
+		t.Errorf("ExpandDistrict(D24) = %v, want the district's own areas only", got)
+	}
+	got = ExpandDistrict("D2")
+	if !reflect.DeepEqual(got, []string{"Anson", "Tanjong Pagar", "Tg Pagar", "Chinatown"}) {
+		t.Errorf("ExpandDistrict(D2) = %v, want canonical areas with their aliases", got)
+	}
+}
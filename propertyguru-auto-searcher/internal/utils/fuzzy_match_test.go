@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFuzzyMatchAmenity(t *testing.T) {
+	tests := []struct {
+		search  string
+		amenity string
+		want    bool
+	}{
+		{"pool", "Swimming pool", true},
+		{"aircon", "Air conditioning", true},
+		{"fitness", "Gym", false},
+		{"gym", "Fitness center", true},
+		{"balcony", "Private terrace", true},
+		{"sauna", "Swimming pool", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.search+"/"+tt.amenity, func(t *testing.T) {
+			if got := FuzzyMatchAmenity(tt.search, tt.amenity); got != tt.want {
+				t.Errorf("FuzzyMatchAmenity(%q, %q) = %v, want %v", tt.search, tt.amenity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeAmenity(t *testing.T) {
+	if got := NormalizeAmenity("  Barbecue "); got != "BBQ pits" {
+		t.Errorf("NormalizeAmenity(Barbecue) = %q", got)
+	}
+}
+
+// TestFuzzyTables_ConcurrentAccess exercises the shared tables from many goroutines; run with -race
+func TestFuzzyTables_ConcurrentAccess(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				FuzzyMatchAmenity("pool", "Swimming pool")
+				NormalizeAmenity("gymnasium")
+				BuildFuzzyAmenityQuery([]string{"gym", "bbq"}, 1)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkBuildFuzzyAmenityQuery(b *testing.B) {
+	terms := []string{"pool", "gym", "aircon"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		BuildFuzzyAmenityQuery(terms, 1)
+	}
+}
+
+func BenchmarkFuzzyMatchAmenity(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		FuzzyMatchAmenity("washer", "Washing machine")
+	}
+}
+
+func BenchmarkNormalizeAmenity(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		NormalizeAmenity("fitness center")
+	}
+}
+
+func TestCountAmenityMatches(t *testing.T) {
+	values := []string{"Swimming Pool", "Covered parking", "Air conditioning"}
+	if got := CountAmenityMatches([]string{"pool", "gym", "aircon", "parking"}, values); got != 3 {
+		t.Errorf("CountAmenityMatches = %d, want 3", got)
+	}
+	if got := CountAmenityMatches([]string{"sauna"}, values); got != 0 {
+		t.Errorf("CountAmenityMatches(sauna) = %d, want 0", got)
+	}
+	if got := CountAmenityMatches([]string{"gym"}, nil); got != 0 {
+		t.Errorf("CountAmenityMatches with no values = %d, want 0", got)
+	}
+}
+
+func TestBuildFuzzyAmenityQuery_MultiDigitPlaceholders(t *testing.T) {
+	conds, params, next := BuildFuzzyAmenityQuery([]string{"pool", "gym", "sauna"}, 12)
+	if len(conds) != 3 {
+		t.Fatalf("Expected one condition per term, got %v", conds)
+	}
+
+	// Placeholders run $12, $13, ... in the order of params
+	var placeholders []string
+	for _, cond := range conds {
+		placeholders = append(placeholders, regexp.MustCompile(`\$\S+`).FindAllString(cond, -1)...)
+	}
+	if len(placeholders) != len(params) || next != 12+len(params) {
+		t.Fatalf("Expected %d placeholders ending before $%d, got %v", len(params), next, placeholders)
+	}
+	for i, placeholder := range placeholders {
+		if want := fmt.Sprintf("$%d", 12+i); strings.TrimRight(placeholder, ")") != want {
+			t.Errorf("Placeholder %d = %q, want %s", i, placeholder, want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package utils
+
+import "math"
+
+// EarthRadiusKm is the mean Earth radius used for great-circle distances
+const EarthRadiusKm = 6371.0
+
+// WalkingMetersPerMinute is the walking pace used to convert between the walking minutes
+// users ask for ("15 minutes to MRT") and the stored mrt_distance_m
+const WalkingMetersPerMinute = 80
+
+// WalkMinutesToMeters returns the distance covered in the given minutes of walking
+func WalkMinutesToMeters(minutes int) int {
+	return minutes * WalkingMetersPerMinute
+}
+
+// WalkMetersToMinutes returns the whole minutes needed to walk the given distance, rounded up
+func WalkMetersToMinutes(meters int) int {
+	return (meters + WalkingMetersPerMinute - 1) / WalkingMetersPerMinute
+}
+
+// HaversineKm returns the great-circle distance in km between two points given in degrees.
+// Keep in sync with the radius filter in buildFilterWhere.
+func HaversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLng := (lng2 - lng1) * math.Pi / 180
+	a := math.Pow(math.Sin(dLat/2), 2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
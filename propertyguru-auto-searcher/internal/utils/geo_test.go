@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHaversineKm(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want                   float64
+	}{
+		{"same point", 1.3521, 103.8198, 1.3521, 103.8198, 0},
+		{"Raffles Place to Changi Airport", 1.2840, 103.8514, 1.3644, 103.9915, 17.95},
+		{"one degree of latitude", 0, 0, 1, 0, 111.19},
+	}
+	for _, tt := range tests {
+		if got := HaversineKm(tt.lat1, tt.lng1, tt.lat2, tt.lng2); math.Abs(got-tt.want) > 0.05 {
+			t.Errorf("%s: HaversineKm = %.2f, want %.2f", tt.name, got, tt.want)
+		}
+	}
+}
@@ -7,43 +7,66 @@ import (
 	"strings"
 )
 
+// JSON repair strategies reported by ParseAIJSONStrategy, in the order they are tried
+const (
+	JSONStrategyDirect   = "direct"   // Input was valid JSON as-is
+	JSONStrategyMarkdown = "markdown" // Extracted from a markdown code block
+	JSONStrategyExtract  = "extract"  // Extracted the first balanced object/array from surrounding text
+	JSONStrategyCleanup  = "cleanup"  // Fixed common syntax issues
+	JSONStrategyTruncate = "truncate" // Closed a cut-off object, dropping an incomplete trailing member
+)
+
 // ParseAIJSON extracts and parses JSON from AI output that may contain:
 // - Pure JSON
 // - JSON wrapped in markdown code blocks (```json ... ```)
 // - JSON with surrounding text
-// - Partial or malformed JSON
+// - Partial or malformed JSON, including output cut off mid-object
 func ParseAIJSON(input string, target interface{}) error {
+	_, err := ParseAIJSONStrategy(input, target)
+	return err
+}
+
+// ParseAIJSONStrategy is ParseAIJSON that also reports which strategy succeeded
+// (JSONStrategyDirect when no repair was needed)
+func ParseAIJSONStrategy(input string, target interface{}) (string, error) {
 	if input == "" {
-		return fmt.Errorf("empty input")
+		return "", fmt.Errorf("empty input")
 	}
 
 	// Try direct parsing first (most common case)
 	if err := json.Unmarshal([]byte(input), target); err == nil {
-		return nil
+		return JSONStrategyDirect, nil
 	}
 
 	// Try to extract JSON from markdown code blocks
 	if extracted := extractFromMarkdown(input); extracted != "" {
 		if err := json.Unmarshal([]byte(extracted), target); err == nil {
-			return nil
+			return JSONStrategyMarkdown, nil
 		}
 	}
 
 	// Try to find JSON object/array in text
 	if extracted := extractJSONFromText(input); extracted != "" {
 		if err := json.Unmarshal([]byte(extracted), target); err == nil {
-			return nil
+			return JSONStrategyExtract, nil
 		}
 	}
 
 	// Try to clean and fix common JSON issues
 	if cleaned := cleanAndFixJSON(input); cleaned != "" {
 		if err := json.Unmarshal([]byte(cleaned), target); err == nil {
-			return nil
+			return JSONStrategyCleanup, nil
 		}
 	}
 
-	return fmt.Errorf("failed to parse JSON from input: %s", truncateString(input, 100))
+	// Try to close an object the model (or a dropped stream) cut off
+	for _, repaired := range repairTruncatedJSON(input) {
+		if err := json.Unmarshal([]byte(repaired), target); err == nil {
+			return JSONStrategyTruncate, nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to parse JSON from input: %s", truncateString(input, 100))
 }
 
 // extractFromMarkdown extracts JSON from markdown code blocks
@@ -134,6 +157,81 @@ func extractBalancedBraces(input string, open, close rune) string {
 	return ""
 }
 
+// repairTruncatedJSON returns candidate completions of a JSON object or array that ends
+// before its closing brackets: first the input as-is with the open brackets closed (when the
+// last value is complete), then cut back to the last complete member. Returns nil when the
+// input has no unclosed object or array.
+func repairTruncatedJSON(input string) []string {
+	start := strings.IndexAny(input, "{[")
+	if start < 0 {
+		return nil
+	}
+	s := strings.TrimSpace(input[start:])
+
+	var stack []byte
+	inString, escape := false, false
+	cut, cutStack := -1, ""
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if escape {
+			escape = false
+			continue
+		}
+		if inString {
+			if ch == '\\' {
+				escape = true
+			} else if ch == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			inString = true
+		case '{', '[':
+			stack = append(stack, ch)
+			cut, cutStack = i+1, string(stack)
+		case '}', ']':
+			if len(stack) == 0 {
+				return nil
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return nil // Complete value; nothing was truncated
+			}
+			cut, cutStack = i+1, string(stack)
+		case ',':
+			cut, cutStack = i, string(stack)
+		}
+	}
+	if len(stack) == 0 {
+		return nil
+	}
+
+	var candidates []string
+	if !inString {
+		candidates = append(candidates, strings.TrimSuffix(s, ",")+closingBrackets(string(stack)))
+	}
+	if cut >= 0 {
+		candidates = append(candidates, s[:cut]+closingBrackets(cutStack))
+	}
+	return candidates
+}
+
+// closingBrackets returns the brackets that close the open ones, innermost first
+func closingBrackets(open string) string {
+	closing := make([]byte, len(open))
+	for i := range open {
+		if open[len(open)-1-i] == '{' {
+			closing[i] = '}'
+		} else {
+			closing[i] = ']'
+		}
+	}
+	return string(closing)
+}
+
 // cleanAndFixJSON attempts to fix common JSON formatting issues
 func cleanAndFixJSON(input string) string {
 	s := strings.TrimSpace(input)
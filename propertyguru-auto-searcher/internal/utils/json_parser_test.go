@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"encoding/json"
 	"testing"
 )
 
@@ -90,6 +91,68 @@ func TestParseAIJSON(t *testing.T) {
 	}
 }
 
+func TestParseAIJSONStrategy(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"valid JSON", `{"bedrooms": 3}`, JSONStrategyDirect},
+		{"markdown block", "```json\n{\"bedrooms\": 3}\n```", JSONStrategyMarkdown},
+		{"surrounding text", `Sure! {"bedrooms": 3} Hope that helps.`, JSONStrategyExtract},
+		{"missing closing brace", `{"bedrooms": 3, "location": "Tampines"`, JSONStrategyTruncate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result map[string]interface{}
+			got, err := ParseAIJSONStrategy(tt.input, &result)
+			if err != nil {
+				t.Fatalf("ParseAIJSONStrategy() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAIJSONStrategy() strategy = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	var result map[string]interface{}
+	if strategy, err := ParseAIJSONStrategy("no json here", &result); err == nil || strategy != "" {
+		t.Errorf("Expected failure with no strategy, got %q, %v", strategy, err)
+	}
+}
+
+func TestRepairTruncatedJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"cut inside a string", `{"bedrooms": 3, "location": "Tamp`, `{"bedrooms":3}`},
+		{"cut after a key", `{"bedrooms": 3, "location":`, `{"bedrooms":3}`},
+		{"trailing comma", `{"bedrooms": 3,`, `{"bedrooms":3}`},
+		{"cut inside an array", `{"bedrooms": 3, "keywords": ["pool", "gy`, `{"bedrooms":3,"keywords":["pool"]}`},
+		{"nested object", "```json\n{\"a\": {\"b\": 1, \"c\": [1, 2", `{"a":{"b":1,"c":[1,2]}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result map[string]interface{}
+			strategy, err := ParseAIJSONStrategy(tt.input, &result)
+			if err != nil || strategy != JSONStrategyTruncate {
+				t.Fatalf("ParseAIJSONStrategy() = %q, %v; want %q", strategy, err, JSONStrategyTruncate)
+			}
+			if got, _ := json.Marshal(result); string(got) != tt.want {
+				t.Errorf("Repaired to %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if candidates := repairTruncatedJSON(`{"complete": true}`); candidates != nil {
+		t.Errorf("Expected no repair for complete JSON, got %v", candidates)
+	}
+}
+
 func TestExtractFromMarkdown(t *testing.T) {
 	tests := []struct {
 		name  string
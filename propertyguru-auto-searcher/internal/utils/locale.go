@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// localeTagPattern matches a BCP 47 language tag such as "de", "en-IN", or "zh-Hans-SG"
+var localeTagPattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// ParseLocale returns the preferred locale from a language tag or an Accept-Language header
+// ("de-DE,de;q=0.9,en;q=0.8" -> "de-DE"), with the language lowercased and the region
+// uppercased. Returns "" for wildcards and anything that isn't a language tag.
+func ParseLocale(raw string) string {
+	first, _, _ := strings.Cut(raw, ",")
+	tag, _, _ := strings.Cut(first, ";")
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if !localeTagPattern.MatchString(tag) {
+		return ""
+	}
+
+	parts := strings.Split(tag, "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		switch len(parts[i]) {
+		case 2:
+			parts[i] = strings.ToUpper(parts[i]) // Region: "in" -> "IN"
+		case 4:
+			parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:]) // Script: "hans" -> "Hans"
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// LocaleLanguage returns the language subtag of a parsed locale ("en-IN" -> "en")
+func LocaleLanguage(locale string) string {
+	language, _, _ := strings.Cut(locale, "-")
+	return language
+}
+
+// LocaleRegion returns the region subtag of a parsed locale ("zh-Hans-SG" -> "SG"), or ""
+func LocaleRegion(locale string) string {
+	parts := strings.Split(locale, "-")
+	for _, part := range parts[1:] {
+		if len(part) == 2 || (len(part) == 3 && part[0] >= '0' && part[0] <= '9') {
+			return part
+		}
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestParseLocale(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"de-DE,de;q=0.9,en;q=0.8", "de-DE"},
+		{"en_in", "en-IN"},
+		{" fr ", "fr"},
+		{"zh-hans-sg", "zh-Hans-SG"},
+		{"*", ""},
+		{"", ""},
+		{"not a locale", ""},
+	}
+	for _, tt := range tests {
+		if got := ParseLocale(tt.raw); got != tt.want {
+			t.Errorf("ParseLocale(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+
+	if got := LocaleRegion("zh-Hans-SG"); got != "SG" {
+		t.Errorf("LocaleRegion = %q, want SG", got)
+	}
+	if got := LocaleLanguage("en-IN"); got != "en" {
+		t.Errorf("LocaleLanguage = %q, want en", got)
+	}
+}
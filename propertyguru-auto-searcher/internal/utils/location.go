@@ -0,0 +1,290 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Area is a canonical Singapore area with the spellings that identify it
+type Area struct {
+	Name          string   `json:"name"`
+	Aliases       []string `json:"aliases,omitempty"`       // Alternative spellings also seen in listing addresses (matched in SQL)
+	Abbreviations []string `json:"abbreviations,omitempty"` // Query-only shorthands such as "AMK" (too short to match addresses safely)
+}
+
+// trigramThreshold is the minimum similarity for a misspelled term to resolve to an area
+const trigramThreshold = 0.45
+
+// minPartialLength is the shortest term that may partially match several area names
+const minPartialLength = 4
+
+// defaultAreas is the built-in canonical area table, used unless LOCATION_AREAS_PATH overrides it
+var defaultAreas = []Area{
+	{Name: "Ang Mo Kio", Abbreviations: []string{"AMK"}},
+	{Name: "Bedok"},
+	{Name: "Bishan"},
+	{Name: "Boon Lay"},
+	{Name: "Bugis"},
+	{Name: "Buona Vista"},
+	{Name: "Bukit Batok", Aliases: []string{"Bt Batok"}},
+	{Name: "Bukit Merah", Aliases: []string{"Bt Merah"}},
+	{Name: "Bukit Panjang", Aliases: []string{"Bt Panjang"}},
+	{Name: "Bukit Timah", Aliases: []string{"Bt Timah"}},
+	{Name: "Changi"},
+	{Name: "Chinatown"},
+	{Name: "Choa Chu Kang", Abbreviations: []string{"CCK"}},
+	{Name: "Clementi"},
+	{Name: "Dover"},
+	{Name: "Eunos"},
+	{Name: "Farrer Park"},
+	{Name: "Geylang"},
+	{Name: "Holland Village", Aliases: []string{"Holland V"}},
+	{Name: "Hougang"},
+	{Name: "Joo Chiat"},
+	{Name: "Jurong East"},
+	{Name: "Jurong West"},
+	{Name: "Kallang"},
+	{Name: "Katong"},
+	{Name: "Kembangan"},
+	{Name: "Lavender"},
+	{Name: "MacPherson", Aliases: []string{"Macpherson"}},
+	{Name: "Marina Bay"},
+	{Name: "Marine Parade"},
+	{Name: "Newton"},
+	{Name: "Novena"},
+	{Name: "Orchard"},
+	{Name: "Outram"},
+	{Name: "Pasir Panjang"},
+	{Name: "Pasir Ris"},
+	{Name: "Paya Lebar"},
+	{Name: "Potong Pasir"},
+	{Name: "Punggol"},
+	{Name: "Queenstown"},
+	{Name: "Raffles Place"},
+	{Name: "River Valley"},
+	{Name: "Sembawang"},
+	{Name: "Sengkang"},
+	{Name: "Sentosa"},
+	{Name: "Serangoon"},
+	{Name: "Siglap"},
+	{Name: "Simei"},
+	{Name: "Tampines"},
+	{Name: "Tanjong Katong", Aliases: []string{"Tg Katong"}},
+	{Name: "Tanjong Pagar", Aliases: []string{"Tg Pagar"}},
+	{Name: "Tanjong Rhu", Aliases: []string{"Tg Rhu"}},
+	{Name: "Telok Blangah"},
+	{Name: "Tengah"},
+	{Name: "Tiong Bahru"},
+	{Name: "Toa Payoh", Abbreviations: []string{"TPY"}},
+	{Name: "Upper Bukit Timah", Aliases: []string{"Upp Bukit Timah"}},
+	{Name: "Upper Thomson"},
+	{Name: "West Coast"},
+	{Name: "Whampoa"},
+	{Name: "Woodlands"},
+	{Name: "Yishun"},
+	{Name: "Yio Chu Kang", Abbreviations: []string{"YCK"}},
+}
+
+// AreaResolver resolves free-text location terms to canonical areas.
+// It is immutable after construction and safe for concurrent use.
+type AreaResolver struct {
+	areas    []Area
+	exact    map[string]int // lowercased name/alias/abbreviation -> area index
+	trigrams []map[string]struct{}
+}
+
+// NewAreaResolver builds a resolver over the given area table
+func NewAreaResolver(areas []Area) *AreaResolver {
+	r := &AreaResolver{
+		areas:    areas,
+		exact:    make(map[string]int),
+		trigrams: make([]map[string]struct{}, len(areas)),
+	}
+	for i, area := range areas {
+		r.exact[strings.ToLower(area.Name)] = i
+		for _, alias := range area.Aliases {
+			r.exact[strings.ToLower(alias)] = i
+		}
+		for _, abbr := range area.Abbreviations {
+			r.exact[strings.ToLower(abbr)] = i
+		}
+		r.trigrams[i] = trigramSet(area.Name)
+	}
+	return r
+}
+
+var activeAreaResolver atomic.Pointer[AreaResolver]
+
+func init() {
+	activeAreaResolver.Store(NewAreaResolver(defaultAreas))
+}
+
+// LoadAreaTable replaces the built-in area table with one loaded from a JSON file
+// (an array of {"name", "aliases", "abbreviations"} objects)
+func LoadAreaTable(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read area table: %w", err)
+	}
+	var areas []Area
+	if err := json.Unmarshal(data, &areas); err != nil {
+		return 0, fmt.Errorf("failed to parse area table: %w", err)
+	}
+	for i, area := range areas {
+		if strings.TrimSpace(area.Name) == "" {
+			return 0, fmt.Errorf("area table entry %d has no name", i)
+		}
+	}
+	activeAreaResolver.Store(NewAreaResolver(areas))
+	return len(areas), nil
+}
+
+// ResolveAreas returns the canonical areas matching a location term:
+// an exact name/alias/abbreviation, every area containing a partial term ("Tanjong"),
+// or the closest misspelling by trigram similarity. Returns nil when nothing matches.
+func (r *AreaResolver) ResolveAreas(term string) []Area {
+	term = strings.ToLower(strings.TrimSpace(term))
+	if term == "" {
+		return nil
+	}
+
+	if i, ok := r.exact[term]; ok {
+		return []Area{r.areas[i]}
+	}
+
+	if len(term) >= minPartialLength {
+		var matches []Area
+		for _, area := range r.areas {
+			if areaContains(area, term) {
+				matches = append(matches, area)
+			}
+		}
+		if len(matches) > 0 {
+			return matches
+		}
+	}
+
+	termTrigrams := trigramSet(term)
+	best, bestScore := -1, 0.0
+	for i := range r.areas {
+		if score := trigramSimilarity(termTrigrams, r.trigrams[i]); score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best >= 0 && bestScore >= trigramThreshold {
+		return []Area{r.areas[best]}
+	}
+
+	return nil
+}
+
+// CanonicalArea returns the canonical name for a location term, or the trimmed term when it
+// doesn't resolve to exactly one area
+func CanonicalArea(term string) string {
+	areas := activeAreaResolver.Load().ResolveAreas(term)
+	if len(areas) == 1 {
+		return areas[0].Name
+	}
+	return strings.TrimSpace(term)
+}
+
+// AreaOfAddress returns the canonical area a listing address lies in: the area whose name or
+// alias appears in it as whole words, the longest spelling winning so "Upper Bukit Timah Road"
+// is Upper Bukit Timah rather than Bukit Timah. Returns "" when no area is named.
+func AreaOfAddress(address string) string {
+	return activeAreaResolver.Load().AreaOfAddress(address)
+}
+
+// AreaOfAddress is the package-level AreaOfAddress over this resolver's area table
+func (r *AreaResolver) AreaOfAddress(address string) string {
+	address = strings.ToLower(address)
+	best, bestLen := "", 0
+	for _, area := range r.areas {
+		for _, spelling := range append([]string{area.Name}, area.Aliases...) {
+			spelling = strings.ToLower(spelling)
+			if len(spelling) > bestLen && containsWords(address, spelling) {
+				best, bestLen = area.Name, len(spelling)
+			}
+		}
+	}
+	return best
+}
+
+// containsWords reports whether phrase occurs in s bounded by non-alphanumeric characters
+func containsWords(s, phrase string) bool {
+	for start := 0; ; {
+		i := strings.Index(s[start:], phrase)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(phrase)
+		if (i == 0 || !isWordByte(s[i-1])) && (end == len(s) || !isWordByte(s[end])) {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= '0' && b <= '9'
+}
+
+// ExpandLocation returns the address substrings to OR together when filtering by a location term:
+// every matching canonical name plus its aliases, or the raw term when it doesn't resolve
+func ExpandLocation(term string) []string {
+	areas := activeAreaResolver.Load().ResolveAreas(term)
+	if len(areas) == 0 {
+		return []string{strings.TrimSpace(term)}
+	}
+
+	patterns := make([]string, 0, len(areas))
+	for _, area := range areas {
+		patterns = append(patterns, area.Name)
+		patterns = append(patterns, area.Aliases...)
+	}
+	return patterns
+}
+
+// areaContains reports whether a partial term occurs in an area's name or aliases
+func areaContains(area Area, term string) bool {
+	if strings.Contains(strings.ToLower(area.Name), term) {
+		return true
+	}
+	for _, alias := range area.Aliases {
+		if strings.Contains(strings.ToLower(alias), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// trigramSet returns the padded character trigrams of a string, as pg_trgm does per word
+func trigramSet(s string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, word := range strings.Fields(strings.ToLower(s)) {
+		padded := "  " + word + " "
+		runes := []rune(padded)
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+// trigramSimilarity is the Jaccard similarity of two trigram sets
+func trigramSimilarity(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range a {
+		if _, ok := b[t]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
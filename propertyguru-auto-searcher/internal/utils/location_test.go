@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandLocation(t *testing.T) {
+	tests := []struct {
+		name string
+		term string
+		want []string
+	}{
+		{name: "Canonical name", term: "Punggol", want: []string{"Punggol"}},
+		{name: "Alias", term: "tg pagar", want: []string{"Tanjong Pagar", "Tg Pagar"}},
+		{name: "Abbreviation", term: "AMK", want: []string{"Ang Mo Kio"}},
+		{name: "Misspelling", term: "Sengkhang", want: []string{"Sengkang"}},
+		{name: "Partial term", term: "Tanjong", want: []string{"Tanjong Katong", "Tg Katong", "Tanjong Pagar", "Tg Pagar", "Tanjong Rhu", "Tg Rhu"}},
+		{name: "Unknown term", term: "Zzyzx Road", want: []string{"Zzyzx Road"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpandLocation(tt.term); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandLocation(%q) = %v, want %v", tt.term, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalArea(t *testing.T) {
+	tests := []struct {
+		term string
+		want string
+	}{
+		{"Bt Timah", "Bukit Timah"},
+		{"cck", "Choa Chu Kang"},
+		{"Punggoll", "Punggol"},
+		{"Tanjong", "Tanjong"}, // Ambiguous, left as-is
+		{"  Somewhere  ", "Somewhere"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			if got := CanonicalArea(tt.term); got != tt.want {
+				t.Errorf("CanonicalArea(%q) = %q, want %q", tt.term, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAreaOfAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{"8 Tampines Street 86", "Tampines"},
+		{"Bt Batok West Avenue 8", "Bukit Batok"},
+		{"1 Upper Bukit Timah Road", "Upper Bukit Timah"},
+		{"Punggol Field, Punggol", "Punggol"},
+		{"Bedokville Road", ""}, // Only whole words count
+		{"1 Zzyzx Road", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			if got := AreaOfAddress(tt.address); got != tt.want {
+				t.Errorf("AreaOfAddress(%q) = %q, want %q", tt.address, got, tt.want)
+			}
+		})
+	}
+}
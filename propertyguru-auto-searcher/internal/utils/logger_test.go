@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNewLoggerLevelAndFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(&buf, "info", "json")
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+
+	logger.Debug("hidden")
+	if buf.Len() != 0 {
+		t.Errorf("debug line written at info level: %s", buf.String())
+	}
+
+	logger.Info("search completed", "query", "3 bed condo", "took_ms", 42)
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("json format wrote %q: %v", buf.String(), err)
+	}
+	if line["msg"] != "search completed" || line["query"] != "3 bed condo" || line["took_ms"] != float64(42) {
+		t.Errorf("unexpected line: %v", line)
+	}
+
+	buf.Reset()
+	logger, err = NewLogger(&buf, "DEBUG", "text")
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	logger.Debug("shown", "chunk", 1)
+	if !strings.Contains(buf.String(), "level=DEBUG") || !strings.Contains(buf.String(), "chunk=1") {
+		t.Errorf("text format wrote %q", buf.String())
+	}
+}
+
+func TestNewLoggerRejectsInvalidSettings(t *testing.T) {
+	if _, err := NewLogger(&bytes.Buffer{}, "verbose", "json"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+	if _, err := NewLogger(&bytes.Buffer{}, "info", "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestLoggerFromContext(t *testing.T) {
+	if LoggerFrom(context.Background()) != slog.Default() {
+		t.Error("expected the default logger without one in the context")
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil)).With("query", "punggol")
+	LoggerFrom(WithLogger(context.Background(), logger)).Info("done")
+	if !strings.Contains(buf.String(), "query=punggol") {
+		t.Errorf("request-scoped fields missing: %q", buf.String())
+	}
+}
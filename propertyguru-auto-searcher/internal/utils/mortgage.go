@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"math"
+)
+
+// MonthlyMortgage returns the fixed monthly repayment that amortizes principal over years at
+// annualRatePercent (3.5 = 3.5% a year), compounded monthly. A zero rate spreads the
+// principal evenly; a non-positive principal or term needs no repayment.
+func MonthlyMortgage(principal, annualRatePercent float64, years int) float64 {
+	if principal <= 0 || years <= 0 {
+		return 0
+	}
+	months := float64(years * 12)
+	rate := annualRatePercent / 100 / 12
+	if rate <= 0 {
+		return principal / months
+	}
+	return principal * rate / (1 - math.Pow(1+rate, -months))
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMonthlyMortgage(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal float64
+		rate      float64
+		years     int
+		want      float64
+	}{
+		{"standard loan", 750000, 3.5, 25, 3754.68},
+		{"zero interest", 600000, 0, 25, 2000},
+		{"zero price", 0, 3.5, 25, 0},
+		{"zero tenure", 500000, 3.5, 0, 0},
+	}
+	for _, tt := range tests {
+		got := MonthlyMortgage(tt.principal, tt.rate, tt.years)
+		if math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("%s: MonthlyMortgage(%v, %v, %d) = %.2f, want %.2f", tt.name, tt.principal, tt.rate, tt.years, got, tt.want)
+		}
+	}
+}
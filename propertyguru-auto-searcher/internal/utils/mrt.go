@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MRTLine is a Singapore rail line with the stations it serves
+type MRTLine struct {
+	Code     string   `json:"code"`
+	Name     string   `json:"name"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Stations []string `json:"stations"`
+}
+
+// mrtLines is the station -> line mapping used by the MRT line filter
+var mrtLines = []MRTLine{
+	{
+		Code: "NSL", Name: "North-South Line", Aliases: []string{"NS", "red line"},
+		Stations: []string{
+			"Jurong East", "Bukit Batok", "Bukit Gombak", "Choa Chu Kang", "Yew Tee", "Kranji", "Marsiling",
+			"Woodlands", "Admiralty", "Sembawang", "Canberra", "Yishun", "Khatib", "Yio Chu Kang", "Ang Mo Kio",
+			"Bishan", "Braddell", "Toa Payoh", "Novena", "Newton", "Orchard", "Somerset", "Dhoby Ghaut",
+			"City Hall", "Raffles Place", "Marina Bay", "Marina South Pier",
+		},
+	},
+	{
+		Code: "EWL", Name: "East-West Line", Aliases: []string{"EW", "green line"},
+		Stations: []string{
+			"Pasir Ris", "Tampines", "Simei", "Tanah Merah", "Bedok", "Kembangan", "Eunos", "Paya Lebar",
+			"Aljunied", "Kallang", "Lavender", "Bugis", "City Hall", "Raffles Place", "Tanjong Pagar",
+			"Outram Park", "Tiong Bahru", "Redhill", "Queenstown", "Commonwealth", "Buona Vista", "Dover",
+			"Clementi", "Jurong East", "Chinese Garden", "Lakeside", "Boon Lay", "Pioneer", "Joo Koon",
+			"Gul Circle", "Tuas Crescent", "Tuas West Road", "Tuas Link", "Expo", "Changi Airport",
+		},
+	},
+	{
+		Code: "NEL", Name: "North East Line", Aliases: []string{"NE", "purple line"},
+		Stations: []string{
+			"HarbourFront", "Outram Park", "Chinatown", "Clarke Quay", "Dhoby Ghaut", "Little India",
+			"Farrer Park", "Boon Keng", "Potong Pasir", "Woodleigh", "Serangoon", "Kovan", "Hougang",
+			"Buangkok", "Sengkang", "Punggol", "Punggol Coast",
+		},
+	},
+	{
+		Code: "CCL", Name: "Circle Line", Aliases: []string{"CC", "CE", "yellow line"},
+		Stations: []string{
+			"Dhoby Ghaut", "Bras Basah", "Esplanade", "Promenade", "Nicoll Highway", "Stadium", "Mountbatten",
+			"Dakota", "Paya Lebar", "MacPherson", "Tai Seng", "Bartley", "Serangoon", "Lorong Chuan", "Bishan",
+			"Marymount", "Caldecott", "Botanic Gardens", "Farrer Road", "Holland Village", "Buona Vista",
+			"one-north", "Kent Ridge", "Haw Par Villa", "Pasir Panjang", "Labrador Park", "Telok Blangah",
+			"HarbourFront", "Bayfront", "Marina Bay",
+		},
+	},
+	{
+		Code: "DTL", Name: "Downtown Line", Aliases: []string{"DT", "blue line"},
+		Stations: []string{
+			"Bukit Panjang", "Cashew", "Hillview", "Hume", "Beauty World", "King Albert Park", "Sixth Avenue",
+			"Tan Kah Kee", "Botanic Gardens", "Stevens", "Newton", "Little India", "Rochor", "Bugis",
+			"Promenade", "Bayfront", "Downtown", "Telok Ayer", "Chinatown", "Fort Canning", "Bencoolen",
+			"Jalan Besar", "Bendemeer", "Geylang Bahru", "Mattar", "MacPherson", "Ubi", "Kaki Bukit",
+			"Bedok North", "Bedok Reservoir", "Tampines West", "Tampines", "Tampines East", "Upper Changi", "Expo",
+		},
+	},
+	{
+		Code: "TEL", Name: "Thomson-East Coast Line", Aliases: []string{"TE", "brown line", "Thomson Line"},
+		Stations: []string{
+			"Woodlands North", "Woodlands", "Woodlands South", "Springleaf", "Lentor", "Mayflower",
+			"Bright Hill", "Upper Thomson", "Caldecott", "Stevens", "Napier", "Orchard Boulevard", "Orchard",
+			"Great World", "Havelock", "Outram Park", "Maxwell", "Shenton Way", "Marina Bay",
+			"Gardens by the Bay", "Tanjong Rhu", "Katong Park", "Tanjong Katong", "Marine Parade",
+			"Marine Terrace", "Siglap", "Bayshore",
+		},
+	},
+}
+
+// normalizeLineTerm lowercases a line name and strips separators and the word "line"
+// so "North-East Line", "north east", and "NEL" compare consistently
+func normalizeLineTerm(term string) string {
+	term = strings.ToLower(strings.TrimSpace(term))
+	term = strings.NewReplacer("-", " ", "_", " ", "mrt", " ").Replace(term)
+	fields := strings.Fields(term)
+	if n := len(fields); n > 1 && fields[n-1] == "line" {
+		fields = fields[:n-1]
+	}
+	return strings.Join(fields, "")
+}
+
+// ResolveMRTLine returns the line matching a code, name, or alias ("NEL", "North East Line",
+// "purple line"), or nil when the term isn't a known line
+func ResolveMRTLine(term string) *MRTLine {
+	key := normalizeLineTerm(term)
+	if key == "" {
+		return nil
+	}
+	for i := range mrtLines {
+		line := &mrtLines[i]
+		if key == normalizeLineTerm(line.Code) || key == normalizeLineTerm(line.Name) {
+			return line
+		}
+		for _, alias := range line.Aliases {
+			if key == normalizeLineTerm(alias) {
+				return line
+			}
+		}
+	}
+	return nil
+}
+
+// MRTLineCodes returns the codes of every known line
+func MRTLineCodes() []string {
+	codes := make([]string, len(mrtLines))
+	for i, line := range mrtLines {
+		codes[i] = line.Code
+	}
+	return codes
+}
+
+// stationLabelRegexp matches the station codes and "MRT"/"LRT" suffix a listing's station
+// label wraps the name in ("NS9/TE2 Woodlands MRT Station"); the repository's
+// mrtStationKeyExpr mirrors it in SQL
+var stationLabelRegexp = regexp.MustCompile(`(?i)^([a-z]{2}[0-9]+[a-z]?[ /]*)+|\s+(mrt|lrt)(\s+station)?\s*$`)
+
+// StationKey returns the lowercased bare station name of a label ("NE6 Dhoby Ghaut MRT" ->
+// "dhoby ghaut"), the form the MRT filters compare exactly
+func StationKey(station string) string {
+	return strings.ToLower(strings.TrimSpace(stationLabelRegexp.ReplaceAllString(strings.TrimSpace(station), "")))
+}
+
+// LinesForStation returns the codes of every line serving a station (interchanges return several)
+func LinesForStation(station string) []string {
+	station = strings.ToLower(strings.TrimSpace(station))
+	var codes []string
+	for _, line := range mrtLines {
+		for _, s := range line.Stations {
+			if strings.ToLower(s) == station {
+				codes = append(codes, line.Code)
+				break
+			}
+		}
+	}
+	return codes
+}
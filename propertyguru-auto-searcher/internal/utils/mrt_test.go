@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveMRTLine(t *testing.T) {
+	tests := []struct {
+		term string
+		want string
+	}{
+		{"NEL", "NEL"},
+		{"North East Line", "NEL"},
+		{"north-east line", "NEL"},
+		{"Circle Line", "CCL"},
+		{"circle", "CCL"},
+		{"purple line", "NEL"},
+		{"Thomson-East Coast Line", "TEL"},
+		{"DTL MRT", "DTL"},
+		{"Hogwarts Line", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			got := ""
+			if line := ResolveMRTLine(tt.term); line != nil {
+				got = line.Code
+			}
+			if got != tt.want {
+				t.Errorf("ResolveMRTLine(%q) = %q, want %q", tt.term, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinesForStation(t *testing.T) {
+	if got, want := LinesForStation("Dhoby Ghaut"), []string{"NSL", "NEL", "CCL"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LinesForStation(Dhoby Ghaut) = %v, want %v", got, want)
+	}
+	if got := LinesForStation("punggol"); !reflect.DeepEqual(got, []string{"NEL"}) {
+		t.Errorf("LinesForStation(punggol) = %v", got)
+	}
+	if got := LinesForStation("Nowhere"); got != nil {
+		t.Errorf("LinesForStation(Nowhere) = %v, want nil", got)
+	}
+}
+
+func TestStationKey(t *testing.T) {
+	tests := map[string]string{
+		"NE6 Dhoby Ghaut MRT":           "dhoby ghaut",
+		"NS9/TE2 Woodlands MRT Station": "woodlands",
+		"TE1 Woodlands North MRT":       "woodlands north",
+		"PE6 Oasis LRT":                 "oasis",
+		" dhoby ghaut ":                 "dhoby ghaut",
+		"DT27 CE1 Bayfront MRT":         "bayfront",
+		"HarbourFront":                  "harbourfront",
+	}
+	for label, want := range tests {
+		if got := StationKey(label); got != want {
+			t.Errorf("StationKey(%q) = %q, want %q", label, got, want)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"regexp"
+)
+
+// piiPatterns match personal data users sometimes type into the search box
+var piiPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), // Email
+	regexp.MustCompile(`(?i)\b[STFGM]\d{7}[A-Z]\b`),                      // NRIC / FIN
+	regexp.MustCompile(`(?:\+65[\s-]?)?\b[689]\d{3}[\s-]?\d{4}\b`),       // Singapore phone number
+	regexp.MustCompile(`\b\d{9,}\b`),                                     // Long digit runs (card, account, or foreign phone numbers)
+}
+
+// ContainsPII reports whether text looks like it contains an email, NRIC/FIN, or phone number
+func ContainsPII(text string) bool {
+	for _, pattern := range piiPatterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package utils
+
+import "testing"
+
+func TestContainsPII(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"3 bedroom condo near MRT under $1.5M", false},
+		{"hdb punggol below 600000", false},
+		{"condo 1500000 budget", false},
+		{"contact me at jane.tan@example.com", true},
+		{"call 9123 4567 for viewing", true},
+		{"+65 81234567 landed", true},
+		{"S1234567D rental", true},
+		{"account 123456789012", true},
+	}
+	for _, tt := range tests {
+		if got := ContainsPII(tt.text); got != tt.want {
+			t.Errorf("ContainsPII(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
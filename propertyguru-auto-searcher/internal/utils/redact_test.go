@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactDSN(t *testing.T) {
+	tests := []struct {
+		dsn  string
+		want string
+	}{
+		{"postgres://user:s3cret@db:5432/app?sslmode=disable", "postgres://user:****@db:5432/app?sslmode=disable"},
+		{"host=db port=5432 user=app password=s3cret dbname=app", "host=db port=5432 user=app password=**** dbname=app"},
+		{"host=db password='with space' dbname=app", "host=db password=**** dbname=app"},
+		{"postgres://db/app", "postgres://db/app"},
+	}
+	for _, tt := range tests {
+		if got := RedactDSN(tt.dsn); got != tt.want {
+			t.Errorf("RedactDSN(%q) = %q, want %q", tt.dsn, got, tt.want)
+		}
+	}
+}
+
+func TestRedactURLQueryParameters(t *testing.T) {
+	got := RedactURL("https://llm.example.com/v1?api_key=sk-123&region=sg")
+	if strings.Contains(got, "sk-123") || !strings.Contains(got, "region=sg") {
+		t.Errorf("RedactURL = %q", got)
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	body := []byte(`{"model":"m","extra_body":{"api_key":"sk-123","nested":[{"Authorization":"Bearer x"}]},"max_tokens":10}`)
+	got := RedactJSON(body)
+	if strings.Contains(got, "sk-123") || strings.Contains(got, "Bearer x") {
+		t.Errorf("RedactJSON leaked a secret: %s", got)
+	}
+	if !strings.Contains(got, `"max_tokens":10`) || !strings.Contains(got, `"model":"m"`) {
+		t.Errorf("RedactJSON masked non-secret fields: %s", got)
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	got := RedactSecrets(`invalid key sk-abc123 (header "Authorization: Bearer sk-abc123")`, "sk-abc123")
+	if strings.Contains(got, "sk-abc123") {
+		t.Errorf("RedactSecrets leaked the key: %s", got)
+	}
+	if got := RedactSecrets("parse postgres://app:pw@db/app: invalid port"); strings.Contains(got, ":pw@") {
+		t.Errorf("RedactSecrets leaked a URL password: %s", got)
+	}
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Canonical unit types used by filters and intent validation
+const (
+	UnitTypeHDB       = "HDB"
+	UnitTypeCondo     = "Condo"
+	UnitTypeLanded    = "Landed"
+	UnitTypeExecutive = "Executive"
+	UnitTypeEC        = "EC"
+)
+
+// CanonicalUnitTypes lists the unit type enum in display order
+var CanonicalUnitTypes = []string{UnitTypeHDB, UnitTypeCondo, UnitTypeLanded, UnitTypeExecutive, UnitTypeEC}
+
+// landedKeywords identify landed property types as scraped from listings
+var landedKeywords = []string{
+	"landed", "terrace", "semi-d", "semi d", "detached", "bungalow",
+	"cluster", "townhouse", "town house", "good class", "shophouse",
+}
+
+// condoKeywords identify private non-landed types
+var condoKeywords = []string{"condo", "apartment", "walk-up", "walkup", "penthouse"}
+
+// hdbFlatTypeRegexp matches an HDB flat type at the start of a value ("3-Room Flat", "4 rooms"),
+// not any mention of a room ("2 Bedroom Condo", "Showroom")
+var hdbFlatTypeRegexp = regexp.MustCompile(`^\d\s*-?\s*rooms?\b`)
+
+// NormalizeUnitType maps a raw unit type ("Condominium", "HDB 4 Rooms", "Semi-Detached House")
+// to the canonical enum. Returns "" when the value can't be classified.
+//
+// Keep in sync with the normalize_unit_type() SQL function in sql/add_unit_type_normalized.sql.
+func NormalizeUnitType(raw string) string {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	if value == "" {
+		return ""
+	}
+
+	// Executive condominiums must be checked before the generic condo/executive rules
+	if value == "ec" || strings.Contains(value, "executive condo") {
+		return UnitTypeEC
+	}
+	if strings.Contains(value, "executive") {
+		return UnitTypeExecutive
+	}
+	if strings.Contains(value, "hdb") {
+		return UnitTypeHDB
+	}
+	for _, keyword := range condoKeywords {
+		if strings.Contains(value, keyword) {
+			return UnitTypeCondo
+		}
+	}
+	for _, keyword := range landedKeywords {
+		if strings.Contains(value, keyword) {
+			return UnitTypeLanded
+		}
+	}
+	// A bare flat type names an HDB flat unless a private type was named above ("2 room condo")
+	if hdbFlatTypeRegexp.MatchString(value) {
+		return UnitTypeHDB
+	}
+
+	return ""
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestNormalizeUnitType(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"Condominium", UnitTypeCondo},
+		{"Condo", UnitTypeCondo},
+		{"Apartment", UnitTypeCondo},
+		{"Walk-up", UnitTypeCondo},
+		{"HDB 4 Rooms", UnitTypeHDB},
+		{"hdb", UnitTypeHDB},
+		{"3-Room Flat", UnitTypeHDB},
+		{"4 Rooms", UnitTypeHDB},
+		{"2 bedroom condo", UnitTypeCondo},
+		{"2 Room Condo", UnitTypeCondo},
+		{"Showroom", ""},
+		{"3 Bedroom", ""},
+		{"HDB Executive", UnitTypeExecutive},
+		{"Executive Maisonette", UnitTypeExecutive},
+		{"Executive Condominium", UnitTypeEC},
+		{"EC", UnitTypeEC},
+		{"Semi-Detached House", UnitTypeLanded},
+		{"Terraced House", UnitTypeLanded},
+		{"Good Class Bungalow", UnitTypeLanded},
+		{"Landed", UnitTypeLanded},
+		{"", ""},
+		{"Office", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := NormalizeUnitType(tt.raw); got != tt.want {
+				t.Errorf("NormalizeUnitType(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
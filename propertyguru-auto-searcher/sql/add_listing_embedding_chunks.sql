@@ -0,0 +1,41 @@
+-- =========================================================
+-- 新增 listing_embedding_chunks：每个房源存储多个分块向量
+-- =========================================================
+-- 用途：长描述整体生成一个向量会丢失细节。描述按 EMBEDDING_CHUNK_SIZE 分块，
+--       每块单独生成向量；向量搜索取每个房源最近的块，再按 VECTOR_CHUNK_AGGREGATE（max/mean）
+--       聚合为房源得分。没有分块的房源仍使用 listing_info.embedding。
+-- 执行方式：psql -U property_user -d property_search -f add_listing_embedding_chunks.sql
+-- 注意：向量维度需与 listing_info.embedding 一致（见 update_embedding_dimension.sql）
+-- =========================================================
+
+\echo '🔄 开始添加 listing_embedding_chunks...'
+
+-- 1. 创建表
+\echo '1️⃣ 创建 listing_embedding_chunks 表...'
+CREATE TABLE IF NOT EXISTS listing_embedding_chunks (
+    listing_id BIGINT NOT NULL,
+    chunk_index INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    embedding vector(1024) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (listing_id, chunk_index),
+
+    CONSTRAINT fk_embedding_chunks_listing
+        FOREIGN KEY (listing_id)
+        REFERENCES listing_info(listing_id)
+        ON DELETE CASCADE
+);
+
+COMMENT ON TABLE listing_embedding_chunks IS '房源分块向量表（搜索引擎写入）';
+COMMENT ON COLUMN listing_embedding_chunks.listing_id IS '关联房源ID';
+COMMENT ON COLUMN listing_embedding_chunks.chunk_index IS '块序号（从0开始）';
+COMMENT ON COLUMN listing_embedding_chunks.content IS '生成该向量的文本块';
+COMMENT ON COLUMN listing_embedding_chunks.embedding IS '文本块向量（维度与 listing_info.embedding 相同）';
+
+-- 2. 向量索引
+\echo '2️⃣ 创建 HNSW 向量索引...'
+CREATE INDEX IF NOT EXISTS idx_embedding_chunks_embedding ON listing_embedding_chunks
+    USING hnsw (embedding vector_cosine_ops);
+
+\echo '✅ listing_embedding_chunks 添加完成'
@@ -0,0 +1,44 @@
+-- =========================================================
+-- 新增 stale_at：标记长期未被爬虫更新的过期房源
+-- =========================================================
+-- 用途：POST /api/v1/admin/listings/purge-stale（mode=mark）将过期房源标记为 stale，
+--       搜索时排除；爬虫再次更新该房源时自动取消标记
+-- 执行方式：psql -U property_user -d property_search -f add_listing_stale_at.sql
+-- =========================================================
+
+\echo '🔄 开始添加 stale_at...'
+
+-- 1. 添加列
+\echo '1️⃣ 添加 stale_at 列...'
+ALTER TABLE listing_info ADD COLUMN IF NOT EXISTS stale_at TIMESTAMP DEFAULT NULL;
+COMMENT ON COLUMN listing_info.stale_at IS '被标记为过期的时间（NULL 表示正常）';
+
+-- 2. 更新 updated_at 触发器
+--    标记过期属于维护操作，不刷新 updated_at；其他更新（如爬虫重新写入）会取消过期标记，
+--    但仅更新 embedding 时保留标记
+\echo '2️⃣ 更新 updated_at 触发器...'
+CREATE OR REPLACE FUNCTION update_listing_info_timestamps()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.stale_at IS NOT NULL AND NEW.stale_at IS DISTINCT FROM OLD.stale_at THEN
+        RETURN NEW;
+    END IF;
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    IF NEW.embedding IS NOT DISTINCT FROM OLD.embedding THEN
+        NEW.stale_at = NULL;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS update_listing_info_updated_at ON listing_info;
+CREATE TRIGGER update_listing_info_updated_at
+    BEFORE UPDATE ON listing_info
+    FOR EACH ROW
+    EXECUTE FUNCTION update_listing_info_timestamps();
+
+-- 3. 索引
+\echo '3️⃣ 创建索引...'
+CREATE INDEX IF NOT EXISTS idx_listing_updated_at ON listing_info (updated_at);
+
+\echo '✅ 完成'
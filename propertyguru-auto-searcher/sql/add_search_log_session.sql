@@ -0,0 +1,20 @@
+-- =========================================================
+-- 新增 search_logs.session_id：按会话记录搜索，用于排除已看过的房源
+-- =========================================================
+-- 用途：搜索请求带 session_id 且 options.exclude_seen=true 时，
+--       排除该会话近期反馈过（点击/联系/查看详情/不感兴趣）的房源
+-- 执行方式：psql -U property_user -d property_search -f add_search_log_session.sql
+-- =========================================================
+
+\echo '🔄 开始添加 session_id...'
+
+-- 1. 添加列
+\echo '1️⃣ 添加 session_id 列...'
+ALTER TABLE search_logs ADD COLUMN IF NOT EXISTS session_id VARCHAR(100);
+COMMENT ON COLUMN search_logs.session_id IS '客户端会话标识（可选，用于 exclude_seen 排除已看过的房源）';
+
+-- 2. 索引
+\echo '2️⃣ 创建索引...'
+CREATE INDEX IF NOT EXISTS idx_search_logs_session_id ON search_logs (session_id, created_at);
+
+\echo '✅ 完成'
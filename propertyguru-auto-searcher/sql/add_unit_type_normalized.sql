@@ -0,0 +1,64 @@
+-- =========================================================
+-- 新增 unit_type_normalized：规范化房源类型
+-- =========================================================
+-- 用途：将爬取的 unit_type（"Condominium"、"HDB 4 Rooms"、"Semi-Detached House" 等）
+--       映射为统一枚举 HDB / Condo / Landed / Executive / EC，供搜索过滤使用
+-- 执行方式：psql -U property_user -d property_search -f add_unit_type_normalized.sql
+-- 存量数据回填：go run ./cmd/normalize-unit-types（规则变更后重新执行本脚本和回填，会修正已写入的值）
+-- 注意：normalize_unit_type() 需与 internal/utils/unit_type.go 中的 NormalizeUnitType 保持一致
+-- =========================================================
+
+\echo '🔄 开始添加 unit_type_normalized...'
+
+-- 1. 添加列
+\echo '1️⃣ 添加 unit_type_normalized 列...'
+ALTER TABLE listing_info ADD COLUMN IF NOT EXISTS unit_type_normalized VARCHAR(20) DEFAULT NULL;
+COMMENT ON COLUMN listing_info.unit_type_normalized IS '规范化单位类型：HDB/Condo/Landed/Executive/EC';
+
+-- 2. 规范化函数
+\echo '2️⃣ 创建 normalize_unit_type 函数...'
+CREATE OR REPLACE FUNCTION normalize_unit_type(raw TEXT)
+RETURNS VARCHAR(20) AS $$
+DECLARE
+    v TEXT := LOWER(TRIM(COALESCE(raw, '')));
+BEGIN
+    IF v = '' THEN
+        RETURN NULL;
+    ELSIF v = 'ec' OR v LIKE '%executive condo%' THEN
+        RETURN 'EC';
+    ELSIF v LIKE '%executive%' THEN
+        RETURN 'Executive';
+    ELSIF v LIKE '%hdb%' THEN
+        RETURN 'HDB';
+    ELSIF v ~ '(condo|apartment|walk-up|walkup|penthouse)' THEN
+        RETURN 'Condo';
+    ELSIF v ~ '(landed|terrace|semi-d|semi d|detached|bungalow|cluster|townhouse|town house|good class|shophouse)' THEN
+        RETURN 'Landed';
+    ELSIF v ~ '^[0-9]\s*-?\s*rooms?\M' THEN
+        RETURN 'HDB';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- 3. 写入时自动规范化（爬虫 upsert 时生效）
+\echo '3️⃣ 创建写入触发器...'
+CREATE OR REPLACE FUNCTION update_unit_type_normalized()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.unit_type_normalized = normalize_unit_type(NEW.unit_type);
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS update_listing_unit_type_normalized ON listing_info;
+CREATE TRIGGER update_listing_unit_type_normalized
+    BEFORE INSERT OR UPDATE OF unit_type ON listing_info
+    FOR EACH ROW
+    EXECUTE FUNCTION update_unit_type_normalized();
+
+-- 4. 索引
+\echo '4️⃣ 创建索引...'
+CREATE INDEX IF NOT EXISTS idx_listing_unit_type_normalized ON listing_info (unit_type_normalized);
+
+\echo '✅ 完成。请运行 go run ./cmd/normalize-unit-types 回填存量数据'
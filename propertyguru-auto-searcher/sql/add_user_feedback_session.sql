@@ -0,0 +1,24 @@
+-- =========================================================
+-- 新增 user_feedback.search_id / session_id：逐条记录每次反馈
+-- =========================================================
+-- 用途：POST /api/v1/feedback 每次点击/联系/查看详情/不感兴趣都写入一行 user_feedback，
+--       exclude_seen 按会话读取这些记录；search_logs.clicked_listing_id 只保留最后一次反馈
+-- 执行方式：psql -U property_user -d property_search -f add_user_feedback_session.sql
+-- 依赖：add_search_log_session.sql（反馈从 search_logs 继承 session_id）
+-- =========================================================
+
+\echo '🔄 开始添加 user_feedback 会话列...'
+
+-- 1. 添加列
+\echo '1️⃣ 添加 search_id / session_id 列...'
+ALTER TABLE user_feedback ADD COLUMN IF NOT EXISTS search_id VARCHAR(64);
+ALTER TABLE user_feedback ADD COLUMN IF NOT EXISTS session_id VARCHAR(100);
+COMMENT ON COLUMN user_feedback.search_id IS '反馈所属的搜索 ID（search_logs.search_id）';
+COMMENT ON COLUMN user_feedback.session_id IS '反馈所属的会话标识（用于 exclude_seen）';
+COMMENT ON COLUMN user_feedback.feedback_type IS '反馈类型：click/contact/view_details/dismiss';
+
+-- 2. 索引
+\echo '2️⃣ 创建索引...'
+CREATE INDEX IF NOT EXISTS idx_user_feedback_session_id ON user_feedback (session_id, created_at);
+
+\echo '✅ 完成'
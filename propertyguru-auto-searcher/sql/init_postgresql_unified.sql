@@ -51,6 +51,7 @@ CREATE TABLE IF NOT EXISTS listing_info (
     bathrooms INTEGER DEFAULT NULL,
     area_sqft DECIMAL(10,2) DEFAULT NULL,
     unit_type VARCHAR(100) DEFAULT NULL,
+    unit_type_normalized VARCHAR(20) DEFAULT NULL,
     tenure VARCHAR(100) DEFAULT NULL,
     build_year INTEGER DEFAULT NULL,
 
@@ -79,6 +80,7 @@ CREATE TABLE IF NOT EXISTS listing_info (
 
     -- 爬虫状态
     is_completed BOOLEAN DEFAULT FALSE,
+    stale_at TIMESTAMP DEFAULT NULL,
 
     -- ========== AI 搜索引擎字段 ==========
     -- 向量嵌入（OpenAI text-embedding-3-small: 1024维）
@@ -103,6 +105,7 @@ COMMENT ON COLUMN listing_info.bedrooms IS '卧室数量';
 COMMENT ON COLUMN listing_info.bathrooms IS '浴室数量';
 COMMENT ON COLUMN listing_info.area_sqft IS '面积 (平方英尺)';
 COMMENT ON COLUMN listing_info.unit_type IS '单位类型，例如 HDB Flat, Condo';
+COMMENT ON COLUMN listing_info.unit_type_normalized IS '规范化单位类型：HDB/Condo/Landed/Executive/EC';
 COMMENT ON COLUMN listing_info.tenure IS '租赁类型';
 COMMENT ON COLUMN listing_info.build_year IS '建造年份';
 COMMENT ON COLUMN listing_info.mrt_station IS '最近地铁站';
@@ -121,6 +124,7 @@ COMMENT ON COLUMN listing_info.description_title IS '描述标题';
 COMMENT ON COLUMN listing_info.amenities IS '便利设施';
 COMMENT ON COLUMN listing_info.facilities IS '公共设施';
 COMMENT ON COLUMN listing_info.is_completed IS '爬虫是否完成';
+COMMENT ON COLUMN listing_info.stale_at IS '被标记为过期的时间（NULL 表示正常）';
 COMMENT ON COLUMN listing_info.embedding IS 'AI向量嵌入（1024维，用于语义搜索）';
 COMMENT ON COLUMN listing_info.search_vector IS '全文搜索向量（自动生成）';
 COMMENT ON COLUMN listing_info.created_at IS '创建时间';
@@ -171,6 +175,7 @@ CREATE TABLE IF NOT EXISTS search_logs (
     id BIGSERIAL PRIMARY KEY,
     query TEXT NOT NULL,
     user_id VARCHAR(100),
+    session_id VARCHAR(100),
 
     -- 解析后的过滤条件（JSONB存储）
     filters JSONB,
@@ -188,6 +193,7 @@ CREATE TABLE IF NOT EXISTS search_logs (
 COMMENT ON TABLE search_logs IS '搜索日志表（搜索引擎写入）';
 COMMENT ON COLUMN search_logs.query IS '用户搜索查询';
 COMMENT ON COLUMN search_logs.user_id IS '用户ID（可选）';
+COMMENT ON COLUMN search_logs.session_id IS '客户端会话标识或匿名会话 Cookie（可选，用于 exclude_seen 和最近搜索）';
 COMMENT ON COLUMN search_logs.filters IS '解析后的过滤条件';
 COMMENT ON COLUMN search_logs.result_count IS '结果数量';
 COMMENT ON COLUMN search_logs.result_ids IS '返回的房源ID列表';
@@ -201,6 +207,8 @@ CREATE TABLE IF NOT EXISTS user_feedback (
     search_log_id BIGINT,
     listing_id BIGINT NOT NULL,
     user_id VARCHAR(100),
+    search_id VARCHAR(64),
+    session_id VARCHAR(100),
 
     -- 反馈类型：click（点击）, like（喜欢）, dislike（不喜欢）
     feedback_type VARCHAR(20) NOT NULL,
@@ -226,18 +234,49 @@ COMMENT ON TABLE user_feedback IS '用户反馈表（搜索引擎写入）';
 COMMENT ON COLUMN user_feedback.search_log_id IS '关联的搜索日志ID';
 COMMENT ON COLUMN user_feedback.listing_id IS '反馈的房源ID';
 COMMENT ON COLUMN user_feedback.user_id IS '用户ID';
+COMMENT ON COLUMN user_feedback.search_id IS '反馈所属的搜索 ID（search_logs.search_id）';
+COMMENT ON COLUMN user_feedback.session_id IS '反馈所属的会话标识（用于 exclude_seen）';
 COMMENT ON COLUMN user_feedback.feedback_type IS '反馈类型：click/like/dislike';
 COMMENT ON COLUMN user_feedback.comment IS '用户评论';
 
+-- 房源分块向量：长描述按块分别生成 embedding，向量搜索取每个房源最近的块
+CREATE TABLE IF NOT EXISTS listing_embedding_chunks (
+    listing_id BIGINT NOT NULL,
+    chunk_index INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    embedding vector(1024) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (listing_id, chunk_index),
+
+    CONSTRAINT fk_embedding_chunks_listing
+        FOREIGN KEY (listing_id)
+        REFERENCES listing_info(listing_id)
+        ON DELETE CASCADE
+);
+
+COMMENT ON TABLE listing_embedding_chunks IS '房源分块向量表（搜索引擎写入）';
+COMMENT ON COLUMN listing_embedding_chunks.listing_id IS '关联房源ID';
+COMMENT ON COLUMN listing_embedding_chunks.chunk_index IS '块序号（从0开始）';
+COMMENT ON COLUMN listing_embedding_chunks.content IS '生成该向量的文本块';
+COMMENT ON COLUMN listing_embedding_chunks.embedding IS '文本块向量（维度与 listing_info.embedding 相同）';
+
 -- =========================================================
 -- 8️⃣ 自动更新触发器
 -- =========================================================
 
 -- 自动更新 updated_at
-CREATE OR REPLACE FUNCTION update_updated_at_column()
+-- 标记过期（stale_at）不刷新 updated_at；其他更新会取消过期标记（仅更新 embedding 时除外）
+CREATE OR REPLACE FUNCTION update_listing_info_timestamps()
 RETURNS TRIGGER AS $$
 BEGIN
+    IF NEW.stale_at IS NOT NULL AND NEW.stale_at IS DISTINCT FROM OLD.stale_at THEN
+        RETURN NEW;
+    END IF;
     NEW.updated_at = CURRENT_TIMESTAMP;
+    IF NEW.embedding IS NOT DISTINCT FROM OLD.embedding THEN
+        NEW.stale_at = NULL;
+    END IF;
     RETURN NEW;
 END;
 $$ language 'plpgsql';
@@ -246,7 +285,7 @@ DROP TRIGGER IF EXISTS update_listing_info_updated_at ON listing_info;
 CREATE TRIGGER update_listing_info_updated_at
     BEFORE UPDATE ON listing_info
     FOR EACH ROW
-    EXECUTE FUNCTION update_updated_at_column();
+    EXECUTE FUNCTION update_listing_info_timestamps();
 
 -- 自动更新全文搜索向量
 CREATE OR REPLACE FUNCTION update_search_vector()
@@ -268,6 +307,45 @@ CREATE TRIGGER update_listing_search_vector
     FOR EACH ROW
     EXECUTE FUNCTION update_search_vector();
 
+-- 自动规范化 unit_type（与 internal/utils/unit_type.go 保持一致）
+CREATE OR REPLACE FUNCTION normalize_unit_type(raw TEXT)
+RETURNS VARCHAR(20) AS $$
+DECLARE
+    v TEXT := LOWER(TRIM(COALESCE(raw, '')));
+BEGIN
+    IF v = '' THEN
+        RETURN NULL;
+    ELSIF v = 'ec' OR v LIKE '%executive condo%' THEN
+        RETURN 'EC';
+    ELSIF v LIKE '%executive%' THEN
+        RETURN 'Executive';
+    ELSIF v LIKE '%hdb%' THEN
+        RETURN 'HDB';
+    ELSIF v ~ '(condo|apartment|walk-up|walkup|penthouse)' THEN
+        RETURN 'Condo';
+    ELSIF v ~ '(landed|terrace|semi-d|semi d|detached|bungalow|cluster|townhouse|town house|good class|shophouse)' THEN
+        RETURN 'Landed';
+    ELSIF v ~ '^[0-9]\s*-?\s*rooms?\M' THEN
+        RETURN 'HDB';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION update_unit_type_normalized()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.unit_type_normalized = normalize_unit_type(NEW.unit_type);
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS update_listing_unit_type_normalized ON listing_info;
+CREATE TRIGGER update_listing_unit_type_normalized
+    BEFORE INSERT OR UPDATE OF unit_type ON listing_info
+    FOR EACH ROW
+    EXECUTE FUNCTION update_unit_type_normalized();
+
 -- =========================================================
 -- 9️⃣ 创建索引
 -- =========================================================
@@ -279,7 +357,9 @@ CREATE INDEX IF NOT EXISTS idx_listing_price ON listing_info (price);
 CREATE INDEX IF NOT EXISTS idx_listing_bedrooms ON listing_info (bedrooms);
 CREATE INDEX IF NOT EXISTS idx_listing_bathrooms ON listing_info (bathrooms);
 CREATE INDEX IF NOT EXISTS idx_listing_unit_type ON listing_info (unit_type);
+CREATE INDEX IF NOT EXISTS idx_listing_unit_type_normalized ON listing_info (unit_type_normalized);
 CREATE INDEX IF NOT EXISTS idx_listing_created_at ON listing_info (created_at);
+CREATE INDEX IF NOT EXISTS idx_listing_updated_at ON listing_info (updated_at);
 
 -- 地理坐标索引
 CREATE INDEX IF NOT EXISTS idx_listing_latitude ON listing_info(latitude);
@@ -295,6 +375,8 @@ CREATE INDEX IF NOT EXISTS idx_listing_facilities ON listing_info USING GIN (fac
 -- 向量相似度搜索索引（HNSW 算法，速度快）
 CREATE INDEX IF NOT EXISTS idx_listing_embedding ON listing_info
     USING hnsw (embedding vector_cosine_ops);
+CREATE INDEX IF NOT EXISTS idx_embedding_chunks_embedding ON listing_embedding_chunks
+    USING hnsw (embedding vector_cosine_ops);
 
 -- 全文搜索索引
 CREATE INDEX IF NOT EXISTS idx_listing_search_vector ON listing_info
@@ -308,6 +390,7 @@ CREATE INDEX IF NOT EXISTS idx_media_watermark ON listing_media (watermark_remov
 -- search_logs 索引
 CREATE INDEX IF NOT EXISTS idx_search_logs_created_at ON search_logs (created_at);
 CREATE INDEX IF NOT EXISTS idx_search_logs_user_id ON search_logs (user_id);
+CREATE INDEX IF NOT EXISTS idx_search_logs_session_id ON search_logs (session_id, created_at);
 CREATE INDEX IF NOT EXISTS idx_search_logs_filters ON search_logs USING GIN (filters);
 
 -- user_feedback 索引
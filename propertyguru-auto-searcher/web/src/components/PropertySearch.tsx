@@ -29,6 +29,7 @@ interface Property {
   title: string;
   price?: number;
   price_per_sqft?: number;
+  price_per_sqft_derived?: boolean;
   bedrooms?: number;
   bathrooms?: number;
   area_sqft?: number;
@@ -302,6 +303,7 @@ const PropertySearch: React.FC = () => {
             S${property.price?.toLocaleString() || "N/A"}
           </Descriptions.Item>
           <Descriptions.Item itemKey="Per sqft">
+            {property.price_per_sqft_derived ? "≈ " : ""}
             S${property.price_per_sqft?.toLocaleString() || "N/A"}/sqft
           </Descriptions.Item>
         </Descriptions>
@@ -117,7 +117,7 @@ function displayResults(data) {
 // Create listing card HTML
 function createListingCard(listing) {
     const price = listing.price ? `S$${listing.price.toLocaleString()}` : 'Price on request';
-    const pricePerSqft = listing.price_per_sqft ? `S$${listing.price_per_sqft.toFixed(0)}/sqft` : '';
+    const pricePerSqft = listing.price_per_sqft ? `${listing.price_per_sqft_derived ? '≈ ' : ''}S$${listing.price_per_sqft.toFixed(0)}/sqft` : '';
     const bedrooms = listing.bedrooms || '-';
     const bathrooms = listing.bathrooms || '-';
     const area = listing.area_sqft ? `${listing.area_sqft.toFixed(0)} sqft` : '-';